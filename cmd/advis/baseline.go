@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/baseline"
+	"github.com/s-archdev/Terminal_ADVIS/internal/config"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/proc"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/sysstat"
+)
+
+// runBaseline records the host at rest as the baseline the monitors
+// highlight drift from, or shows or removes the one recorded
+func runBaseline(name string, args []string) error {
+	fs := flag.NewFlagSet(name+" baseline", flag.ExitOnError)
+	window := fs.Duration("for", 10*time.Second, "how long to measure CPU use and idle traffic over")
+	show := fs.Bool("show", false, "print the recorded baseline instead of recording one")
+	remove := fs.Bool("clear", false, "remove the recorded baseline")
+	if err := config.SetFlagsFromEnv(fs); err != nil {
+		return err
+	}
+	fs.Parse(args)
+
+	switch {
+	case *remove:
+		if err := baseline.Remove(); err != nil {
+			return err
+		}
+		fmt.Println("Removed", baseline.Path())
+		return nil
+	case *show:
+		b, err := baseline.Load(baseline.Path())
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			return fmt.Errorf("no baseline recorded; record one with: %s baseline", name)
+		}
+		printBaseline(b)
+		return nil
+	}
+	if *window < time.Second {
+		return fmt.Errorf("-for %s is below a second", *window)
+	}
+
+	fmt.Printf("Measuring for %s; leave the host at rest...\n", *window)
+	cpus := &sysstat.CPUSampler{}
+	procs := &proc.Sampler{}
+	cpus.Sample()
+	procs.Sample()
+	before := netstat.Interfaces()
+	start := time.Now()
+	time.Sleep(*window)
+
+	b := baseline.Baseline{Taken: time.Now(), Traffic: make(map[string]baseline.Rate)}
+	b.Host, _ = os.Hostname()
+	b.CPU, _ = cpus.Sample()
+	b.MemUsed = sysstat.ReadInfo().MemUsed
+	for _, p := range procs.Sample() {
+		b.Processes = append(b.Processes, p.Name)
+	}
+	elapsed := time.Since(start).Seconds()
+	for _, c := range netstat.Interfaces() {
+		for _, p := range before {
+			if p.Name == c.Name && c.RxBytes >= p.RxBytes && c.TxBytes >= p.TxBytes {
+				b.Traffic[c.Name] = baseline.Rate{
+					Down: float64(c.RxBytes-p.RxBytes) / elapsed,
+					Up:   float64(c.TxBytes-p.TxBytes) / elapsed,
+				}
+			}
+		}
+	}
+	conns, err := netstat.Connections()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: listeners not recorded: %v\n", err)
+	}
+	for _, c := range conns {
+		if baseline.Listening(c) {
+			b.Listeners = append(b.Listeners, baseline.Listener(c.Protocol, c.LocalAddr))
+		}
+	}
+	if err := baseline.Save(b); err != nil {
+		return err
+	}
+	saved, err := baseline.Load(baseline.Path())
+	if err != nil {
+		return err
+	}
+	printBaseline(saved)
+	fmt.Printf("\nSaved to %s. The monitors now highlight what is %.0f%% above it or was not\n", baseline.Path(), baseline.Tolerance())
+	fmt.Println("there; see -baseline-tolerance and -no-baseline.")
+	return nil
+}
+
+// printBaseline lists a baseline
+func printBaseline(b *baseline.Baseline) {
+	fmt.Printf("Baseline of %s, %s\n\n", b.Host, b.Taken.Format("2006-01-02 15:04"))
+	fmt.Printf("CPU %.1f%%, memory %s\n\n", b.CPU, ui.FormatBytes(b.MemUsed))
+	fmt.Printf("%-16s %-12s %s\n", "INTERFACE", "IDLE DOWN", "IDLE UP")
+	for _, name := range slices.Sorted(maps.Keys(b.Traffic)) {
+		r := b.Traffic[name]
+		fmt.Printf("%-16s %-12s %s\n", name, ui.FormatBytes(uint64(r.Down))+"/s", ui.FormatBytes(uint64(r.Up))+"/s")
+	}
+	fmt.Printf("\nListening (%d): %s\n", len(b.Listeners), strings.Join(b.Listeners, ", "))
+	fmt.Printf("Processes (%d): %s\n", len(b.Processes), strings.Join(b.Processes, ", "))
+}
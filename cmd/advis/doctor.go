@@ -0,0 +1,275 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+	"github.com/s-archdev/Terminal_ADVIS/internal/caps"
+	"github.com/s-archdev/Terminal_ADVIS/internal/config"
+	"github.com/s-archdev/Terminal_ADVIS/internal/plugins"
+	"github.com/s-archdev/Terminal_ADVIS/internal/rules"
+)
+
+// check is one line of the doctor report: whether something this host
+// provides is there, what it enables and what to do when it is not
+type check struct {
+	name     string
+	ok       bool
+	optional bool   // Missing only loses an extra, not a core view
+	enables  string // Features depending on it
+	hint     string // Shown when it is missing
+}
+
+// runDoctor prints which features will and will not work on this host
+// and why, so an empty panel can be explained without reading the code
+func runDoctor(name string, args []string) error {
+	fs := flag.NewFlagSet(name+" doctor", flag.ExitOnError)
+	shared := []*flag.FlagSet{config.Flags, plugins.Flags, rules.Flags}
+	for _, set := range shared {
+		set.VisitAll(func(f *flag.Flag) {
+			fs.Var(f.Value, f.Name, f.Usage)
+		})
+	}
+	if err := config.SetFlagsFromEnv(fs, shared...); err != nil {
+		return err
+	}
+	fs.Parse(args)
+
+	host := caps.Get()
+	missing := 0
+	section := func(title string, checks []check) {
+		fmt.Println(title)
+		for _, c := range checks {
+			mark := "✓"
+			switch {
+			case !c.ok && c.optional:
+				mark = "-"
+			case !c.ok:
+				mark = "✗"
+				missing++
+			}
+			fmt.Printf("  %s %-22s %s\n", mark, c.name, c.enables)
+			if !c.ok && c.hint != "" {
+				fmt.Printf("    %s\n", c.hint)
+			}
+		}
+		fmt.Println()
+	}
+
+	section("Permissions", []check{
+		{name: "root", ok: host.Root, optional: true,
+			enables: "everything below without further setup"},
+		{name: "CAP_SYS_PTRACE", ok: host.OtherProcesses(),
+			enables: "I/O and file descriptors of other users' processes",
+			hint:    "run as root, or grant it with: advis setcap -apply -group <group>"},
+	})
+
+	if host.Android {
+		// What the reduced-feature mode leaves out; the interfaces below that
+		// Android lacks altogether are extras there
+		section("Android", []check{
+			{name: "/proc/stat", ok: !host.NoCPU,
+				enables: "CPU usage",
+				hint:    "Android 8 and later keep it from apps; run as root, such as with tsu"},
+			{name: "socket tables", ok: !host.NoSockets,
+				enables: "Connections tab and top talkers",
+				hint:    "Android 10 and later keep /proc/net from apps; run as root, such as with tsu"},
+			{name: "other apps", ok: host.Root, optional: true,
+				enables: "processes of other apps",
+				hint:    "without root Android lists only this app's processes"},
+		})
+	}
+
+	section("Kernel interfaces", []check{
+		{name: "/proc", ok: host.Procfs,
+			enables: "processes, CPU, memory, mounts, network counters and sockets",
+			hint:    "mount procfs: mount -t proc proc /proc"},
+		{name: "/sys", ok: host.Sysfs, optional: host.Android,
+			enables: "NUMA, hugepages, sensors and throttling",
+			hint:    "mount sysfs: mount -t sysfs sysfs /sys"},
+		{name: "cgroup v2", ok: exists("/sys/fs/cgroup/cgroup.controllers"), optional: host.Android,
+			enables: "Cgroups tab and container resource usage",
+			hint:    "boot with systemd.unified_cgroup_hierarchy=1"},
+		{name: "/dev/kmsg", ok: host.Kmsg, optional: host.Android,
+			enables: "OOM kills, I/O errors and thermal events on the Kernel tab",
+			hint:    "run as root or set kernel.dmesg_restrict=0"},
+		{name: "system D-Bus", ok: host.SystemBus, optional: host.Android,
+			enables: "systemd services and their actions",
+			hint:    "needs systemd with dbus or dbus-broker running"},
+	})
+
+	containers := check{name: "container socket", ok: host.ContainerAccess, optional: true,
+		enables: "Containers tab"}
+	switch {
+	case host.ContainerSocket == "":
+		containers.hint = "no Docker or Podman socket found; set DOCKER_HOST=unix://<path>"
+	case !host.ContainerAccess:
+		containers.hint = "cannot connect to " + host.ContainerSocket + "; run as root or join its group"
+	}
+	section("Optional tools", []check{
+		containers,
+		binary("journalctl", "log pane and unit logs"),
+		binary("virsh", "virtual machines on the Containers tab"),
+		binary("zpool", "ZFS pool health on the Disk tab"),
+		binary("btrfs", "btrfs device errors and scrubs on the Disk tab"),
+		binary("vcgencmd", "Raspberry Pi throttling"),
+	})
+
+	section("Configuration", []check{
+		configCheck(),
+		rulesCheck(rules.Flags.Lookup("rules").Value.String()),
+		pluginsCheck(plugins.Flags.Lookup("plugins").Value.String()),
+	})
+
+	if services := serviceChecks(); len(services) > 0 {
+		section("Services", services)
+	}
+
+	section("Terminal", terminalChecks())
+
+	if missing > 0 {
+		fmt.Printf("%d required check(s) failed; the features they list will be empty or annotated.\n", missing)
+	} else {
+		fmt.Println("Everything advis needs is available.")
+	}
+	return nil
+}
+
+// binary checks for an optional executable on PATH
+func binary(name, enables string) check {
+	path, err := exec.LookPath(name)
+	c := check{name: name, ok: err == nil, optional: true, enables: enables}
+	if err == nil {
+		c.enables += " (" + path + ")"
+	} else {
+		c.hint = "not found on PATH"
+	}
+	return c
+}
+
+func configCheck() check {
+	c := check{name: "config file", ok: true, enables: "key bindings, tabs, palette, icons, number locale, interfaces, bar thresholds, alert notifications, dashboard layouts and profiles"}
+	switch _, err := loadConfig(); {
+	case err != nil:
+		c.ok, c.hint = false, err.Error()
+	case !exists(config.Path()):
+		c.ok, c.optional = false, true
+		c.hint = "none at " + config.Path()
+	default:
+		c.enables += " (" + config.Path() + ")"
+	}
+	return c
+}
+
+func rulesCheck(path string) check {
+	c := check{name: "rules script", ok: true, enables: "derived metrics and alerts"}
+	switch r, err := rules.Load(path); {
+	case err != nil:
+		c.ok, c.hint = false, err.Error()
+	case r == nil:
+		c.ok, c.optional = false, true
+		c.hint = "none at " + path
+	default:
+		c.enables += " (" + path + ")"
+	}
+	return c
+}
+
+func pluginsCheck(dir string) check {
+	c := check{name: "plugins", ok: true, enables: "plugin panels"}
+	switch found, err := plugins.Discover(dir); {
+	case err != nil:
+		c.ok, c.hint = false, err.Error()
+	case len(found) == 0:
+		c.ok, c.optional = false, true
+		c.hint = "no executables in " + dir
+	default:
+		c.enables += fmt.Sprintf(" (%d in %s)", len(found), dir)
+	}
+	return c
+}
+
+// serviceChecks look at the units "advis install" wrote, whose sandbox
+// must let the alerting channels of the service's configuration through
+func serviceChecks() []check {
+	units, _ := filepath.Glob("/etc/systemd/system/advis-*.service")
+	var checks []check
+	for _, path := range units {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		c := check{name: filepath.Base(path), ok: true, enables: "alerts sent by the service"}
+		for line := range strings.Lines(string(data)) {
+			families, ok := strings.CutPrefix(strings.TrimSpace(line), "RestrictAddressFamilies=")
+			if ok && !strings.Contains(families, "AF_INET") && serviceAlerts() {
+				c.ok = false
+				c.hint = "the unit keeps the network from its alerting channels; write it again with: advis install"
+			}
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// terminalChecks look at what the monitors draw with: a terminal to draw
+// on, colors, box-drawing and emoji glyphs and OSC 52 copying
+func terminalChecks() []check {
+	tty := term.IsTerminal(os.Stdout.Fd())
+	checks := []check{{name: "terminal", ok: tty,
+		enables: "the interactive monitors",
+		hint:    "stdout is not a terminal; snapshot and report still work"}}
+	if tty {
+		if w, h, err := term.GetSize(os.Stdout.Fd()); err == nil {
+			checks[0].enables += fmt.Sprintf(" (%dx%d)", w, h)
+			if w < 100 || h < 30 {
+				checks = append(checks, check{name: "size", optional: true,
+					enables: "full-width tables", hint: "tables are designed for at least 100x30"})
+			}
+		}
+	}
+
+	profile := lipgloss.ColorProfile()
+	colors := map[termenv.Profile]string{
+		termenv.TrueColor: "24-bit", termenv.ANSI256: "256", termenv.ANSI: "16", termenv.Ascii: "no",
+	}[profile]
+	checks = append(checks, check{name: "colors", ok: profile <= termenv.ANSI256, optional: true,
+		enables: colors + " colors for bars and heat maps",
+		hint:    "set COLORTERM=truecolor or TERM=xterm-256color if the terminal supports more"})
+
+	locale := firstEnv("LC_ALL", "LC_CTYPE", "LANG")
+	utf8 := strings.Contains(strings.ToUpper(strings.ReplaceAll(locale, "-", "")), "UTF8")
+	checks = append(checks, check{name: "UTF-8", ok: utf8,
+		enables: "bars, borders and icons",
+		hint:    fmt.Sprintf("locale is %q; set LANG to a UTF-8 locale such as C.UTF-8", locale)})
+
+	clipboard := check{name: "clipboard", ok: true, enables: "y/Y copy via OSC 52"}
+	if os.Getenv("TMUX") != "" {
+		clipboard.optional = true
+		clipboard.hint = "inside tmux, copying needs: set -g set-clipboard on"
+		clipboard.ok = false
+	}
+	return append(checks, clipboard)
+}
+
+// firstEnv is the first of the variables that is set
+func firstEnv(names ...string) string {
+	for _, n := range names {
+		if v := os.Getenv(n); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// i3Block is a block of the i3bar mode: a status format and the one a
+// left click swaps it to, if any
+type i3Block struct {
+	format, alt string
+	urgent      func(statusSample) bool // Highlighted by the bar
+}
+
+var i3Blocks = map[string]i3Block{
+	"cpu":  {format: "CPU %cpu", alt: "LOAD %load", urgent: func(s statusSample) bool { return s.cpu >= 90 }},
+	"mem":  {format: "MEM %mem", alt: "MEM %memused", urgent: memoryPressure},
+	"net":  {format: "↓%down ↑%up"},
+	"host": {format: "%host"},
+}
+
+// memoryPressure reports memory at least 90% used
+func memoryPressure(s statusSample) bool {
+	return s.info.MemTotal > 0 && float64(s.info.MemUsed) >= 0.9*float64(s.info.MemTotal)
+}
+
+// i3Status is one block as the i3bar protocol sends it
+type i3Status struct {
+	Name     string `json:"name"`
+	FullText string `json:"full_text"`
+	Urgent   bool   `json:"urgent,omitempty"`
+}
+
+// i3Click is the part of a click event the blocks act on
+type i3Click struct {
+	Name   string `json:"name"`
+	Button int    `json:"button"`
+}
+
+// checkI3Blocks reports the first unknown block of names
+func checkI3Blocks(names []string) error {
+	for _, name := range names {
+		if _, ok := i3Blocks[name]; !ok {
+			return fmt.Errorf("unknown i3bar block %q (known: cpu, mem, net, host)", name)
+		}
+	}
+	return nil
+}
+
+// runI3bar speaks the i3bar protocol on stdout until the bar closes it,
+// sampling every interval and right after a click. A left click swaps a
+// block between its figures; a right click runs onClick, if given, with
+// the block's name in ADVIS_BLOCK, such as to open advis in a terminal.
+func runI3bar(s *statusSampler, names []string, interval time.Duration, onClick string) error {
+	clicks := make(chan i3Click)
+	go readI3Clicks(os.Stdin, clicks)
+
+	out := json.NewEncoder(os.Stdout)
+	if err := out.Encode(map[string]any{"version": 1, "click_events": true}); err != nil {
+		return err
+	}
+	if _, err := fmt.Println("["); err != nil {
+		return err
+	}
+
+	alt := make(map[string]bool)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		sample := s.sample()
+		blocks := make([]i3Status, len(names))
+		for i, name := range names {
+			b := i3Blocks[name]
+			format := b.format
+			if alt[name] {
+				format = b.alt
+			}
+			blocks[i] = i3Status{Name: name, FullText: formatStatus(format, sample)}
+			if b.urgent != nil {
+				blocks[i].Urgent = b.urgent(sample)
+			}
+		}
+		line, err := json.Marshal(blocks)
+		if err != nil {
+			return err
+		}
+		// The bar stops reading when it exits, ending the mode
+		if _, err := fmt.Printf("%s,\n", line); err != nil {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case c := <-clicks:
+			switch {
+			case c.Button == 1 && i3Blocks[c.Name].alt != "":
+				alt[c.Name] = !alt[c.Name]
+			case c.Button == 3 && onClick != "":
+				cmd := exec.Command("sh", "-c", onClick)
+				cmd.Env = append(os.Environ(), "ADVIS_BLOCK="+c.Name)
+				if err := cmd.Start(); err == nil {
+					go cmd.Wait()
+				}
+			}
+		}
+	}
+}
+
+// readI3Clicks decodes the endless JSON array of click events the bar
+// writes on r, one event per line after the opening bracket
+func readI3Clicks(r io.Reader, clicks chan<- i3Click) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimLeft(strings.TrimSpace(scanner.Text()), "[,")
+		var c i3Click
+		if line == "" || json.Unmarshal([]byte(line), &c) != nil {
+			continue
+		}
+		clicks <- c
+	}
+}
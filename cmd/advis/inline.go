@@ -0,0 +1,104 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/budget"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// minInlineHeight fits one row of panels and the hint line
+const minInlineHeight = 7
+
+// inline runs monitors without the alternate screen, drawing only their
+// dashboard panels in a fixed number of lines below the shell prompt.
+// Commands run in another terminal pane scroll above it, and the last
+// frame stays in the scrollback on exit.
+type inline struct {
+	monitors []tea.Model
+	width    int
+	height   int
+	layout   *dashboardLayout
+}
+
+// newInline tells the monitors they only feed the dashboard, so they skip
+// collecting what their full views need
+func newInline(monitors []tea.Model, height int) inline {
+	in := inline{monitors: monitors, height: max(height, minInlineHeight), layout: &dashboardLayout{}}
+	for i, m := range in.monitors {
+		_, paneler := m.(ui.Paneler)
+		in.monitors[i], _ = m.Update(ui.VisibilityMsg{Dashboard: paneler})
+	}
+	return in
+}
+
+// hasPanels reports whether any of the monitors draws dashboard panels
+func hasPanels(monitors []tea.Model) bool {
+	return slices.ContainsFunc(monitors, func(m tea.Model) bool {
+		_, ok := m.(ui.Paneler)
+		return ok
+	})
+}
+
+func (in inline) Init() tea.Cmd {
+	cmds := make([]tea.Cmd, len(in.monitors))
+	for i, m := range in.monitors {
+		cmds[i] = m.Init()
+	}
+	return tea.Batch(cmds...)
+}
+
+func (in inline) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		in.width = msg.Width
+		msg.Height = in.height
+	case tea.KeyMsg:
+		// The monitors' keys act on views that are not drawn
+		if key.Matches(msg, switcherKeys.Quit) {
+			return in, tea.Quit
+		}
+		return in, nil
+	case tea.MouseMsg, ui.ClipboardMsg:
+		return in, nil
+	}
+	var cmds []tea.Cmd
+	for i, m := range in.monitors {
+		var cmd tea.Cmd
+		in.monitors[i], cmd = m.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	return in, tea.Batch(cmds...)
+}
+
+func (in inline) View() string {
+	if in.width == 0 {
+		return "Initializing..."
+	}
+	var panels []ui.Panel
+	for _, m := range in.monitors {
+		if p, ok := m.(ui.Paneler); ok {
+			panels = append(panels, p.Panels(ui.PanelWidth(in.width))...)
+		}
+	}
+	l := in.layout
+	if l.out == "" || l.width != in.width || !slices.Equal(l.panels, panels) {
+		l.panels, l.width = panels, in.width
+		lines := strings.Split(ui.Dashboard(panels, in.width, in.height-1), "\n")
+		// Panels keep a minimum height, so a short inline area cuts them;
+		// padding the rest keeps the area from jumping as panels change
+		lines = lines[:min(len(lines), in.height-1)]
+		for len(lines) < in.height-1 {
+			lines = append(lines, "")
+		}
+		l.out = strings.Join(lines, "\n")
+	}
+	hint := ui.HelpLine(switcherKeys.Quit)
+	if usage := budget.Status(); usage != "" {
+		hint += " | " + usage
+	}
+	return l.out + "\n" + switcherStyle.Render(hint)
+}
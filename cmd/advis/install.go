@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/config"
+)
+
+// installModes are what "advis install" can set up, by -mode, as the
+// arguments the service runs advis with
+var installModes = map[string]func(every time.Duration, api string) []string{
+	// Adds a snapshot to the history every interval, for "advis report"
+	// and the history views to read, and serves the REST API with -api
+	"exporter": func(every time.Duration, api string) []string {
+		args := []string{"snapshot", "-every", every.String(), "-quiet"}
+		if api != "" {
+			args = append(args, "-api", api)
+		}
+		return args
+	},
+}
+
+// serviceConfig is the configuration file the service reads, in the
+// unit's configuration directory: the made-up user cannot read the one in
+// the installing user's home
+const serviceConfig = "/etc/advis/config.json"
+
+// unitTemplate is the systemd unit of a service. It runs as a user made up
+// for it, whose state directory holds the history, with the two
+// capabilities reading other users' /proc entries takes: the open file
+// counts and I/O of their processes need CAP_SYS_PTRACE, and mounts below
+// directories it cannot search CAP_DAC_READ_SEARCH. Nothing else is
+// granted, and the rest of the system is read-only to it.
+var unitTemplate = template.Must(template.New("unit").Parse(`# Written by "advis install -mode {{.Mode}}"
+[Unit]
+Description=advis {{.Mode}}
+Documentation=https://github.com/s-archdev/Terminal_ADVIS
+After=local-fs.target
+
+[Service]
+Type=simple
+# Alerting, listen, history and the other settings are read from
+# {{.Config}}, or may be given here as ADVIS_CONFIG_ variables,
+# such as Environment='ADVIS_CONFIG_HISTORY={"raw":"48h"}'
+ExecStart={{.Command}}
+Restart=on-failure
+RestartSec=10s
+
+DynamicUser=yes
+StateDirectory=advis
+ConfigurationDirectory=advis
+Environment=XDG_STATE_HOME=%S
+AmbientCapabilities=CAP_SYS_PTRACE CAP_DAC_READ_SEARCH
+CapabilityBoundingSet=CAP_SYS_PTRACE CAP_DAC_READ_SEARCH
+NoNewPrivileges=yes
+
+ProtectSystem=strict
+ProtectHome=read-only
+PrivateTmp=yes
+PrivateDevices=yes
+ProtectClock=yes
+ProtectHostname=yes
+ProtectKernelLogs=yes
+ProtectKernelModules=yes
+ProtectKernelTunables=yes
+ProtectControlGroups=yes
+RestrictAddressFamilies={{.Families}}
+RestrictNamespaces=yes
+RestrictRealtime=yes
+RestrictSUIDSGID=yes
+LockPersonality=yes
+MemoryDenyWriteExecute=yes
+SystemCallArchitectures=native
+SystemCallFilter=@system-service
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+// runInstall writes the systemd unit of a headless mode and enables it,
+// so collecting history for the long term is one command
+func runInstall(name string, args []string) error {
+	fs := flag.NewFlagSet(name+" install", flag.ExitOnError)
+	mode := fs.String("mode", "exporter", "service to install: "+strings.Join(slices.Sorted(maps.Keys(installModes)), ", "))
+	every := fs.Duration("every", time.Minute, "time between snapshots")
+	api := fs.String("api", "", `also serve the REST API on this address, such as ":9273"; see the config file's "listen"`)
+	exe := fs.String("exe", "", "advis binary the service runs (default this one)")
+	unitDir := fs.String("unit-dir", "/etc/systemd/system", "directory the unit is written to")
+	printUnit := fs.Bool("print", false, "print the unit instead of installing it")
+	enable := fs.Bool("enable", true, "reload systemd and enable and start the unit once written")
+	if err := config.SetFlagsFromEnv(fs); err != nil {
+		return err
+	}
+	fs.Parse(args)
+
+	command, ok := installModes[*mode]
+	if !ok {
+		return fmt.Errorf("unknown mode %q", *mode)
+	}
+	if *every < time.Second {
+		return fmt.Errorf("-every %s is below a second", *every)
+	}
+	if *exe == "" {
+		path, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		*exe = path
+	}
+	path, err := filepath.Abs(*exe)
+	if err != nil {
+		return err
+	}
+	// The made-up user cannot enter home directories, nor go run's cache
+	home, err := os.UserHomeDir()
+	if err == nil && strings.HasPrefix(path, home+string(filepath.Separator)) || strings.HasPrefix(path, os.TempDir()) {
+		fmt.Fprintf(os.Stderr, "warning: the service cannot run %s; install advis to /usr/local/bin or give -exe\n", path)
+	}
+
+	var unit bytes.Buffer
+	families := "AF_UNIX AF_NETLINK"
+	if *api != "" || serviceAlerts() {
+		families += " AF_INET AF_INET6"
+	}
+	argv := append([]string{path}, command(*every, *api)...)
+	argv = append(argv, "-config", serviceConfig)
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = systemdQuote(arg)
+	}
+	err = unitTemplate.Execute(&unit, struct{ Mode, Command, Config, Families string }{
+		Mode:     *mode,
+		Command:  strings.Join(quoted, " "),
+		Config:   serviceConfig,
+		Families: families,
+	})
+	if err != nil {
+		return err
+	}
+	if *printUnit {
+		_, err := os.Stdout.Write(unit.Bytes())
+		return err
+	}
+	if runtime.GOOS != "linux" {
+		return errors.New("services are installed as systemd units, which need Linux; see -print")
+	}
+
+	unitName := "advis-" + *mode + ".service"
+	unitPath := filepath.Join(*unitDir, unitName)
+	if err := os.WriteFile(unitPath, unit.Bytes(), 0o644); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf("%w; run it as root, such as with sudo", err)
+		}
+		return err
+	}
+	fmt.Println("Wrote", unitPath)
+	if _, err := os.Stat(serviceConfig); errors.Is(err, os.ErrNotExist) {
+		fmt.Printf("The service reads its settings from %s; copy %s there to keep them\n", serviceConfig, config.Path())
+	}
+	if !*enable {
+		fmt.Printf("Start it with: systemctl daemon-reload && systemctl enable --now %s\n", unitName)
+		return nil
+	}
+	for _, cmd := range [][]string{{"daemon-reload"}, {"enable", "--now", unitName}} {
+		c := exec.Command("systemctl", cmd...)
+		c.Stdout, c.Stderr = os.Stdout, os.Stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("systemctl %s: %w", strings.Join(cmd, " "), err)
+		}
+	}
+	fmt.Printf("Enabled and started %s; see its log with: journalctl -u %s\n", unitName, unitName)
+	return nil
+}
+
+// serviceAlerts reports whether the configuration the service will read,
+// the one in place or else this user's, which is copied there, sends
+// alerts over the network. One that does not load may well, once fixed.
+func serviceAlerts() bool {
+	path := serviceConfig
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		path = config.Path()
+	}
+	cfg, err := config.Load(path)
+	return err != nil || cfg.Alerting.Remote()
+}
+
+// systemdQuote quotes an argument of ExecStart, so that spaces do not split
+// it and systemd expands neither specifiers nor variables in it
+func systemdQuote(arg string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "%", "%%", "$", "$$")
+	return `"` + r.Replace(arg) + `"`
+}
@@ -0,0 +1,670 @@
+// Command advis is a terminal dashboard for system and network monitoring.
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/s-archdev/Terminal_ADVIS/internal/alerting"
+	"github.com/s-archdev/Terminal_ADVIS/internal/baseline"
+	"github.com/s-archdev/Terminal_ADVIS/internal/budget"
+	"github.com/s-archdev/Terminal_ADVIS/internal/caps"
+	"github.com/s-archdev/Terminal_ADVIS/internal/config"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+	"github.com/s-archdev/Terminal_ADVIS/internal/demo"
+	"github.com/s-archdev/Terminal_ADVIS/internal/netmon"
+	"github.com/s-archdev/Terminal_ADVIS/internal/plugins"
+	"github.com/s-archdev/Terminal_ADVIS/internal/rules"
+	"github.com/s-archdev/Terminal_ADVIS/internal/server"
+	"github.com/s-archdev/Terminal_ADVIS/internal/state"
+	"github.com/s-archdev/Terminal_ADVIS/internal/sysmon"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// alertingGrace is how long advis waits at exit for the alerting channels
+// to send what they hold
+const alertingGrace = 10 * time.Second
+
+const usage = `Usage: %[1]s [command] [flags]
+
+Commands:
+  all       dashboard combining both monitors (default)
+  sys       system monitor
+  net       network monitor
+  plugins   panels from the executables in the plugin directory
+  snapshot  capture a one-off or scheduled snapshot
+  report    summarize a day of snapshots, or with -range 7d the last week;
+            -out report.html charts it in a file fit to mail
+  query     print a metric from the history as text, CSV or JSON, such as
+            "iface=wlan0 metric=down range=24h step=5m"
+  compare   set a metric over two time ranges of the history side by side,
+            such as before and after an update
+  status    print one line of figures for tmux, i3status or a prompt, or
+            with -i3bar feed a desktop bar
+  doctor    check which features will work on this host
+  install   set up a headless mode as a systemd service, such as
+            -mode exporter to keep collecting history
+  watchdog  check the services of the config file's "watchdog" section and
+            run their recovery commands as they go down, without a terminal
+  baseline  record the host at rest, which the monitors then highlight
+            new processes, new listeners and higher traffic against
+  setcap    list the capabilities advis lacks and grant them to the binary
+
+Run "%[1]s <command> -h" for the flags of a command. The monitors accept
+-demo to replay a bundled synthetic dataset instead of reading this machine,
+-inline to draw a compact dashboard below the prompt rather than full
+screen, and -screen-reader to list the figures as plain labelled lines.
+They save the day's totals, peaks and alerts, the tabs and the dashboard
+layout on quit and restore them on start, unless given -fresh.
+
+Plugins are executables that print a JSON panel; "all" adds a tab and
+dashboard panels for each one found in -plugins. A Starlark script given by
+-rules can derive metrics and raise alerts, shown on their own tab.
+
+Every flag and config file key can be set in the environment instead, as
+ADVIS_, the command and the flag's name in upper case, ADVIS_STATUS_FORMAT
+for "status -format", or ADVIS_CONFIG_ and the key's,
+ADVIS_CONFIG_PALETTE=colorblind for "palette". The flags of the monitors
+and those several commands share, such as -profile, leave the command out:
+ADVIS_PROFILE=gateway.
+`
+
+func main() {
+	name := filepath.Base(os.Args[0])
+	cmd, args := "all", os.Args[1:]
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		cmd, args = args[0], args[1:]
+	}
+
+	var m tea.Model
+	opts := []tea.ProgramOption{tea.WithAltScreen()}
+	keep := false // Save the session's state on quit
+	switch cmd {
+	case "sys", "net", "all", "plugins":
+		fs := flag.NewFlagSet(name+" "+cmd, flag.ExitOnError)
+		demoMode := fs.Bool("demo", false, "replay the bundled synthetic dataset instead of reading this machine")
+		cpuBudget := fs.Float64("cpu-budget", 2,
+			"percent of one core advis may use before it samples less often (0 never backs off)")
+		debugMode := fs.Bool("debug", false, "log collector timings, parse errors and dropped data to -debug-file")
+		debugFile := fs.String("debug-file", debug.DefaultPath(), "rotated log written with -debug")
+		pprofAddr := fs.String("pprof", "", "serve runtime profiles on this loopback address, such as localhost:6060")
+		inlineMode := fs.Bool("inline", false, "draw a compact live dashboard below the prompt instead of taking over the terminal")
+		inlineHeight := fs.Int("inline-height", 14, "lines the -inline dashboard takes")
+		screenReader := fs.Bool("screen-reader", false,
+			"list the figures as plain labelled lines for a screen reader, without bars, emoji or animation")
+		readerInterval := fs.Duration("screen-reader-interval", 5*time.Second, "time between -screen-reader redraws")
+		sets := map[string][]*flag.FlagSet{
+			"sys":     {config.Flags, state.Flags, baseline.Flags, ui.ScreenshotFlags, sysmon.Flags},
+			"net":     {config.Flags, state.Flags, baseline.Flags, ui.ScreenshotFlags, netmon.Flags},
+			"all":     {config.Flags, state.Flags, baseline.Flags, ui.ScreenshotFlags, sysmon.Flags, netmon.Flags, plugins.Flags, rules.Flags},
+			"plugins": {config.Flags, plugins.Flags},
+		}
+		for _, set := range sets[cmd] {
+			set.VisitAll(func(f *flag.Flag) {
+				fs.Var(f.Value, f.Name, f.Usage)
+			})
+		}
+		// The monitors' flags mean the same to each one
+		if err := config.SetFlagsFromEnv(fs, fs); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		fs.Parse(args)
+		if *inlineMode && *screenReader {
+			fmt.Fprintln(os.Stderr, "-inline and -screen-reader cannot be combined")
+			os.Exit(2)
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "config: %v\n", err)
+			os.Exit(1)
+		}
+		budget.SetLimit(*cpuBudget)
+		if !*demoMode {
+			if err := alerting.Configure(cfg.Alerting); err != nil {
+				fmt.Fprintf(os.Stderr, "config: %s: %v\n", config.Path(), err)
+				os.Exit(1)
+			}
+		}
+		if *debugMode {
+			if err := debug.Open(*debugFile); err != nil {
+				fmt.Fprintf(os.Stderr, "debug: %v\n", err)
+				os.Exit(1)
+			}
+			defer debug.Close()
+			debug.Logf("advis %s started: %q", cmd, os.Args)
+		}
+		if *pprofAddr != "" {
+			if err := servePprof(*pprofAddr); err != nil {
+				fmt.Fprintf(os.Stderr, "pprof: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if cmd == "sys" || cmd == "all" {
+			if err := sysmon.ServeAPI(); err != nil {
+				fmt.Fprintf(os.Stderr, "api: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		rules.SetReadOnly(sysmon.ReadOnly())
+		sys, net, script := sysmon.New, netmon.New, rules.New
+		keep = !*demoMode
+		if *demoMode {
+			replay, err := demo.New(1)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			caps.Assume(caps.Host{Procfs: true, Sysfs: true, Root: true, SystemBus: true})
+			sys = func() tea.Model { return sysmon.NewDemo(replay) }
+			net = func() tea.Model { return netmon.NewDemo(replay) }
+			script = func() (tea.Model, error) { return rules.NewDemo(replay) }
+		}
+		// Plugins read this machine, so the demo leaves them out
+		var extra tea.Model
+		if cmd == "all" && !*demoMode || cmd == "plugins" {
+			var err error
+			if extra, err = plugins.New(); err != nil {
+				fmt.Fprintf(os.Stderr, "plugins: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		switch cmd {
+		case "sys":
+			m = sys()
+		case "net":
+			m = net()
+		case "plugins":
+			if extra == nil {
+				fmt.Fprintln(os.Stderr, "plugins: no executables found, see -plugins")
+				os.Exit(1)
+			}
+			m = extra
+		default:
+			s := newSwitcher(sys(), net(), cfg, config.ProfileName())
+			if extra != nil {
+				s.add("plugins", extra)
+			}
+			r, err := script()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "rules: %v\n", err)
+				os.Exit(1)
+			}
+			if r != nil {
+				s.add("rules", r)
+			}
+			if keep {
+				s.restoreState()
+			}
+			s.notify()
+			m = s
+		}
+		if *inlineMode {
+			monitors := []tea.Model{m}
+			if s, ok := m.(switcher); ok {
+				monitors = s.monitors
+			}
+			if !hasPanels(monitors) {
+				fmt.Fprintf(os.Stderr, "%s: -inline needs a monitor with dashboard panels\n", cmd)
+				os.Exit(1)
+			}
+			m = newInline(monitors, *inlineHeight)
+			opts = nil
+		}
+		if *screenReader {
+			monitors := []tea.Model{m}
+			if s, ok := m.(switcher); ok {
+				monitors = s.monitors
+			}
+			if !hasReadings(monitors) {
+				fmt.Fprintf(os.Stderr, "%s: -screen-reader needs the sys, net or rules monitor\n", cmd)
+				os.Exit(1)
+			}
+			m = newReader(monitors, *readerInterval)
+			opts = nil
+		}
+	case "snapshot", "report", "query", "compare", "watchdog":
+		run := map[string]func([]string) error{
+			"snapshot": sysmon.RunSnapshot,
+			"watchdog": sysmon.RunWatchdog,
+			"report":   sysmon.RunReport,
+			"query":    sysmon.RunQuery,
+			"compare":  sysmon.RunCompare,
+		}[cmd]
+		// -h comes back as flag.ErrHelp once the usage is printed
+		if err := run(args); err != nil && !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cmd, err)
+			os.Exit(1)
+		}
+		return
+	case "status":
+		if err := runStatus(name, args); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cmd, err)
+			os.Exit(1)
+		}
+		return
+	case "doctor":
+		if err := runDoctor(name, args); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cmd, err)
+			os.Exit(1)
+		}
+		return
+	case "install":
+		if err := runInstall(name, args); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cmd, err)
+			os.Exit(1)
+		}
+		return
+	case "baseline":
+		if err := runBaseline(name, args); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cmd, err)
+			os.Exit(1)
+		}
+		return
+	case "setcap":
+		if err := runSetcap(name, args); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cmd, err)
+			os.Exit(1)
+		}
+		return
+	case "privileged":
+		// What the monitor reruns through sudo when an action was denied
+		if err := sysmon.RunPrivileged(args); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cmd, err)
+			os.Exit(1)
+		}
+		return
+	case "help":
+		fmt.Printf(usage, name)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, usage, name)
+		os.Exit(2)
+	}
+
+	p := tea.NewProgram(m, opts...)
+	final, err := p.Run()
+	if err != nil {
+		debug.Logf("exiting: %v", err)
+		fmt.Printf("Error: %v", err)
+		os.Exit(1)
+	}
+	if keep {
+		saveState(final)
+	}
+	alerting.Close(alertingGrace)
+}
+
+// switcherKeyMap holds the bindings "advis all" handles itself rather
+// than passing to a monitor. The key tags are the names the "all" section
+// of the config file overrides them by.
+type switcherKeyMap struct {
+	Switch     key.Binding `key:"switch"`     // Cycles the dashboard and the monitors
+	Help       key.Binding `key:"help"`       // Only on the dashboard; monitors have their own
+	Layout     key.Binding `key:"layout"`     // Cycles the dashboard layouts of the config file
+	Profile    key.Binding `key:"profile"`    // Cycles the profiles of the config file
+	Screenshot key.Binding `key:"screenshot"` // Of the dashboard; monitors have their own
+	Quit       key.Binding `key:"quit"`       // Only on the dashboard
+}
+
+var switcherKeys = switcherKeyMap{
+	Switch:     key.NewBinding(key.WithKeys("`"), key.WithHelp("`", "switch")),
+	Help:       key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "all keys")),
+	Layout:     key.NewBinding(key.WithKeys("l"), key.WithHelp("l", "next layout")),
+	Profile:    key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "next profile")),
+	Screenshot: key.NewBinding(key.WithKeys("P"), key.WithHelp("P", "screenshot")),
+	Quit:       key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+}
+
+var switcherStyle lipgloss.Style
+
+func init() {
+	ui.OnPalette(func(p ui.Palette) {
+		switcherStyle = lipgloss.NewStyle().Foreground(ui.ColorOr(p.Dim, "#626262"))
+	})
+}
+
+// switcher runs several monitors in one program. It opens on a dashboard
+// combining their panels and can show each monitor full screen. Keys and
+// clipboard results go to the visible monitor; ticks and data messages go
+// to all of them so the hidden ones stay current.
+type switcher struct {
+	monitors  []tea.Model
+	names     []string
+	active    int  // Monitor shown full screen
+	dashboard bool // Show the combined dashboard instead
+	keysOpen  bool // Show the key overlay over the dashboard
+	flash     string
+	width     int
+	height    int
+	config    config.File // As read, without a profile applied
+	profile   string      // Profile applied, empty for none
+	layouts   []ui.Layout // From the config file or the profile
+	current   int         // Layout shown, 0 for the default and i for layouts[i-1]
+	layout    *dashboardLayout
+}
+
+// dashboardLayout is the last dashboard drawn. Monitors hand back the same
+// panels until their data changes, so the bordered layout is only redone
+// when a panel or the terminal size did.
+type dashboardLayout struct {
+	panels        []ui.Panel
+	width, height int
+	current       int
+	out           string
+}
+
+// newSwitcher returns the switcher with the profile of cfg called profile
+// applied, which loadConfig has checked
+func newSwitcher(sys, net tea.Model, cfg config.File, profile string) switcher {
+	p, _ := cfg.WithProfile(profile)
+	return switcher{
+		monitors:  []tea.Model{sys, net},
+		names:     []string{"sys", "net"},
+		dashboard: true,
+		config:    cfg,
+		profile:   profile,
+		layouts:   p.Layouts,
+		layout:    &dashboardLayout{},
+	}
+}
+
+func (s switcher) Init() tea.Cmd {
+	cmds := make([]tea.Cmd, len(s.monitors))
+	for i, m := range s.monitors {
+		cmds[i] = m.Init()
+	}
+	return tea.Batch(cmds...)
+}
+
+func (s switcher) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.width, s.height = msg.Width, msg.Height
+		// Leave the last line for the switch hint
+		msg.Height--
+		return s.updateAll(msg)
+	case tea.KeyMsg:
+		if s.dashboard && s.keysOpen {
+			// Any key closes the overlay, and quitting still works
+			s.keysOpen = false
+			if !key.Matches(msg, switcherKeys.Quit) {
+				return s, nil
+			}
+		}
+		if key.Matches(msg, switcherKeys.Switch) {
+			s.next()
+			return s, s.notify()
+		}
+		if s.dashboard {
+			s.flash = ""
+			switch {
+			case key.Matches(msg, switcherKeys.Screenshot):
+				return s, ui.Screenshot(s.View())
+			case key.Matches(msg, switcherKeys.Quit):
+				return s, tea.Quit
+			case key.Matches(msg, switcherKeys.Help):
+				s.keysOpen = true
+			case key.Matches(msg, switcherKeys.Layout) && len(s.layouts) > 0:
+				s.current = (s.current + 1) % (len(s.layouts) + 1)
+				return s, s.notify()
+			case key.Matches(msg, switcherKeys.Profile) && len(s.config.Profiles) > 0:
+				s.nextProfile()
+				return s, s.notify()
+			}
+			return s, nil
+		}
+		return s.updateActive(msg)
+	case ui.ScreenshotMsg:
+		if s.dashboard {
+			s.flash = msg.Status()
+			return s, nil
+		}
+		return s.updateActive(msg)
+	case tea.MouseMsg, ui.ClipboardMsg:
+		if s.dashboard {
+			return s, nil
+		}
+		return s.updateActive(msg)
+	}
+	return s.updateAll(msg)
+}
+
+// add appends a monitor after the built-in ones
+func (s *switcher) add(name string, m tea.Model) {
+	s.monitors = append(s.monitors, m)
+	s.names = append(s.names, name)
+}
+
+// next moves from the dashboard to each monitor in turn and back
+func (s *switcher) next() {
+	switch {
+	case s.dashboard:
+		s.dashboard = false
+		s.active = 0
+	case s.active == len(s.monitors)-1:
+		s.dashboard = true
+	default:
+		s.active++
+	}
+}
+
+// nextProfile applies the config file's next profile, after the last
+// going back to none, and keeps the layout shown if the profile has it
+func (s *switcher) nextProfile() {
+	names := append([]string{""}, s.config.ProfileNames()...)
+	next := names[(slices.Index(names, s.profile)+1)%len(names)]
+	p, err := applyProfile(s.config, next)
+	if err != nil {
+		s.flash = err.Error()
+		return
+	}
+	shown := ""
+	if s.current > 0 {
+		shown = s.layouts[s.current-1].Name
+	}
+	s.profile, s.layouts, s.current = next, p.Layouts, 0
+	if i := slices.IndexFunc(s.layouts, func(l ui.Layout) bool { return l.Name == shown }); i >= 0 && shown != "" {
+		s.current = i + 1
+	}
+	s.flash = "Profile " + cmp.Or(next, "none")
+}
+
+// notify tells every monitor whether it is on screen, so hidden ones can
+// stop collecting what only their views need
+func (s *switcher) notify() tea.Cmd {
+	var widgets []string
+	if s.dashboard && s.current > 0 {
+		for _, row := range s.layouts[s.current-1].Rows {
+			for _, c := range row.Widgets {
+				widgets = append(widgets, c.Widget)
+			}
+		}
+	}
+	var cmds []tea.Cmd
+	for i, m := range s.monitors {
+		_, paneler := m.(ui.Paneler)
+		var cmd tea.Cmd
+		s.monitors[i], cmd = m.Update(ui.VisibilityMsg{
+			Full:      !s.dashboard && i == s.active,
+			Dashboard: s.dashboard && paneler,
+			Widgets:   widgets,
+		})
+		cmds = append(cmds, cmd)
+	}
+	return tea.Batch(cmds...)
+}
+
+func (s switcher) updateAll(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	for i, m := range s.monitors {
+		var cmd tea.Cmd
+		s.monitors[i], cmd = m.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	return s, tea.Batch(cmds...)
+}
+
+func (s switcher) updateActive(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	s.monitors[s.active], cmd = s.monitors[s.active].Update(msg)
+	return s, cmd
+}
+
+func (s switcher) View() string {
+	if s.dashboard {
+		full := switcherKeys.Switch
+		full.SetHelp(full.Help().Key, "full-screen monitors")
+		name := "dashboard"
+		var layout key.Binding
+		if len(s.layouts) > 0 {
+			if s.current > 0 {
+				name += " " + s.layouts[s.current-1].Name
+			}
+			layout = switcherKeys.Layout
+		}
+		var profile key.Binding
+		if len(s.config.Profiles) > 0 {
+			if s.profile != "" {
+				name += " [" + s.profile + "]"
+			}
+			profile = switcherKeys.Profile
+		}
+		hint := name + " | " + ui.HelpLine(full, layout, profile, switcherKeys.Help, switcherKeys.Quit)
+		if usage := budget.Status(); usage != "" {
+			hint += " | " + usage
+		}
+		if s.flash != "" {
+			hint = s.flash + " | " + hint
+		}
+		hint = switcherStyle.Render(hint)
+		if s.keysOpen {
+			return s.renderHelp() + "\n" + hint
+		}
+		return s.renderDashboard() + "\n" + hint
+	}
+	hint := switcherStyle.Render(s.names[s.active] + " monitor | " + ui.HelpLine(switcherKeys.Switch))
+	return s.monitors[s.active].View() + "\n" + hint
+}
+
+// renderHelp is the key overlay of the dashboard. Shown full screen, each
+// monitor has its own overlay on the same key.
+func (s switcher) renderHelp() string {
+	help := switcherKeys.Help
+	help.SetHelp(help.Help().Key, "all keys (of the monitor when full screen)")
+	return ui.HelpOverlay("⌨️  Keys", []ui.KeyGroup{{Title: "Dashboard",
+		Bindings: []key.Binding{switcherKeys.Switch, switcherKeys.Layout, switcherKeys.Profile, switcherKeys.Screenshot, help, switcherKeys.Quit}}}, s.width) +
+		"\n" + switcherStyle.Render("Press any key to close")
+}
+
+// renderDashboard lays out the panels of every monitor that has some
+func (s switcher) renderDashboard() string {
+	if s.width == 0 {
+		return "Initializing..."
+	}
+	width := ui.PanelWidth(s.width)
+	if s.current > 0 {
+		width = ui.LayoutPanelWidth(s.layouts[s.current-1], s.width)
+	}
+	var panels []ui.Panel
+	for _, m := range s.monitors {
+		if p, ok := m.(ui.Paneler); ok {
+			panels = append(panels, p.Panels(width)...)
+		}
+	}
+	l := s.layout
+	if l.out == "" || l.width != s.width || l.height != s.height || l.current != s.current || !slices.Equal(l.panels, panels) {
+		l.panels, l.width, l.height, l.current = panels, s.width, s.height, s.current
+		if s.current > 0 {
+			l.out = ui.Grid(s.layouts[s.current-1], panels, s.width, s.height-1)
+		} else {
+			l.out = ui.Dashboard(panels, s.width, s.height-1)
+		}
+	}
+	return l.out
+}
+
+// loadConfig reads the config file and applies its settings, those of
+// the -profile profile in place of its own, returning it as read for the
+// switcher to change profiles
+func loadConfig() (config.File, error) {
+	cfg, err := config.Read()
+	if err != nil {
+		return cfg, err
+	}
+	setKeys := map[string]func(map[string][]string) error{
+		"sys":     sysmon.SetKeys,
+		"net":     netmon.SetKeys,
+		"plugins": plugins.SetKeys,
+		"rules":   rules.SetKeys,
+		"all":     func(o map[string][]string) error { return ui.Rebind(&switcherKeys, o) },
+	}
+	for section, overrides := range cfg.Keys {
+		set, ok := setKeys[section]
+		if !ok {
+			return cfg, fmt.Errorf("%s: unknown keys section %q", config.Path(), section)
+		}
+		if err := set(overrides); err != nil {
+			return cfg, fmt.Errorf("%s: keys.%s: %w", config.Path(), section, err)
+		}
+	}
+	if err := ui.SetPalette(cfg.Palette); err != nil {
+		return cfg, fmt.Errorf("%s: %w", config.Path(), err)
+	}
+	if err := ui.SetIcons(cfg.Icons); err != nil {
+		return cfg, fmt.Errorf("%s: %w", config.Path(), err)
+	}
+	if err := ui.SetLocale(cfg.Locale); err != nil {
+		return cfg, fmt.Errorf("%s: %w", config.Path(), err)
+	}
+	setTabs := map[string]func([]string) error{"sys": sysmon.SetTabs, "net": netmon.SetTabs}
+	for section, names := range cfg.Tabs {
+		set, ok := setTabs[section]
+		if !ok {
+			return cfg, fmt.Errorf("%s: unknown tabs section %q", config.Path(), section)
+		}
+		if err := set(names); err != nil {
+			return cfg, fmt.Errorf("%s: tabs.%s: %w", config.Path(), section, err)
+		}
+	}
+	if err := sysmon.SetWatchdog(cfg.Watchdog); err != nil {
+		return cfg, fmt.Errorf("%s: %w", config.Path(), err)
+	}
+	if err := server.Configure(cfg.Listen); err != nil {
+		return cfg, fmt.Errorf("%s: %w", config.Path(), err)
+	}
+	if _, err := applyProfile(cfg, config.ProfileName()); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// applyProfile applies the interfaces, thresholds and notify settings of
+// the profile of cfg called name, or of cfg itself for none, and returns
+// cfg with the profile in place
+func applyProfile(cfg config.File, name string) (config.File, error) {
+	p, err := cfg.WithProfile(name)
+	if err != nil {
+		return p, fmt.Errorf("%s: %w", config.Path(), err)
+	}
+	if err := netmon.SetInterfaces(p.Interfaces); err != nil {
+		return p, fmt.Errorf("%s: %w", config.Path(), err)
+	}
+	if err := ui.SetThresholds(p.Thresholds); err != nil {
+		return p, fmt.Errorf("%s: %w", config.Path(), err)
+	}
+	if err := ui.SetNotification(p.Notify); err != nil {
+		return p, fmt.Errorf("%s: %w", config.Path(), err)
+	}
+	return p, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// readerFirstFrame is how soon the first readings are drawn, once the
+// monitors have rates to report
+const readerFirstFrame = 2 * time.Second
+
+// reader runs monitors for a terminal screen reader. It draws no bars,
+// emoji, graphics or colors, only the monitors' readings as "label:
+// value" lines in a stable order, and redraws at most once an interval so
+// a line is not read out while it changes.
+type reader struct {
+	monitors []tea.Model
+	interval time.Duration
+	frame    string
+}
+
+// readerTickMsg asks the reader to redraw
+type readerTickMsg struct{}
+
+// newReader tells the monitors they only feed the dashboard, which
+// collects what their readings need
+func newReader(monitors []tea.Model, interval time.Duration) reader {
+	r := reader{monitors: monitors, interval: max(interval, time.Second)}
+	for i, m := range r.monitors {
+		r.monitors[i], _ = m.Update(ui.VisibilityMsg{Dashboard: true})
+	}
+	return r
+}
+
+// hasReadings reports whether any of the monitors has readings
+func hasReadings(monitors []tea.Model) bool {
+	return slices.ContainsFunc(monitors, func(m tea.Model) bool {
+		_, ok := m.(ui.Reader)
+		return ok
+	})
+}
+
+func readerTick(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg { return readerTickMsg{} })
+}
+
+func (r reader) Init() tea.Cmd {
+	cmds := []tea.Cmd{readerTick(readerFirstFrame)}
+	for _, m := range r.monitors {
+		cmds = append(cmds, m.Init())
+	}
+	return tea.Batch(cmds...)
+}
+
+func (r reader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case readerTickMsg:
+		var readings []ui.Reading
+		for _, m := range r.monitors {
+			if rd, ok := m.(ui.Reader); ok {
+				readings = append(readings, rd.Readings()...)
+			}
+		}
+		r.frame = ui.RenderReadings(readings)
+		return r, readerTick(r.interval)
+	case tea.KeyMsg:
+		// The monitors' keys act on views that are not drawn
+		if key.Matches(msg, switcherKeys.Quit) {
+			return r, tea.Quit
+		}
+		return r, nil
+	case tea.MouseMsg, ui.ClipboardMsg:
+		return r, nil
+	}
+	var cmds []tea.Cmd
+	for i, m := range r.monitors {
+		var cmd tea.Cmd
+		r.monitors[i], cmd = m.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	return r, tea.Batch(cmds...)
+}
+
+func (r reader) View() string {
+	if r.frame == "" {
+		return "Collecting figures..."
+	}
+	quit := switcherKeys.Quit.Help()
+	return r.frame + "Press " + quit.Key + " to " + quit.Desc + "."
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/caps"
+	"github.com/s-archdev/Terminal_ADVIS/internal/config"
+)
+
+// runSetcap tells which capabilities advis lacks for what, and with
+// -apply grants them to the binary through sudo or pkexec, so the monitor
+// can read everything without running as root. cap_dac_read_search lets
+// the binary read any file, and advis opens the files its user names, such
+// as -config, -rules and -geoip, so the binary is first kept to a group:
+// anyone who may run it may read every file on the host.
+func runSetcap(name string, args []string) error {
+	fs := flag.NewFlagSet(name+" setcap", flag.ExitOnError)
+	apply := fs.Bool("apply", false, "grant the missing capabilities to the advis binary now")
+	all := fs.Bool("all", false, "grant every capability advis can use, not only the missing ones")
+	group := fs.String("group", "", "group alone allowed to run the binary once it has the capabilities (required with -apply)")
+	if err := config.SetFlagsFromEnv(fs); err != nil {
+		return err
+	}
+	fs.Parse(args)
+	if runtime.GOOS != "linux" {
+		return errors.New("file capabilities are a Linux feature")
+	}
+
+	host := caps.Get()
+	fmt.Println("Capabilities")
+	for _, p := range caps.Privileges {
+		mark := "✓"
+		if !p.Held(host) {
+			mark = "✗"
+		}
+		fmt.Printf("  %s %-20s %s\n", mark, p.Cap, p.Enables)
+	}
+	fmt.Println()
+	fmt.Println("Network")
+	fmt.Printf("  - %-20s %s\n", "cap_net_raw", "not needed: advis captures no packets")
+	fmt.Printf("  - %-20s %s\n", "cap_net_admin", "not needed: the socket tables and their byte counts come from /proc and sock_diag")
+	if host.NetAdmin && !host.Root {
+		fmt.Println("    held all the same; it can be dropped")
+	}
+	if !host.Netlink {
+		fmt.Println("    sock_diag is unavailable, so sockets show no byte counts; load it with: sudo modprobe inet_diag")
+	}
+	fmt.Println()
+
+	grant := caps.Missing(host)
+	if *all {
+		grant = caps.Privileges
+	}
+	if len(grant) == 0 {
+		fmt.Println("advis has every capability it can use.")
+		return nil
+	}
+	names := make([]string, len(grant))
+	for i, p := range grant {
+		names[i] = p.Cap
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	groupName := *group
+	if groupName == "" {
+		groupName = "<group>"
+	} else if _, err := user.LookupGroup(groupName); err != nil {
+		return err
+	}
+	// setcap last: changing the owner of a file drops its capabilities
+	steps := [][]string{
+		{"chgrp", groupName, exe},
+		{"chmod", "0750", exe},
+		{"setcap", strings.Join(names, ",") + "+ep", exe},
+	}
+	if !*apply || *group == "" {
+		fmt.Println("Anyone who can run a binary with these capabilities can read every file on")
+		fmt.Println("the host through it, as advis opens the files its user names, such as")
+		fmt.Println("-config and -rules. Keep the binary to a trusted group, then grant them:")
+		for _, step := range steps {
+			fmt.Printf("  sudo %s\n", strings.Join(step, " "))
+		}
+		fmt.Println("or run this command with -apply -group <group>. Installing a new binary")
+		fmt.Println("drops them. Single actions that need root, such as deleting a file of")
+		fmt.Println("another user, ask to rerun through sudo.")
+		if *apply {
+			return errors.New("-apply needs -group")
+		}
+		return nil
+	}
+	for _, step := range steps {
+		cmd, err := caps.AsRoot(step[0], step[1:]...)
+		if err != nil {
+			return err
+		}
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %w", step[0], err)
+		}
+	}
+	fmt.Printf("Granted %s to %s, which only %s may run; restart advis to use them.\n", strings.Join(names, ", "), exe, *group)
+	return nil
+}
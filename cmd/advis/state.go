@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/state"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// switcherState is the section of the state file for "advis all"
+type switcherState struct {
+	Dashboard bool   `json:"dashboard"`
+	Monitor   string `json:"monitor"`
+	Layout    string `json:"layout"` // Empty for the default
+}
+
+// keepStates merges the sections of the monitors that keep any
+func keepStates(monitors []tea.Model) map[string]any {
+	sections := make(map[string]any)
+	for _, m := range monitors {
+		if k, ok := m.(state.Keeper); ok {
+			for name, v := range k.KeepState() {
+				sections[name] = v
+			}
+		}
+	}
+	return sections
+}
+
+// KeepState implements state.Keeper
+func (s switcher) KeepState() map[string]any {
+	sections := keepStates(s.monitors)
+	saved := switcherState{Dashboard: s.dashboard, Monitor: s.names[s.active]}
+	if s.current > 0 {
+		saved.Layout = s.layouts[s.current-1].Name
+	}
+	sections["all"] = saved
+	return sections
+}
+
+// restoreState shows the monitor or dashboard layout the last session
+// quit on, for those still there
+func (s *switcher) restoreState() {
+	var saved switcherState
+	if _, ok := state.Restore("all", &saved); !ok {
+		return
+	}
+	if i := slices.Index(s.names, saved.Monitor); i >= 0 {
+		s.dashboard, s.active = saved.Dashboard, i
+	}
+	if i := slices.IndexFunc(s.layouts, func(l ui.Layout) bool { return l.Name == saved.Layout }); i >= 0 {
+		s.current = i + 1
+	}
+}
+
+// KeepState implements state.Keeper
+func (in inline) KeepState() map[string]any { return keepStates(in.monitors) }
+
+// KeepState implements state.Keeper
+func (r reader) KeepState() map[string]any { return keepStates(r.monitors) }
+
+// saveState writes what the final model of a session keeps, reporting
+// rather than failing on errors since the session is over
+func saveState(m tea.Model) {
+	k, ok := m.(state.Keeper)
+	if !ok {
+		return
+	}
+	if err := state.Save(k.KeepState()); err != nil {
+		fmt.Fprintf(os.Stderr, "state: %v\n", err)
+	}
+}
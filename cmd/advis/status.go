@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/config"
+	"github.com/s-archdev/Terminal_ADVIS/internal/demo"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/sysstat"
+)
+
+// statusTimeout bounds one collection, so a hung mount cannot stall a
+// status bar waiting on the command
+const statusTimeout = 2 * time.Second
+
+// statusSample holds the figures a status line can show
+type statusSample struct {
+	host     string
+	cpu      float64 // Percent of all CPUs
+	down, up float64 // Bytes per second over every interface but loopback
+	info     sysstat.Info
+}
+
+// statusFields are the placeholders of a status format, by name
+var statusFields = map[string]func(statusSample) string{
+	"down": func(s statusSample) string { return ui.FormatValue(s.down, "bytes/s") },
+	"up":   func(s statusSample) string { return ui.FormatValue(s.up, "bytes/s") },
+	"cpu":  func(s statusSample) string { return ui.FormatValue(s.cpu, "%") },
+	"mem": func(s statusSample) string {
+		if s.info.MemTotal == 0 {
+			return "?"
+		}
+		return ui.FormatValue(float64(s.info.MemUsed)/float64(s.info.MemTotal)*100, "%")
+	},
+	"memused": func(s statusSample) string { return ui.FormatBytes(s.info.MemUsed) },
+	"load":    func(s statusSample) string { return strconv.FormatFloat(s.info.LoadAverage, 'f', 2, 64) },
+	"host":    func(s statusSample) string { return s.host },
+}
+
+var statusPlaceholder = regexp.MustCompile(`%(%|[a-z]+)`)
+
+// checkStatusFormat reports the first unknown placeholder of format
+func checkStatusFormat(format string) error {
+	for _, m := range statusPlaceholder.FindAllStringSubmatch(format, -1) {
+		if _, ok := statusFields[m[1]]; !ok && m[1] != "%" {
+			return fmt.Errorf("unknown placeholder %s (known: %s)", m[0], statusFieldNames())
+		}
+	}
+	return nil
+}
+
+// statusFieldNames lists the placeholders for error messages and usage
+func statusFieldNames() string {
+	var names []string
+	for name := range statusFields {
+		names = append(names, "%"+name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, " ")
+}
+
+// formatStatus replaces the placeholders of a checked format with the
+// figures of s
+func formatStatus(format string, s statusSample) string {
+	return statusPlaceholder.ReplaceAllStringFunc(format, func(p string) string {
+		if p == "%%" {
+			return "%"
+		}
+		return statusFields[p[1:]](s)
+	})
+}
+
+// statusSampler reads the monitors' collectors. Rates are over the time
+// since the previous sample, so the first one only primes it.
+type statusSampler struct {
+	host    string
+	system  sysstat.Collector
+	network netstat.Collector
+	last    netstat.Snapshot
+}
+
+func newStatusSampler(demoMode bool) (*statusSampler, error) {
+	host, _ := os.Hostname()
+	if !demoMode {
+		network := &netstat.System{}
+		network.SkipConnections(true)
+		return &statusSampler{host: host, system: &sysstat.System{}, network: network}, nil
+	}
+	replay, err := demo.New(1)
+	if err != nil {
+		return nil, err
+	}
+	return &statusSampler{host: "demo", system: replay.System(time.Second), network: replay.Network(time.Second)}, nil
+}
+
+// sample collects both sources. Figures that could not be read are left
+// at zero rather than failing the line.
+func (s *statusSampler) sample() statusSample {
+	ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+	defer cancel()
+	sys, _ := s.system.Collect(ctx)
+	net, _ := s.network.Collect(ctx)
+
+	out := statusSample{host: s.host, cpu: sys.CPU, info: sys.Info}
+	if elapsed := net.Time.Sub(s.last.Time).Seconds(); !s.last.Time.IsZero() && elapsed > 0 {
+		before := make(map[string]netstat.Counter, len(s.last.Interfaces))
+		for _, c := range s.last.Interfaces {
+			before[c.Name] = c
+		}
+		for _, c := range net.Interfaces {
+			b, ok := before[c.Name]
+			if c.Name == "lo" || !ok || c.RxBytes < b.RxBytes || c.TxBytes < b.TxBytes {
+				continue
+			}
+			out.down += float64(c.RxBytes-b.RxBytes) / elapsed
+			out.up += float64(c.TxBytes-b.TxBytes) / elapsed
+		}
+	}
+	s.last = net
+	return out
+}
+
+// runStatus prints one formatted line for tmux status-right, i3status or a
+// shell prompt and exits. Rates and CPU need two samples, so it takes
+// -interval to run. With -i3bar it keeps running as a bar's status command.
+func runStatus(name string, args []string) error {
+	fs := flag.NewFlagSet(name+" status", flag.ExitOnError)
+	format := fs.String("format", "%down %up %cpu %mem", "line to print; placeholders: "+statusFieldNames()+" and %% for a percent sign")
+	interval := fs.Duration("interval", 500*time.Millisecond, "time between the two samples rates and CPU are measured over (with -i3bar, between updates; default 2s)")
+	demoMode := fs.Bool("demo", false, "read the bundled synthetic dataset instead of this machine")
+	i3bar := fs.Bool("i3bar", false, "keep running, writing i3bar protocol blocks for i3bar, swaybar or waybar")
+	blocks := fs.String("blocks", "cpu,mem,net", "comma separated -i3bar blocks: cpu, mem, net, host")
+	onClick := fs.String("on-click", "", "shell command a right click on an -i3bar block runs, given the block in $ADVIS_BLOCK")
+	if err := config.SetFlagsFromEnv(fs); err != nil {
+		return err
+	}
+	fs.Parse(args)
+	if err := checkStatusFormat(*format); err != nil {
+		return err
+	}
+	names := strings.Split(*blocks, ",")
+	if err := checkI3Blocks(names); *i3bar && err != nil {
+		return err
+	}
+
+	s, err := newStatusSampler(*demoMode)
+	if err != nil {
+		return err
+	}
+	if *i3bar {
+		// A bar redrawing twice a second would cost more than it shows
+		refresh := 2 * time.Second
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == "interval" {
+				refresh = *interval
+			}
+		})
+		return runI3bar(s, names, refresh, *onClick)
+	}
+	s.sample()
+	time.Sleep(*interval)
+	fmt.Println(formatStatus(*format, s.sample()))
+	return nil
+}
@@ -0,0 +1,271 @@
+// Package alerting delivers alert events beyond the terminal, through the
+// channels the config file's "alerting" section sets up, so an alert on a
+// headless server reaches someone with no monitor open. The monitors and
+// the snapshot collector hand it the alerts that fire and clear; each
+// channel sends them on its own goroutine and never holds up a caller.
+// Every function is a no-op until Configure is given a channel.
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+)
+
+// Alert levels and event kinds. Only the syslog channel, which logs
+// everything, takes the kinds besides Fired and Cleared.
+const (
+	LevelWarning  = "warning"
+	LevelCritical = "critical"
+
+	Fired    = "fired"
+	Cleared  = "cleared"
+	Action   = "action"   // Taken from a monitor, such as killing a process
+	Listener = "listener" // A socket that started listening
+)
+
+// queueSize is how many deliveries wait for a busy channel before more
+// are dropped
+const queueSize = 64
+
+// maxFailures bounds the failures kept until Failures is called
+const maxFailures = 20
+
+// Event is an alert firing or clearing, or another event worth logging
+type Event struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`   // Fired, Cleared, Action or Listener
+	Level   string    `json:"level"`  // LevelWarning or LevelCritical
+	Source  string    `json:"source"` // Such as "storage", or a rules script alert's name
+	Message string    `json:"message"`
+	Host    string    `json:"host"`
+	Key     string    `json:"key"` // The same for every event of an alert while it fires, its figures aside
+
+	Fields map[string]string `json:"fields,omitempty"` // More to log, such as the target of an action
+}
+
+// Config is the "alerting" section of the config file; a channel left
+// out is not used
+type Config struct {
+	Email     *Email     `json:"email"`
+	PagerDuty *PagerDuty `json:"pagerduty"`
+	Opsgenie  *Opsgenie  `json:"opsgenie"`
+	Syslog    *Syslog    `json:"syslog"`
+	Flap      Flap       `json:"flap"`
+}
+
+// Route overrides where a channel sends the alerts of one source
+type Route struct {
+	To    []string `json:"to"`    // Recipients in place of the channel's
+	Level string   `json:"level"` // Lowest level sent, or "off" for none
+}
+
+// channel sends the events queued for it until the queue is closed, and
+// then sends what it still holds
+type channel interface {
+	run(queue <-chan []Event)
+}
+
+// sink is a running channel
+type sink struct {
+	name  string
+	queue chan []Event
+	done  chan struct{}
+}
+
+var state struct {
+	sync.Mutex
+	sinks    []*sink
+	damper   *damper
+	failures []error
+	host     string
+}
+
+// Configure starts the channels of c, after stopping those of an earlier
+// call
+func Configure(c Config) error {
+	channels := make(map[string]channel)
+	if c.Email != nil {
+		m, err := newMailer(*c.Email)
+		if err != nil {
+			return fmt.Errorf("alerting.email: %w", err)
+		}
+		channels["email"] = m
+	}
+	if c.PagerDuty != nil {
+		p, err := newPagerDuty(*c.PagerDuty)
+		if err != nil {
+			return fmt.Errorf("alerting.pagerduty: %w", err)
+		}
+		channels["pagerduty"] = p
+	}
+	if c.Opsgenie != nil {
+		o, err := newOpsgenie(*c.Opsgenie)
+		if err != nil {
+			return fmt.Errorf("alerting.opsgenie: %w", err)
+		}
+		channels["opsgenie"] = o
+	}
+	if c.Syslog != nil {
+		l, err := newSyslogger(*c.Syslog)
+		if err != nil {
+			return fmt.Errorf("alerting.syslog: %w", err)
+		}
+		channels["syslog"] = l
+	}
+
+	d, err := newDamper(c.Flap)
+	if err != nil {
+		return fmt.Errorf("alerting.%w", err)
+	}
+
+	Close(0)
+	host, _ := os.Hostname()
+	state.Lock()
+	defer state.Unlock()
+	state.host, state.damper = host, d
+	for name, ch := range channels {
+		s := &sink{name: name, queue: make(chan []Event, queueSize), done: make(chan struct{})}
+		go func() {
+			defer close(s.done)
+			ch.run(s.queue)
+		}()
+		state.sinks = append(state.sinks, s)
+	}
+	return nil
+}
+
+// Remote reports whether c has a channel sending over the network: email,
+// the incident services, or syslog to a udp:// or tcp:// address
+func (c Config) Remote() bool {
+	if c.Email != nil || c.PagerDuty != nil || c.Opsgenie != nil {
+		return true
+	}
+	return c.Syslog != nil && (strings.HasPrefix(c.Syslog.Address, "udp://") || strings.HasPrefix(c.Syslog.Address, "tcp://"))
+}
+
+// Enabled reports whether any channel is configured
+func Enabled() bool {
+	state.Lock()
+	defer state.Unlock()
+	return len(state.sinks) > 0
+}
+
+// Deliver queues events for every channel, but for repeats and those of
+// alerts flapping. The monitors call it on every tick, even without
+// events, for flapping alerts that settled to be sent. A channel too far
+// behind drops events rather than block the caller.
+func Deliver(events []Event) {
+	state.Lock()
+	defer state.Unlock()
+	if len(state.sinks) == 0 {
+		return
+	}
+	for i := range events {
+		if events[i].Host == "" {
+			events[i].Host = state.host
+		}
+	}
+	var alerts, others []Event
+	for _, e := range events {
+		if e.Kind == Fired || e.Kind == Cleared {
+			alerts = append(alerts, e)
+		} else {
+			others = append(others, e)
+		}
+	}
+	alerts = state.damper.filter(time.Now(), alerts)
+	for _, s := range state.sinks {
+		events := alerts
+		if s.name == "syslog" {
+			events = slices.Concat(others, alerts)
+		}
+		if len(events) == 0 {
+			continue
+		}
+		select {
+		case s.queue <- events:
+		default:
+			debug.Logf("alerting: %s queue full, %d events dropped", s.name, len(events))
+		}
+	}
+}
+
+// Close stops the channels, waiting up to timeout for them to send what
+// they hold
+func Close(timeout time.Duration) {
+	state.Lock()
+	sinks := state.sinks
+	state.sinks = nil
+	state.Unlock()
+
+	deadline := time.After(timeout)
+	for _, s := range sinks {
+		close(s.queue)
+	}
+	for _, s := range sinks {
+		select {
+		case <-s.done:
+		case <-deadline:
+			debug.Logf("alerting: %s still sending at exit", s.name)
+			return
+		}
+	}
+}
+
+// fail records a channel's failure for Failures to report
+func fail(name string, err error) {
+	err = fmt.Errorf("%s: %w", name, err)
+	debug.Logf("alerting: %v", err)
+	state.Lock()
+	defer state.Unlock()
+	if len(state.failures) < maxFailures {
+		state.failures = append(state.failures, err)
+	}
+}
+
+// Failures returns the delivery failures since the last call, joined, or
+// nil when there were none
+func Failures() error {
+	state.Lock()
+	defer state.Unlock()
+	err := errors.Join(state.failures...)
+	state.failures = nil
+	return err
+}
+
+// secret is value, or the content of file when one is given, such as a
+// password kept out of the config file
+func secret(value, file string) (string, error) {
+	if file == "" {
+		return value, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// atLeast reports whether an event at level passes a channel set to send
+// those at min and above
+func atLeast(level, min string) bool {
+	return min == LevelWarning || level == LevelCritical && min == LevelCritical
+}
+
+// checkLevel checks a level setting, "off" being allowed where off is
+func checkLevel(level string, off bool) error {
+	if level == LevelWarning || level == LevelCritical || off && level == "off" {
+		return nil
+	}
+	if off {
+		return fmt.Errorf("level %q is not warning, critical or off", level)
+	}
+	return fmt.Errorf("level %q is not warning or critical", level)
+}
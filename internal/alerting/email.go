@@ -0,0 +1,303 @@
+package alerting
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// mailTimeout bounds connecting to the mail server
+const mailTimeout = 30 * time.Second
+
+// Email is the "email" channel: the events of every batch go out as one
+// mail per set of recipients, written from the subject and body
+// templates. The templates see .Host, .Events, each with .Time, .Kind,
+// .Level, .Source and .Message, and the counts .Fired, .Cleared and
+// .Critical.
+type Email struct {
+	Server       string           `json:"server"` // host:port; 465 speaks TLS at once, other ports STARTTLS when offered
+	Username     string           `json:"username"`
+	Password     string           `json:"password"`
+	PasswordFile string           `json:"password_file"` // Read in place of password
+	From         string           `json:"from"`
+	To           []string         `json:"to"`
+	Level        string           `json:"level"`   // Lowest level mailed: warning or critical
+	Batch        string           `json:"batch"`   // How long to gather events into one mail, such as "5m"
+	Subject      string           `json:"subject"` // Template
+	Body         string           `json:"body"`    // Template
+	Routes       map[string]Route `json:"routes"`  // By alert source or rules script alert name
+}
+
+// The defaults of what the "email" section leaves out
+const (
+	defaultBatch   = time.Minute
+	defaultSubject = `[advis] {{.Host}}: {{if .Fired}}{{.Fired}} alert{{if gt .Fired 1}}s{{end}} fired{{end}}` +
+		`{{if and .Fired .Cleared}}, {{end}}{{if .Cleared}}{{.Cleared}} cleared{{end}}`
+	defaultBody = `{{range .Events}}{{.Time.Format "2006-01-02 15:04:05"}}  {{.Kind}}  {{.Level}}  [{{.Source}}] {{.Message}}
+{{end}}
+--
+advis on {{.Host}}
+`
+)
+
+// mailer is the email channel as configured
+type mailer struct {
+	server  string
+	host    string
+	auth    smtp.Auth
+	from    string
+	to      []string
+	level   string
+	batch   time.Duration
+	subject *template.Template
+	body    *template.Template
+	routes  map[string]Route
+}
+
+// mailBatch is what the templates of a mail see
+type mailBatch struct {
+	Host                     string
+	Events                   []Event
+	Fired, Cleared, Critical int
+}
+
+func newMailer(e Email) (*mailer, error) {
+	m := &mailer{server: e.Server, level: orDefault(e.Level, LevelWarning), batch: defaultBatch}
+	var err error
+	if m.host, _, err = net.SplitHostPort(e.Server); err != nil {
+		return nil, fmt.Errorf("server %q is not host:port", e.Server)
+	}
+	if e.From == "" {
+		return nil, errors.New("no from address")
+	}
+	from, err := mail.ParseAddress(e.From)
+	if err != nil {
+		return nil, fmt.Errorf("from: %w", err)
+	}
+	m.from = from.Address
+	if m.to, err = addresses(e.To); err != nil {
+		return nil, fmt.Errorf("to: %w", err)
+	}
+	if err := checkLevel(m.level, false); err != nil {
+		return nil, err
+	}
+	m.routes = make(map[string]Route, len(e.Routes))
+	for name, r := range e.Routes {
+		if r.Level != "" {
+			if err := checkLevel(r.Level, true); err != nil {
+				return nil, fmt.Errorf("routes.%s: %w", name, err)
+			}
+		}
+		if r.To != nil {
+			if r.To, err = addresses(r.To); err != nil {
+				return nil, fmt.Errorf("routes.%s: to: %w", name, err)
+			}
+		}
+		m.routes[name] = r
+	}
+	if len(m.to) == 0 && len(m.routes) == 0 {
+		return nil, errors.New("no recipients in to or routes")
+	}
+	if e.Batch != "" {
+		if m.batch, err = time.ParseDuration(e.Batch); err != nil || m.batch < 0 {
+			return nil, fmt.Errorf("invalid batch %q", e.Batch)
+		}
+	}
+
+	password, err := secret(e.Password, e.PasswordFile)
+	if err != nil {
+		return nil, err
+	}
+	if e.Username != "" {
+		m.auth = smtp.PlainAuth("", e.Username, password, m.host)
+	}
+
+	if m.subject, err = template.New("subject").Parse(orDefault(e.Subject, defaultSubject)); err != nil {
+		return nil, fmt.Errorf("subject: %w", err)
+	}
+	if m.body, err = template.New("body").Parse(orDefault(e.Body, defaultBody)); err != nil {
+		return nil, fmt.Errorf("body: %w", err)
+	}
+	return m, nil
+}
+
+// addresses parses a list of addresses, such as "Ops <ops@example.com>",
+// to the bare ones SMTP takes, never nil
+func addresses(list []string) ([]string, error) {
+	addrs := []string{}
+	for _, s := range list {
+		a, err := mail.ParseAddress(s)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, a.Address)
+	}
+	return addrs, nil
+}
+
+// route returns the recipients of an event, none when it is not mailed
+func (m *mailer) route(e Event) []string {
+	to, level := m.to, m.level
+	if r, ok := m.routes[e.Source]; ok {
+		if r.To != nil {
+			to = r.To
+		}
+		if r.Level != "" {
+			level = r.Level
+		}
+	}
+	if level == "off" || !atLeast(e.Level, level) {
+		return nil
+	}
+	return to
+}
+
+// run gathers the events arriving within a batch of the first and mails
+// them together, one mail per set of recipients
+func (m *mailer) run(queue <-chan []Event) {
+	pending := make(map[string][]Event) // By recipients, comma separated
+	var timer <-chan time.Time
+	for {
+		select {
+		case events, ok := <-queue:
+			if !ok {
+				m.sendAll(pending)
+				return
+			}
+			for _, e := range events {
+				if to := m.route(e); len(to) > 0 {
+					key := strings.Join(to, ",")
+					pending[key] = append(pending[key], e)
+				}
+			}
+			if len(pending) > 0 && timer == nil {
+				timer = time.After(m.batch)
+			}
+		case <-timer:
+			timer = nil
+			m.sendAll(pending)
+			clear(pending)
+		}
+	}
+}
+
+func (m *mailer) sendAll(pending map[string][]Event) {
+	for key, events := range pending {
+		to := strings.Split(key, ",")
+		msg, err := m.compose(to, events)
+		if err == nil {
+			err = m.send(to, msg)
+		}
+		if err != nil {
+			fail("email", err)
+		}
+	}
+}
+
+// compose writes the mail of a batch
+func (m *mailer) compose(to []string, events []Event) ([]byte, error) {
+	batch := mailBatch{Host: events[0].Host, Events: events}
+	for _, e := range events {
+		if e.Kind == Fired {
+			batch.Fired++
+		} else {
+			batch.Cleared++
+		}
+		if e.Level == LevelCritical {
+			batch.Critical++
+		}
+	}
+	var subject, body bytes.Buffer
+	if err := m.subject.Execute(&subject, batch); err != nil {
+		return nil, fmt.Errorf("subject: %w", err)
+	}
+	if err := m.body.Execute(&body, batch); err != nil {
+		return nil, fmt.Errorf("body: %w", err)
+	}
+
+	var msg bytes.Buffer
+	header := func(name, value string) { fmt.Fprintf(&msg, "%s: %s\r\n", name, value) }
+	header("From", m.from)
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", strings.Join(strings.Fields(subject.String()), " ")))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<advis.%d@%s>", time.Now().UnixNano(), batch.Host))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	msg.WriteString("\r\n")
+	qp := quotedprintable.NewWriter(&msg)
+	qp.Write(bytes.ReplaceAll(body.Bytes(), []byte("\n"), []byte("\r\n")))
+	qp.Close()
+	return msg.Bytes(), nil
+}
+
+// send hands msg to the mail server for to
+func (m *mailer) send(to []string, msg []byte) error {
+	dialer := &net.Dialer{Timeout: mailTimeout}
+	var conn net.Conn
+	var err error
+	if _, port, _ := net.SplitHostPort(m.server); port == "465" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", m.server, &tls.Config{ServerName: m.host})
+	} else {
+		conn, err = dialer.Dial("tcp", m.server)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(2 * mailTimeout))
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if _, tlsConn := conn.(*tls.Conn); !tlsConn {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+				return err
+			}
+		}
+	}
+	if m.auth != nil {
+		if err := c.Auth(m.auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(m.from); err != nil {
+		return err
+	}
+	for _, rcpt := range slices.Compact(slices.Sorted(slices.Values(to))) {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,105 @@
+package alerting
+
+import (
+	"fmt"
+	"time"
+)
+
+// Flap is the "flap" setting of the "alerting" section: an alert that
+// fires or clears changes times within window is flapping, and its events
+// are held back from the channels until it keeps still for window, when
+// the state it settled in is sent if the channels have not seen it
+type Flap struct {
+	Changes int    `json:"changes"` // Zero for the default, negative to never hold events back
+	Window  string `json:"window"`
+}
+
+var defaultFlap = Flap{Changes: 4, Window: "10m"}
+
+// damper drops the repeated events of an alert, those firing it while it
+// fires at no lower a level or clearing it while clear, and holds back
+// those of alerts that flap
+type damper struct {
+	changes int // Zero to never hold events back
+	window  time.Duration
+	alerts  map[string]*damped // By host and key
+}
+
+// damped is where an alert stands with the channels
+type damped struct {
+	latest  Event       // The last event seen
+	sent    Event       // The last event sent
+	changes []time.Time // Within the window
+	held    bool
+}
+
+func newDamper(f Flap) (*damper, error) {
+	d := &damper{changes: defaultFlap.Changes, alerts: make(map[string]*damped)}
+	if f.Changes != 0 {
+		d.changes = max(f.Changes, 0)
+	}
+	var err error
+	if d.window, err = time.ParseDuration(orDefault(f.Window, defaultFlap.Window)); err != nil || d.window <= 0 {
+		return nil, fmt.Errorf("flap: invalid window %q", f.Window)
+	}
+	return d, nil
+}
+
+// filter returns the events of events to send at now, and those of alerts
+// that settled since the last call
+func (d *damper) filter(now time.Time, events []Event) []Event {
+	var out []Event
+	for _, e := range events {
+		key := e.Host + "\x00" + e.Key
+		a := d.alerts[key]
+		if a == nil {
+			a = &damped{sent: Event{Kind: Cleared}}
+			d.alerts[key] = a
+		}
+		if e.Kind == a.latest.Kind && !escalates(e, a.latest) {
+			continue // The center or the rules already passed this one on
+		}
+		a.latest = e
+		a.changes = append(a.changes, now)
+		a.prune(now, d.window)
+		if d.changes > 0 && len(a.changes) >= d.changes {
+			a.held = true
+		}
+		if !a.held {
+			a.sent = e
+			out = append(out, e)
+		}
+	}
+
+	for key, a := range d.alerts {
+		a.prune(now, d.window)
+		if a.held && len(a.changes) == 0 {
+			a.held = false
+			if a.latest.Kind != a.sent.Kind || escalates(a.latest, a.sent) {
+				a.sent = a.latest
+				settled := a.latest
+				settled.Message += " (settled after flapping)"
+				out = append(out, settled)
+			}
+		}
+		if !a.held && a.sent.Kind == Cleared && len(a.changes) == 0 {
+			delete(d.alerts, key)
+		}
+	}
+	return out
+}
+
+// escalates reports whether e fires at a higher level the alert that
+// earlier already fired
+func escalates(e, earlier Event) bool {
+	return e.Kind == Fired && earlier.Kind == Fired && e.Level == LevelCritical && earlier.Level != LevelCritical
+}
+
+// prune forgets the changes older than window
+func (a *damped) prune(now time.Time, window time.Duration) {
+	i := 0
+	for i < len(a.changes) && now.Sub(a.changes[i]) >= window {
+		i++
+	}
+	a.changes = a.changes[i:]
+}
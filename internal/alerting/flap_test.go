@@ -0,0 +1,96 @@
+package alerting
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDamperFilter(t *testing.T) {
+	type step struct {
+		at    time.Duration
+		kind  string // Of the event given, empty for none
+		level string
+		want  []string // Kinds sent
+	}
+	fired := func(level string) string { return Fired + " " + level }
+	tests := []struct {
+		name  string
+		flap  Flap
+		steps []step
+	}{
+		{
+			name: "repeats dropped",
+			flap: Flap{Changes: -1, Window: "10m"},
+			steps: []step{
+				{0, Fired, LevelWarning, []string{fired(LevelWarning)}},
+				{time.Second, Fired, LevelWarning, nil},
+				{2 * time.Second, Cleared, LevelWarning, []string{Cleared + " " + LevelWarning}},
+				{3 * time.Second, Cleared, LevelWarning, nil},
+			},
+		},
+		{
+			name: "escalation sent",
+			flap: Flap{Changes: -1, Window: "10m"},
+			steps: []step{
+				{0, Fired, LevelWarning, []string{fired(LevelWarning)}},
+				{time.Second, Fired, LevelCritical, []string{fired(LevelCritical)}},
+				{2 * time.Second, Fired, LevelCritical, nil},
+			},
+		},
+		{
+			name: "flapping held until it settles",
+			flap: Flap{Changes: 3, Window: "1m"},
+			steps: []step{
+				{0, Fired, LevelWarning, []string{fired(LevelWarning)}},
+				{time.Second, Cleared, LevelWarning, []string{Cleared + " " + LevelWarning}},
+				{2 * time.Second, Fired, LevelWarning, nil},
+				{3 * time.Second, Cleared, LevelWarning, nil},
+				{4 * time.Second, Fired, LevelWarning, nil},
+				{30 * time.Second, "", "", nil},
+				{65 * time.Second, "", "", []string{fired(LevelWarning)}},
+				{70 * time.Second, "", "", nil},
+			},
+		},
+		{
+			name: "flapping that settles where it was sends nothing",
+			flap: Flap{Changes: 2, Window: "1m"},
+			steps: []step{
+				{0, Fired, LevelWarning, []string{fired(LevelWarning)}},
+				{time.Second, Cleared, LevelWarning, nil},
+				{2 * time.Second, Fired, LevelWarning, nil},
+				{63 * time.Second, "", "", nil},
+			},
+		},
+	}
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := newDamper(tt.flap)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.steps {
+				var events []Event
+				if s.kind != "" {
+					events = []Event{{Kind: s.kind, Level: s.level, Source: "disk", Key: "disk /"}}
+				}
+				var got []string
+				for _, e := range d.filter(start.Add(s.at), events) {
+					got = append(got, e.Kind+" "+e.Level)
+				}
+				if !slices.Equal(got, s.want) {
+					t.Errorf("at %v: sent %v, want %v", s.at, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewDamperRejects(t *testing.T) {
+	for _, window := range []string{"soon", "0s", "-1m"} {
+		if _, err := newDamper(Flap{Window: window}); err == nil {
+			t.Errorf("window %q accepted, want an error", window)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package alerting
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// The timeout of a request to an incident service, and how often one
+// failing with a server error or a rate limit is tried in all
+const (
+	incidentTimeout  = 15 * time.Second
+	incidentAttempts = 3
+)
+
+// PagerDuty is the "pagerduty" channel, which triggers an incident
+// through the Events API v2 when an alert fires and resolves it when the
+// alert clears
+type PagerDuty struct {
+	RoutingKey     string `json:"routing_key"`      // The integration key of an Events API v2 integration
+	RoutingKeyFile string `json:"routing_key_file"` // Read in place of routing_key
+	Level          string `json:"level"`            // Lowest level sent: critical, the default, or warning
+	URL            string `json:"url"`              // In place of the Events API's
+}
+
+// Opsgenie is the "opsgenie" channel, which creates an alert through the
+// Alert API when one fires and closes it when it clears
+type Opsgenie struct {
+	APIKey     string `json:"api_key"`      // Of an API integration
+	APIKeyFile string `json:"api_key_file"` // Read in place of api_key
+	Level      string `json:"level"`        // Lowest level sent: critical, the default, or warning
+	URL        string `json:"url"`          // Such as https://api.eu.opsgenie.com for the EU instance
+}
+
+const (
+	pagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+	opsgenieURL  = "https://api.opsgenie.com"
+)
+
+// incidents is a channel opening an incident per alert that fires and
+// closing it when it clears, sending one request per event in order
+type incidents struct {
+	name    string
+	level   string
+	request func(e Event) (*http.Request, error)
+	client  *http.Client
+}
+
+func newPagerDuty(p PagerDuty) (*incidents, error) {
+	key, err := secret(p.RoutingKey, p.RoutingKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, errors.New("no routing_key")
+	}
+	endpoint := orDefault(p.URL, pagerDutyURL)
+	return newIncidents("pagerduty", p.Level, func(e Event) (*http.Request, error) {
+		body := map[string]any{
+			"routing_key":  key,
+			"event_action": "trigger",
+			"dedup_key":    dedupKey(e),
+		}
+		if e.Kind == Cleared {
+			body["event_action"] = "resolve"
+		} else {
+			body["client"] = "advis"
+			body["payload"] = map[string]any{
+				"summary":   truncate(fmt.Sprintf("%s: [%s] %s", e.Host, e.Source, e.Message), 1024),
+				"source":    e.Host,
+				"severity":  e.Level,
+				"timestamp": e.Time.Format(time.RFC3339),
+				"component": e.Source,
+			}
+		}
+		return jsonRequest(endpoint, body)
+	})
+}
+
+func newOpsgenie(o Opsgenie) (*incidents, error) {
+	key, err := secret(o.APIKey, o.APIKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, errors.New("no api_key")
+	}
+	base := orDefault(o.URL, opsgenieURL)
+	return newIncidents("opsgenie", o.Level, func(e Event) (*http.Request, error) {
+		alias := dedupKey(e)
+		var req *http.Request
+		var err error
+		if e.Kind == Cleared {
+			req, err = jsonRequest(base+"/v2/alerts/"+url.PathEscape(alias)+"/close?identifierType=alias",
+				map[string]any{"source": "advis", "note": "Cleared: " + e.Message})
+		} else {
+			priority := "P3"
+			if e.Level == LevelCritical {
+				priority = "P1"
+			}
+			req, err = jsonRequest(base+"/v2/alerts", map[string]any{
+				"message":     truncate(fmt.Sprintf("%s: [%s] %s", e.Host, e.Source, e.Message), 130),
+				"alias":       alias,
+				"description": e.Message,
+				"priority":    priority,
+				"source":      "advis",
+				"entity":      e.Host,
+				"tags":        []string{"advis", e.Source},
+			})
+		}
+		if err == nil {
+			req.Header.Set("Authorization", "GenieKey "+key)
+		}
+		return req, err
+	})
+}
+
+func newIncidents(name, level string, request func(Event) (*http.Request, error)) (*incidents, error) {
+	level = orDefault(level, LevelCritical)
+	if err := checkLevel(level, false); err != nil {
+		return nil, err
+	}
+	return &incidents{name: name, level: level, request: request, client: &http.Client{Timeout: incidentTimeout}}, nil
+}
+
+// dedupKey identifies an alert of a host for as long as it fires, so its
+// events update one incident: the host and source, which reads well in
+// the service, and a hash of the alert's key
+func dedupKey(e Event) string {
+	sum := sha256.Sum256([]byte(e.Host + "\x00" + e.Key))
+	return "advis:" + e.Host + ":" + e.Source + ":" + hex.EncodeToString(sum[:6])
+}
+
+// run sends the events at the channel's level and above. An alert that
+// opened an incident resolves it when it clears at whatever level, as it
+// may have dropped below the channel's meanwhile.
+func (c *incidents) run(queue <-chan []Event) {
+	open := make(map[string]bool) // Incidents triggered, by dedupKey
+	for events := range queue {
+		for _, e := range events {
+			key := dedupKey(e)
+			if e.Kind == Cleared && open[key] {
+				delete(open, key)
+			} else if !atLeast(e.Level, c.level) {
+				continue
+			} else if e.Kind == Fired {
+				open[key] = true
+			}
+			if err := c.send(e); err != nil {
+				fail(c.name, fmt.Errorf("%s %s: %w", e.Kind, e.Source, err))
+			}
+		}
+	}
+}
+
+// send makes the request of e, again after a pause while the service is
+// rate limiting or failing
+func (c *incidents) send(e Event) error {
+	var err error
+	for attempt := range incidentAttempts {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * 2 * time.Second)
+		}
+		var req *http.Request
+		if req, err = c.request(e); err != nil {
+			return err
+		}
+		var resp *http.Response
+		if resp, err = c.client.Do(req); err != nil {
+			continue
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		err = fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			return err
+		}
+	}
+	return err
+}
+
+// jsonRequest is a POST of body as JSON to endpoint
+func jsonRequest(endpoint string, body any) (*http.Request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// truncate shortens s to n runes, marking the cut
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,225 @@
+package alerting
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Syslog is the "syslog" channel, which logs every event, alerts firing
+// and clearing as well as actions taken and new listeners, with its
+// fields kept apart for log pipelines to index: as journal fields when
+// written to the local journal, as RFC 5424 structured data otherwise
+type Syslog struct {
+	Address  string `json:"address"`  // Empty for the local journal, else /dev/log; or udp://host:514, tcp://host:601 or unix:///path
+	Facility string `json:"facility"` // Such as daemon, the default, auth or local0 to local7
+	Tag      string `json:"tag"`      // The program name logged, advis by default
+}
+
+// journalSocket is where the journal takes entries in its own protocol
+const journalSocket = "/run/systemd/journal/socket"
+
+// sdID is the ID of advis's structured data element, under the private
+// enterprise number of the example in RFC 5424
+const sdID = "advis@32473"
+
+var facilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// Syslog severities
+const (
+	severityCrit    = 2
+	severityError   = 3
+	severityWarning = 4
+	severityNotice  = 5
+)
+
+// syslogger is the syslog channel as configured
+type syslogger struct {
+	network, address string
+	journal          bool // Write the journal's protocol rather than syslog's
+	facility         int
+	tag              string
+	conn             net.Conn
+}
+
+func newSyslogger(s Syslog) (*syslogger, error) {
+	l := &syslogger{facility: facilities["daemon"], tag: orDefault(s.Tag, "advis")}
+	if s.Facility != "" {
+		f, ok := facilities[s.Facility]
+		if !ok {
+			return nil, fmt.Errorf("unknown facility %q (known: %s)", s.Facility, strings.Join(slices.Sorted(maps.Keys(facilities)), ", "))
+		}
+		l.facility = f
+	}
+	switch scheme, addr, _ := strings.Cut(s.Address, "://"); {
+	case s.Address == "":
+		if _, err := os.Stat(journalSocket); err == nil {
+			l.network, l.address, l.journal = "unixgram", journalSocket, true
+		} else {
+			l.network, l.address = "unixgram", "/dev/log"
+		}
+	case scheme == "udp" || scheme == "tcp":
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return nil, fmt.Errorf("address %q is not %s://host:port", s.Address, scheme)
+		}
+		l.network, l.address = scheme, addr
+	case scheme == "unix":
+		l.network, l.address = "unixgram", addr
+	default:
+		return nil, fmt.Errorf("address %q is not udp://, tcp:// or unix://", s.Address)
+	}
+	return l, nil
+}
+
+func (l *syslogger) run(queue <-chan []Event) {
+	defer func() {
+		if l.conn != nil {
+			l.conn.Close()
+		}
+	}()
+	for events := range queue {
+		for _, e := range events {
+			if err := l.write(e); err != nil {
+				fail("syslog", err)
+			}
+		}
+	}
+}
+
+// write logs e, connecting again once should the connection have broken
+func (l *syslogger) write(e Event) error {
+	var msg []byte
+	if l.journal {
+		msg = l.journalEntry(e)
+	} else {
+		msg = l.syslogMessage(e)
+	}
+	var err error
+	for range 2 {
+		if l.conn == nil {
+			if l.conn, err = net.DialTimeout(l.network, l.address, incidentTimeout); err != nil {
+				l.conn = nil
+				return err
+			}
+		}
+		l.conn.SetWriteDeadline(time.Now().Add(incidentTimeout))
+		if _, err = l.conn.Write(msg); err == nil {
+			return nil
+		}
+		l.conn.Close()
+		l.conn = nil
+	}
+	return err
+}
+
+// severity is the syslog severity of e
+func severity(e Event) int {
+	switch {
+	case e.Kind == Cleared:
+		return severityNotice
+	case e.Kind == Action && e.Fields["outcome"] == "failed":
+		return severityError
+	case e.Kind == Action, e.Kind == Listener && e.Fields["in_baseline"] == "true":
+		return severityNotice
+	case e.Level == LevelCritical:
+		return severityCrit
+	}
+	return severityWarning
+}
+
+// fields are the structured fields of e, in a stable order
+func fields(e Event) [][2]string {
+	list := [][2]string{{"kind", e.Kind}, {"source", e.Source}}
+	if e.Level != "" {
+		list = append(list, [2]string{"level", e.Level})
+	}
+	for _, name := range slices.Sorted(maps.Keys(e.Fields)) {
+		list = append(list, [2]string{name, e.Fields[name]})
+	}
+	return list
+}
+
+// syslogMessage is e as RFC 5424 puts it, framed by its length over TCP
+// as RFC 6587 has it. The local socket gets the older RFC 3164 form every
+// syslog daemon reads there, the structured data closing the message.
+func (l *syslogger) syslogMessage(e Event) []byte {
+	var sd strings.Builder
+	sd.WriteString("[" + sdID)
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+	for _, f := range fields(e) {
+		fmt.Fprintf(&sd, ` %s="%s"`, sdName(f[0]), escape.Replace(f[1]))
+	}
+	sd.WriteString("]")
+	pri := l.facility*8 + severity(e)
+	if l.network == "unixgram" {
+		return []byte(fmt.Sprintf("<%d>%s %s[%d]: %s %s", pri, e.Time.Format(time.Stamp), l.tag, os.Getpid(), e.Message, sd.String()))
+	}
+	host := e.Host
+	if host == "" {
+		host = "-"
+	}
+	msg := fmt.Sprintf("<%d>1 %s %s %s %d - %s %s", pri, e.Time.Format(time.RFC3339Nano),
+		host, l.tag, os.Getpid(), sd.String(), e.Message)
+	if l.network == "tcp" {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+	return []byte(msg)
+}
+
+// sdName makes name a valid structured data parameter name
+func sdName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// journalEntry is e in the journal's native protocol, its fields as
+// ADVIS_ ones
+func (l *syslogger) journalEntry(e Event) []byte {
+	var b bytes.Buffer
+	field := func(name, value string) {
+		if !strings.Contains(value, "\n") {
+			fmt.Fprintf(&b, "%s=%s\n", name, value)
+			return
+		}
+		// Values spanning lines are written with their length
+		b.WriteString(name + "\n")
+		binary.Write(&b, binary.LittleEndian, uint64(len(value)))
+		b.WriteString(value + "\n")
+	}
+	field("MESSAGE", e.Message)
+	field("PRIORITY", fmt.Sprint(severity(e)))
+	field("SYSLOG_FACILITY", fmt.Sprint(l.facility))
+	field("SYSLOG_IDENTIFIER", l.tag)
+	for _, f := range fields(e) {
+		field("ADVIS_"+journalName(f[0]), f[1])
+	}
+	return b.Bytes()
+}
+
+// journalName makes name a valid journal field name: upper case letters,
+// digits and underscores
+func journalName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
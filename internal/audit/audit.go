@@ -0,0 +1,55 @@
+// Package audit keeps the log of the actions taken from the monitors that
+// changed the host, such as a deleted file or a service recovered, in the
+// data directory. The system monitor's action log overlay reads it back.
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/state"
+)
+
+// Entry is a line of the audit log: an action and how it went
+type Entry struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Action  string    `json:"action"` // Such as "delete file" or "restart service"
+	Target  string    `json:"target"`
+	Outcome string    `json:"outcome"` // ok or failed
+	Error   string    `json:"error,omitempty"`
+}
+
+// New is the entry of action on target at now, failed with result unless
+// nil, taken by the current user
+func New(now time.Time, action, target string, result error) Entry {
+	e := Entry{Time: now, Action: action, Target: target, Outcome: "ok"}
+	if u, err := user.Current(); err == nil {
+		e.User = u.Username
+	}
+	if result != nil {
+		e.Outcome, e.Error = "failed", result.Error()
+	}
+	return e
+}
+
+// File is the audit log, next to the history
+func File() string {
+	return filepath.Join(state.Dir(), "audit.jsonl")
+}
+
+// Append adds e to the audit log
+func Append(e Entry) error {
+	if err := os.MkdirAll(state.Dir(), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(File(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return json.NewEncoder(file).Encode(e)
+}
@@ -0,0 +1,180 @@
+// Package baseline keeps a record of a host at rest, written by "advis
+// baseline": the processes it runs, the sockets it listens on, its CPU and
+// memory use and the idle traffic of each interface. The monitors
+// highlight whatever drifts from it beyond a tolerance, such as a process
+// or a listener that was not there, as a quick check of what changed on a
+// server.
+package baseline
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/state"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+)
+
+// Flags holds the tolerance of the comparison, parsed by the advis
+// command before the monitors are created
+var Flags = flag.NewFlagSet("baseline", flag.ExitOnError)
+
+var (
+	flagTolerance = Flags.Float64("baseline-tolerance", 50,
+		"percent above the baseline's CPU, memory and idle traffic that is highlighted as drift")
+	flagIgnore = Flags.Bool("no-baseline", false, "do not compare with the baseline recorded by advis baseline")
+)
+
+// Baseline is a host at rest
+type Baseline struct {
+	Taken     time.Time       `json:"taken"`
+	Host      string          `json:"host"`
+	CPU       float64         `json:"cpu_percent"`
+	MemUsed   uint64          `json:"mem_used"`
+	Processes []string        `json:"processes"` // Names, sorted
+	Listeners []string        `json:"listeners"` // As Listener makes them, sorted
+	Traffic   map[string]Rate `json:"traffic"`   // Idle rates by interface
+}
+
+// Rate is the traffic of an interface in bytes per second
+type Rate struct {
+	Down float64 `json:"down"`
+	Up   float64 `json:"up"`
+}
+
+// The use above the baseline's that is never drift, as an idle host has
+// the odd burst
+const (
+	trafficSlack = 4 << 10 // Bytes per second
+	cpuSlack     = 10      // Percentage points
+)
+
+// Path is the baseline file in the data directory
+func Path() string {
+	return filepath.Join(state.Dir(), "baseline.json")
+}
+
+// current is the baseline as advis started, read on first use
+var current = sync.OnceValues(func() (*Baseline, error) {
+	return Load(Path())
+})
+
+// Get returns the baseline to compare with, or nil when none was recorded
+// or -no-baseline is given
+func Get() *Baseline {
+	if *flagIgnore {
+		return nil
+	}
+	b, _ := current()
+	return b
+}
+
+// Load reads the baseline at path, nil when there is none
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Save writes b as the baseline
+func Save(b Baseline) error {
+	slices.Sort(b.Processes)
+	b.Processes = slices.Compact(b.Processes)
+	slices.Sort(b.Listeners)
+	b.Listeners = slices.Compact(b.Listeners)
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(state.Dir(), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(Path(), data, 0o644)
+}
+
+// Remove deletes the baseline
+func Remove() error {
+	err := os.Remove(Path())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Listener names a listening socket by protocol and port, such as
+// "TCP6 :22", so a service rebinding to another address is not drift
+func Listener(protocol, localAddr string) string {
+	_, port, err := net.SplitHostPort(localAddr)
+	if err != nil {
+		port = localAddr
+	}
+	return protocol + " :" + port
+}
+
+// Listening reports whether c waits for peers: a TCP socket listening, or
+// a UDP one bound without a peer
+func Listening(c netstat.Connection) bool {
+	if c.State == "LISTEN" {
+		return true
+	}
+	_, port, err := net.SplitHostPort(c.RemoteAddr)
+	return strings.HasPrefix(c.Protocol, "UDP") && (err != nil || port == "0" || port == "*")
+}
+
+// NewProcess reports whether no process called name ran in the baseline
+func (b *Baseline) NewProcess(name string) bool {
+	_, found := slices.BinarySearch(b.Processes, name)
+	return !found
+}
+
+// NewListener reports whether nothing listened on the socket in the
+// baseline
+func (b *Baseline) NewListener(protocol, localAddr string) bool {
+	_, found := slices.BinarySearch(b.Listeners, Listener(protocol, localAddr))
+	return !found
+}
+
+// Above reports whether v exceeds base by more than the tolerance
+func Above(base, v float64) bool {
+	return v > base*(1+*flagTolerance/100)
+}
+
+// CPUAbove reports whether CPU use at percent exceeds the baseline's
+// beyond the tolerance
+func (b *Baseline) CPUAbove(percent float64) bool {
+	return Above(b.CPU+cpuSlack, percent)
+}
+
+// MemoryAbove reports whether used memory exceeds the baseline's beyond
+// the tolerance
+func (b *Baseline) MemoryAbove(used uint64) bool {
+	return Above(float64(b.MemUsed), float64(used))
+}
+
+// TrafficAbove reports whether the rates of iface exceed its idle ones
+// beyond the tolerance; an interface the baseline lacks is compared with
+// no traffic at all
+func (b *Baseline) TrafficAbove(iface string, down, up float64) bool {
+	idle := b.Traffic[iface]
+	return Above(idle.Down+trafficSlack, down) || Above(idle.Up+trafficSlack, up)
+}
+
+// Tolerance is the -baseline-tolerance percentage
+func Tolerance() float64 { return *flagTolerance }
@@ -0,0 +1,88 @@
+// Package budget measures advis's own CPU and memory use and slows the
+// monitors' sampling down while the CPU use is over budget, so the tool
+// never becomes the load it is meant to diagnose.
+package budget
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/platform"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+const (
+	// window is the shortest span CPU use is averaged over, long enough
+	// that one expensive tick does not trigger a back-off on its own
+	window = 5 * time.Second
+	// maxFactor caps how far sampling is slowed down
+	maxFactor = 8
+)
+
+var guard = struct {
+	sync.Mutex
+	limit    float64 // Percent of one core, 0 for no limit
+	lastCPU  time.Duration
+	lastTime time.Time
+	cpu      float64 // Percent of one core over the last window
+	rss      uint64
+	factor   int // Current slowdown of every sampling interval
+}{factor: 1}
+
+// SetLimit sets the CPU budget in percent of one core. Zero or less
+// measures without ever backing off.
+func SetLimit(percent float64) {
+	guard.Lock()
+	defer guard.Unlock()
+	guard.limit = percent
+	if percent <= 0 {
+		guard.factor = 1
+	}
+}
+
+// Interval is base stretched by the current back-off. Monitors call it
+// whenever they schedule their next tick, which is also when the usage is
+// measured.
+func Interval(base time.Duration) time.Duration {
+	guard.Lock()
+	defer guard.Unlock()
+	measure(time.Now())
+	return base * time.Duration(guard.factor)
+}
+
+// measure updates the usage once per window and adjusts the back-off:
+// doubling it while over budget and halving it once well under
+func measure(now time.Time) {
+	if now.Sub(guard.lastTime) < window {
+		return
+	}
+	used := platform.SelfCPUTime()
+	if !guard.lastTime.IsZero() {
+		guard.cpu = float64(used-guard.lastCPU) / float64(now.Sub(guard.lastTime)) * 100
+	}
+	guard.lastCPU, guard.lastTime = used, now
+	guard.rss = platform.SelfRSS()
+
+	switch {
+	case guard.limit <= 0:
+	case guard.cpu > guard.limit:
+		guard.factor = min(guard.factor*2, maxFactor)
+	case guard.cpu < guard.limit/2 && guard.factor > 1:
+		guard.factor /= 2
+	}
+}
+
+// Status is a short summary of advis's own usage for footers
+func Status() string {
+	guard.Lock()
+	defer guard.Unlock()
+	if guard.lastTime.IsZero() {
+		return ""
+	}
+	status := fmt.Sprintf("advis %.1f%% CPU %s", guard.cpu, ui.FormatBytes(guard.rss))
+	if guard.factor > 1 {
+		status += fmt.Sprintf(" (budget %.0f%%, sampling %d× slower)", guard.limit, guard.factor)
+	}
+	return status
+}
@@ -0,0 +1,155 @@
+// Package caps probes what this host lets advis read, once at startup, so
+// views can say what a missing panel requires instead of showing zeroes.
+package caps
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/platform"
+)
+
+// Host is the outcome of the probes
+type Host struct {
+	Procfs        bool   // /proc is mounted
+	Sysfs         bool   // /sys is mounted
+	Root          bool   // Running as UID 0
+	NetAdmin      bool   // CAP_NET_ADMIN is in the effective set
+	SysPtrace     bool   // CAP_SYS_PTRACE is in the effective set
+	DacReadSearch bool   // CAP_DAC_READ_SEARCH is in the effective set
+	Netlink       bool   // sock_diag netlink sockets can be opened
+	Kmsg          bool   // /dev/kmsg can be read
+	SystemBus     bool   // The system D-Bus socket exists
+	Smartctl      string // Path of smartctl, empty when not installed
+
+	// Android is the reduced-feature mode of Android and Termux, where
+	// SELinux keeps apps from most of /proc. NoCPU and NoSockets are set
+	// when it keeps this one from /proc/stat and the socket tables, as it
+	// does from Android 8 and 10 on unless running as root.
+	Android   bool
+	NoCPU     bool
+	NoSockets bool
+
+	// ContainerSocket is the first Docker or Podman socket found, empty
+	// when none is; ContainerAccess is whether this user may connect to it
+	ContainerSocket string
+	ContainerAccess bool
+}
+
+// Capability bits of the effective set in /proc/self/status
+const (
+	capDacReadSearch = 2
+	capNetAdmin      = 12
+	capSysPtrace     = 19
+)
+
+var (
+	once sync.Once
+	host Host
+)
+
+// Get returns the probes, running them on the first call
+func Get() Host {
+	once.Do(func() { host = probe() })
+	return host
+}
+
+// Assume replaces the probes with h. The demo dataset uses it so views do
+// not annotate synthetic data with what this host lacks. It must be called
+// before the first Get.
+func Assume(h Host) {
+	once.Do(func() { host = h })
+}
+
+func probe() Host {
+	h := Host{
+		Procfs:    exists("/proc/self/stat"),
+		Sysfs:     exists("/sys/class"),
+		Root:      os.Geteuid() == 0,
+		SystemBus: exists("/run/dbus/system_bus_socket"),
+	}
+
+	if effective, ok := effectiveCaps(); ok {
+		h.NetAdmin = effective&(1<<capNetAdmin) != 0
+		h.SysPtrace = effective&(1<<capSysPtrace) != 0
+		h.DacReadSearch = effective&(1<<capDacReadSearch) != 0
+	} else {
+		h.NetAdmin, h.SysPtrace, h.DacReadSearch = h.Root, h.Root, h.Root
+	}
+
+	h.Netlink = platform.Netlink()
+	if f, err := os.OpenFile("/dev/kmsg", os.O_RDONLY|syscall.O_NONBLOCK, 0); err == nil {
+		f.Close()
+		h.Kmsg = true
+	}
+	h.Smartctl, _ = exec.LookPath("smartctl")
+
+	if h.Android = platform.Android(); h.Android {
+		h.NoCPU = !readable("/proc/stat")
+		h.NoSockets = !readable("/proc/net/tcp") && !readable("/proc/net/tcp6")
+	}
+
+	for _, socket := range runtimeSockets() {
+		if info, err := os.Stat(socket); err == nil && info.Mode()&os.ModeSocket != 0 {
+			h.ContainerSocket = socket
+			h.ContainerAccess = platform.CanConnect(socket)
+			break
+		}
+	}
+	return h
+}
+
+// OtherProcesses reports whether the I/O counters and file descriptors of
+// other users' processes are readable
+func (h Host) OtherProcesses() bool {
+	return h.Root || h.SysPtrace
+}
+
+// runtimeSockets are the Docker and Podman sockets looked for, in order
+func runtimeSockets() []string {
+	var sockets []string
+	if host := os.Getenv("DOCKER_HOST"); strings.HasPrefix(host, "unix://") {
+		sockets = append(sockets, strings.TrimPrefix(host, "unix://"))
+	}
+	sockets = append(sockets, "/var/run/docker.sock", "/run/podman/podman.sock")
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		sockets = append(sockets, filepath.Join(dir, "podman", "podman.sock"))
+	}
+	return sockets
+}
+
+// effectiveCaps reads the CapEff mask of this process
+func effectiveCaps() (uint64, bool) {
+	data, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return 0, false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if hex, ok := strings.CutPrefix(line, "CapEff:"); ok {
+			mask, err := strconv.ParseUint(strings.TrimSpace(hex), 16, 64)
+			return mask, err == nil
+		}
+	}
+	return 0, false
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// readable reports whether path opens for reading, which SELinux may
+// refuse for a file that exists
+func readable(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
@@ -0,0 +1,67 @@
+package caps
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// Privilege is a capability that lets advis read what otherwise takes
+// root, given to the binary with setcap
+type Privilege struct {
+	Cap     string // As setcap names it
+	Enables string
+	held    func(Host) bool
+}
+
+// Privileges are the capabilities "advis setcap" grants, each needed by
+// the features it enables. With cap_dac_read_search whoever runs the
+// binary reads any file through the paths advis is given, so it grants
+// them to a binary only a group may run.
+var Privileges = []Privilege{
+	{Cap: "cap_sys_ptrace", Enables: "I/O and file descriptors of other users' processes, and the sockets of containers they run",
+		held: func(h Host) bool { return h.OtherProcesses() }},
+	{Cap: "cap_dac_read_search", Enables: "directory sizes and mounts below directories this user cannot read",
+		held: func(h Host) bool { return h.Root || h.DacReadSearch }},
+	{Cap: "cap_syslog", Enables: "kernel events on the Kernel tab where dmesg_restrict is set",
+		held: func(h Host) bool { return h.Kmsg }},
+}
+
+// Held reports whether advis has p, or does not need it
+func (p Privilege) Held(h Host) bool { return p.held(h) }
+
+// Missing returns the privileges advis lacks on h
+func Missing(h Host) []Privilege {
+	var missing []Privilege
+	for _, p := range Privileges {
+		if !p.Held(h) {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// AsRoot returns the command running name with args as root, through sudo
+// or, where it is missing, pkexec. It is for one privileged action, so the
+// monitor itself never has to run as root.
+func AsRoot(name string, args ...string) (*exec.Cmd, error) {
+	if os.Geteuid() == 0 {
+		return exec.Command(name, args...), nil
+	}
+	for _, tool := range []string{"sudo", "pkexec"} {
+		if path, err := exec.LookPath(tool); err == nil {
+			return exec.Command(path, append([]string{name}, args...)...), nil
+		}
+	}
+	return nil, errors.New("neither sudo nor pkexec is installed")
+}
+
+// Elevate returns the command running this advis binary with args as
+// root, as AsRoot does
+func Elevate(args ...string) (*exec.Cmd, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return AsRoot(exe, args...)
+}
@@ -0,0 +1,325 @@
+// Package config reads the optional advis configuration file, a JSON
+// document for settings that do not fit on a command line:
+//
+//	{
+//	  "alerting": {
+//	    "email": {
+//	      "server": "smtp.example.com:587", "username": "advis", "password_file": "/etc/advis/smtp",
+//	      "from": "advis@example.com", "to": ["ops@example.com"], "level": "critical", "batch": "5m",
+//	      "routes": {"baseline": {"to": ["security@example.com"], "level": "warning"}, "entropy": {"level": "off"}}
+//	    },
+//	    "pagerduty": {"routing_key_file": "/etc/advis/pagerduty"},
+//	    "syslog": {"address": "udp://logs.example.com:514", "facility": "local0"},
+//	    "flap": {"changes": 4, "window": "10m"}
+//	  },
+//	  "keys": {
+//	    "sys": {"quit": ["q", "ctrl+q"], "logs": ["l"]},
+//	    "net": {"reset": []}
+//	  },
+//	  "history": {"raw": "24h", "minutely": "30d", "max_size": "1G"},
+//	  "icons": "nerdfont",
+//	  "interfaces": ["eth*", "wlan0"],
+//	  "listen": {"token_file": "/etc/advis/token", "tls_cert": "/etc/advis/cert.pem", "tls_key": "/etc/advis/key.pem"},
+//	  "locale": "de",
+//	  "notify": {
+//	    "on": "bell", "level": "critical", "quiet_hours": "22:00-07:00",
+//	    "rules": {"watch": {"on": "flash", "level": "warning"}, "disk_full": {"quiet_hours": "none"}}
+//	  },
+//	  "palette": "colorblind",
+//	  "tabs": {
+//	    "sys": ["processes", "services", "system"]
+//	  },
+//	  "thresholds": {
+//	    "cpu": {"warning": 60, "critical": 90},
+//	    "bandwidth": {"warning": 70, "critical": 95, "critical_color": "#FF00FF"}
+//	  },
+//	  "layouts": [
+//	    {"name": "network", "columns": 3, "rows": [
+//	      {"height": 2, "widgets": [{"widget": "netgraph", "span": 2}, "toptalkers"]},
+//	      {"widgets": ["cpu", "mem", "procs"]}
+//	    ]}
+//	  ],
+//	  "profiles": {
+//	    "gateway": {"interfaces": ["wan0", "lan0"], "thresholds": {"bandwidth": {"warning": 50}}},
+//	    "laptop": {"interfaces": ["wlan0"], "layouts": []}
+//	  },
+//	  "watchdog": [
+//	    {"name": "nginx", "process": "nginx", "url": "http://127.0.0.1/health", "failures": 3,
+//	     "recover": ["systemctl", "restart", "nginx"], "cooldown": "5m"}
+//	  ]
+//	}
+//
+// "alerting" sends alerts as they fire and clear to channels beyond the
+// terminal, from the monitors and from "advis snapshot" as it collects. The
+// "email" channel mails the events arriving within batch of the first
+// (default 1m) together, at or above level (default warning), through
+// server: port 465 speaks TLS at once and others upgrade with STARTTLS
+// when offered. "subject" and "body" are Go templates over .Host,
+// .Events, each with .Time, .Kind, .Level, .Source and .Message, and the
+// counts .Fired, .Cleared and .Critical. "routes" mails the alerts of a
+// source, or of a rules script alert by name, to other recipients or at
+// another level, "off" mailing none. "pagerduty" triggers an incident
+// through the Events API v2, and "opsgenie" creates an alert, when an
+// alert at or above level (default critical) fires, and resolves or closes
+// it when the alert clears; the incident of an alert is found again by a
+// key made from the host and the alert. The channels see an alert fire
+// or clear once, and "flap" holds back the events of an alert that fires
+// or clears changes times within window, these being the defaults, until
+// it keeps still for window; a negative changes never holds them back. The
+// rules script sets hysteresis and minimum durations per alert. "syslog"
+// logs every alert as it fires and clears, and also the actions taken from
+// the monitors and the sockets that start listening while the socket table
+// is read, to the local journal with ADVIS_ fields, or with no journal to
+// /dev/log; an address of udp://, tcp:// or unix:// logs there in RFC 5424
+// with the fields as structured data. facility defaults to daemon and tag
+// to advis.
+//
+// "keys" overrides key bindings per monitor ("sys", "net", "plugins",
+// "rules" and "all" for the dashboard switcher), by the binding names
+// each monitor's keymap declares. An empty list unbinds the action.
+//
+// "history" bounds the snapshots "advis snapshot" keeps: a day is kept as
+// taken until all of it is older than raw, as 1-minute averages until
+// minutely and as hourly ones beyond, and the oldest days are removed
+// while the history takes more than max_size. These are the defaults. A
+// day is packed once it is over, its figures Gorilla encoded, which takes
+// about a hundredth of the JSON lines it is written as.
+//
+// "icons" is "emoji", "nerdfont" for the glyphs of a Nerd Font patched
+// font, or "text" for words in place of the icons that carry meaning
+// and nothing for the rest. Left out it is text on the Linux console,
+// nerdfont in kitty and WezTerm, which bundle the glyphs, and emoji
+// elsewhere.
+//
+// "interfaces" are the network interfaces the net monitor shows and
+// counts in its totals, by name or by a pattern such as "eth*". Left out it
+// shows them all.
+//
+// "listen" secures the modes that serve over HTTP: clients must send
+// "Authorization: Bearer" and the token, given here or read from
+// token_file, and with tls_cert and tls_key the modes serve HTTPS. They
+// bind the loopback interface unless their address names another, which
+// they refuse without a token.
+//
+// "locale" is how figures are written, a language tag such as "en" for
+// 1,234.5 or "de" for 1.234,5. Left out it follows LC_ALL, LC_NUMERIC or
+// LANG; "none" writes them without digit grouping.
+//
+// "notify" rings the terminal bell, flashes the screen or both when an
+// alert fires, unless it is below the level or within the quiet hours,
+// which may wrap past midnight. "rules" overrides these by alert source,
+// such as "watch", "systemd" or "storage", or by the name of a rules script
+// alert; fields left out there follow the defaults. Alerts notify
+// nothing unless "on" is set.
+//
+// "palette" recolors every monitor: "colorblind" tells download from
+// upload and healthy from critical by blue and orange, safe with
+// deuteranopia and protanopia, and "high-contrast" uses saturated colors
+// on black. Threshold colors given below still win.
+//
+// "tabs" picks the tabs of "sys" and "net" to show, in tab bar order; the
+// number keys follow it. A monitor left out shows all of its tabs. The
+// sys tabs are system, disk, processes, dirscan, containers, services,
+// kernel, cgroups, memory, interrupts and watchdog; the net tabs are speed,
+// interfaces, connections, graph and map.
+//
+// "thresholds" color the bars of cpu, memory, disk, bandwidth and every
+// other bar: above the warning or critical percentage they take that
+// level's color. Bandwidth bars are relative to the peak rate seen.
+//
+// "layouts" are dashboards for "advis all" to cycle through besides the
+// default one. Each row takes a share of the height given by its height
+// and each widget spans one or more columns. The widgets are cpu, mem,
+// disk, procs, sensors, net, netgraph, toptalkers, rules and
+// plugin:<file name>.
+//
+// "profiles" are named sets of interfaces, thresholds, layouts and notify
+// settings for the machines one config file serves, such as "laptop" or
+// "homeserver". The one given by -profile replaces those sections, a
+// threshold metric at a time; "advis all" switches between them at
+// runtime.
+//
+// "watchdog" lists the services the system monitor and "advis watchdog"
+// supervise: every interval (default 10s) the named process must be
+// running and the url must answer a GET with a 2xx status within timeout
+// (default 5s). A service failing failures checks in a row (default 3) is
+// down, which raises a critical alert and runs recover, a command and its
+// arguments given ADVIS_SERVICE and ADVIS_CAUSE, at most once per
+// cooldown (default 5m) and never with -read-only. The system monitor
+// only runs it with -watchdog-recover. The Watchdog tab shows each
+// service's checks and recoveries.
+//
+// Every key may also be given as an environment variable, ADVIS_CONFIG_
+// and the key in upper case such as ADVIS_CONFIG_PALETTE=colorblind,
+// which wins over the file. Lists of names may be comma separated, as in
+// ADVIS_CONFIG_INTERFACES=eth0,eth1, and the other sections take the JSON
+// the file would have. Every flag may be given as ADVIS_, the subcommand
+// and its name, as in ADVIS_STATUS_FORMAT for "advis status -format", or
+// as ADVIS_ and its name for a flag of these shared by the subcommands, as
+// in ADVIS_PROFILE=gateway for -profile; the command line wins.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/alerting"
+	"github.com/s-archdev/Terminal_ADVIS/internal/server"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/internal/watchdog"
+)
+
+// Flags holds the location of the configuration file, parsed by the advis
+// command before Read is called
+var Flags = flag.NewFlagSet("config", flag.ExitOnError)
+
+var (
+	flagPath    = Flags.String("config", DefaultPath(), "JSON configuration file, such as key binding overrides")
+	flagProfile = Flags.String("profile", "", "profile of the configuration file to start with")
+)
+
+// File is the configuration file's content
+type File struct {
+	Keys       map[string]map[string][]string `json:"keys"`
+	Icons      string                         `json:"icons"`
+	Interfaces []string                       `json:"interfaces"`
+	Listen     server.Options                 `json:"listen"`
+	Locale     string                         `json:"locale"`
+	Notify     ui.Notification                `json:"notify"`
+	Palette    string                         `json:"palette"`
+	Tabs       map[string][]string            `json:"tabs"`
+	Thresholds map[string]ui.Threshold        `json:"thresholds"`
+	Layouts    []ui.Layout                    `json:"layouts"`
+	Profiles   map[string]Profile             `json:"profiles"`
+	History    History                        `json:"history"`
+	Alerting   alerting.Config                `json:"alerting"`
+	Watchdog   []watchdog.Service             `json:"watchdog"`
+}
+
+// History is how long the snapshot history is kept at which resolution,
+// and the most disk space it may take
+type History struct {
+	Raw      string `json:"raw"`      // As taken, such as "24h"
+	Minutely string `json:"minutely"` // As 1-minute averages, such as "30d"; hourly beyond
+	MaxSize  string `json:"max_size"` // Such as "1G", or "0" for no cap
+}
+
+// Profile is a named set of settings to use in place of the file's own.
+// Those left out are the file's.
+type Profile struct {
+	Interfaces []string                `json:"interfaces"`
+	Thresholds map[string]ui.Threshold `json:"thresholds"`
+	Layouts    []ui.Layout             `json:"layouts"`
+	Notify     *ui.Notification        `json:"notify"`
+}
+
+// DefaultPath is advis/config.json under the user's configuration
+// directory, or empty when that cannot be determined
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "advis", "config.json")
+}
+
+// Path is the configuration file given by Flags
+func Path() string { return *flagPath }
+
+// ProfileName is the profile given by Flags, empty for none
+func ProfileName() string { return *flagProfile }
+
+// ProfileNames returns the names of the file's profiles in order
+func (f File) ProfileNames() []string {
+	return slices.Sorted(maps.Keys(f.Profiles))
+}
+
+// WithProfile returns the settings of the profile called name in place of
+// the file's own. An empty name is the file as it is.
+func (f File) WithProfile(name string) (File, error) {
+	if name == "" {
+		return f, nil
+	}
+	p, ok := f.Profiles[name]
+	if !ok {
+		return f, fmt.Errorf("no profile %q (known: %s)", name, strings.Join(f.ProfileNames(), ", "))
+	}
+	if p.Interfaces != nil {
+		f.Interfaces = p.Interfaces
+	}
+	if p.Thresholds != nil {
+		thresholds := maps.Clone(f.Thresholds)
+		if thresholds == nil {
+			thresholds = make(map[string]ui.Threshold, len(p.Thresholds))
+		}
+		maps.Copy(thresholds, p.Thresholds)
+		f.Thresholds = thresholds
+	}
+	if p.Layouts != nil {
+		f.Layouts = p.Layouts
+	}
+	if p.Notify != nil {
+		f.Notify = *p.Notify
+	}
+	return f, nil
+}
+
+// Read loads the configuration file given by Flags
+func Read() (File, error) {
+	return Load(*flagPath)
+}
+
+// Load reads the configuration at path, with the ADVIS_CONFIG_ variables
+// set in the environment in place of its keys. A missing file, or an empty
+// path, is an empty configuration.
+func Load(path string) (File, error) {
+	var f File
+	var data []byte
+	var err error
+	if path != "" {
+		data, err = os.ReadFile(path)
+	}
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return f, err
+	case data != nil:
+		if err := json.Unmarshal(data, &f); err != nil {
+			return f, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := fromEnv(&f); err != nil {
+		return f, err
+	}
+	if err := checkLayouts(f.Layouts); err != nil {
+		return f, fmt.Errorf("%s: %w", path, err)
+	}
+	for name, p := range f.Profiles {
+		if err := checkLayouts(p.Layouts); err != nil {
+			return f, fmt.Errorf("%s: profiles.%s: %w", path, name, err)
+		}
+	}
+	return f, nil
+}
+
+func checkLayouts(layouts []ui.Layout) error {
+	names := make(map[string]bool, len(layouts))
+	for _, l := range layouts {
+		if err := l.Check(); err != nil {
+			return err
+		}
+		if names[l.Name] {
+			return fmt.Errorf("layout %q defined twice", l.Name)
+		}
+		names[l.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// EnvPrefix starts the environment variables standing in for the flags
+// and the config file's settings, for containers and systemd units
+// configured without a config file. The keys have their own prefix after
+// it, as a key may share a flag's name but not its syntax: "history" is a
+// JSON section of the file and a duration for -history.
+const (
+	EnvPrefix    = "ADVIS_"
+	envKeyPrefix = EnvPrefix + "CONFIG_"
+)
+
+// EnvName is the variable for the flag name of the subcommand cmd: ADVIS_,
+// the subcommand and the name in upper case with dashes as underscores,
+// such as ADVIS_STATUS_FORMAT for "advis status -format", as subcommands
+// give one name flags of their own syntax. A flag shared by subcommands,
+// such as -profile, which means the same to each, leaves the subcommand
+// out, cmd being empty: ADVIS_PROFILE.
+func EnvName(cmd, name string) string {
+	if cmd == "" {
+		return EnvPrefix + envSuffix(name)
+	}
+	return EnvPrefix + envSuffix(cmd) + "_" + envSuffix(name)
+}
+
+// keyEnvName is the variable for a config file key, such as
+// ADVIS_CONFIG_PALETTE for "palette"
+func keyEnvName(key string) string {
+	return envKeyPrefix + envSuffix(key)
+}
+
+func envSuffix(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// SetFlagsFromEnv sets each flag of fs whose variable is set, fs being the
+// flags of the subcommand its name ends with. The flags of the shared sets
+// it took in take the variables without the subcommand. Called before fs
+// parses the command line, so flags given there win.
+func SetFlagsFromEnv(fs *flag.FlagSet, shared ...*flag.FlagSet) error {
+	cmd := fs.Name()
+	if i := strings.LastIndexByte(cmd, ' '); i >= 0 {
+		cmd = cmd[i+1:]
+	}
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		env := EnvName(cmd, f.Name)
+		for _, set := range shared {
+			if set.Lookup(f.Name) != nil {
+				env = EnvName("", f.Name)
+			}
+		}
+		v, ok := os.LookupEnv(env)
+		if !ok || err != nil {
+			return
+		}
+		if e := fs.Set(f.Name, v); e != nil {
+			err = fmt.Errorf("%s: %w", env, e)
+		}
+	})
+	return err
+}
+
+// fromEnv sets the keys of f whose variable is set, in place of the
+// file's. A string is taken as it is, a list of strings may be comma
+// separated, and the rest are JSON as in the file, such as
+// ADVIS_CONFIG_THRESHOLDS='{"cpu": {"critical": 90}}'.
+func fromEnv(f *File) error {
+	v := reflect.ValueOf(f).Elem()
+	for i := range v.NumField() {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		env := keyEnvName(name)
+		s, ok := os.LookupEnv(env)
+		if !ok {
+			continue
+		}
+		switch field := v.Field(i).Addr().Interface().(type) {
+		case *string:
+			*field = s
+		case *[]string:
+			if !strings.HasPrefix(strings.TrimSpace(s), "[") {
+				*field = splitList(s)
+				continue
+			}
+			if err := json.Unmarshal([]byte(s), field); err != nil {
+				return fmt.Errorf("%s: %w", env, err)
+			}
+		default:
+			if err := json.Unmarshal([]byte(s), field); err != nil {
+				return fmt.Errorf("%s: %w", env, err)
+			}
+		}
+	}
+	return nil
+}
+
+// splitList splits a comma separated list, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
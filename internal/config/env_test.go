@@ -0,0 +1,120 @@
+package config
+
+import (
+	"flag"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("ADVIS_CONFIG_PALETTE", "colorblind")
+	t.Setenv("ADVIS_CONFIG_INTERFACES", " eth0, ,eth1,")
+	t.Setenv("ADVIS_CONFIG_HISTORY", `{"raw": "12h", "max_size": "1G"}`)
+	t.Setenv("ADVIS_CONFIG_THRESHOLDS", `{"cpu": {"critical": 90}}`)
+	f := File{Palette: "dark", Interfaces: []string{"wlan0"}}
+	if err := fromEnv(&f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Palette != "colorblind" {
+		t.Errorf("palette = %q, want colorblind", f.Palette)
+	}
+	if want := []string{"eth0", "eth1"}; !slices.Equal(f.Interfaces, want) {
+		t.Errorf("interfaces = %q, want %q", f.Interfaces, want)
+	}
+	if f.History.Raw != "12h" || f.History.MaxSize != "1G" {
+		t.Errorf("history = %+v", f.History)
+	}
+	if f.Thresholds["cpu"].Critical != 90 {
+		t.Errorf("thresholds = %+v", f.Thresholds)
+	}
+}
+
+func TestFromEnvJSONList(t *testing.T) {
+	t.Setenv("ADVIS_CONFIG_INTERFACES", ` ["eth0", "br,0"]`)
+	var f File
+	if err := fromEnv(&f); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"eth0", "br,0"}; !slices.Equal(f.Interfaces, want) {
+		t.Errorf("interfaces = %q, want %q", f.Interfaces, want)
+	}
+}
+
+func TestFromEnvBadJSON(t *testing.T) {
+	for _, env := range []string{"ADVIS_CONFIG_HISTORY", "ADVIS_CONFIG_INTERFACES"} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, "[30m")
+			var f File
+			err := fromEnv(&f)
+			if err == nil || !strings.HasPrefix(err.Error(), env+": ") {
+				t.Errorf("error = %v, want one naming %s", err, env)
+			}
+		})
+	}
+}
+
+// A key and a flag of one name take their own variables, so neither's
+// syntax breaks the other
+func TestEnvKeyAndFlagOfOneName(t *testing.T) {
+	t.Setenv("ADVIS_SNAPSHOT_HISTORY", "30m")
+	t.Setenv("ADVIS_CONFIG_HISTORY", `{"raw": "2h"}`)
+
+	var f File
+	if err := fromEnv(&f); err != nil {
+		t.Fatalf("fromEnv: %v", err)
+	}
+	if f.History.Raw != "2h" {
+		t.Errorf("history.raw = %q, want 2h", f.History.Raw)
+	}
+
+	fs := flag.NewFlagSet("advis snapshot", flag.ContinueOnError)
+	history := fs.Duration("history", time.Hour, "")
+	if err := SetFlagsFromEnv(fs); err != nil {
+		t.Fatalf("SetFlagsFromEnv: %v", err)
+	}
+	if *history != 30*time.Minute {
+		t.Errorf("-history = %v, want 30m", *history)
+	}
+}
+
+// Flags of one name in two subcommands take variables of their own, and
+// flags the subcommands share take one without the subcommand
+func TestSetFlagsFromEnvScoped(t *testing.T) {
+	t.Setenv("ADVIS_STATUS_FORMAT", "i3bar")
+	t.Setenv("ADVIS_QUERY_FORMAT", "json")
+	t.Setenv("ADVIS_PROFILE", "gateway")
+	t.Setenv("ADVIS_STATUS_PROFILE", "laptop")
+
+	shared := flag.NewFlagSet("shared", flag.ContinueOnError)
+	shared.String("profile", "", "")
+	sets := map[string]string{"advis status": "i3bar", "advis query": "json"}
+	for name, want := range sets {
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		format := fs.String("format", "text", "")
+		profile := fs.String("profile", "", "")
+		if err := SetFlagsFromEnv(fs, shared); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if *format != want {
+			t.Errorf("%s -format = %q, want %q", name, *format, want)
+		}
+		if *profile != "gateway" {
+			t.Errorf("%s -profile = %q, want gateway", name, *profile)
+		}
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := []struct{ cmd, name, want string }{
+		{"status", "format", "ADVIS_STATUS_FORMAT"},
+		{"", "screenshot-dir", "ADVIS_SCREENSHOT_DIR"},
+		{"setcap", "dry-run", "ADVIS_SETCAP_DRY_RUN"},
+	}
+	for _, tt := range tests {
+		if got := EnvName(tt.cmd, tt.name); got != tt.want {
+			t.Errorf("EnvName(%q, %q) = %q, want %q", tt.cmd, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package debug writes diagnostics, such as collector timings, parse
+// failures and dropped data, to a log file when advis runs with -debug.
+// The monitors own the terminal, so nothing is ever written to stdout.
+// Every function is a no-op until Open is called.
+package debug
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// maxSize is the size at which the log is rotated
+	maxSize = 5 << 20
+	// keep is how many rotated logs are kept next to the current one
+	keep = 3
+)
+
+var log struct {
+	sync.Mutex
+	path string
+	file *os.File
+	size int64
+}
+
+// DefaultPath is advis/debug.log under the user's cache directory
+func DefaultPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "advis", "debug.log")
+}
+
+// Open starts logging to path, appending to what is already there
+func Open(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	log.Lock()
+	defer log.Unlock()
+	log.path, log.file, log.size = path, file, info.Size()
+	return nil
+}
+
+// Close stops logging
+func Close() error {
+	log.Lock()
+	defer log.Unlock()
+	if log.file == nil {
+		return nil
+	}
+	err := log.file.Close()
+	log.file = nil
+	return err
+}
+
+// Enabled reports whether Open was called, for callers that want to skip
+// work only the log needs
+func Enabled() bool {
+	log.Lock()
+	defer log.Unlock()
+	return log.file != nil
+}
+
+// Logf writes one timestamped line
+func Logf(format string, args ...any) {
+	log.Lock()
+	defer log.Unlock()
+	if log.file == nil {
+		return
+	}
+	line := time.Now().Format("2006-01-02T15:04:05.000 ") + fmt.Sprintf(format, args...) + "\n"
+	if log.size+int64(len(line)) > maxSize {
+		rotate()
+	}
+	n, _ := log.file.WriteString(line)
+	log.size += int64(n)
+}
+
+// Timing logs how long what took since start, and its error if any
+func Timing(what string, start time.Time, err error) {
+	if err != nil {
+		Logf("%s took %v: %v", what, time.Since(start).Round(time.Microsecond), err)
+		return
+	}
+	Logf("%s took %v", what, time.Since(start).Round(time.Microsecond))
+}
+
+// rotate shifts debug.log to debug.log.1 and so on, dropping the oldest,
+// and starts an empty log. Failing to, it keeps appending to the old one.
+func rotate() {
+	for i := keep - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", log.path, i), fmt.Sprintf("%s.%d", log.path, i+1))
+	}
+	log.file.Close()
+	os.Rename(log.path, log.path+".1")
+	file, err := os.OpenFile(log.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		// Reopen the renamed file so logging carries on
+		if file, err = os.OpenFile(log.path+".1", os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+			log.file = nil
+			return
+		}
+	}
+	log.file, log.size = file, 0
+}
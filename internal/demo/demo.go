@@ -0,0 +1,342 @@
+// Package demo replays a bundled synthetic dataset through the same
+// collector interfaces as real data, for screenshots and talks. The
+// scenario loops every ten minutes through a CPU spike, a network burst, a
+// process running out of file descriptors, processes stuck in D state,
+// memory pressure and a filling disk, with a slow leak throughout.
+package demo
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/proc"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/sysstat"
+)
+
+//go:embed scenario.json
+var scenarioJSON []byte
+
+// jitter is the relative noise added to every replayed value
+const jitter = 0.04
+
+// scenario is the decoded scenario.json
+type scenario struct {
+	Length float64               `json:"length"` // Seconds before the scenario loops
+	CPUs   int                   `json:"cpus"`
+	Memory uint64                `json:"memory"`
+	Series map[string][]keyframe `json:"series"`
+
+	Mounts []struct {
+		Path   string `json:"path"`
+		Device string `json:"device"`
+		FSType string `json:"fstype"`
+		Size   uint64 `json:"size"`
+		Used   value  `json:"used"` // Percent
+	} `json:"mounts"`
+
+	Interfaces []struct {
+		Name string `json:"name"`
+		Down value  `json:"down"` // Bytes per second
+		Up   value  `json:"up"`
+	} `json:"interfaces"`
+
+	Processes []struct {
+		PID     int    `json:"pid"`
+		Name    string `json:"name"`
+		Cmdline string `json:"cmdline"`
+		User    string `json:"user"`
+		CPU     value  `json:"cpu"`
+		Memory  value  `json:"memory"`
+		FDs     value  `json:"fds"`
+		FDLimit uint64 `json:"fd_limit"`
+		Read    value  `json:"read"`
+		Write   value  `json:"write"`
+	} `json:"processes"`
+
+	Connections []struct {
+		Protocol string `json:"protocol"`
+		Local    string `json:"local"`
+		Remote   string `json:"remote"`
+		State    string `json:"state"`
+	} `json:"connections"`
+}
+
+// keyframe is a [seconds, value] pair; values in between are interpolated
+type keyframe [2]float64
+
+// value is either a constant or the name of a series
+type value struct {
+	constant float64
+	series   string
+}
+
+func (v *value) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &v.series); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &v.constant)
+}
+
+// Replay hands out collectors that play the scenario back. Every collector
+// advances by its own interval per Collect, so a given seed always
+// produces the same data regardless of timing.
+type Replay struct {
+	scenario scenario
+	seed     int64
+	start    time.Time
+}
+
+// New loads the bundled scenario
+func New(seed int64) (*Replay, error) {
+	r := &Replay{seed: seed, start: time.Now()}
+	if err := json.Unmarshal(scenarioJSON, &r.scenario); err != nil {
+		return nil, fmt.Errorf("demo scenario: %w", err)
+	}
+	for _, name := range []string{"cpu", "load", "memory", "stuck"} {
+		if len(r.scenario.Series[name]) == 0 {
+			return nil, fmt.Errorf("demo scenario: missing series %q", name)
+		}
+	}
+	for _, v := range r.values() {
+		if v.series != "" && r.scenario.Series[v.series] == nil {
+			return nil, fmt.Errorf("demo scenario: unknown series %q", v.series)
+		}
+	}
+	return r, nil
+}
+
+// values lists every value reference, for validation
+func (r *Replay) values() []value {
+	var vs []value
+	for _, m := range r.scenario.Mounts {
+		vs = append(vs, m.Used)
+	}
+	for _, i := range r.scenario.Interfaces {
+		vs = append(vs, i.Down, i.Up)
+	}
+	for _, p := range r.scenario.Processes {
+		vs = append(vs, p.CPU, p.Memory, p.FDs, p.Read, p.Write)
+	}
+	return vs
+}
+
+// clock is the playback position of one collector
+type clock struct {
+	replay   *Replay
+	interval time.Duration
+	step     int
+	rng      *rand.Rand
+}
+
+func (r *Replay) newClock(interval time.Duration, stream int64) *clock {
+	return &clock{replay: r, interval: interval, rng: rand.New(rand.NewSource(r.seed*31 + stream))}
+}
+
+// tick advances one interval and returns the wall time to report and the
+// position within the scenario in seconds
+func (c *clock) tick() (time.Time, float64) {
+	c.step++
+	elapsed := time.Duration(c.step) * c.interval
+	pos := elapsed.Seconds()
+	if length := c.replay.scenario.Length; length > 0 {
+		for pos >= length {
+			pos -= length
+		}
+	}
+	return c.replay.start.Add(elapsed), pos
+}
+
+// at evaluates v at pos with jitter
+func (c *clock) at(v value, pos float64) float64 {
+	x := v.constant
+	if v.series != "" {
+		x = interpolate(c.replay.scenario.Series[v.series], pos)
+	}
+	return x * (1 + jitter*(2*c.rng.Float64()-1))
+}
+
+// interpolate returns the value of a keyframe series at pos
+func interpolate(frames []keyframe, pos float64) float64 {
+	i := sort.Search(len(frames), func(i int) bool { return frames[i][0] > pos })
+	switch {
+	case i == 0:
+		return frames[0][1]
+	case i == len(frames):
+		return frames[len(frames)-1][1]
+	}
+	a, b := frames[i-1], frames[i]
+	return a[1] + (b[1]-a[1])*(pos-a[0])/(b[0]-a[0])
+}
+
+// System returns a collector for host-wide figures, sampled every interval
+func (r *Replay) System(interval time.Duration) sysstat.Collector {
+	return &systemReplay{clock: r.newClock(interval, 1)}
+}
+
+type systemReplay struct {
+	*clock
+}
+
+func (s *systemReplay) Collect(ctx context.Context) (sysstat.Snapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return sysstat.Snapshot{}, err
+	}
+	sc := s.replay.scenario
+	now, pos := s.tick()
+
+	memPercent := min(s.at(value{series: "memory"}, pos), 100)
+	used := uint64(float64(sc.Memory) * memPercent / 100)
+	snap := sysstat.Snapshot{
+		Time: now,
+		Info: sysstat.Info{
+			OS:          "linux",
+			Arch:        "amd64",
+			CPUs:        sc.CPUs,
+			MemTotal:    sc.Memory,
+			MemUsed:     used,
+			MemFree:     sc.Memory - used,
+			LoadAverage: s.at(value{series: "load"}, pos),
+			FilesOpen:   uint64(s.at(value{constant: 9800}, pos)),
+			FilesMax:    9223372036854775807,
+			Entropy:     256,
+			EntropyPool: 256,
+		},
+		CPU: min(s.at(value{series: "cpu"}, pos), 100),
+	}
+	for range sc.CPUs {
+		snap.Cores = append(snap.Cores, min(s.at(value{constant: snap.CPU}, pos)*(0.6+0.8*s.rng.Float64()), 100))
+	}
+	for _, m := range sc.Mounts {
+		used := uint64(float64(m.Size) * min(s.at(m.Used, pos), 100) / 100)
+		snap.Mounts = append(snap.Mounts, sysstat.Disk{
+			Total:  m.Size,
+			Used:   used,
+			Free:   m.Size - used,
+			Path:   m.Path,
+			Device: m.Device,
+			FSType: m.FSType,
+		})
+	}
+	return snap, nil
+}
+
+// Processes returns a collector for the process list, sampled every
+// interval
+func (r *Replay) Processes(interval time.Duration) proc.Collector {
+	return &processReplay{clock: r.newClock(interval, 2)}
+}
+
+type processReplay struct {
+	*clock
+	io map[int][2]uint64 // Cumulative read and write bytes by PID
+}
+
+func (p *processReplay) Collect(ctx context.Context) ([]proc.Process, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sc := p.replay.scenario
+	_, pos := p.tick()
+	if p.io == nil {
+		p.io = make(map[int][2]uint64)
+	}
+
+	var procs []proc.Process
+	for _, sp := range sc.Processes {
+		pr := proc.Process{
+			PID:       sp.PID,
+			PPID:      1,
+			Name:      sp.Name,
+			Cmdline:   sp.Cmdline,
+			State:     "S",
+			User:      sp.User,
+			CPU:       p.at(sp.CPU, pos),
+			Memory:    uint64(p.at(sp.Memory, pos)),
+			ReadRate:  p.at(sp.Read, pos),
+			WriteRate: p.at(sp.Write, pos),
+			HasIO:     true,
+			FDs:       int(p.at(sp.FDs, pos)),
+			FDLimit:   sp.FDLimit,
+			OOMScore:  int(p.at(sp.Memory, pos) / float64(sc.Memory) * 1000),
+		}
+		if sp.PID == 1 {
+			pr.PPID = 0
+		}
+		if pr.CPU > 1 {
+			pr.State = "R"
+		}
+		pr.FDs = min(pr.FDs, int(sp.FDLimit))
+		total := p.io[sp.PID]
+		total[0] += uint64(pr.ReadRate * p.interval.Seconds())
+		total[1] += uint64(pr.WriteRate * p.interval.Seconds())
+		p.io[sp.PID] = total
+		pr.ReadBytes, pr.WriteBytes = total[0], total[1]
+		procs = append(procs, pr)
+	}
+
+	// Backup workers blocked on a hung NFS server
+	stuck := int(interpolate(sc.Series["stuck"], pos))
+	for i := range stuck {
+		procs = append(procs, proc.Process{
+			PID:     4100 + i,
+			PPID:    3001,
+			Name:    "rsync",
+			Cmdline: "rsync -a --delete /srv/media/ /mnt/nfs/backup/media/",
+			State:   "D",
+			User:    "backup",
+			FDs:     -1,
+		})
+	}
+	return procs, nil
+}
+
+// Network returns a collector for interface counters and sockets, sampled
+// every interval
+func (r *Replay) Network(interval time.Duration) netstat.Collector {
+	return &networkReplay{clock: r.newClock(interval, 3)}
+}
+
+type networkReplay struct {
+	*clock
+	counters []netstat.Counter
+}
+
+func (n *networkReplay) Collect(ctx context.Context) (netstat.Snapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return netstat.Snapshot{}, err
+	}
+	sc := n.replay.scenario
+	now, pos := n.tick()
+	if n.counters == nil {
+		for _, i := range sc.Interfaces {
+			n.counters = append(n.counters, netstat.Counter{Name: i.Name})
+		}
+	}
+
+	seconds := n.interval.Seconds()
+	for k, i := range sc.Interfaces {
+		c := &n.counters[k]
+		down, up := n.at(i.Down, pos)*seconds, n.at(i.Up, pos)*seconds
+		c.RxBytes += uint64(down)
+		c.TxBytes += uint64(up)
+		c.RxPackets += uint64(down / 1500)
+		c.TxPackets += uint64(up / 1500)
+	}
+
+	snap := netstat.Snapshot{Time: now, Interfaces: append([]netstat.Counter(nil), n.counters...), ConnectionsRead: true}
+	for _, c := range sc.Connections {
+		snap.Connections = append(snap.Connections, netstat.Connection{
+			Protocol:   c.Protocol,
+			LocalAddr:  c.Local,
+			RemoteAddr: c.Remote,
+			State:      c.State,
+		})
+	}
+	return snap, nil
+}
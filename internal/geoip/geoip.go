@@ -0,0 +1,340 @@
+// Package geoip places IP addresses in countries by a MaxMind DB file,
+// such as GeoLite2-Country.mmdb or DB-IP's free country database, which
+// advis does not ship. The reader is enough of the format for country
+// lookups: the search tree and the data section's maps, strings and
+// numbers.
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"os"
+	"sync"
+)
+
+// metadataMarker precedes the metadata map at the end of the file
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// dataSeparator is the gap of zeros between the search tree and the data
+// section
+const dataSeparator = 16
+
+// Country is where an address is
+type Country struct {
+	Code string // ISO 3166-1 alpha-2, such as "DE"
+	Name string // In English
+}
+
+// DB is an opened database. Its lookups are safe for concurrent use.
+type DB struct {
+	data       []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	treeSize   uint
+	ipv4Start  uint // Node an IPv4 address starts from in an IPv6 tree
+
+	mu    sync.Mutex
+	cache map[netip.Addr]Country // Lookups, which repeat for every read of the socket table
+}
+
+// maxCache bounds the lookups kept, cleared whole when reached
+const maxCache = 4096
+
+// Open reads the database at path
+func Open(path string) (*DB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	db, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return db, nil
+}
+
+func parse(data []byte) (*DB, error) {
+	i := bytes.LastIndex(data, metadataMarker)
+	if i < 0 {
+		return nil, errors.New("not a MaxMind DB file")
+	}
+	meta := decoder{data: data[i+len(metadataMarker):]}
+	v, _, err := meta.decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("metadata: %w", err)
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, errors.New("metadata is not a map")
+	}
+	db := &DB{data: data, cache: make(map[netip.Addr]Country)}
+	db.nodeCount, db.recordSize, db.ipVersion = uintOf(m["node_count"]), uintOf(m["record_size"]), uintOf(m["ip_version"])
+	switch db.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("record size %d is not 24, 28 or 32", db.recordSize)
+	}
+	db.treeSize = db.nodeCount * db.recordSize / 4
+	if db.treeSize+dataSeparator > uint(i) {
+		return nil, errors.New("search tree runs past the file")
+	}
+	if db.ipVersion == 6 {
+		// IPv4 addresses are the IPv6 addresses of 96 zero bits and them
+		for range 96 {
+			if db.ipv4Start >= db.nodeCount {
+				break
+			}
+			db.ipv4Start = db.record(db.ipv4Start, 0)
+		}
+	}
+	return db, nil
+}
+
+// uintOf is a metadata number, 0 for anything else
+func uintOf(v any) uint {
+	if n, ok := v.(uint64); ok {
+		return uint(n)
+	}
+	return 0
+}
+
+// record is the left (bit 0) or right (bit 1) record of a node
+func (db *DB) record(node, bit uint) uint {
+	b := db.data[node*db.recordSize/4:]
+	switch db.recordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	}
+	return uint(binary.BigEndian.Uint32(b[bit*4:]))
+}
+
+// Country looks up the country of addr, reporting false when the
+// database does not place it
+func (db *DB) Country(addr netip.Addr) (Country, bool) {
+	addr = addr.Unmap()
+	db.mu.Lock()
+	c, ok := db.cache[addr]
+	db.mu.Unlock()
+	if ok {
+		return c, c.Code != ""
+	}
+	c = db.lookup(addr)
+	db.mu.Lock()
+	if len(db.cache) >= maxCache {
+		clear(db.cache)
+	}
+	db.cache[addr] = c
+	db.mu.Unlock()
+	return c, c.Code != ""
+}
+
+func (db *DB) lookup(addr netip.Addr) Country {
+	var bits []byte
+	node := uint(0)
+	switch {
+	case addr.Is4():
+		a := addr.As4()
+		bits = a[:]
+		if db.ipVersion == 6 {
+			node = db.ipv4Start
+		}
+	case db.ipVersion == 6:
+		a := addr.As16()
+		bits = a[:]
+	default:
+		return Country{}
+	}
+	for i := 0; i < len(bits)*8 && node < db.nodeCount; i++ {
+		node = db.record(node, uint(bits[i/8]>>(7-i%8)&1))
+	}
+	if node <= db.nodeCount {
+		return Country{} // Not in the database
+	}
+	d := decoder{data: db.data[db.treeSize+dataSeparator:]}
+	v, _, err := d.decode(node - db.nodeCount - dataSeparator)
+	if err != nil {
+		return Country{}
+	}
+	// The country the address is registered in stands in for one it is
+	// not placed in, as anycast networks are
+	m, _ := v.(map[string]any)
+	country, _ := m["country"].(map[string]any)
+	if country == nil {
+		country, _ = m["registered_country"].(map[string]any)
+	}
+	code, _ := country["iso_code"].(string)
+	names, _ := country["names"].(map[string]any)
+	name, _ := names["en"].(string)
+	if name == "" {
+		name = code
+	}
+	return Country{Code: code, Name: name}
+}
+
+// decoder reads values of the data section format
+type decoder struct {
+	data []byte
+}
+
+var errTruncated = errors.New("data runs past the file")
+
+// The data section's types
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEnd
+	typeBool
+	typeFloat
+)
+
+// decode reads the value at offset, returning it and the offset after it
+func (d decoder) decode(offset uint) (any, uint, error) {
+	return d.decodeDepth(offset, 0)
+}
+
+func (d decoder) decodeDepth(offset uint, depth int) (any, uint, error) {
+	if depth > 32 {
+		return nil, 0, errors.New("data nested too deep")
+	}
+	if offset >= uint(len(d.data)) {
+		return nil, 0, errTruncated
+	}
+	ctrl := d.data[offset]
+	offset++
+	typ := uint(ctrl >> 5)
+	if typ == typePointer {
+		ptr, next, err := d.pointer(ctrl, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		v, _, err := d.decodeDepth(ptr, depth+1)
+		return v, next, err
+	}
+	if typ == typeExtended {
+		if offset >= uint(len(d.data)) {
+			return nil, 0, errTruncated
+		}
+		typ = 7 + uint(d.data[offset])
+		offset++
+	}
+	size := uint(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28
+		if offset+n > uint(len(d.data)) {
+			return nil, 0, errTruncated
+		}
+		var extra uint
+		for _, b := range d.data[offset : offset+n] {
+			extra = extra<<8 | uint(b)
+		}
+		offset += n
+		size = []uint{29, 285, 65821}[n-1] + extra
+	}
+
+	switch typ {
+	case typeMap:
+		m := make(map[string]any, size)
+		for range size {
+			k, next, err := d.decodeDepth(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, 0, errors.New("map key is not a string")
+			}
+			v, next, err := d.decodeDepth(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[key], offset = v, next
+		}
+		return m, offset, nil
+	case typeArray:
+		a := make([]any, 0, size)
+		for range size {
+			v, next, err := d.decodeDepth(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			a, offset = append(a, v), next
+		}
+		return a, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	case typeContainer, typeEnd:
+		return nil, offset, nil
+	}
+
+	if offset+size > uint(len(d.data)) {
+		return nil, 0, errTruncated
+	}
+	b := d.data[offset : offset+size]
+	offset += size
+	switch typ {
+	case typeString:
+		return string(b), offset, nil
+	case typeBytes, typeUint128:
+		return bytes.Clone(b), offset, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, errors.New("double is not 8 bytes")
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), offset, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, errors.New("float is not 4 bytes")
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), offset, nil
+	case typeUint16, typeUint32, typeUint64, typeInt32:
+		var n uint64
+		for _, c := range b {
+			n = n<<8 | uint64(c)
+		}
+		if typ == typeInt32 {
+			return int64(int32(n)), offset, nil
+		}
+		return n, offset, nil
+	}
+	return nil, 0, fmt.Errorf("unknown data type %d", typ)
+}
+
+// pointer reads the offset a pointer's control byte and what follows
+// point to, returning it and the offset after the pointer
+func (d decoder) pointer(ctrl byte, offset uint) (uint, uint, error) {
+	n := uint(ctrl>>3&3) + 1
+	if offset+n > uint(len(d.data)) {
+		return 0, 0, errTruncated
+	}
+	var ptr uint
+	if n < 4 {
+		ptr = uint(ctrl & 7)
+	}
+	for _, b := range d.data[offset : offset+n] {
+		ptr = ptr<<8 | uint(b)
+	}
+	ptr += []uint{0, 2048, 526336, 0}[n-1]
+	return ptr, offset + n, nil
+}
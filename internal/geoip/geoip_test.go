@@ -0,0 +1,252 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The encoders below write just enough of the MaxMind DB format to craft
+// the databases the tests read
+
+// ctrl is a control byte and its extended type byte for typ and a size
+// under 29
+func ctrl(typ, size int) []byte {
+	if typ > 7 {
+		return []byte{byte(size), byte(typ - 7)}
+	}
+	return []byte{byte(typ<<5 | size)}
+}
+
+func str(s string) []byte {
+	if len(s) >= 29 {
+		return append([]byte{typeString<<5 | 29, byte(len(s) - 29)}, s...)
+	}
+	return append(ctrl(typeString, len(s)), s...)
+}
+
+func uint16v(n uint16) []byte {
+	return append(ctrl(typeUint16, 2), byte(n>>8), byte(n))
+}
+
+func uint32v(n uint32) []byte {
+	return binary.BigEndian.AppendUint32(ctrl(typeUint32, 4), n)
+}
+
+// pointer is a pointer of the smallest form, for offsets under 2048
+func pointer(offset int) []byte {
+	return []byte{byte(typePointer<<5 | offset>>8&7), byte(offset)}
+}
+
+// mapv is a map of the keys and values given in turn, already encoded
+func mapv(kv ...[]byte) []byte {
+	b := ctrl(typeMap, len(kv)/2)
+	for _, e := range kv {
+		b = append(b, e...)
+	}
+	return b
+}
+
+// country is the record of a country with an English name
+func country(code, name string) []byte {
+	return mapv(str("iso_code"), str(code), str("names"), mapv(str("en"), str(name)))
+}
+
+// record appends a node's two records of size bits
+func record(b []byte, size int, left, right uint32) []byte {
+	switch size {
+	case 24:
+		return append(b, byte(left>>16), byte(left>>8), byte(left), byte(right>>16), byte(right>>8), byte(right))
+	case 28:
+		return append(b, byte(left>>16), byte(left>>8), byte(left), byte(left>>24<<4|right>>24&0x0f),
+			byte(right>>16), byte(right>>8), byte(right))
+	}
+	return binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(b, left), right)
+}
+
+// testDB builds a database of ipVersion and recordSize placing
+//
+//	0.0.0.0/2    in France, its country given by a pointer
+//	64.0.0.0/2   nowhere
+//	128.0.0.0/2  in Germany
+//	192.0.0.0/2  in Japan, as only the registered country
+//
+// and, for IPv6, nothing outside the IPv4 addresses
+func testDB(t *testing.T, ipVersion, recordSize int) *DB {
+	t.Helper()
+	var data []byte
+	france := len(data)
+	data = append(data, country("FR", "France")...)
+	frEntry := len(data)
+	data = append(data, mapv(str("country"), pointer(france))...)
+	deEntry := len(data)
+	data = append(data, mapv(str("country"), country("DE", "Germany"))...)
+	jpEntry := len(data)
+	data = append(data, mapv(str("registered_country"), country("JP", "Japan"))...)
+
+	// A chain of 96 zero bits leads an IPv6 tree to the IPv4 addresses
+	chain := 0
+	if ipVersion == 6 {
+		chain = 96
+	}
+	nodes := uint32(chain + 3)
+	leaf := func(offset int) uint32 { return nodes + dataSeparator + uint32(offset) }
+	var tree []byte
+	for i := range chain {
+		tree = record(tree, recordSize, uint32(i+1), nodes)
+	}
+	first := uint32(chain)
+	tree = record(tree, recordSize, first+1, first+2)
+	tree = record(tree, recordSize, leaf(frEntry), nodes)
+	tree = record(tree, recordSize, leaf(deEntry), leaf(jpEntry))
+
+	file := append(tree, make([]byte, dataSeparator)...)
+	file = append(file, data...)
+	file = append(file, metadataMarker...)
+	file = append(file, mapv(str("node_count"), uint32v(nodes), str("record_size"), uint16v(uint16(recordSize)),
+		str("ip_version"), uint16v(uint16(ipVersion)), str("database_type"), str("Test-Country"))...)
+	db, err := parse(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestCountry(t *testing.T) {
+	for _, version := range []int{4, 6} {
+		for _, size := range []int{24, 28, 32} {
+			db := testDB(t, version, size)
+			for _, c := range []struct {
+				addr string
+				want Country
+			}{
+				{"10.1.2.3", Country{"FR", "France"}},
+				{"63.255.255.255", Country{"FR", "France"}},
+				{"64.0.0.1", Country{}},
+				{"130.0.0.1", Country{"DE", "Germany"}},
+				{"200.0.0.1", Country{"JP", "Japan"}},
+				{"::ffff:130.0.0.1", Country{"DE", "Germany"}},
+				{"2001:db8::1", Country{}},
+			} {
+				// Twice, the second from the cache
+				for range 2 {
+					got, ok := db.Country(netip.MustParseAddr(c.addr))
+					if got != c.want || ok != (c.want.Code != "") {
+						t.Errorf("IPv%d %d-bit: %s = %v %v, want %v", version, size, c.addr, got, ok, c.want)
+					}
+				}
+			}
+		}
+	}
+}
+
+// A record size of 28 bits splits the high bits of both records between
+// the nibbles of the middle byte
+func TestRecord28(t *testing.T) {
+	db := &DB{data: record(nil, 28, 0xabcdef1, 0x2345678), recordSize: 28}
+	if l, r := db.record(0, 0), db.record(0, 1); l != 0xabcdef1 || r != 0x2345678 {
+		t.Errorf("records = %#x %#x", l, r)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	meta := func(nodes uint32, size uint16) []byte {
+		return append(append([]byte(nil), metadataMarker...), mapv(str("node_count"), uint32v(nodes),
+			str("record_size"), uint16v(size), str("ip_version"), uint16v(4))...)
+	}
+	for _, c := range []struct {
+		name string
+		file []byte
+		want string
+	}{
+		{"no metadata", []byte("not a database"), "not a MaxMind DB file"},
+		{"metadata truncated", append(append([]byte(nil), metadataMarker...), typeMap<<5|3), "metadata"},
+		{"metadata not a map", append(append([]byte(nil), metadataMarker...), str("x")...), "not a map"},
+		{"record size", append(make([]byte, 64), meta(1, 20)...), "record size 20"},
+		{"tree past the file", append(make([]byte, 20), meta(100, 24)...), "search tree"},
+	} {
+		if _, err := parse(c.file); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: error %v, want one about %q", c.name, err, c.want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	long := strings.Repeat("x", 40)
+	double := binary.BigEndian.AppendUint64(ctrl(typeDouble, 8), math.Float64bits(2.5))
+	float := binary.BigEndian.AppendUint32(ctrl(typeFloat, 4), math.Float32bits(0.5))
+	for _, c := range []struct {
+		name string
+		data []byte
+		want any
+	}{
+		{"string", str("DE"), "DE"},
+		{"long string", str(long), long},
+		{"uint16", uint16v(300), uint64(300)},
+		{"uint32 short", append(ctrl(typeUint32, 1), 7), uint64(7)},
+		{"uint64", append(ctrl(typeUint64, 3), 1, 0, 0), uint64(1 << 16)},
+		{"int32", append(ctrl(typeInt32, 4), 0xff, 0xff, 0xff, 0xfe), int64(-2)},
+		{"double", double, 2.5},
+		{"float", float, 0.5},
+		{"bool", ctrl(typeBool, 1), true},
+		{"bytes", append(ctrl(typeBytes, 2), 1, 2), []byte{1, 2}},
+		{"array", append(ctrl(typeArray, 2), append(str("a"), uint16v(1)...)...), []any{"a", uint64(1)}},
+		{"map", mapv(str("k"), str("v")), map[string]any{"k": "v"}},
+		{"pointer", append(pointer(2), str("v")...), "v"},
+	} {
+		got, _, err := decoder{data: c.data}.decode(0)
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: %#v, %v, want %#v", c.name, got, err, c.want)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, errTruncated},
+		{"string", append(ctrl(typeString, 5), "ab"...), errTruncated},
+		{"size bytes", []byte{typeString<<5 | 30, 1}, errTruncated},
+		{"extended type", []byte{0}, errTruncated},
+		{"pointer", []byte{typePointer<<5 | 1<<3, 0}, errTruncated},
+		{"pointer past the data", pointer(100), errTruncated},
+		{"map value", append(ctrl(typeMap, 1), str("k")...), errTruncated},
+	} {
+		if _, _, err := (decoder{data: c.data}).decode(0); !errors.Is(err, c.want) {
+			t.Errorf("%s: error %v, want %v", c.name, err, c.want)
+		}
+	}
+
+	for _, c := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"pointer loop", pointer(0), "nested too deep"},
+		{"map key", mapv(uint16v(1), str("v")), "not a string"},
+		{"double size", append(ctrl(typeDouble, 4), 0, 0, 0, 0), "not 8 bytes"},
+		{"float size", append(ctrl(typeFloat, 8), make([]byte, 8)...), "not 4 bytes"},
+		{"unknown type", []byte{0, 12}, "unknown data type"},
+	} {
+		if _, _, err := (decoder{data: c.data}).decode(0); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: error %v, want one about %q", c.name, err, c.want)
+		}
+	}
+}
+
+// A leaf pointing past the data section is an address the database does
+// not place, not a panic
+func TestLookupTruncatedData(t *testing.T) {
+	db := testDB(t, 4, 24)
+	db.data = db.data[:db.treeSize+dataSeparator+4]
+	if c, ok := db.Country(netip.MustParseAddr("130.0.0.1")); ok {
+		t.Errorf("placed in %v", c)
+	}
+}
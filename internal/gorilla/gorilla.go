@@ -0,0 +1,244 @@
+// Package gorilla packs time series the way Facebook's Gorilla does:
+// timestamps and counters as the difference between successive deltas,
+// which is a single bit for a regular interval or a steady rate, and
+// gauges as the XOR of successive values, which is a single bit for a
+// repeated value and a few for a close one.
+//
+// A series is a value type holding the previous values; the same series
+// writes a stream and, fresh, reads it back. Several series may share a
+// stream as long as they are read in the order they were written.
+package gorilla
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+)
+
+// ErrShort is returned for a stream that ends within a value
+var ErrShort = errors.New("gorilla: stream ends within a value")
+
+// Writer accumulates a bit stream
+type Writer struct {
+	buf  []byte
+	used uint8 // Bits used of the last byte, 0 for a full one
+}
+
+// Bytes returns the stream, its last byte padded with zeros
+func (w *Writer) Bytes() []byte { return w.buf }
+
+// write appends the n low bits of v, most significant first
+func (w *Writer) write(v uint64, n int) {
+	for n > 0 {
+		if w.used == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		free := int(8 - w.used)
+		take := min(free, n)
+		chunk := byte(v>>(n-take)) & (1<<take - 1)
+		w.buf[len(w.buf)-1] |= chunk << (free - take)
+		w.used = uint8((int(w.used) + take) % 8)
+		n -= take
+	}
+}
+
+func (w *Writer) bit(b bool) {
+	if b {
+		w.write(1, 1)
+	} else {
+		w.write(0, 1)
+	}
+}
+
+// Reader reads back a stream a Writer made
+type Reader struct {
+	buf []byte
+	pos int // In bits
+}
+
+// NewReader reads the stream b
+func NewReader(b []byte) *Reader { return &Reader{buf: b} }
+
+func (r *Reader) read(n int) (uint64, error) {
+	if r.pos+n > len(r.buf)*8 {
+		return 0, ErrShort
+	}
+	var v uint64
+	for n > 0 {
+		b := r.buf[r.pos/8]
+		off := r.pos % 8
+		take := min(8-off, n)
+		v = v<<take | uint64(b>>(8-off-take))&(1<<take-1)
+		r.pos += take
+		n -= take
+	}
+	return v, nil
+}
+
+func (r *Reader) bit() (bool, error) {
+	v, err := r.read(1)
+	return v == 1, err
+}
+
+// Ints is a series of integers packed as delta-of-delta: timestamps at a
+// steady interval and counters growing at a steady rate cost a bit each.
+// Arithmetic wraps, so uint64 counters round-trip through int64.
+type Ints struct {
+	started bool
+	prev    int64
+	delta   int64
+}
+
+// dodClasses are the widths the change of delta is written in, after a
+// prefix of as many ones as the index and a zero; the last takes all 64
+// bits and has no terminating zero
+var dodClasses = []int{7, 9, 12, 20, 64}
+
+// Write appends v to the series on w
+func (s *Ints) Write(w *Writer, v int64) {
+	if !s.started {
+		s.started, s.prev = true, v
+		w.write(uint64(v), 64)
+		return
+	}
+	delta := v - s.prev
+	dod := delta - s.delta
+	s.prev, s.delta = v, delta
+	if dod == 0 {
+		w.bit(false)
+		return
+	}
+	for i, width := range dodClasses {
+		last := i == len(dodClasses)-1
+		if !last && (dod < -(1<<(width-1)) || dod >= 1<<(width-1)) {
+			continue
+		}
+		w.write(1<<(i+1)-1, i+1) // i+1 ones
+		if !last {
+			w.bit(false)
+		}
+		w.write(uint64(dod), width)
+		return
+	}
+}
+
+// Read reads the next value of the series from r
+func (s *Ints) Read(r *Reader) (int64, error) {
+	if !s.started {
+		v, err := r.read(64)
+		if err != nil {
+			return 0, err
+		}
+		s.started, s.prev = true, int64(v)
+		return s.prev, nil
+	}
+	class := -1
+	for class < len(dodClasses)-1 {
+		one, err := r.bit()
+		if err != nil {
+			return 0, err
+		}
+		if !one {
+			break
+		}
+		class++
+	}
+	var dod int64
+	if class >= 0 {
+		width := dodClasses[class]
+		v, err := r.read(width)
+		if err != nil {
+			return 0, err
+		}
+		// Sign extended from width bits
+		dod = int64(v<<(64-width)) >> (64 - width)
+	}
+	s.delta += dod
+	s.prev += s.delta
+	return s.prev, nil
+}
+
+// Floats is a series of floats packed as the XOR of successive values,
+// with the meaningful bits of the XOR placed within the window of the
+// one before when they fit
+type Floats struct {
+	started  bool
+	prev     uint64
+	leading  int
+	trailing int
+}
+
+// Write appends v to the series on w
+func (s *Floats) Write(w *Writer, v float64) {
+	b := math.Float64bits(v)
+	if !s.started {
+		s.started, s.prev = true, b
+		s.leading, s.trailing = -1, 0
+		w.write(b, 64)
+		return
+	}
+	x := b ^ s.prev
+	s.prev = b
+	if x == 0 {
+		w.bit(false)
+		return
+	}
+	w.bit(true)
+	leading := min(bits.LeadingZeros64(x), 31)
+	trailing := bits.TrailingZeros64(x)
+	if s.leading >= 0 && leading >= s.leading && trailing >= s.trailing {
+		w.bit(false)
+		w.write(x>>s.trailing, 64-s.leading-s.trailing)
+		return
+	}
+	s.leading, s.trailing = leading, trailing
+	size := 64 - leading - trailing
+	w.bit(true)
+	w.write(uint64(leading), 5)
+	w.write(uint64(size%64), 6) // 64 fits as 0
+	w.write(x>>trailing, size)
+}
+
+// Read reads the next value of the series from r
+func (s *Floats) Read(r *Reader) (float64, error) {
+	if !s.started {
+		v, err := r.read(64)
+		if err != nil {
+			return 0, err
+		}
+		s.started, s.prev = true, v
+		s.leading, s.trailing = -1, 0
+		return math.Float64frombits(v), nil
+	}
+	changed, err := r.bit()
+	if err != nil || !changed {
+		return math.Float64frombits(s.prev), err
+	}
+	fresh, err := r.bit()
+	if err != nil {
+		return 0, err
+	}
+	if fresh {
+		leading, err := r.read(5)
+		if err != nil {
+			return 0, err
+		}
+		size, err := r.read(6)
+		if err != nil {
+			return 0, err
+		}
+		if size == 0 {
+			size = 64
+		}
+		s.leading, s.trailing = int(leading), 64-int(leading)-int(size)
+	}
+	if s.leading < 0 {
+		return 0, errors.New("gorilla: value reuses a window before any was set")
+	}
+	x, err := r.read(64 - s.leading - s.trailing)
+	if err != nil {
+		return 0, err
+	}
+	s.prev ^= x << s.trailing
+	return math.Float64frombits(s.prev), nil
+}
@@ -0,0 +1,225 @@
+package gorilla
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// roundTripInts writes values as one series and reads them back
+func roundTripInts(t *testing.T, values []int64) {
+	t.Helper()
+	var w Writer
+	var ws Ints
+	for _, v := range values {
+		ws.Write(&w, v)
+	}
+	r := NewReader(w.Bytes())
+	var rs Ints
+	for i, want := range values {
+		got, err := rs.Read(r)
+		if err != nil {
+			t.Fatalf("value %d: %v", i, err)
+		}
+		if got != want {
+			t.Fatalf("value %d = %d, want %d", i, got, want)
+		}
+	}
+}
+
+// roundTripFloats writes values as one series and reads them back bit for
+// bit, so NaN payloads and the sign of zero survive too
+func roundTripFloats(t *testing.T, values []float64) {
+	t.Helper()
+	var w Writer
+	var ws Floats
+	for _, v := range values {
+		ws.Write(&w, v)
+	}
+	r := NewReader(w.Bytes())
+	var rs Floats
+	for i, want := range values {
+		got, err := rs.Read(r)
+		if err != nil {
+			t.Fatalf("value %d: %v", i, err)
+		}
+		if math.Float64bits(got) != math.Float64bits(want) {
+			t.Fatalf("value %d = %v (%#x), want %v (%#x)", i, got, math.Float64bits(got), want, math.Float64bits(want))
+		}
+	}
+}
+
+func TestIntsRoundTrip(t *testing.T) {
+	wrap := uint64(math.MaxUint64 - 2)
+	for name, values := range map[string][]int64{
+		"first only":   {1_700_000_000_000},
+		"first zero":   {0, 0},
+		"steady":       {1000, 2000, 3000, 4000, 5000},
+		"equal":        {42, 42, 42, 42},
+		"each class":   {0, 63, 0, 255, 0, 2047, 0, 1 << 19, 0, 1 << 40, 0},
+		"class edges":  {0, -64, 0, 64, 0, -256, 256, -2048, 2048, -(1 << 19), 1 << 19},
+		"extremes":     {math.MinInt64, math.MaxInt64, math.MinInt64, 0, math.MaxInt64},
+		"large gaps":   {1_700_000_000_000, 1_700_000_001_000, 1_700_086_400_000, 1_700_086_401_000, 1_800_000_000_000},
+		"counter wrap": {int64(wrap), int64(wrap + 1), int64(wrap + 2), int64(wrap + 3), int64(wrap + 4), int64(wrap + 5)},
+		"going back":   {5000, 4000, 100, 7_000_000, 3},
+	} {
+		t.Run(name, func(t *testing.T) { roundTripInts(t, values) })
+	}
+}
+
+func TestIntsRandom(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	values := make([]int64, 5000)
+	for i := range values {
+		switch rng.IntN(4) {
+		case 0:
+			values[i] = int64(rng.Uint64())
+		case 1:
+			values[i] = rng.Int64N(1 << 20)
+		default:
+			if i > 0 {
+				values[i] = values[i-1] + rng.Int64N(100)
+			}
+		}
+	}
+	roundTripInts(t, values)
+}
+
+func TestFloatsRoundTrip(t *testing.T) {
+	for name, values := range map[string][]float64{
+		"first only": {12.5},
+		"equal":      {3.25, 3.25, 3.25, 3.25},
+		"close":      {50.0, 50.1, 50.2, 49.9, 50.0},
+		"specials":   {math.NaN(), math.Inf(1), math.Inf(-1), 0, math.Copysign(0, -1), math.NaN(), math.NaN(), 1},
+		"extremes":   {math.MaxFloat64, math.SmallestNonzeroFloat64, -math.MaxFloat64, 1e-300, 1e300},
+		"full xor":   {math.Float64frombits(0x8000000000000001), math.Float64frombits(1), math.Float64frombits(0x8000000000000000)},
+		"window":     {1, 1.5, 1.25, 1 << 40, 1.125, 1.5},
+	} {
+		t.Run(name, func(t *testing.T) { roundTripFloats(t, values) })
+	}
+}
+
+func TestFloatsRandom(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	values := make([]float64, 5000)
+	for i := range values {
+		switch rng.IntN(4) {
+		case 0:
+			values[i] = math.Float64frombits(rng.Uint64())
+		case 1:
+			values[i] = rng.Float64() * 100
+		default:
+			if i > 0 {
+				values[i] = values[i-1]
+			}
+		}
+	}
+	roundTripFloats(t, values)
+}
+
+// TestSharedStream checks series interleaved on one stream read back in
+// the order they were written
+func TestSharedStream(t *testing.T) {
+	var w Writer
+	var times Ints
+	var cpu Floats
+	var bytes Ints
+	for i := range 100 {
+		times.Write(&w, int64(i)*10_000)
+		cpu.Write(&w, float64(i%7)*1.5)
+		bytes.Write(&w, int64(i*i))
+	}
+	r := NewReader(w.Bytes())
+	var rtimes, rbytes Ints
+	var rcpu Floats
+	for i := range 100 {
+		ts, err1 := rtimes.Read(r)
+		c, err2 := rcpu.Read(r)
+		b, err3 := rbytes.Read(r)
+		if err := errors.Join(err1, err2, err3); err != nil {
+			t.Fatalf("sample %d: %v", i, err)
+		}
+		if ts != int64(i)*10_000 || c != float64(i%7)*1.5 || b != int64(i*i) {
+			t.Fatalf("sample %d = %d, %v, %d", i, ts, c, b)
+		}
+	}
+}
+
+func TestShortStream(t *testing.T) {
+	var w Writer
+	var s Ints
+	s.Write(&w, 1)
+	s.Write(&w, 1<<40)
+	stream := w.Bytes()
+	r := NewReader(stream[:len(stream)-2])
+	var rs Ints
+	if _, err := rs.Read(r); err != nil {
+		t.Fatalf("first value: %v", err)
+	}
+	if _, err := rs.Read(r); !errors.Is(err, ErrShort) {
+		t.Fatalf("truncated value: err = %v, want ErrShort", err)
+	}
+	var f Floats
+	if _, err := f.Read(NewReader(nil)); !errors.Is(err, ErrShort) {
+		t.Fatalf("empty stream: err = %v, want ErrShort", err)
+	}
+}
+
+// benchSamples is a day of samples a minute apart
+const benchSamples = 24 * 60
+
+// BenchmarkIntsTimestamps packs timestamps a steady interval apart with a
+// little jitter, reporting the bytes each costs
+func BenchmarkIntsTimestamps(b *testing.B) {
+	rng := rand.New(rand.NewPCG(5, 6))
+	values := make([]int64, benchSamples)
+	for i := range values {
+		values[i] = 1_700_000_000_000 + int64(i)*60_000 + rng.Int64N(5)
+	}
+	benchmarkInts(b, values)
+}
+
+// BenchmarkIntsCounters packs a byte counter growing at a varying rate
+func BenchmarkIntsCounters(b *testing.B) {
+	rng := rand.New(rand.NewPCG(7, 8))
+	values := make([]int64, benchSamples)
+	for i := 1; i < len(values); i++ {
+		values[i] = values[i-1] + 1<<20 + rng.Int64N(1<<16)
+	}
+	benchmarkInts(b, values)
+}
+
+// BenchmarkFloatsGauge packs a CPU percentage that mostly drifts
+func BenchmarkFloatsGauge(b *testing.B) {
+	rng := rand.New(rand.NewPCG(9, 10))
+	values := make([]float64, benchSamples)
+	for i := range values {
+		values[i] = math.Round(rng.NormFloat64()*5+20) / 10
+	}
+	b.ReportAllocs()
+	var size int
+	for range b.N {
+		var w Writer
+		var s Floats
+		for _, v := range values {
+			s.Write(&w, v)
+		}
+		size = len(w.Bytes())
+	}
+	b.ReportMetric(float64(size)/float64(len(values)), "bytes/sample")
+}
+
+func benchmarkInts(b *testing.B, values []int64) {
+	b.ReportAllocs()
+	var size int
+	for range b.N {
+		var w Writer
+		var s Ints
+		for _, v := range values {
+			s.Write(&w, v)
+		}
+		size = len(w.Bytes())
+	}
+	b.ReportMetric(float64(size)/float64(len(values)), "bytes/sample")
+}
@@ -0,0 +1,263 @@
+package netmon
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+)
+
+// The connection map shows up to mapGroups groups, the rest gathered on
+// one line, and up to mapHosts hosts under each
+const (
+	mapGroups = 12
+	mapHosts  = 3
+)
+
+// peerGroup is the connections to one network or country on the
+// connection map
+type peerGroup struct {
+	name  string
+	count int
+	rate  float64            // Bytes per second both ways
+	hosts map[string]int     // Connections by remote address
+	rates map[string]float64 // Bytes per second by remote address
+}
+
+// weight is what the map sizes and ranks a group or host by: its
+// connections, or its traffic
+func (m model) weight(count int, rate float64) float64 {
+	if m.mapByTraffic {
+		return rate
+	}
+	return float64(count)
+}
+
+// peerOf is the address of a connection's peer, false for a socket with
+// none
+func peerOf(c netstat.Connection) (netip.Addr, bool) {
+	if c.State == "LISTEN" {
+		return netip.Addr{}, false
+	}
+	h, _, err := net.SplitHostPort(c.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	addr, err := netip.ParseAddr(h)
+	if err != nil || addr.IsUnspecified() {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// peerGroupOf is the group of the connection map a peer is in: its
+// country with a GeoIP database, else its /16 for IPv4 and its /32 for
+// IPv6
+func (m model) peerGroupOf(addr netip.Addr) string {
+	switch {
+	case addr.IsLoopback():
+		return "loopback"
+	case addr.IsLinkLocalUnicast():
+		return "link-local"
+	case m.geo != nil && addr.IsPrivate():
+		return "private"
+	case m.geo != nil:
+		if c, ok := m.geo.Country(addr); ok {
+			return c.Name + " (" + c.Code + ")"
+		}
+		return "unknown"
+	}
+	bits := 16
+	if addr.Is6() {
+		bits = 32
+	}
+	prefix, _ := addr.Prefix(bits)
+	if addr.IsPrivate() {
+		return prefix.String() + " (private)"
+	}
+	return prefix.String()
+}
+
+// groupPeers groups the connections with a peer, the busiest group
+// first by connections or by traffic. The traffic is that of the TCP
+// sockets whose byte counts the platform reads.
+func (m model) groupPeers() (groups []*peerGroup, total int) {
+	byName := make(map[string]*peerGroup)
+	for _, c := range m.connections {
+		addr, ok := peerOf(c)
+		if !ok {
+			continue
+		}
+		name := m.peerGroupOf(addr)
+		g := byName[name]
+		if g == nil {
+			g = &peerGroup{name: name, hosts: make(map[string]int), rates: make(map[string]float64)}
+			byName[name] = g
+			groups = append(groups, g)
+		}
+		rate := m.traffic.rate(c)
+		g.count++
+		g.rate += rate
+		g.hosts[addr.String()]++
+		g.rates[addr.String()] += rate
+		total++
+	}
+	slices.SortFunc(groups, func(a, b *peerGroup) int {
+		return cmp.Or(cmp.Compare(m.weight(b.count, b.rate), m.weight(a.count, a.rate)),
+			cmp.Compare(b.count, a.count), strings.Compare(a.name, b.name))
+	})
+	if len(groups) > mapGroups {
+		rest := &peerGroup{name: fmt.Sprintf("%d more", len(groups)-mapGroups+1)}
+		for _, g := range groups[mapGroups-1:] {
+			rest.count += g.count
+			rest.rate += g.rate
+		}
+		groups = append(groups[:mapGroups-1:mapGroups-1], rest)
+	}
+	return groups, total
+}
+
+// inPeerGroup reports whether c goes to the peers of the group named
+func (m model) inPeerGroup(c netstat.Connection, group string) bool {
+	addr, ok := peerOf(c)
+	return ok && m.peerGroupOf(addr) == group
+}
+
+// filterConnections narrows the connection table to the group of the
+// connection map it is filtered to, if any
+func (m *model) filterConnections() {
+	m.table = m.connections
+	if m.connFilter != "" {
+		m.table = slices.DeleteFunc(slices.Clone(m.connections), func(c netstat.Connection) bool {
+			return !m.inPeerGroup(c, m.connFilter)
+		})
+	}
+	if m.connCursor >= len(m.table) {
+		m.connCursor = max(len(m.table)-1, 0)
+	}
+}
+
+// filterToGroup filters the connection table to the group selected on
+// the Map tab and shows it, unless the tab is hidden
+func (m *model) filterToGroup() {
+	groups, _ := m.groupPeers()
+	if m.mapCursor >= len(groups) {
+		return
+	}
+	g := groups[m.mapCursor]
+	if g.hosts == nil {
+		m.flash = "Pick a single group to filter by"
+		return
+	}
+	if m.tabs.Position(2) < 0 {
+		m.flash = "The Connections tab is not on the tab bar"
+		return
+	}
+	m.connFilter, m.connCursor = g.name, 0
+	m.filterConnections()
+	m.currentTab = 2
+	m.split.SetTab(m.currentTab)
+}
+
+// formatRate is a traffic rate on the map, "-" where the sockets carry no
+// byte counts
+func (m model) formatRate(rate float64) string {
+	if !m.traffic.known {
+		return "-"
+	}
+	return ui.FormatBytes(uint64(rate)) + "/s"
+}
+
+// flowBar is a bar of width cells filled to share, in eighths of a cell
+func flowBar(share float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	eighths := max(int(share*float64(width*8)), 1)
+	bar := strings.Repeat("█", eighths/8)
+	if r := eighths % 8; r > 0 {
+		bar += string([]rune("▏▎▍▌▋▊▉")[r-1])
+	}
+	return bar
+}
+
+// renderMapView draws the connections as a tree flowing from this host to
+// the networks or countries of their peers, each branch as wide as its
+// share of them
+func (m model) renderMapView() string {
+	var content strings.Builder
+	content.WriteString(headerStyle.Render(ui.Icon("map")+"Connection Map") + "\n\n")
+
+	groups, total := m.groupPeers()
+	if total == 0 {
+		if len(m.connections) == 0 {
+			content.WriteString(m.noData() + "\n")
+		} else {
+			content.WriteString("No connection has a peer\n")
+		}
+		return content.String()
+	}
+	by := "networks"
+	if m.geo != nil {
+		by = "countries"
+	}
+	ranked := "connections"
+	if m.mapByTraffic {
+		ranked = "traffic"
+	}
+	var sum float64
+	for _, g := range groups {
+		sum += m.weight(g.count, g.rate)
+	}
+	summary := fmt.Sprintf("%d connections, by %s, ranked by %s", total, by, ranked)
+	if !m.traffic.known {
+		summary += "; no byte counts for these sockets"
+	}
+	content.WriteString(infoStyle.Render(summary) + "\n\n")
+
+	const nameWidth = 28
+	barWidth := max(m.width-nameWidth-36, 10)
+	content.WriteString("this host\n")
+	for i, g := range groups {
+		branch, stem := "├─", "│ "
+		if i == len(groups)-1 {
+			branch, stem = "└─", "  "
+		}
+		cursor := " "
+		if i == m.mapCursor {
+			cursor = headerStyle.Render("▶")
+		}
+		share := 0.0
+		if sum > 0 {
+			share = m.weight(g.count, g.rate) / sum
+		}
+		content.WriteString(fmt.Sprintf("%s %s %s %s %5d %11s %3d%%\n", cursor, branch, ui.Fit(g.name, nameWidth),
+			downloadStyle.Render(fmt.Sprintf("%-*s", barWidth, flowBar(share, barWidth))), g.count, m.formatRate(g.rate), int(share*100)))
+
+		hosts := make([]string, 0, len(g.hosts))
+		for h := range g.hosts {
+			hosts = append(hosts, h)
+		}
+		slices.SortFunc(hosts, func(a, b string) int {
+			return cmp.Or(cmp.Compare(m.weight(g.hosts[b], g.rates[b]), m.weight(g.hosts[a], g.rates[a])),
+				cmp.Compare(g.hosts[b], g.hosts[a]), strings.Compare(a, b))
+		})
+		shown := hosts[:min(len(hosts), mapHosts)]
+		for j, h := range shown {
+			twig := "├─"
+			if j == len(shown)-1 && len(hosts) == len(shown) {
+				twig = "└─"
+			}
+			content.WriteString(fmt.Sprintf("  %s  %s %s %d  %s\n", stem, twig, ui.Fit(h, nameWidth-4), g.hosts[h], m.formatRate(g.rates[h])))
+		}
+		if more := len(hosts) - len(shown); more > 0 {
+			content.WriteString(fmt.Sprintf("  %s  └─ %d more hosts\n", stem, more))
+		}
+	}
+	return content.String()
+}
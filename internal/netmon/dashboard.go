@@ -0,0 +1,121 @@
+package netmon
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// Panels contributes the network throughput panel to the combined
+// dashboard of "advis all", and a larger graph and the top talkers to
+// layouts asking for them
+func (m model) Panels(width int) []ui.Panel {
+	return m.frames.panels.Get([2]uint64{m.rev, uint64(width)}, func() []ui.Panel {
+		return m.renderPanels(width)
+	})
+}
+
+func (m model) renderPanels(width int) []ui.Panel {
+	var content strings.Builder
+
+	for _, name := range m.interfaceNames() {
+		iface := m.interfaces[name]
+		content.WriteString(fmt.Sprintf("%s %s %-12s %s %s\n", ui.Fit(name, 10),
+			downloadStyle.Render("↓"), ui.FormatBytes(uint64(iface.DownloadRate))+"/s",
+			uploadStyle.Render("↑"), ui.FormatBytes(uint64(iface.UploadRate))+"/s"))
+	}
+
+	if primary := m.interfaces[m.primary]; primary != nil && primary.History.Len() > 0 {
+		down := make([]float64, primary.History.Len())
+		up := make([]float64, primary.History.Len())
+		for i, p := range primary.History.All() {
+			down[i], up[i] = p.Download, p.Upload
+		}
+		content.WriteString("\n" + downloadStyle.Render(ui.Sparkline(down, width, 0)) + "\n")
+		content.WriteString(uploadStyle.Render(ui.Sparkline(up, width, 0)) + "\n")
+	}
+	content.WriteString(fmt.Sprintf("Session: ↓ %s  ↑ %s\n", ui.FormatBytes(m.totalDownload), ui.FormatBytes(m.totalUpload)))
+
+	return []ui.Panel{
+		{Title: ui.Icon("network") + "Network", Body: content.String(), Widget: "net"},
+		{Title: ui.Icon("graph") + "Throughput", Body: m.renderGraphPanel(width), Widget: "netgraph", Extra: true},
+		{Title: ui.Icon("talkers") + "Top Talkers", Body: m.renderTalkersPanel(width), Widget: "toptalkers", Extra: true},
+	}
+}
+
+// renderGraphPanel draws download and upload of the primary interface as
+// sparklines labelled with their peaks
+func (m model) renderGraphPanel(width int) string {
+	primary := m.interfaces[m.primary]
+	if primary == nil || primary.History.Len() == 0 {
+		return infoStyle.Render("Waiting for samples...") + "\n"
+	}
+	down := make([]float64, primary.History.Len())
+	up := make([]float64, primary.History.Len())
+	var peakDown, peakUp float64
+	for i, p := range primary.History.All() {
+		down[i], up[i] = p.Download, p.Upload
+		peakDown, peakUp = max(peakDown, p.Download), max(peakUp, p.Upload)
+	}
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("%s  peak ↓ %s/s  ↑ %s/s\n", m.primary,
+		ui.FormatBytes(uint64(peakDown)), ui.FormatBytes(uint64(peakUp))))
+	content.WriteString(downloadStyle.Render(ui.Sparkline(down, width, 0)) + "\n")
+	content.WriteString(downloadStyle.Render(fmt.Sprintf("↓ %s/s", ui.FormatBytes(uint64(primary.DownloadRate)))) + "\n")
+	content.WriteString(uploadStyle.Render(ui.Sparkline(up, width, 0)) + "\n")
+	content.WriteString(uploadStyle.Render(fmt.Sprintf("↑ %s/s", ui.FormatBytes(uint64(primary.UploadRate)))) + "\n")
+	return content.String()
+}
+
+// talkersShown caps the rows of the top talkers panel
+const talkersShown = 8
+
+// renderTalkersPanel lists the remote hosts with the most connections.
+// Per-connection byte counts are not available, so count stands in for
+// traffic.
+func (m model) renderTalkersPanel(width int) string {
+	counts := make(map[string]int)
+	for _, c := range m.connections {
+		host, _, err := net.SplitHostPort(c.RemoteAddr)
+		if err != nil || c.State == "LISTEN" || host == "0.0.0.0" || host == "::" || host == "*" {
+			continue
+		}
+		counts[host]++
+	}
+	if len(counts) == 0 {
+		return infoStyle.Render("No remote connections") + "\n"
+	}
+	hosts := slices.Collect(maps.Keys(counts))
+	slices.SortFunc(hosts, func(a, b string) int {
+		return cmp.Or(counts[b]-counts[a], strings.Compare(a, b))
+	})
+	var content strings.Builder
+	for _, host := range hosts[:min(talkersShown, len(hosts))] {
+		content.WriteString(fmt.Sprintf("%5d  %s\n", counts[host], ui.Truncate(host, max(width-7, 8))))
+	}
+	if len(hosts) > talkersShown {
+		content.WriteString(infoStyle.Render(fmt.Sprintf("%d more hosts", len(hosts)-talkersShown)) + "\n")
+	}
+	return content.String()
+}
+
+// Readings describes each interface's rates and the session's traffic to
+// the screen reader mode
+func (m model) Readings() []ui.Reading {
+	var r []ui.Reading
+	for _, name := range m.interfaceNames() {
+		iface := m.interfaces[name]
+		r = append(r, ui.Reading{Label: "Interface " + name, Value: fmt.Sprintf("download %s/s, upload %s/s",
+			ui.FormatBytes(uint64(iface.DownloadRate)), ui.FormatBytes(uint64(iface.UploadRate)))})
+	}
+	if m.collectErr != nil {
+		r = append(r, ui.Reading{Label: "Network error", Value: m.collectErr.Error()})
+	}
+	return append(r, ui.Reading{Label: "Session traffic", Value: fmt.Sprintf("downloaded %s, uploaded %s",
+		ui.FormatBytes(m.totalDownload), ui.FormatBytes(m.totalUpload))})
+}
@@ -0,0 +1,137 @@
+package netmon
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// keyMap holds every key binding of the network monitor. The key tags are
+// the names the "net" section of the config file overrides them by.
+type keyMap struct {
+	Quit       key.Binding `key:"quit"`
+	NextTab    key.Binding `key:"next_tab"`
+	Tabs       key.Binding `key:"tabs"` // One key per tab, in tab bar order
+	HideTab    key.Binding `key:"hide_tab"`
+	ShowTabs   key.Binding `key:"show_tabs"`
+	CopyRow    key.Binding `key:"copy_row"`
+	Sort       key.Binding `key:"sort"`   // The Map tab by connections or traffic
+	Filter     key.Binding `key:"filter"` // To the Map tab's selected group, and back to all on the Connections tab
+	CopyTable  key.Binding `key:"copy_table"`
+	Errors     key.Binding `key:"errors"`
+	Screenshot key.Binding `key:"screenshot"`
+	Help       key.Binding `key:"help"`
+	Reset      key.Binding `key:"reset"`
+	Pause      key.Binding `key:"pause"`
+
+	ui.NavKeyMap
+	ui.SplitKeyMap
+}
+
+var keys = keyMap{
+	Quit:       key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+	NextTab:    key.NewBinding(key.WithKeys("tab"), key.WithHelp("Tab", "cycle")),
+	Tabs:       key.NewBinding(key.WithKeys("1", "2", "3", "4", "5"), key.WithHelp("1-5", "switch tabs")),
+	HideTab:    key.NewBinding(key.WithKeys("H"), key.WithHelp("H", "hide tab")),
+	ShowTabs:   key.NewBinding(key.WithKeys("U"), key.WithHelp("U", "show hidden tabs")),
+	CopyRow:    key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy row")),
+	Sort:       key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "rank by connections/traffic")),
+	Filter:     key.NewBinding(key.WithKeys("enter", "f"), key.WithHelp("enter", "filter connections")),
+	CopyTable:  key.NewBinding(key.WithKeys("Y"), key.WithHelp("Y", "copy table")),
+	Errors:     key.NewBinding(key.WithKeys("!"), key.WithHelp("!", "error details")),
+	Screenshot: key.NewBinding(key.WithKeys("P"), key.WithHelp("P", "screenshot")),
+	Help:       key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "all keys")),
+	Reset:      key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "reset")),
+	Pause:      key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "start/stop")),
+
+	NavKeyMap:   ui.NewNavKeyMap(),
+	SplitKeyMap: ui.NewSplitKeyMap(),
+}
+
+// SetKeys applies the "net" key overrides of the config file
+func SetKeys(overrides map[string][]string) error {
+	return ui.Rebind(&keys, overrides)
+}
+
+// tabKey is the key that selects a tab, by its place on the tab bar
+func (m model) tabKey(tab int) string {
+	if i, tabs := m.tabs.Position(tab), keys.Tabs.Keys(); i >= 0 && i < len(tabs) {
+		return tabs[i]
+	}
+	return ""
+}
+
+// help is the footer line for the current tab, built from the active
+// bindings
+func (m model) help() string {
+	var panes []key.Binding
+	if m.split.Active() {
+		panes = []key.Binding{keys.NextPane, keys.ClosePane}
+	}
+	if m.split.Zoomed {
+		panes = append(panes, zoomOut())
+	}
+	tabs := ui.TrimKeys(keys.Tabs, len(m.tabs.Shown()))
+	switch {
+	case m.currentTab == 2 && m.connFilter != "":
+		return ui.HelpLine(append([]key.Binding{keys.Up, keys.Down, showAll(), keys.CopyRow, keys.CopyTable},
+			append(panes, tabs, keys.NextTab, m.showTabs(), keys.Help, keys.Quit)...)...)
+	case m.currentTab == 2:
+		return ui.HelpLine(append([]key.Binding{keys.Up, keys.Down, keys.CopyRow, keys.CopyTable},
+			append(panes, tabs, keys.NextTab, m.showTabs(), keys.Help, keys.Quit)...)...)
+	case m.currentTab == 4:
+		return ui.HelpLine(append([]key.Binding{keys.Up, keys.Down, keys.Filter, keys.Sort, keys.CopyTable},
+			append(panes, tabs, keys.NextTab, m.showTabs(), keys.Help, keys.Quit)...)...)
+	}
+	return ui.HelpLine(append([]key.Binding{tabs, keys.NextTab, m.showTabs(), keys.Reset, keys.Pause, keys.CopyTable},
+		append(panes, keys.Help, keys.Quit)...)...)
+}
+
+// showAll is the filter binding as the footer offers it while the
+// connection table is filtered
+func showAll() key.Binding {
+	b := keys.Filter
+	b.SetHelp(b.Help().Key, "show all")
+	return b
+}
+
+// zoomOut is the zoom binding as the footer offers it while zoomed
+func zoomOut() key.Binding {
+	b := keys.Zoom
+	b.SetHelp(b.Help().Key, "unzoom")
+	return b
+}
+
+// renderHelp is the help overlay: every binding of the current tab and
+// those working everywhere
+func (m model) renderHelp() string {
+	var groups []ui.KeyGroup
+	switch m.currentTab {
+	case 2:
+		groups = append(groups, ui.KeyGroup{Title: "Connections",
+			Bindings: append(keys.NavKeyMap.Bindings(), keys.CopyRow, showAll())})
+	case 4:
+		groups = append(groups, ui.KeyGroup{Title: "Map",
+			Bindings: []key.Binding{keys.Up, keys.Down, keys.Top, keys.Bottom, keys.Filter, keys.Sort}})
+	}
+	groups = append(groups, ui.KeyGroup{Title: "Panes", Bindings: []key.Binding{
+		keys.Stacked, keys.SideBySide, keys.NextPane, keys.PrevPane, keys.ClosePane, keys.Grow, keys.Shrink, keys.Zoom}})
+	groups = append(groups, ui.KeyGroup{Title: "Everywhere", Bindings: []key.Binding{
+		keys.CopyTable, keys.Reset, keys.Pause, ui.TrimKeys(keys.Tabs, len(m.tabs.Shown())), keys.NextTab,
+		keys.HideTab, keys.ShowTabs, keys.Errors, keys.Screenshot, keys.Help, keys.Quit}})
+	return ui.HelpOverlay("⌨️  Keys", groups, m.width) + "\n" + infoStyle.Render("Press any key to close") + "\n"
+}
+
+// tabIndex is the tab a key of the Tabs binding selects, or -1
+func (m model) tabIndex(k string) int {
+	return m.tabs.At(slices.Index(keys.Tabs.Keys(), k))
+}
+
+// showTabs is the binding restoring hidden tabs, offered only while some
+// are
+func (m model) showTabs() key.Binding {
+	b := keys.ShowTabs
+	b.SetEnabled(len(m.tabs.Hidden) > 0)
+	return b
+}
@@ -0,0 +1,171 @@
+package netmon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/budget"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+)
+
+// lifetimeBuckets are the upper bounds of the lifetime histogram's
+// buckets but the last, which takes the rest
+var lifetimeBuckets = []time.Duration{time.Second, 10 * time.Second, time.Minute, 10 * time.Minute, time.Hour}
+
+var lifetimeLabels = []string{"<1s", "1-10s", "10s-1m", "1-10m", "10m-1h", ">1h"}
+
+// readGap is how many sampling intervals, as the CPU budget stretches
+// them, the socket table may go unread before the sockets seen are
+// forgotten, as those opened and closed meanwhile went unseen
+const readGap = 3
+
+// connAges tracks when each socket was first seen, for its age, and how
+// long those seen from start to end lived. Shared by the copies Update
+// makes.
+type connAges struct {
+	seen      map[string]*connSeen // By connKey
+	lastRead  time.Time
+	skipped   bool  // A snapshot left the table unread since lastRead
+	lifetimes []int // Per bucket
+	closed    int
+}
+
+// connSeen is a socket being tracked
+type connSeen struct {
+	first time.Time
+	exact bool // Seen opening, rather than there on the first read
+	ended bool // Its lifetime was counted
+}
+
+func newConnAges() *connAges {
+	return &connAges{seen: make(map[string]*connSeen), lifetimes: make([]int, len(lifetimeLabels))}
+}
+
+// connKey identifies a socket across reads
+func connKey(c netstat.Connection) string {
+	return c.Protocol + " " + c.LocalAddr + " " + c.RemoteAddr + " " + c.NamespaceID
+}
+
+// connOpen reports whether c still carries traffic: TCP sockets that
+// began to close and sockets without a peer do not
+func connOpen(c netstat.Connection) bool {
+	switch c.State {
+	case "ESTABLISHED", "SYN_SENT", "SYN_RECV":
+		return true
+	}
+	return false
+}
+
+// update takes a read of the socket table at now. A connection's lifetime
+// ends as it starts closing, rather than minutes later as it leaves
+// TIME_WAIT, or as it goes when it never closed in view.
+func (a *connAges) update(now time.Time, conns []netstat.Connection) {
+	gap := now.Sub(a.lastRead) > readGap*budget.Interval(tickInterval)
+	first := a.lastRead.IsZero() || a.skipped && gap
+	a.lastRead, a.skipped = now, false
+	if first {
+		clear(a.seen)
+	}
+	current := make(map[string]bool, len(conns))
+	for _, c := range conns {
+		k := connKey(c)
+		current[k] = true
+		s := a.seen[k]
+		if s == nil {
+			s = &connSeen{first: now, exact: !first}
+			a.seen[k] = s
+			if !connOpen(c) {
+				s.ended = true // Listening, unconnected or closing when first seen
+			}
+		}
+		if !connOpen(c) && !s.ended {
+			a.end(now, s)
+		}
+	}
+	for k, s := range a.seen {
+		if !current[k] {
+			if !s.ended {
+				a.end(now, s)
+			}
+			delete(a.seen, k)
+		}
+	}
+}
+
+// unread notes a snapshot that left the socket table unread, as while
+// the Connections tab is hidden
+func (a *connAges) unread() {
+	a.skipped = true
+}
+
+// end counts the lifetime of a connection seen opening
+func (a *connAges) end(now time.Time, s *connSeen) {
+	s.ended = true
+	if !s.exact {
+		return
+	}
+	life := now.Sub(s.first)
+	i := 0
+	for i < len(lifetimeBuckets) && life >= lifetimeBuckets[i] {
+		i++
+	}
+	a.lifetimes[i]++
+	a.closed++
+}
+
+// reset forgets the lifetimes counted
+func (a *connAges) reset() {
+	clear(a.lifetimes)
+	a.closed = 0
+}
+
+// age is how long c has been seen at now, ">" marking those already there
+// when the table was first read
+func (a *connAges) age(c netstat.Connection, now time.Time) string {
+	s := a.seen[connKey(c)]
+	if s == nil {
+		return "-"
+	}
+	age := formatAge(now.Sub(s.first))
+	if !s.exact {
+		return ">" + age
+	}
+	return age
+}
+
+// formatAge writes d in its largest unit or two, such as 45s, 3m12s or
+// 2h05m
+func formatAge(d time.Duration) string {
+	switch d = d.Truncate(time.Second); {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd%02dh", int(d.Hours())/24, int(d.Hours())%24)
+}
+
+// renderLifetimes is the histogram of connection lifetimes on one line,
+// each bucket as a bar an eighth of a cell high per step
+func (a *connAges) renderLifetimes() string {
+	if a.closed == 0 {
+		return "No connection seen from open to close yet"
+	}
+	peak := 0
+	for _, n := range a.lifetimes {
+		peak = max(peak, n)
+	}
+	bars := []rune(" ▁▂▃▄▅▆▇█")
+	parts := []string{fmt.Sprintf("Lifetimes of %d closed:", a.closed)}
+	for i, n := range a.lifetimes {
+		level := 0
+		if n > 0 {
+			level = max(n*(len(bars)-1)/peak, 1)
+		}
+		parts = append(parts, fmt.Sprintf("%s %c %d", lifetimeLabels[i], bars[level], n))
+	}
+	return strings.Join(parts, "  ")
+}
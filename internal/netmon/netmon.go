@@ -0,0 +1,1021 @@
+// Package netmon is the network monitor: interface throughput, a speed test
+// graph, the connection table and a map of where the connections go.
+package netmon
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/s-archdev/Terminal_ADVIS/internal/alerting"
+	"github.com/s-archdev/Terminal_ADVIS/internal/baseline"
+	"github.com/s-archdev/Terminal_ADVIS/internal/budget"
+	"github.com/s-archdev/Terminal_ADVIS/internal/caps"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+	"github.com/s-archdev/Terminal_ADVIS/internal/demo"
+	"github.com/s-archdev/Terminal_ADVIS/internal/geoip"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ring"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+)
+
+// tabNames are the titles of the tabs, in the order of their keys
+var tabNames = []string{"Live Speed", "Interfaces", "Connections", "Graph", "Map"}
+
+// tabIcons are the icons before the tab titles
+var tabIcons = []string{"stats", "interfaces", "connections", "graph", "map"}
+
+// tabIDs are how the config file names the tabs
+var tabIDs = []string{"speed", "interfaces", "connections", "graph", "map"}
+
+// tabSet is the tabs the config file enables, every one by default
+var tabSet, _ = ui.NewTabSet(tabIDs, nil)
+
+// SetTabs applies the "net" list of the config file's "tabs": the tabs to
+// show, in order
+func SetTabs(names []string) error {
+	s, err := ui.NewTabSet(tabIDs, names)
+	if err != nil {
+		return err
+	}
+	tabSet = s
+	return nil
+}
+
+// watched are the config file's "interfaces", empty for all
+var watched []string
+
+// SetInterfaces applies the config file's "interfaces": the names or
+// patterns of the interfaces to show. Interfaces it drops leave the table
+// at the next collection.
+func SetInterfaces(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("interfaces: %q: %w", p, err)
+		}
+	}
+	watched = patterns
+	return nil
+}
+
+// isWatched reports whether the interface called name is shown
+func isWatched(name string) bool {
+	if len(watched) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(watched, func(p string) bool {
+		ok, _ := path.Match(p, name)
+		return ok
+	})
+}
+
+// Styles, set from the palette
+var titleStyle, downloadStyle, uploadStyle, infoStyle, alertStyle, driftStyle, headerStyle, borderStyle lipgloss.Style
+
+func init() { ui.OnPalette(setStyles) }
+
+// setStyles styles the monitor in p's colors, keeping its own for the
+// roles p leaves empty
+func setStyles(p ui.Palette) {
+	titleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ui.ColorOr(p.Title, "#00D4AA")).
+		Background(ui.ColorOr(p.TitleBg, "#1a1a1a")).
+		Padding(0, 2)
+
+	downloadStyle = lipgloss.NewStyle().
+		Foreground(ui.ColorOr(p.Download, "#00FF87")).
+		Bold(true)
+
+	uploadStyle = lipgloss.NewStyle().
+		Foreground(ui.ColorOr(p.Upload, "#FF6B9D")).
+		Bold(true)
+
+	infoStyle = lipgloss.NewStyle().
+		Foreground(ui.ColorOr(p.Info, "#87CEEB")).
+		Italic(true)
+
+	alertStyle = lipgloss.NewStyle().
+		Foreground(ui.ColorOr(p.Critical, "#FF4444")).
+		Bold(true)
+
+	driftStyle = lipgloss.NewStyle().
+		Foreground(ui.ColorOr(p.Warning, "#FFB347"))
+
+	headerStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ui.ColorOr(p.Accent, "#FFD700")).
+		Underline(true)
+
+	borderStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ColorOr(p.Border, "#444444")).
+		Padding(1, 2)
+}
+
+// Flags are the options of the network monitor, parsed by the advis
+// command before New is called
+var Flags = flag.NewFlagSet("net", flag.ExitOnError)
+
+var (
+	flagSource  = sourceFlag("proc")
+	flagHistory = Flags.Duration("throughput-history", 30*time.Second,
+		"how much throughput history to keep per interface for the graph")
+	flagLazyConnections = Flags.Bool("lazy-connections", true,
+		"only read the socket table while the Connections or Map tab is visible, or an alerting channel takes new listeners")
+	flagGeoIP = Flags.String("geoip", "",
+		"MaxMind DB `file` of countries, such as GeoLite2-Country.mmdb, to group the Map tab's peers by")
+)
+
+func init() {
+	Flags.Var(&flagSource, "source",
+		"where interface and connection data comes from: proc (the kernel) or sim (a deterministic simulation)")
+}
+
+// sourceFlag implements flag.Value, accepting only known collectors
+type sourceFlag string
+
+func (s *sourceFlag) String() string { return string(*s) }
+
+func (s *sourceFlag) Set(v string) error {
+	if v != "proc" && v != "sim" {
+		return fmt.Errorf("unknown source %q, want proc or sim", v)
+	}
+	*s = sourceFlag(v)
+	return nil
+}
+
+// NetworkInterface represents a network interface
+type NetworkInterface struct {
+	Name         string
+	BytesRecv    uint64
+	BytesSent    uint64
+	PacketsRecv  uint64
+	PacketsSent  uint64
+	DownloadRate float64 // bytes per second
+	UploadRate   float64 // bytes per second
+	History      *ring.Buffer[SpeedPoint]
+}
+
+// SpeedPoint represents a point in time for speed history
+type SpeedPoint struct {
+	Download float64
+	Upload   float64
+	Time     time.Time
+}
+
+// Model represents the application state
+type model struct {
+	interfaces    map[string]*NetworkInterface
+	connections   []netstat.Connection
+	collector     netstat.Collector
+	lastSample    time.Time // Time of the snapshot the rates were computed against
+	primary       string    // Busiest non-loopback interface, shown on the speed and graph tabs
+	collectErr    error
+	width         int
+	height        int
+	currentTab    int       // 0: Speed, 1: Interfaces, 2: Connections, 3: Graph, 4: Map
+	tabs          ui.TabSet // Tabs on the tab bar
+	scrollX       int       // Columns the connection table is scrolled sideways by
+	lastUpdate    time.Time
+	maxDownload   float64
+	maxUpload     float64
+	totalDownload uint64
+	totalUpload   uint64
+	isRunning     bool
+	connCursor    int    // Selected row on the Connections tab
+	flash         string // One-off status shown in the footer until the next key
+	chord         ui.KeyChord
+	keysOpen      bool // Show the key overlay instead of the tab
+	split         ui.Split
+	shown         ui.VisibilityMsg
+	source        string      // Shown in the status bar: live, sim or demo
+	rev           uint64      // Bumped by every message that can change the view
+	frames        *frameCache // Shared by the copies Update makes
+	errs          *ui.ErrorLog
+	baseline      *baseline.Baseline // Drift is highlighted from, nil for none
+	listening     *listening         // Sockets seen listening, nil but for live sources
+	ages          *connAges
+	protocols     *protocols
+	geo           *geoip.DB            // Places peers in countries, nil without -geoip
+	mapCursor     int                  // Selected group on the Map tab
+	connFilter    string               // Group of the Map tab the connection table is narrowed to, empty for none
+	table         []netstat.Connection // The connection table's rows: connections, narrowed to connFilter
+	mapByTraffic  bool                 // The Map tab ranks by traffic rather than connections
+	traffic       *connTraffic
+}
+
+// listening is the set of listening sockets last read, by
+// baseline.Listener and namespace, for those that start listening to be
+// logged
+type listening struct {
+	read    bool
+	sockets map[string]bool
+}
+
+// frameCache holds the last rendering of the view and the dashboard
+// panels, redrawn only once a message changed the model
+type frameCache struct {
+	view   ui.Memo[uint64, string]
+	panels ui.Memo[[2]uint64, []ui.Panel] // Revision and panel width
+}
+
+// tickInterval is how often the collector is sampled
+const tickInterval = 500 * time.Millisecond
+
+// Messages
+type tickMsg time.Time
+type collectMsg struct {
+	snap netstat.Snapshot
+	err  error
+}
+
+// historyLength is how many samples each interface keeps
+func historyLength() int {
+	return max(int(*flagHistory/tickInterval), 2)
+}
+
+func tickCmd() tea.Cmd {
+	return tea.Tick(budget.Interval(tickInterval), func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// collectTimeout bounds a single collection so a stuck source cannot
+// stall the monitor
+const collectTimeout = 2 * time.Second
+
+func collectCmd(c netstat.Collector) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+		defer cancel()
+		start := time.Now()
+		snap, err := c.Collect(ctx)
+		debug.Timing(fmt.Sprintf("netmon: collection (%d interfaces, %d sockets)", len(snap.Interfaces), len(snap.Connections)), start, err)
+		return collectMsg{snap: snap, err: err}
+	}
+}
+
+// New returns the network monitor, configured from Flags
+func New() tea.Model {
+	var collector netstat.Collector = &netstat.System{}
+	source := "live"
+	if flagSource == "sim" {
+		collector, source = netstat.NewSimulator(1, tickInterval), "sim"
+	}
+	m := initialModel(collector, source)
+	m.restoreState()
+	if source == "live" {
+		m.baseline = baseline.Get()
+		m.listening = &listening{}
+	}
+	return m
+}
+
+// NewDemo returns the network monitor playing back the demo dataset
+func NewDemo(r *demo.Replay) tea.Model {
+	return initialModel(r.Network(tickInterval), "demo")
+}
+
+func initialModel(collector netstat.Collector, source string) model {
+	m := model{
+		interfaces: make(map[string]*NetworkInterface),
+		collector:  collector,
+		currentTab: tabSet.Order[0],
+		tabs:       tabSet,
+		lastUpdate: time.Now(),
+		isRunning:  true,
+		source:     source,
+		shown:      ui.VisibilityMsg{Full: true},
+		frames:     &frameCache{},
+		errs:       &ui.ErrorLog{},
+		ages:       newConnAges(),
+		protocols:  newProtocols(),
+		traffic:    newConnTraffic(),
+	}
+	if *flagGeoIP != "" {
+		db, err := geoip.Open(*flagGeoIP)
+		if err != nil {
+			m.errs.Add("geoip", err, time.Now())
+		}
+		m.geo = db
+	}
+	return m
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(tickCmd(), collectCmd(m.collector))
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Assume the message changes what is shown; the default case below
+	// takes this back for messages meant for other monitors
+	m.rev++
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		// "gg" waits on its second key; one that does not complete it
+		// comes with the held one
+		pressed := m.chord.Feed(msg, keys.Top)
+		var cmds []tea.Cmd
+		var next tea.Model = m
+		for _, k := range pressed {
+			var cmd tea.Cmd
+			next, cmd = next.(model).updateKey(k)
+			cmds = append(cmds, cmd)
+		}
+		return next, tea.Batch(cmds...)
+
+	case ui.VisibilityMsg:
+		m.shown = msg
+
+	case tickMsg:
+		m.lastUpdate = time.Time(msg)
+		if s, ok := m.collector.(netstat.ConnectionSkipper); ok {
+			s.SkipConnections(!m.wantConnections() || caps.Get().NoSockets)
+		}
+		if m.isRunning {
+			return m, tea.Batch(tickCmd(), collectCmd(m.collector))
+		}
+		m.ages.unread()
+		return m, tickCmd()
+
+	case ui.ClipboardMsg:
+		m.flash = msg.Status()
+
+	case ui.ScreenshotMsg:
+		m.flash = msg.Status()
+
+	case collectMsg:
+		m.collectErr = msg.err
+		m.errs.Add("network", msg.err, time.Now())
+		if m.isRunning {
+			m.applySnapshot(msg.snap)
+		}
+
+	default:
+		m.rev--
+	}
+
+	return m, nil
+}
+
+// updateKey handles a key press
+func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.flash = ""
+	if m.keysOpen {
+		// Any key closes the overlay, and quitting still works
+		m.keysOpen = false
+		if !key.Matches(msg, keys.Quit) {
+			return m, nil
+		}
+	}
+	switch {
+	case key.Matches(msg, keys.Quit):
+		return m, tea.Quit
+	case keys.Scrolls(msg):
+		if m.currentTab == 2 {
+			m.scrollX = keys.ScrollOffset(msg, m.scrollX, ui.Widest(m.renderConnectionTable())-m.width)
+		}
+	case keys.Moves(msg):
+		switch m.currentTab {
+		case 2:
+			m.connCursor = keys.Move(msg, m.connCursor, len(m.table), m.height)
+		case 4:
+			groups, _ := m.groupPeers()
+			m.mapCursor = keys.Move(msg, m.mapCursor, len(groups), m.height)
+		}
+	case key.Matches(msg, keys.Filter):
+		switch {
+		case m.currentTab == 4:
+			m.filterToGroup()
+		case m.currentTab == 2 && m.connFilter != "":
+			m.connFilter = ""
+			m.filterConnections()
+		}
+	case key.Matches(msg, keys.Sort):
+		if m.currentTab == 4 {
+			m.mapByTraffic = !m.mapByTraffic
+		}
+	case key.Matches(msg, keys.CopyRow):
+		if m.currentTab == 2 && m.connCursor < len(m.table) {
+			c := m.table[m.connCursor]
+			line := fmt.Sprintf("%s\t%s\t%s\t%s\t%s", c.Protocol, c.LocalAddr, c.RemoteAddr, c.State, m.ages.age(c, m.lastSample))
+			return m, ui.Copy(line, "selected connection")
+		}
+	case key.Matches(msg, keys.CopyTable):
+		return m, ui.Copy(strings.TrimRight(ansi.Strip(m.renderTab()), "\n")+"\n", "visible table")
+	case key.Matches(msg, keys.Errors):
+		m.errs.Expanded = !m.errs.Expanded
+	case key.Matches(msg, keys.Help):
+		m.keysOpen = true
+	case key.Matches(msg, keys.Screenshot):
+		return m, ui.Screenshot(m.View())
+	case key.Matches(msg, keys.NextTab):
+		m.currentTab = m.tabs.Next(m.currentTab)
+		m.split.SetTab(m.currentTab)
+	case key.Matches(msg, keys.Tabs) && m.tabIndex(msg.String()) >= 0:
+		m.currentTab = m.tabIndex(msg.String())
+		m.split.SetTab(m.currentTab)
+	case key.Matches(msg, keys.HideTab):
+		if next, ok := m.tabs.Hide(m.currentTab); ok {
+			m.currentTab = next
+			m.split.SetTab(m.currentTab)
+		} else {
+			m.flash = "The last tab stays shown"
+		}
+	case key.Matches(msg, keys.ShowTabs):
+		m.tabs.Unhide()
+	case key.Matches(msg, keys.Reset):
+		// Reset statistics
+		for _, iface := range m.interfaces {
+			iface.History.Reset()
+		}
+		m.maxDownload = 0
+		m.maxUpload = 0
+		m.totalDownload = 0
+		m.totalUpload = 0
+		m.ages.reset()
+	case key.Matches(msg, keys.Pause):
+		// Toggle running state
+		m.isRunning = !m.isRunning
+	default:
+		m.currentTab, _ = m.split.Update(msg, keys.SplitKeyMap, m.currentTab)
+	}
+	return m, nil
+}
+
+func (m model) View() string {
+	if m.width == 0 {
+		return "Initializing network monitor..."
+	}
+	view := m.frames.view.Get(m.rev, m.renderView)
+	// Our own usage changes without any message to this monitor
+	if usage := budget.Status(); usage != "" {
+		view += "\n" + infoStyle.Render(usage)
+	}
+	return view
+}
+
+// renderView draws everything but the usage line
+func (m model) renderView() string {
+	var content strings.Builder
+
+	// Header
+	status := ui.Icon("running") + "RUNNING"
+	if !m.isRunning {
+		status = ui.Icon("paused") + "PAUSED"
+	}
+
+	header := titleStyle.Render(ui.Icon("network")+"Network Speed Visualizer") + " " + status
+	content.WriteString(header + "\n\n")
+
+	// Tab navigation
+	var tabStrings []string
+	for _, i := range m.tabs.Shown() {
+		if i == m.currentTab {
+			tabStrings = append(tabStrings, headerStyle.Render(fmt.Sprintf("[%s] %s%s", m.tabKey(i), ui.Icon(tabIcons[i]), tabNames[i])))
+		} else {
+			tabStrings = append(tabStrings, fmt.Sprintf(" %s  %s%s ", m.tabKey(i), ui.Icon(tabIcons[i]), tabNames[i]))
+		}
+	}
+	content.WriteString(strings.Join(tabStrings, " | ") + "\n\n")
+
+	// The footer comes first so split panes know the height left to them
+	var footer strings.Builder
+	if m.errs.Expanded {
+		footer.WriteString("\n" + headerStyle.Render(ui.Icon("errors")+"Collector Errors") + "\n")
+		footer.WriteString(m.errs.Render(m.width, 8))
+	}
+	if m.flash != "" {
+		footer.WriteString("\n" + downloadStyle.Render(m.flash))
+	}
+	if status := m.errs.Status(m.width, keys.Errors); status != "" {
+		footer.WriteString("\n" + status)
+	}
+	footer.WriteString("\n" + ui.StatusBar{Paused: !m.isRunning, Source: m.source, Help: m.help()}.Render(m.width, time.Now()))
+
+	// Content based on current tab
+	switch {
+	case m.keysOpen:
+		content.WriteString(m.renderHelp())
+	case m.split.Zoomed:
+		// The tab takes the header's and the journal's lines too
+		used := lipgloss.Height(content.String()) + lipgloss.Height(footer.String())
+		footer := strings.TrimPrefix(footer.String(), "\n")
+		return m.renderZoomed(m.height-used, m.height-lipgloss.Height(footer)) + footer
+	case m.split.Active():
+		used := lipgloss.Height(content.String()) + lipgloss.Height(footer.String())
+		content.WriteString(m.renderPanes(max(m.height-used, 4*len(m.split.Tabs))))
+	default:
+		content.WriteString(m.renderTab())
+	}
+	content.WriteString(footer.String())
+
+	return content.String()
+}
+
+// renderPanes draws the split panes in height lines, each rendering its
+// tab as if the terminal were the pane's size plus the rest of the screen
+func (m model) renderPanes(height int) string {
+	title := func(tab int) string {
+		return fmt.Sprintf("[%s] %s%s", m.tabKey(tab), ui.Icon(tabIcons[tab]), tabNames[tab])
+	}
+	return m.split.Render(m.width, height, title, func(tab, width, h int) string {
+		p := m
+		p.currentTab, p.width, p.height = tab, width, m.height-height+h
+		return p.renderTab()
+	})
+}
+
+// renderZoomed draws the current tab alone in height lines, given the
+// area it has when not zoomed
+func (m model) renderZoomed(area, height int) string {
+	return m.split.RenderZoomed(m.width, height, func(width, h int) string {
+		p := m
+		p.width, p.height = width, m.height-area+h
+		return p.renderTab()
+	})
+}
+
+// renderTab renders the content of the current tab
+func (m model) renderTab() string {
+	switch m.currentTab {
+	case 0:
+		return m.renderSpeedView()
+	case 1:
+		return m.renderInterfacesView()
+	case 2:
+		return m.renderConnectionsView()
+	case 3:
+		return m.renderGraphView()
+	case 4:
+		return m.renderMapView()
+	}
+	return ""
+}
+
+func (m model) renderSpeedView() string {
+	var content strings.Builder
+
+	primary := m.interfaces[m.primary]
+	if primary == nil {
+		return m.noData()
+	}
+
+	// Current speeds
+	content.WriteString(headerStyle.Render(ui.Icon("speed")+"Current Network Speed") + " " + infoStyle.Render(primary.Name) + "\n\n")
+
+	downloadMbps := primary.DownloadRate * 8 / (1024 * 1024) // Convert to Mbps
+	uploadMbps := primary.UploadRate * 8 / (1024 * 1024)
+
+	// Large speed display
+	content.WriteString(fmt.Sprintf("%sDownload: %s %.2f Mbps\n", ui.Icon("download"),
+		downloadStyle.Render("▼"), downloadMbps))
+	content.WriteString(fmt.Sprintf("%sUpload:   %s %.2f Mbps\n\n", ui.Icon("upload"),
+		uploadStyle.Render("▲"), uploadMbps))
+
+	// Visual bars
+	maxBarWidth := 50
+	if m.width > 80 {
+		maxBarWidth = m.width - 30
+	}
+
+	// Download bar
+	maxSpeed := math.Max(m.maxDownload, primary.DownloadRate*1.2)
+	if maxSpeed == 0 {
+		maxSpeed = 1
+	}
+	downloadPercent := int((primary.DownloadRate / maxSpeed) * 100)
+	downloadBar := createAnimatedBar(downloadPercent, maxBarWidth, "download")
+	content.WriteString(fmt.Sprintf("Download: %s %s/s\n", downloadBar, ui.FormatBytes(uint64(primary.DownloadRate))))
+
+	// Upload bar
+	maxUpSpeed := math.Max(m.maxUpload, primary.UploadRate*1.2)
+	if maxUpSpeed == 0 {
+		maxUpSpeed = 1
+	}
+	uploadPercent := int((primary.UploadRate / maxUpSpeed) * 100)
+	uploadBar := createAnimatedBar(uploadPercent, maxBarWidth, "upload")
+	content.WriteString(fmt.Sprintf("Upload:   %s %s/s\n\n", uploadBar, ui.FormatBytes(uint64(primary.UploadRate))))
+
+	// Statistics
+	content.WriteString(headerStyle.Render(ui.Icon("stats")+"Session Statistics") + "\n")
+	content.WriteString(fmt.Sprintf("Total Downloaded: %s\n", ui.FormatBytes(m.totalDownload)))
+	content.WriteString(fmt.Sprintf("Total Uploaded:   %s\n", ui.FormatBytes(m.totalUpload)))
+	content.WriteString(fmt.Sprintf("Peak Download:    %.2f Mbps\n", m.maxDownload*8/(1024*1024)))
+	content.WriteString(fmt.Sprintf("Peak Upload:      %.2f Mbps\n", m.maxUpload*8/(1024*1024)))
+	content.WriteString(fmt.Sprintf("Duration:         %v\n", time.Since(m.lastUpdate).Truncate(time.Second)))
+
+	return content.String()
+}
+
+func (m model) renderInterfacesView() string {
+	var content strings.Builder
+
+	content.WriteString(headerStyle.Render(ui.Icon("interfaces")+"Network Interfaces") + "\n\n")
+
+	content.WriteString(fmt.Sprintf("%-12s %-15s %-15s %-10s %-10s\n",
+		"INTERFACE", "DOWNLOAD", "UPLOAD", "PACKETS RX", "PACKETS TX"))
+	content.WriteString(strings.Repeat("─", 70) + "\n")
+
+	for _, name := range m.interfaceNames() {
+		iface := m.interfaces[name]
+		rates := fmt.Sprintf("%-15s %-15s", ui.FormatBytes(uint64(iface.DownloadRate))+"/s", ui.FormatBytes(uint64(iface.UploadRate))+"/s")
+		if m.baseline != nil && m.baseline.TrafficAbove(name, iface.DownloadRate, iface.UploadRate) {
+			rates = driftStyle.Render(rates)
+		}
+
+		content.WriteString(fmt.Sprintf("%s %s %-10s %-10s\n",
+			ui.Fit(name, 12), rates,
+			formatCount(iface.PacketsRecv), formatCount(iface.PacketsSent)))
+	}
+	if len(m.interfaces) == 0 {
+		content.WriteString(m.noData() + "\n")
+	}
+	content.WriteString("\n" + m.protocols.render(m.width))
+
+	return content.String()
+}
+
+func (m model) renderConnectionsView() string {
+	var content strings.Builder
+
+	content.WriteString(headerStyle.Render(ui.Icon("connections")+"Active Connections") + "\n\n")
+	if m.connFilter != "" {
+		content.WriteString(infoStyle.Render(fmt.Sprintf("Connections to %s, %s shows all", m.connFilter, keys.Filter.Help().Key)) + "\n\n")
+	}
+	// IPv6 addresses run past the screen, so the columns after the
+	// protocol scroll sideways
+	content.WriteString(ui.ScrollX(m.renderConnectionTable(), connFrozen, m.scrollX, m.width))
+
+	switch {
+	case len(m.connections) == 0 && caps.Get().NoSockets:
+		content.WriteString("No socket table available (Android keeps /proc/net/tcp from apps)\n")
+	case len(m.connections) == 0:
+		content.WriteString(m.noData() + "\n")
+	default:
+		sockets := fmt.Sprintf("%d sockets", len(m.connections))
+		if m.connFilter != "" {
+			sockets = fmt.Sprintf("%d of %d sockets", len(m.table), len(m.connections))
+		}
+		content.WriteString("\n" + infoStyle.Render(sockets))
+		if n := m.newListeners(); n > 0 {
+			content.WriteString("  " + driftStyle.Render(fmt.Sprintf("%d listening that were not in the baseline", n)))
+		}
+		content.WriteString("\n" + ui.Truncate(m.ages.renderLifetimes(), m.width))
+	}
+
+	return content.String()
+}
+
+// connFrozen is the width of the connection table's cursor and protocol
+// columns, which stay in place when it scrolls sideways
+const connFrozen = 11
+
+// renderConnectionTable draws the header and the visible rows of the
+// connection table at full width, the address columns as wide as the
+// longest address shown
+func (m model) renderConnectionTable() string {
+	var content strings.Builder
+
+	// Only render the rows that fit, keeping the cursor visible
+	start, end := ui.Viewport(m.connCursor, len(m.table), max(m.height-12, 5))
+	local, remote := 25, 25
+	for _, conn := range m.table[start:end] {
+		local = max(local, len(conn.LocalAddr))
+		remote = max(remote, len(conn.RemoteAddr))
+	}
+	// Sockets of containers and other network namespaces name theirs
+	namespaces := slices.ContainsFunc(m.table, func(c netstat.Connection) bool { return c.Namespace != "" })
+	header := fmt.Sprintf("  %-8s %-*s %-*s %-18s %8s", "PROTO", local, "LOCAL ADDRESS", remote, "REMOTE ADDRESS", "STATE", "AGE")
+	rule := 40 + local + remote
+	if namespaces {
+		header += " NAMESPACE"
+		rule += 26
+	}
+	content.WriteString(header + "\n")
+	content.WriteString(strings.Repeat("─", rule) + "\n")
+
+	for i := start; i < end; i++ {
+		conn := m.table[i]
+		stateStyle := infoStyle
+		if conn.State == "ESTABLISHED" {
+			stateStyle = downloadStyle
+		} else if conn.State == "LISTEN" {
+			stateStyle = uploadStyle
+		}
+		state := conn.State
+		if m.newListener(conn) {
+			stateStyle, state = driftStyle, state+" (new)"
+		}
+
+		cursor := "  "
+		if i == m.connCursor {
+			cursor = headerStyle.Render("▶") + " "
+		}
+		row := fmt.Sprintf("%s%-8s %-*s %-*s %s%s %8s",
+			cursor,
+			conn.Protocol,
+			local, conn.LocalAddr,
+			remote, conn.RemoteAddr,
+			stateStyle.Render(state), strings.Repeat(" ", max(18-ui.Width(state), 0)),
+			m.ages.age(conn, m.lastSample))
+		if namespaces {
+			row += " " + conn.Namespace
+		}
+		content.WriteString(row + "\n")
+	}
+	return content.String()
+}
+
+// newListener reports whether conn listens where nothing did in the
+// baseline
+func (m model) newListener(conn netstat.Connection) bool {
+	return m.baseline != nil && baseline.Listening(conn) && m.baseline.NewListener(conn.Protocol, conn.LocalAddr)
+}
+
+// newListeners counts the sockets listening where nothing did in the
+// baseline
+func (m model) newListeners() int {
+	n := 0
+	for _, conn := range m.connections {
+		if m.newListener(conn) {
+			n++
+		}
+	}
+	return n
+}
+
+func (m model) renderGraphView() string {
+	var content strings.Builder
+
+	content.WriteString(headerStyle.Render(ui.Icon("graph")+"Speed History Graph") + "\n\n")
+
+	primary := m.interfaces[m.primary]
+	if primary == nil || primary.History.Len() == 0 {
+		content.WriteString("No history data available yet...\n")
+		return content.String()
+	}
+
+	// ASCII graph
+	graphHeight := 10
+	graphWidth := 60
+	if m.width > 80 {
+		graphWidth = m.width - 24
+	}
+
+	// Find max values for scaling
+	maxVal := 0.0
+	for _, point := range primary.History.All() {
+		if point.Download > maxVal {
+			maxVal = point.Download
+		}
+		if point.Upload > maxVal {
+			maxVal = point.Upload
+		}
+	}
+
+	if maxVal == 0 {
+		maxVal = 1
+	}
+
+	// Draw graph
+	content.WriteString(fmt.Sprintf("%s speed over time (last %v):\n\n", primary.Name, *flagHistory))
+
+	for row := graphHeight - 1; row >= 0; row-- {
+		threshold := maxVal * float64(row) / float64(graphHeight-1)
+
+		// Y-axis label
+		content.WriteString(fmt.Sprintf("%10s │", ui.FormatBytes(uint64(threshold))+"/s"))
+
+		// Graph line
+		historyLen := primary.History.Len()
+		step := float64(historyLen) / float64(graphWidth)
+
+		for col := 0; col < graphWidth; col++ {
+			idx := int(float64(col) * step)
+			if idx >= historyLen {
+				idx = historyLen - 1
+			}
+
+			point := primary.History.At(idx)
+			char := " "
+
+			if point.Download >= threshold {
+				char = downloadStyle.Render("▓")
+			} else if point.Upload >= threshold {
+				char = uploadStyle.Render("░")
+			}
+
+			content.WriteString(char)
+		}
+		content.WriteString("\n")
+	}
+
+	// X-axis
+	content.WriteString("           └" + strings.Repeat("─", graphWidth) + "\n")
+	content.WriteString("            " + strings.Repeat(" ", graphWidth-15) + "Time →\n\n")
+
+	// Legend
+	content.WriteString("Legend: " + downloadStyle.Render("▓ Download") + " " + uploadStyle.Render("░ Upload") + "\n")
+
+	return content.String()
+}
+
+// Helper functions
+
+func createAnimatedBar(percent, width int, barType string) string {
+	if percent > 100 {
+		percent = 100
+	}
+	if percent < 0 {
+		percent = 0
+	}
+
+	filled := int(float64(width) * float64(percent) / 100.0)
+
+	var bar strings.Builder
+	var style lipgloss.Style
+
+	if barType == "download" {
+		style = downloadStyle
+	} else {
+		style = uploadStyle
+	}
+	// Rates near the peak take the bandwidth threshold colors, if set
+	if s, ok := ui.ThresholdStyle("bandwidth", ui.ThresholdLevel("bandwidth", float64(percent))); ok {
+		style = s
+	}
+
+	// Create animated effect with different characters
+	animChars := []string{"█", "▉", "▊", "▋", "▌", "▍", "▎", "▏"}
+	animFrame := int(time.Now().UnixMilli()/200) % len(animChars)
+
+	for i := 0; i < width; i++ {
+		if i < filled-1 {
+			bar.WriteString("█")
+		} else if i == filled-1 && filled > 0 {
+			bar.WriteString(animChars[animFrame])
+		} else {
+			bar.WriteString("░")
+		}
+	}
+
+	return style.Render(bar.String())
+}
+
+// applySnapshot turns a snapshot into per-interface rates against the
+// previous one and refreshes the connection table
+func (m *model) applySnapshot(snap netstat.Snapshot) {
+	elapsed := snap.Time.Sub(m.lastSample).Seconds()
+	first := m.lastSample.IsZero()
+	m.lastSample = snap.Time
+
+	seen := make(map[string]bool, len(snap.Interfaces))
+	var busiest uint64
+	m.primary = ""
+	for _, c := range snap.Interfaces {
+		if !isWatched(c.Name) {
+			continue
+		}
+		seen[c.Name] = true
+		iface := m.interfaces[c.Name]
+		if iface == nil {
+			iface = &NetworkInterface{Name: c.Name, History: ring.New[SpeedPoint](historyLength())}
+			m.interfaces[c.Name] = iface
+		} else if !first && elapsed > 0 && c.RxBytes >= iface.BytesRecv && c.TxBytes >= iface.BytesSent {
+			iface.DownloadRate = float64(c.RxBytes-iface.BytesRecv) / elapsed
+			iface.UploadRate = float64(c.TxBytes-iface.BytesSent) / elapsed
+			iface.History.Push(SpeedPoint{
+				Download: iface.DownloadRate,
+				Upload:   iface.UploadRate,
+				Time:     snap.Time,
+			})
+			if c.Name != "lo" {
+				m.totalDownload += c.RxBytes - iface.BytesRecv
+				m.totalUpload += c.TxBytes - iface.BytesSent
+			}
+		}
+		iface.BytesRecv, iface.BytesSent = c.RxBytes, c.TxBytes
+		iface.PacketsRecv, iface.PacketsSent = c.RxPackets, c.TxPackets
+
+		if c.Name != "lo" && (m.primary == "" || c.RxBytes+c.TxBytes > busiest) {
+			m.primary, busiest = c.Name, c.RxBytes+c.TxBytes
+		}
+	}
+	for name := range m.interfaces {
+		if !seen[name] {
+			delete(m.interfaces, name)
+		}
+	}
+
+	if primary := m.interfaces[m.primary]; primary != nil {
+		m.maxDownload = math.Max(m.maxDownload, primary.DownloadRate)
+		m.maxUpload = math.Max(m.maxUpload, primary.UploadRate)
+	}
+
+	m.protocols.update(snap.Time, snap.Protocols)
+
+	// A collection that skipped the socket table or ran out of time
+	// leaves the last one in place
+	if snap.ConnectionsRead {
+		m.connections = snap.Connections
+		m.ages.update(snap.Time, snap.Connections)
+		m.traffic.update(snap.Time, snap.Connections)
+		m.logListeners(snap)
+	} else {
+		m.ages.unread()
+	}
+	m.filterConnections()
+}
+
+// logListeners hands the sockets that started listening since the last
+// read of the socket table to the alerting channels; those of the first
+// read were there before the monitor started
+func (m model) logListeners(snap netstat.Snapshot) {
+	if m.listening == nil || len(snap.Connections) == 0 {
+		return
+	}
+	sockets := make(map[string]bool)
+	var events []alerting.Event
+	for _, conn := range snap.Connections {
+		if !baseline.Listening(conn) {
+			continue
+		}
+		name := baseline.Listener(conn.Protocol, conn.LocalAddr)
+		key := name + "\x00" + conn.NamespaceID
+		sockets[key] = true
+		if !m.listening.read || m.listening.sockets[key] {
+			continue
+		}
+		e := alerting.Event{Time: snap.Time, Kind: alerting.Listener, Level: alerting.LevelWarning, Source: "network",
+			Message: name + " started listening", Fields: map[string]string{"protocol": conn.Protocol, "address": conn.LocalAddr}}
+		if conn.Namespace != "" {
+			e.Message += " in " + conn.Namespace
+			e.Fields["namespace"] = conn.Namespace
+		}
+		if m.baseline != nil {
+			e.Fields["in_baseline"] = strconv.FormatBool(!m.newListener(conn))
+		}
+		events = append(events, e)
+	}
+	m.listening.read, m.listening.sockets = true, sockets
+	alerting.Deliver(events)
+}
+
+// wantConnections reports whether the socket table is worth reading: for
+// a tab or panel showing it, or for the alerting channels to hear of each
+// new listener when it starts rather than on the next visit
+func (m model) wantConnections() bool {
+	return !*flagLazyConnections || m.shown.Full && (m.currentTab == 2 || m.currentTab == 4 || m.split.Shows(2) || m.split.Shows(4)) ||
+		m.shown.Shows("toptalkers") || m.listening != nil && alerting.Enabled()
+}
+
+// interfaceNames returns the interface names in a stable order
+func (m model) interfaceNames() []string {
+	names := make([]string, 0, len(m.interfaces))
+	for name := range m.interfaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// noData explains an empty view: still waiting, or why collection failed
+func (m model) noData() string {
+	if m.collectErr != nil {
+		return alertStyle.Render("Collection failed: " + m.collectErr.Error())
+	}
+	if !caps.Get().Procfs {
+		return "No network interface data available (requires /proc)"
+	}
+	return "No network interface data available"
+}
+
+// formatCount abbreviates a packet count
+func formatCount(n uint64) string {
+	switch {
+	case n >= 1_000_000_000:
+		return ui.FormatFloat(float64(n)/1e9, 1) + "G"
+	case n >= 1_000_000:
+		return ui.FormatFloat(float64(n)/1e6, 1) + "M"
+	case n >= 1_000:
+		return ui.FormatFloat(float64(n)/1e3, 1) + "k"
+	}
+	return ui.FormatInt(int64(n))
+}
@@ -0,0 +1,100 @@
+package netmon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/ring"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+)
+
+// protocolCounter is one of the kernel's protocol counters shown, as
+// netstat -s would
+type protocolCounter struct {
+	label string
+	get   func(netstat.Protocols) uint64
+}
+
+var protocolCounters = []protocolCounter{
+	{"ICMP unreachable in", func(p netstat.Protocols) uint64 { return p.ICMPInDestUnreachs }},
+	{"ICMP unreachable out", func(p netstat.Protocols) uint64 { return p.ICMPOutDestUnreachs }},
+	{"ICMP errors in", func(p netstat.Protocols) uint64 { return p.ICMPInErrors }},
+	{"TCP active opens", func(p netstat.Protocols) uint64 { return p.TCPActiveOpens }},
+	{"TCP passive opens", func(p netstat.Protocols) uint64 { return p.TCPPassiveOpens }},
+	{"TCP failed attempts", func(p netstat.Protocols) uint64 { return p.TCPAttemptFails }},
+	{"TCP resets received", func(p netstat.Protocols) uint64 { return p.TCPEstabResets }},
+	{"TCP resets sent", func(p netstat.Protocols) uint64 { return p.TCPOutRsts }},
+	{"TCP retransmits", func(p netstat.Protocols) uint64 { return p.TCPRetransSegs }},
+	{"TCP errors in", func(p netstat.Protocols) uint64 { return p.TCPInErrs }},
+	{"UDP to no port", func(p netstat.Protocols) uint64 { return p.UDPNoPorts }},
+	{"UDP errors in", func(p netstat.Protocols) uint64 { return p.UDPInErrors }},
+}
+
+// protocols holds the last protocol counters read and the per-second rate
+// history of each. Shared by the copies Update makes.
+type protocols struct {
+	last    *netstat.Protocols // nil until the collector reads them
+	at      time.Time
+	rates   []float64 // Per protocolCounters entry
+	history []*ring.Buffer[float64]
+}
+
+func newProtocols() *protocols {
+	p := &protocols{rates: make([]float64, len(protocolCounters))}
+	for range protocolCounters {
+		p.history = append(p.history, ring.New[float64](historyLength()))
+	}
+	return p
+}
+
+// update takes the counters read at now, nil where none are kept. A
+// counter that went back, as on a namespace or kernel change, restarts
+// its rate from the new value.
+func (p *protocols) update(now time.Time, counters *netstat.Protocols) {
+	if counters == nil {
+		return
+	}
+	if p.last != nil {
+		if elapsed := now.Sub(p.at).Seconds(); elapsed > 0 {
+			for i, c := range protocolCounters {
+				prev, cur := c.get(*p.last), c.get(*counters)
+				p.rates[i] = 0
+				if cur >= prev {
+					p.rates[i] = float64(cur-prev) / elapsed
+				}
+				p.history[i].Push(p.rates[i])
+			}
+		}
+	}
+	last := *counters
+	p.last, p.at = &last, now
+}
+
+// render lists each counter with its rate, its total and the history of
+// its rate
+func (p *protocols) render(width int) string {
+	var content strings.Builder
+	content.WriteString(headerStyle.Render(ui.Icon("interfaces")+"Protocol Counters") + "\n\n")
+	if p.last == nil {
+		content.WriteString("No protocol counters on this system\n")
+		return content.String()
+	}
+	content.WriteString(fmt.Sprintf("%-21s %10s %12s  %s\n", "COUNTER", "RATE", "TOTAL", "HISTORY"))
+	content.WriteString(strings.Repeat("─", 70) + "\n")
+	spark := max(width-48, 0)
+	for i, c := range protocolCounters {
+		values := make([]float64, 0, p.history[i].Len())
+		for _, v := range p.history[i].All() {
+			values = append(values, v)
+		}
+		rate := fmt.Sprintf("%10s", fmt.Sprintf("%.1f/s", p.rates[i]))
+		if p.rates[i] > 0 {
+			rate = infoStyle.Render(rate)
+		}
+		content.WriteString(fmt.Sprintf("%-21s %s %12s  %s\n",
+			c.label, rate, formatCount(c.get(*p.last)), ui.Sparkline(values, spark, 1)))
+	}
+	return content.String()
+}
@@ -0,0 +1,51 @@
+package netmon
+
+import (
+	"slices"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/state"
+)
+
+// savedState is the network monitor's section of the state file
+type savedState struct {
+	Tab           string  `json:"tab"`
+	TotalDownload uint64  `json:"total_download"`
+	TotalUpload   uint64  `json:"total_upload"`
+	MaxDownload   float64 `json:"max_download"`
+	MaxUpload     float64 `json:"max_upload"`
+}
+
+// restoreState picks up where the last session left off, with the
+// session totals and peaks only if it was the same day. The simulator
+// starts over.
+func (m *model) restoreState() {
+	if m.source != "live" {
+		return
+	}
+	var s savedState
+	saved, ok := state.Restore("net", &s)
+	if !ok {
+		return
+	}
+	if i := slices.Index(tabIDs, s.Tab); i >= 0 && m.tabs.Position(i) >= 0 {
+		m.currentTab = i
+	}
+	if state.SameDay(saved) {
+		m.totalDownload, m.totalUpload = s.TotalDownload, s.TotalUpload
+		m.maxDownload, m.maxUpload = s.MaxDownload, s.MaxUpload
+	}
+}
+
+// KeepState implements state.Keeper
+func (m model) KeepState() map[string]any {
+	if m.source != "live" {
+		return nil
+	}
+	return map[string]any{"net": savedState{
+		Tab:           tabIDs[m.currentTab],
+		TotalDownload: m.totalDownload,
+		TotalUpload:   m.totalUpload,
+		MaxDownload:   m.maxDownload,
+		MaxUpload:     m.maxUpload,
+	}}
+}
@@ -0,0 +1,47 @@
+package netmon
+
+import (
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+)
+
+// connTraffic turns the byte counts the platform reads for TCP sockets
+// into rates. Shared by the copies Update makes.
+type connTraffic struct {
+	last  map[string]uint64  // Bytes both ways at the last read, by connKey
+	rates map[string]float64 // Bytes per second both ways, by connKey
+	at    time.Time
+	known bool // The last read carried byte counts
+}
+
+func newConnTraffic() *connTraffic {
+	return &connTraffic{last: make(map[string]uint64), rates: make(map[string]float64)}
+}
+
+// update takes a read of the socket table at now. A socket's first read
+// gives it no rate, as its bytes may be from long before.
+func (t *connTraffic) update(now time.Time, conns []netstat.Connection) {
+	elapsed := now.Sub(t.at).Seconds()
+	last := make(map[string]uint64, len(t.last))
+	clear(t.rates)
+	t.known = false
+	for _, c := range conns {
+		if !c.HasBytes {
+			continue
+		}
+		t.known = true
+		k := connKey(c)
+		total := c.BytesSent + c.BytesReceived
+		last[k] = total
+		if prev, ok := t.last[k]; ok && elapsed > 0 && total >= prev {
+			t.rates[k] = float64(total-prev) / elapsed
+		}
+	}
+	t.last, t.at = last, now
+}
+
+// rate is the bytes per second c moves both ways, 0 when unknown
+func (t *connTraffic) rate(c netstat.Connection) float64 {
+	return t.rates[connKey(c)]
+}
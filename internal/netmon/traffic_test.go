@@ -0,0 +1,64 @@
+package netmon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+)
+
+// trafficConn is a TCP socket that moved n bytes each way
+func trafficConn(n uint64) netstat.Connection {
+	return netstat.Connection{Protocol: "tcp", LocalAddr: "10.0.0.2:40000", RemoteAddr: "10.0.0.1:443",
+		State: "ESTABLISHED", BytesSent: n, BytesReceived: n, HasBytes: true}
+}
+
+// TestTrafficSurvivesUnreadTable checks that a snapshot leaving the socket
+// table unread, as a skipped or timed-out collection does, keeps the byte
+// baselines, so the next read gives a rate rather than a first sample
+func TestTrafficSurvivesUnreadTable(t *testing.T) {
+	start := time.Now()
+	unread := map[string]func() netstat.Snapshot{
+		"skipped": func() netstat.Snapshot {
+			var s netstat.System
+			s.SkipConnections(true)
+			snap, err := s.Collect(context.Background())
+			if err != nil {
+				t.Fatalf("skipped collection: %v", err)
+			}
+			return snap
+		},
+		"timed out": func() netstat.Snapshot {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			snap, _ := (&netstat.System{}).Collect(ctx)
+			return snap
+		},
+	}
+	for name, collect := range unread {
+		t.Run(name, func(t *testing.T) {
+			m := initialModel(netstat.NewSimulator(1, time.Second), "sim")
+			m.applySnapshot(netstat.Snapshot{Time: start, Connections: []netstat.Connection{trafficConn(1000)}, ConnectionsRead: true})
+
+			snap := collect()
+			if snap.ConnectionsRead {
+				t.Fatal("collection reports the socket table read")
+			}
+			snap.Time = start.Add(tickInterval)
+			m.applySnapshot(snap)
+			if len(m.connections) != 1 {
+				t.Errorf("%d connections after an unread table, want the last read's 1", len(m.connections))
+			}
+
+			now := start.Add(2 * tickInterval)
+			m.applySnapshot(netstat.Snapshot{Time: now, Connections: []netstat.Connection{trafficConn(3000)}, ConnectionsRead: true})
+			if got, want := m.traffic.rate(trafficConn(0)), 4000/(2*tickInterval).Seconds(); got != want {
+				t.Errorf("rate = %v, want %v", got, want)
+			}
+			if age := m.ages.age(trafficConn(0), now); age[0] != '>' {
+				t.Errorf("age = %q, want one from before the first read", age)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package platform
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// Android reports whether this is Android, built for it or built for Linux
+// and run in Termux. SELinux keeps apps out of /proc/stat, the socket
+// tables, the network statistics and other apps' processes there.
+var Android = sync.OnceValue(func() bool {
+	return runtime.GOOS == "android" || os.Getenv("ANDROID_ROOT") != "" || os.Getenv("TERMUX_VERSION") != ""
+})
+
+// denied reports whether err is Android refusing an app a /proc file,
+// which the contract takes as a figure the OS does not keep
+func denied(err error) bool {
+	return Android() && errors.Is(err, fs.ErrPermission)
+}
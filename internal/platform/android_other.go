@@ -0,0 +1,6 @@
+//go:build !linux
+
+package platform
+
+// Android is false: Android builds as Linux
+func Android() bool { return false }
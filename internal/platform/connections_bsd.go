@@ -0,0 +1,109 @@
+//go:build darwin || freebsd || openbsd
+
+package platform
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// netstatProtocols names the protocols of netstat's Proto column, tcp
+// alone being OpenBSD's IPv4. A socket open to both families, such as
+// tcp46, counts as IPv6, as on Linux.
+var netstatProtocols = map[string]string{
+	"tcp": "TCP", "tcp4": "TCP", "tcp6": "TCP6", "tcp46": "TCP6",
+	"udp": "UDP", "udp4": "UDP", "udp6": "UDP6", "udp46": "UDP6",
+}
+
+// netstatStates spells BSD TCP states as /proc/net/tcp's are named
+var netstatStates = map[string]string{
+	"SYN_RCVD": "SYN_RECV", "FIN_WAIT_1": "FIN_WAIT1", "FIN_WAIT_2": "FIN_WAIT2", "CLOSED": "CLOSE",
+}
+
+// Connections lists every TCP and UDP socket by running netstat, as the
+// BSDs only hand the socket table to privileged or private interfaces. An
+// error is only returned when neither protocol could be listed.
+func Connections() ([]Connection, error) {
+	// -W keeps long addresses whole, except on OpenBSD where it asks for
+	// wireless statistics and addresses are never cut
+	flags := "-anW"
+	if runtime.GOOS == "openbsd" {
+		flags = "-an"
+	}
+	var conns []Connection
+	var firstErr error
+	read := 0
+	for _, protocol := range []string{"tcp", "udp"} {
+		out, err := exec.Command("netstat", flags, "-p", protocol).Output()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		read++
+		conns = append(conns, parseNetstat(out)...)
+	}
+	if read == 0 {
+		return nil, firstErr
+	}
+	return conns, nil
+}
+
+// parseNetstat parses the lines of netstat -an:
+// "Proto Recv-Q Send-Q Local Foreign (state)"
+func parseNetstat(out []byte) []Connection {
+	var conns []Connection
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		protocol, ok := netstatProtocols[fields[0]]
+		if !ok {
+			continue // Headers
+		}
+		state := "UNCONN"
+		if strings.HasPrefix(protocol, "TCP") && len(fields) > 5 {
+			state = fields[5]
+			if s, ok := netstatStates[state]; ok {
+				state = s
+			}
+		} else if fields[4] != "*.*" {
+			state = "ESTABLISHED"
+		}
+		ipv6 := strings.HasSuffix(protocol, "6")
+		conns = append(conns, Connection{
+			Protocol:   protocol,
+			LocalAddr:  netstatAddr(fields[3], ipv6),
+			RemoteAddr: netstatAddr(fields[4], ipv6),
+			State:      state,
+		})
+	}
+	return conns
+}
+
+// netstatAddr turns netstat's "address.port", with * for any, into
+// host:port as decoded from /proc/net
+func netstatAddr(s string, ipv6 bool) string {
+	if s == "*.*" {
+		return "*:*"
+	}
+	i := strings.LastIndexByte(s, '.')
+	if i < 0 {
+		return s
+	}
+	host, port := s[:i], s[i+1:]
+	if host == "*" {
+		host = "0.0.0.0"
+		if ipv6 {
+			host = "::"
+		}
+	}
+	return net.JoinHostPort(host, port)
+}
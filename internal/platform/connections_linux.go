@@ -0,0 +1,169 @@
+package platform
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// tcpStates names the st column of /proc/net/tcp
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED", "02": "SYN_SENT", "03": "SYN_RECV", "04": "FIN_WAIT1",
+	"05": "FIN_WAIT2", "06": "TIME_WAIT", "07": "CLOSE", "08": "CLOSE_WAIT",
+	"09": "LAST_ACK", "0A": "LISTEN", "0B": "CLOSING",
+}
+
+// Connections reads every TCP and UDP socket of the current network
+// namespace, and of the namespaces of other processes, such as containers,
+// as far as their /proc entries are readable. Tables that are missing, such
+// as tcp6 with IPv6 disabled, are skipped; an error is only returned when
+// none could be read. Android 10 and later keep the tables from apps,
+// which then list no sockets. The TCP sockets of advis's own namespace
+// carry their byte counts where sock_diag answers.
+func Connections() ([]Connection, error) {
+	conns, err := readSocketTables("/proc/net/", netns{})
+	if err != nil {
+		return nil, err
+	}
+	if traffic, err := tcpTraffic(); err == nil {
+		for i, c := range conns {
+			if b, ok := traffic[tcpKey(c.Protocol, c.LocalAddr, c.RemoteAddr)]; ok {
+				conns[i].BytesSent, conns[i].BytesReceived, conns[i].HasBytes = b.sent, b.received, true
+			}
+		}
+	}
+	for _, ns := range otherNamespaces() {
+		// A process may exit while its tables are read
+		c, _ := readSocketTables(fmt.Sprintf("/proc/%d/net/", ns.pid), ns)
+		conns = append(conns, c...)
+	}
+	return conns, nil
+}
+
+// netns is a network namespace and a process in it
+type netns struct {
+	pid  int
+	id   string // The link of /proc/<pid>/ns/net
+	name string
+}
+
+// otherNamespaces finds the network namespaces besides advis's own, one
+// process each, named by the first process in them. Those of processes
+// of other users only show with root or CAP_SYS_PTRACE.
+func otherNamespaces() []netns {
+	own, err := os.Readlink("/proc/self/ns/net")
+	if err != nil {
+		return nil
+	}
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	seen := map[string]bool{own: true}
+	var found []netns
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		ns, err := os.Readlink("/proc/" + e.Name() + "/ns/net")
+		if err != nil || seen[ns] {
+			continue
+		}
+		seen[ns] = true
+		comm, _ := os.ReadFile("/proc/" + e.Name() + "/comm")
+		found = append(found, netns{pid: pid, id: ns, name: fmt.Sprintf("%s (%d)", strings.TrimSpace(string(comm)), pid)})
+	}
+	return found
+}
+
+// readSocketTables reads the TCP and UDP tables under dir, marking their
+// sockets as those of ns, the zero netns for advis's own
+func readSocketTables(dir string, ns netns) ([]Connection, error) {
+	var conns []Connection
+	var firstErr error
+	read := 0
+	for _, table := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		c, err := readSocketTable(dir+table, strings.ToUpper(table))
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		read++
+		for i := range c {
+			c[i].Namespace, c[i].NamespaceID = ns.name, ns.id
+		}
+		conns = append(conns, c...)
+	}
+	if read == 0 && denied(firstErr) {
+		return nil, nil
+	}
+	if read == 0 {
+		return nil, firstErr
+	}
+	return conns, nil
+}
+
+// readSocketTable parses one of /proc/net/{tcp,tcp6,udp,udp6}
+func readSocketTable(path, protocol string) ([]Connection, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var conns []Connection
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Header
+	for scanner.Scan() {
+		// "sl local_address rem_address st ..."
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		state := tcpStates[fields[3]]
+		if strings.HasPrefix(protocol, "UDP") {
+			// UDP sockets reuse the TCP codes: connected or not
+			state = "UNCONN"
+			if fields[3] == "01" {
+				state = "ESTABLISHED"
+			}
+		}
+		conns = append(conns, Connection{
+			Protocol:   protocol,
+			LocalAddr:  decodeAddr(fields[1]),
+			RemoteAddr: decodeAddr(fields[2]),
+			State:      state,
+		})
+	}
+	return conns, scanner.Err()
+}
+
+// decodeAddr turns the kernel's hex "address:port" into host:port. The
+// address is stored as 32-bit words in host byte order.
+func decodeAddr(s string) string {
+	addr, port, ok := strings.Cut(s, ":")
+	if !ok {
+		return s
+	}
+	raw, err := hex.DecodeString(addr)
+	if err != nil || len(raw)%4 != 0 {
+		return s
+	}
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(raw[i:], binary.LittleEndian.Uint32(raw[i:]))
+	}
+	p, _ := strconv.ParseUint(port, 16, 16)
+	ip := net.IP(raw)
+	if ip.IsUnspecified() && p == 0 {
+		return "*:*"
+	}
+	return net.JoinHostPort(ip.String(), strconv.FormatUint(p, 10))
+}
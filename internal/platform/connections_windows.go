@@ -0,0 +1,130 @@
+package platform
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// tcpStates names MIB_TCP_STATE as /proc/net/tcp's states are named
+var tcpStates = []string{
+	1: "CLOSE", 2: "LISTEN", 3: "SYN_SENT", 4: "SYN_RECV", 5: "ESTABLISHED",
+	6: "FIN_WAIT1", 7: "FIN_WAIT2", 8: "CLOSE_WAIT", 9: "CLOSING",
+	10: "LAST_ACK", 11: "TIME_WAIT", 12: "DELETE_TCB",
+}
+
+// Table classes of GetExtendedTcpTable and GetExtendedUdpTable
+const (
+	tcpTableOwnerPIDAll = 5
+	udpTableOwnerPID    = 1
+)
+
+// socketTable is one of the IP Helper socket tables: where a row keeps
+// its addresses, ports and state. Offsets of -1 are fields the table
+// lacks.
+type socketTable struct {
+	protocol   string
+	proc       *windows.LazyProc
+	family     uint32
+	class      uint32
+	rowSize    int
+	addrLen    int
+	local      int // Offsets in a row
+	localPort  int
+	remote     int
+	remotePort int
+	state      int
+}
+
+var socketTables = []socketTable{
+	// MIB_TCPROW_OWNER_PID: state, local address and port, remote address and port, pid
+	{"TCP", procGetExtendedTcpTable, windows.AF_INET, tcpTableOwnerPIDAll, 24, 4, 4, 8, 12, 16, 0},
+	// MIB_TCP6ROW_OWNER_PID: local address, scope and port, remote address, scope and port, state, pid
+	{"TCP6", procGetExtendedTcpTable, windows.AF_INET6, tcpTableOwnerPIDAll, 56, 16, 0, 20, 24, 44, 48},
+	// MIB_UDPROW_OWNER_PID: local address and port, pid
+	{"UDP", procGetExtendedUdpTable, windows.AF_INET, udpTableOwnerPID, 12, 4, 0, 4, -1, -1, -1},
+	// MIB_UDP6ROW_OWNER_PID: local address, scope and port, pid
+	{"UDP6", procGetExtendedUdpTable, windows.AF_INET6, udpTableOwnerPID, 28, 16, 0, 20, -1, -1, -1},
+}
+
+// Connections reads every TCP and UDP socket from the IP Helper tables.
+// Tables that cannot be read, such as IPv6 ones with the stack removed,
+// are skipped; an error is only returned when none could be read.
+func Connections() ([]Connection, error) {
+	var conns []Connection
+	var firstErr error
+	read := 0
+	for _, t := range socketTables {
+		c, err := t.read()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		read++
+		conns = append(conns, c...)
+	}
+	if read == 0 {
+		return nil, firstErr
+	}
+	return conns, nil
+}
+
+// read fetches the table, growing the buffer while sockets open between
+// asking its size and reading it
+func (t socketTable) read() ([]Connection, error) {
+	var size uint32
+	var buf []byte
+	for {
+		var p uintptr
+		if len(buf) > 0 {
+			p = uintptr(unsafe.Pointer(&buf[0]))
+		}
+		r, _, _ := t.proc.Call(p, uintptr(unsafe.Pointer(&size)), 0, uintptr(t.family), uintptr(t.class), 0)
+		if r == uintptr(windows.ERROR_INSUFFICIENT_BUFFER) {
+			buf = make([]byte, size)
+			continue
+		}
+		if r != 0 {
+			return nil, fmt.Errorf("%s: %w", t.proc.Name, windows.Errno(r))
+		}
+		break
+	}
+	if len(buf) < 4 {
+		return nil, nil
+	}
+	// dwNumEntries, then the rows
+	n := int(binary.LittleEndian.Uint32(buf))
+	conns := make([]Connection, 0, n)
+	for i := range n {
+		row := buf[4+i*t.rowSize:]
+		if len(row) < t.rowSize {
+			break
+		}
+		c := Connection{Protocol: t.protocol, LocalAddr: t.addr(row, t.local, t.localPort), RemoteAddr: "*:*", State: "UNCONN"}
+		if t.state >= 0 {
+			c.RemoteAddr = t.addr(row, t.remote, t.remotePort)
+			if s := binary.LittleEndian.Uint32(row[t.state:]); int(s) < len(tcpStates) {
+				c.State = tcpStates[s]
+			}
+		}
+		conns = append(conns, c)
+	}
+	return conns, nil
+}
+
+// addr formats the address at offset and the port at port of row as
+// host:port. Ports are kept in network byte order in a DWORD.
+func (t socketTable) addr(row []byte, offset, port int) string {
+	ip := net.IP(row[offset : offset+t.addrLen])
+	p := binary.BigEndian.Uint16(row[port:])
+	if ip.IsUnspecified() && p == 0 {
+		return "*:*"
+	}
+	return net.JoinHostPort(ip.String(), strconv.Itoa(int(p)))
+}
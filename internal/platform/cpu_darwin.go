@@ -0,0 +1,44 @@
+//go:build darwin && cgo
+
+package platform
+
+/*
+#include <mach/mach.h>
+#include <mach/processor_info.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"strconv"
+	"unsafe"
+)
+
+// CPUTimes reads the ticks of each CPU from host_processor_info
+func CPUTimes() (map[string]CPUTicks, error) {
+	var count C.natural_t
+	var info C.processor_info_array_t
+	var infoCount C.mach_msg_type_number_t
+	host := C.mach_host_self()
+	defer C.mach_port_deallocate(C.mach_task_self_, host)
+	if ret := C.host_processor_info(host, C.PROCESSOR_CPU_LOAD_INFO, &count, &info, &infoCount); ret != C.KERN_SUCCESS {
+		return nil, fmt.Errorf("host_processor_info: kern_return %d", ret)
+	}
+	defer C.vm_deallocate(C.mach_task_self_, C.vm_address_t(uintptr(unsafe.Pointer(info))),
+		C.vm_size_t(uintptr(infoCount)*unsafe.Sizeof(C.integer_t(0))))
+
+	loads := unsafe.Slice((*C.processor_cpu_load_info_data_t)(unsafe.Pointer(info)), int(count))
+	times := make(map[string]CPUTicks, len(loads)+1)
+	var all CPUTicks
+	for i, load := range loads {
+		ticks := load.cpu_ticks
+		var t CPUTicks
+		t.Busy = uint64(ticks[C.CPU_STATE_USER]) + uint64(ticks[C.CPU_STATE_SYSTEM]) + uint64(ticks[C.CPU_STATE_NICE])
+		t.Total = t.Busy + uint64(ticks[C.CPU_STATE_IDLE])
+		times["cpu"+strconv.Itoa(i)] = t
+		all.Busy += t.Busy
+		all.Total += t.Total
+	}
+	times["cpu"] = all
+	return times, nil
+}
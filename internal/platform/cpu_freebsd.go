@@ -0,0 +1,46 @@
+package platform
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// CPUTimes reads kern.cp_times: for each CPU the ticks spent in user,
+// nice, system, interrupt and idle, as longs
+func CPUTimes() (map[string]CPUTicks, error) {
+	data, err := unix.SysctlRaw("kern.cp_times")
+	if err != nil {
+		return nil, fmt.Errorf("sysctl kern.cp_times: %w", err)
+	}
+	const states = 5
+	size := 8
+	if strconv.IntSize == 32 {
+		size = 4
+	}
+	tick := func(i int) uint64 {
+		if size == 4 {
+			return uint64(binary.NativeEndian.Uint32(data[i*size:]))
+		}
+		return binary.NativeEndian.Uint64(data[i*size:])
+	}
+	times := make(map[string]CPUTicks)
+	var all CPUTicks
+	for cpu := range len(data) / (states * size) {
+		var t CPUTicks
+		for state := range states {
+			v := tick(cpu*states + state)
+			t.Total += v
+			if state != states-1 {
+				t.Busy += v
+			}
+		}
+		times["cpu"+strconv.Itoa(cpu)] = t
+		all.Busy += t.Busy
+		all.Total += t.Total
+	}
+	times["cpu"] = all
+	return times, nil
+}
@@ -0,0 +1,36 @@
+//go:build !linux && !windows && !freebsd && !openbsd && !(darwin && cgo) && gopsutil
+
+package platform
+
+import (
+	"strconv"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+)
+
+// ticksPerSecond scales gopsutil's CPU seconds back to ticks, as USER_HZ
+// does
+const ticksPerSecond = 100
+
+// CPUTimes reads the time of each CPU through gopsutil, which on macOS
+// without cgo loads host_processor_info at run time
+func CPUTimes() (map[string]CPUTicks, error) {
+	stats, err := cpu.Times(true)
+	if err != nil {
+		return nil, err
+	}
+	times := make(map[string]CPUTicks, len(stats)+1)
+	var all CPUTicks
+	for i, s := range stats {
+		busy := s.User + s.System + s.Nice + s.Irq + s.Softirq + s.Steal
+		t := CPUTicks{
+			Busy:  uint64(busy * ticksPerSecond),
+			Total: uint64((busy + s.Idle + s.Iowait) * ticksPerSecond),
+		}
+		times["cpu"+strconv.Itoa(i)] = t
+		all.Busy += t.Busy
+		all.Total += t.Total
+	}
+	times["cpu"] = all
+	return times, nil
+}
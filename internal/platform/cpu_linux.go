@@ -0,0 +1,42 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CPUTimes reads the jiffies of the cpu lines of /proc/stat, none on
+// Android 8 and later, which keeps it from apps
+func CPUTimes() (map[string]CPUTicks, error) {
+	data, err := os.ReadFile("/proc/stat")
+	if denied(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	times := make(map[string]CPUTicks)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		// user nice system idle iowait irq softirq steal; guest time is
+		// already included in user and nice
+		var t CPUTicks
+		for i, f := range fields[1:min(len(fields), 9)] {
+			v, err := strconv.ParseUint(f, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("/proc/stat %s: %w", fields[0], err)
+			}
+			t.Total += v
+			if i != 3 && i != 4 {
+				t.Busy += v
+			}
+		}
+		times[fields[0]] = t
+	}
+	return times, nil
+}
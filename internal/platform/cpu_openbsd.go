@@ -0,0 +1,44 @@
+package platform
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// CPUTimes reads kern.cp_time2 of each CPU: the ticks spent in user,
+// nice, system, spinning, interrupt and idle. CPUs taken offline answer
+// with an error and are left out.
+func CPUTimes() (map[string]CPUTicks, error) {
+	ncpu, err := sysctlUint("hw.ncpu")
+	if err != nil {
+		return nil, err
+	}
+	const states = 6
+	times := make(map[string]CPUTicks)
+	var all CPUTicks
+	for cpu := range int(ncpu) {
+		data, err := unix.SysctlRaw("kern.cp_time2", cpu)
+		if err != nil || len(data) < states*8 {
+			continue
+		}
+		var t CPUTicks
+		for state := range states {
+			v := binary.NativeEndian.Uint64(data[state*8:])
+			t.Total += v
+			if state != states-1 {
+				t.Busy += v
+			}
+		}
+		times["cpu"+strconv.Itoa(cpu)] = t
+		all.Busy += t.Busy
+		all.Total += t.Total
+	}
+	if len(times) == 0 {
+		return nil, fmt.Errorf("sysctl kern.cp_time2: no CPU answered")
+	}
+	times["cpu"] = all
+	return times, nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !windows && !freebsd && !openbsd && !(darwin && cgo) && !gopsutil
+
+package platform
+
+import "errors"
+
+// CPUTimes has no source here: macOS only tells CPU ticks through
+// host_processor_info, which needs cgo
+func CPUTimes() (map[string]CPUTicks, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,83 @@
+package platform
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	pdh                       = windows.NewLazySystemDLL("pdh.dll")
+	procPdhOpenQuery          = pdh.NewProc("PdhOpenQueryW")
+	procPdhAddEnglishCounter  = pdh.NewProc("PdhAddEnglishCounterW")
+	procPdhCollectQueryData   = pdh.NewProc("PdhCollectQueryData")
+	procPdhGetRawCounterArray = pdh.NewProc("PdhGetRawCounterArrayW")
+)
+
+// pdhMoreData is PDH_MORE_DATA, asking for a larger buffer
+const pdhMoreData = 0x800007D2
+
+// pdhRawCounterItem is PDH_RAW_COUNTER_ITEM_W: a counter instance and
+// its PDH_RAW_COUNTER
+type pdhRawCounterItem struct {
+	Name        *uint16
+	Status      uint32
+	TimeStamp   windows.Filetime
+	FirstValue  int64
+	SecondValue int64
+	MultiCount  uint32
+}
+
+// pdhQuery is a PDH query handle and the handle of its one counter
+type pdhQuery struct {
+	query, counter uintptr
+}
+
+// processorTime is the PDH query of the % Processor Time of every CPU,
+// opened on first use and kept for the life of the process
+var processorTime = sync.OnceValues(func() (q pdhQuery, err error) {
+	if r, _, _ := procPdhOpenQuery.Call(0, 0, uintptr(unsafe.Pointer(&q.query))); r != 0 {
+		return q, fmt.Errorf("PdhOpenQuery: status %#x", r)
+	}
+	path, _ := windows.UTF16PtrFromString(`\Processor(*)\% Processor Time`)
+	if r, _, _ := procPdhAddEnglishCounter.Call(q.query, uintptr(unsafe.Pointer(path)), 0, uintptr(unsafe.Pointer(&q.counter))); r != 0 {
+		return q, fmt.Errorf("PdhAddEnglishCounter: status %#x", r)
+	}
+	return q, nil
+})
+
+// CPUTimes reads the raw % Processor Time counters from PDH. Each is
+// the idle time of a CPU against a time base, both in 100ns units, and
+// the _Total instance sums them.
+func CPUTimes() (map[string]CPUTicks, error) {
+	q, err := processorTime()
+	if err != nil {
+		return nil, err
+	}
+	if r, _, _ := procPdhCollectQueryData.Call(q.query); r != 0 {
+		return nil, fmt.Errorf("PdhCollectQueryData: status %#x", r)
+	}
+	var size, count uint32
+	r, _, _ := procPdhGetRawCounterArray.Call(q.counter, uintptr(unsafe.Pointer(&size)), uintptr(unsafe.Pointer(&count)), 0)
+	if r != pdhMoreData {
+		return nil, fmt.Errorf("PdhGetRawCounterArray: status %#x", r)
+	}
+	// The instance names follow the items in the same buffer
+	items := make([]pdhRawCounterItem, uintptr(size)/unsafe.Sizeof(pdhRawCounterItem{})+1)
+	r, _, _ = procPdhGetRawCounterArray.Call(q.counter, uintptr(unsafe.Pointer(&size)), uintptr(unsafe.Pointer(&count)), uintptr(unsafe.Pointer(&items[0])))
+	if r != 0 {
+		return nil, fmt.Errorf("PdhGetRawCounterArray: status %#x", r)
+	}
+	times := make(map[string]CPUTicks, count)
+	for _, item := range items[:count] {
+		name := "cpu" + windows.UTF16PtrToString(item.Name)
+		if name == "cpu_Total" {
+			name = "cpu"
+		}
+		idle, base := uint64(item.FirstValue), uint64(item.SecondValue)
+		times[name] = CPUTicks{Busy: base - min(idle, base), Total: base}
+	}
+	return times, nil
+}
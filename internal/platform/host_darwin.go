@@ -0,0 +1,45 @@
+package platform
+
+import "errors"
+
+// availablePages are the vm page counts macOS can hand out without
+// swapping: free, speculatively read ahead, file-backed and purgeable,
+// as Activity Monitor reckons available memory
+var availablePages = []string{
+	"vm.page_free_count", "vm.page_speculative_count",
+	"vm.page_pageable_external_count", "vm.page_purgeable_count",
+}
+
+// Meminfo reads the installed memory and the pages available
+func Meminfo() (map[string]uint64, error) {
+	info := make(map[string]uint64)
+	total, err := sysctlUint("hw.memsize")
+	if err != nil {
+		return info, err
+	}
+	info["MemTotal"] = total
+	pageSize, err := sysctlUint("hw.pagesize")
+	if err != nil {
+		return info, err
+	}
+	var pages uint64
+	var errs []error
+	for _, name := range availablePages {
+		n, err := sysctlUint(name)
+		errs = append(errs, err)
+		pages += n
+	}
+	info["MemAvailable"] = min(pages*pageSize, total)
+	return info, errors.Join(errs...)
+}
+
+// FileHandles reads the open files and their limit from kern.num_files
+// and kern.maxfiles
+func FileHandles() (open, limit uint64, err error) {
+	open, openErr := sysctlUint("kern.num_files")
+	limit, limitErr := sysctlUint("kern.maxfiles")
+	return open, limit, errors.Join(openErr, limitErr)
+}
+
+// Entropy is unknown: macOS exposes no entropy pool
+func Entropy() (avail, pool uint64) { return 0, 0 }
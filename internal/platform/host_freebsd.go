@@ -0,0 +1,33 @@
+package platform
+
+import "errors"
+
+// Meminfo reads the physical memory and the free and inactive pages,
+// which the kernel hands out without swapping
+func Meminfo() (map[string]uint64, error) {
+	info := make(map[string]uint64)
+	total, err := sysctlUint("hw.physmem")
+	if err != nil {
+		return info, err
+	}
+	info["MemTotal"] = total
+	pageSize, err := sysctlUint("hw.pagesize")
+	if err != nil {
+		return info, err
+	}
+	free, freeErr := sysctlUint("vm.stats.vm.v_free_count")
+	inactive, inactiveErr := sysctlUint("vm.stats.vm.v_inactive_count")
+	info["MemAvailable"] = min((free+inactive)*pageSize, total)
+	return info, errors.Join(freeErr, inactiveErr)
+}
+
+// FileHandles reads the open files and their limit from kern.openfiles
+// and kern.maxfiles
+func FileHandles() (open, limit uint64, err error) {
+	open, openErr := sysctlUint("kern.openfiles")
+	limit, limitErr := sysctlUint("kern.maxfiles")
+	return open, limit, errors.Join(openErr, limitErr)
+}
+
+// Entropy is unknown: FreeBSD's Fortuna keeps no entropy count
+func Entropy() (avail, pool uint64) { return 0, 0 }
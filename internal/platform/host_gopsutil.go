@@ -0,0 +1,37 @@
+//go:build !linux && !darwin && !windows && !freebsd && !openbsd && gopsutil
+
+package platform
+
+import (
+	"errors"
+
+	"github.com/shirou/gopsutil/v4/load"
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// Meminfo reads the installed and available memory through gopsutil
+func Meminfo() (map[string]uint64, error) {
+	info := make(map[string]uint64)
+	vm, err := mem.VirtualMemory()
+	if err != nil {
+		return info, err
+	}
+	info["MemTotal"] = vm.Total
+	info["MemAvailable"] = vm.Available
+	return info, nil
+}
+
+// FileHandles has no reader in gopsutil
+func FileHandles() (open, limit uint64, err error) { return 0, 0, errors.ErrUnsupported }
+
+// LoadAverage reads the one-minute load average through gopsutil
+func LoadAverage() (float64, error) {
+	avg, err := load.Avg()
+	if err != nil {
+		return 0, err
+	}
+	return avg.Load1, nil
+}
+
+// Entropy is unknown: gopsutil tells no entropy pool
+func Entropy() (avail, pool uint64) { return 0, 0 }
@@ -0,0 +1,97 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Meminfo reads /proc/meminfo, reporting lines that did not parse
+func Meminfo() (map[string]uint64, error) {
+	info := make(map[string]uint64)
+	data, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return info, err
+	}
+	var errs []error
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		n, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("/proc/meminfo %s: %w", key, err))
+			continue
+		}
+		if len(fields) > 1 && fields[1] == "kB" {
+			n *= 1024
+		}
+		info[key] = n
+	}
+	return info, errors.Join(errs...)
+}
+
+// FileHandles reads /proc/sys/fs/file-nr ("allocated unused max"), which
+// Android keeps from apps
+func FileHandles() (open, limit uint64, err error) {
+	data, err := os.ReadFile("/proc/sys/fs/file-nr")
+	if denied(err) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return 0, 0, fmt.Errorf("/proc/sys/fs/file-nr: %d fields, want 3", len(fields))
+	}
+	open, openErr := strconv.ParseUint(fields[0], 10, 64)
+	limit, limitErr := strconv.ParseUint(fields[2], 10, 64)
+	if err := errors.Join(openErr, limitErr); err != nil {
+		return open, limit, fmt.Errorf("/proc/sys/fs/file-nr: %w", err)
+	}
+	return open, limit, nil
+}
+
+// LoadAverage reads /proc/loadavg, which some Android versions keep from
+// apps
+func LoadAverage() (float64, error) {
+	data, err := os.ReadFile("/proc/loadavg")
+	if denied(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("/proc/loadavg is empty")
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("/proc/loadavg: %w", err)
+	}
+	return load, nil
+}
+
+// Entropy reads the available bits and size of the kernel entropy pool
+func Entropy() (avail, pool uint64) {
+	return readUint("/proc/sys/kernel/random/entropy_avail"), readUint("/proc/sys/kernel/random/poolsize")
+}
+
+// readUint reads a file holding a single number, returning 0 for errors
+func readUint(path string) uint64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	return n
+}
@@ -0,0 +1,43 @@
+package platform
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// Meminfo reads the physical memory and, from the struct uvmexp of
+// vm.uvmexp, the free and inactive pages the kernel hands out without
+// swapping
+func Meminfo() (map[string]uint64, error) {
+	info := make(map[string]uint64)
+	total, err := sysctlUint("hw.physmem")
+	if err != nil {
+		return info, err
+	}
+	info["MemTotal"] = total
+	data, err := unix.SysctlRaw("vm.uvmexp")
+	if err != nil {
+		return info, fmt.Errorf("sysctl vm.uvmexp: %w", err)
+	}
+	// pagesize, pagemask, pageshift, npages, free, active, inactive: ints
+	if len(data) < 28 {
+		return info, fmt.Errorf("sysctl vm.uvmexp: %d bytes, want 28 or more", len(data))
+	}
+	field := func(i int) uint64 { return uint64(binary.NativeEndian.Uint32(data[i*4:])) }
+	info["MemAvailable"] = min((field(4)+field(6))*field(0), total)
+	return info, nil
+}
+
+// FileHandles reads the open files and their limit from kern.nfiles and
+// kern.maxfiles
+func FileHandles() (open, limit uint64, err error) {
+	open, openErr := sysctlUint("kern.nfiles")
+	limit, limitErr := sysctlUint("kern.maxfiles")
+	return open, limit, errors.Join(openErr, limitErr)
+}
+
+// Entropy is unknown: OpenBSD's arc4random keeps no entropy count
+func Entropy() (avail, pool uint64) { return 0, 0 }
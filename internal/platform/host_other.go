@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !windows && !freebsd && !openbsd && !gopsutil
+
+package platform
+
+import "errors"
+
+// Meminfo has no reader on this OS yet; the runtime's own figures stand
+// in for memory
+func Meminfo() (map[string]uint64, error) {
+	return make(map[string]uint64), errors.ErrUnsupported
+}
+
+// FileHandles has no reader on this OS yet
+func FileHandles() (open, limit uint64, err error) { return 0, 0, errors.ErrUnsupported }
+
+// LoadAverage has no reader on this OS yet
+func LoadAverage() (float64, error) { return 0, errors.ErrUnsupported }
+
+// Entropy is unknown on this OS
+func Entropy() (avail, pool uint64) { return 0, 0 }
@@ -0,0 +1,48 @@
+package platform
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	kernel32                 = windows.NewLazySystemDLL("kernel32.dll")
+	procGlobalMemoryStatusEx = kernel32.NewProc("GlobalMemoryStatusEx")
+)
+
+// memoryStatusEx is MEMORYSTATUSEX
+type memoryStatusEx struct {
+	Length               uint32
+	MemoryLoad           uint32
+	TotalPhys            uint64
+	AvailPhys            uint64
+	TotalPageFile        uint64
+	AvailPageFile        uint64
+	TotalVirtual         uint64
+	AvailVirtual         uint64
+	AvailExtendedVirtual uint64
+}
+
+// Meminfo reads the physical memory from GlobalMemoryStatusEx
+func Meminfo() (map[string]uint64, error) {
+	info := make(map[string]uint64)
+	status := memoryStatusEx{Length: uint32(unsafe.Sizeof(memoryStatusEx{}))}
+	if r, _, err := procGlobalMemoryStatusEx.Call(uintptr(unsafe.Pointer(&status))); r == 0 {
+		return info, fmt.Errorf("GlobalMemoryStatusEx: %w", err)
+	}
+	info["MemTotal"] = status.TotalPhys
+	info["MemAvailable"] = status.AvailPhys
+	return info, nil
+}
+
+// FileHandles is unknown: Windows sets no system-wide handle limit to
+// watch the handles against
+func FileHandles() (open, limit uint64, err error) { return 0, 0, nil }
+
+// LoadAverage is 0: Windows keeps no load average
+func LoadAverage() (float64, error) { return 0, nil }
+
+// Entropy is unknown: Windows exposes no entropy pool
+func Entropy() (avail, pool uint64) { return 0, 0 }
@@ -0,0 +1,51 @@
+//go:build freebsd || openbsd
+
+package platform
+
+import (
+	"encoding/binary"
+	"net"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Interfaces reads the counters of every interface from the RTM_IFINFO
+// messages of the routing sysctl, as netstat -i does
+func Interfaces() []Counter {
+	buf, err := unix.SysctlRaw("net.route", 0, 0, unix.NET_RT_IFLIST, 0)
+	if err != nil {
+		return nil
+	}
+	names := make(map[int]string)
+	if ifaces, err := net.Interfaces(); err == nil {
+		for _, iface := range ifaces {
+			names[iface.Index] = iface.Name
+		}
+	}
+	var counters []Counter
+	for len(buf) >= 4 {
+		size := int(binary.NativeEndian.Uint16(buf)) // rt_msghdr's msglen
+		if size < 4 || size > len(buf) {
+			break
+		}
+		if buf[3] == unix.RTM_IFINFO && size >= unix.SizeofIfMsghdr {
+			// Copy out rather than cast, as messages are not 8-byte aligned
+			var msg unix.IfMsghdr
+			copy(unsafe.Slice((*byte)(unsafe.Pointer(&msg)), unix.SizeofIfMsghdr), buf)
+			if name, ok := names[int(msg.Index)]; ok {
+				counters = append(counters, Counter{
+					Name:      name,
+					RxBytes:   uint64(msg.Data.Ibytes),
+					RxPackets: uint64(msg.Data.Ipackets),
+					RxErrors:  uint64(msg.Data.Ierrors),
+					TxBytes:   uint64(msg.Data.Obytes),
+					TxPackets: uint64(msg.Data.Opackets),
+					TxErrors:  uint64(msg.Data.Oerrors),
+				})
+			}
+		}
+		buf = buf[size:]
+	}
+	return counters
+}
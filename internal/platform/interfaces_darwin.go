@@ -0,0 +1,49 @@
+package platform
+
+import (
+	"encoding/binary"
+	"net"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Interfaces reads the counters of every interface from the RTM_IFINFO2
+// messages of the routing sysctl, which carry 64-bit counters
+func Interfaces() []Counter {
+	buf, err := unix.SysctlRaw("net.route", 0, 0, unix.NET_RT_IFLIST2, 0)
+	if err != nil {
+		return nil
+	}
+	names := make(map[int]string)
+	if ifaces, err := net.Interfaces(); err == nil {
+		for _, iface := range ifaces {
+			names[iface.Index] = iface.Name
+		}
+	}
+	var counters []Counter
+	for len(buf) >= 4 {
+		size := int(binary.NativeEndian.Uint16(buf)) // rt_msghdr's msglen
+		if size < 4 || size > len(buf) {
+			break
+		}
+		if buf[3] == unix.RTM_IFINFO2 && size >= unix.SizeofIfMsghdr2 {
+			// Copy out rather than cast, as messages are not 8-byte aligned
+			var msg unix.IfMsghdr2
+			copy(unsafe.Slice((*byte)(unsafe.Pointer(&msg)), unix.SizeofIfMsghdr2), buf)
+			if name, ok := names[int(msg.Index)]; ok {
+				counters = append(counters, Counter{
+					Name:      name,
+					RxBytes:   msg.Data.Ibytes,
+					RxPackets: msg.Data.Ipackets,
+					RxErrors:  msg.Data.Ierrors,
+					TxBytes:   msg.Data.Obytes,
+					TxPackets: msg.Data.Opackets,
+					TxErrors:  msg.Data.Oerrors,
+				})
+			}
+		}
+		buf = buf[size:]
+	}
+	return counters
+}
@@ -0,0 +1,99 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Interfaces reads the counters of every interface from /proc/net/dev.
+// Android 10 and later keep it from apps, which then read the statistics
+// of /sys/class/net, or the xt_qtaguid table of older Android versions.
+func Interfaces() []Counter {
+	data, err := os.ReadFile("/proc/net/dev")
+	if denied(err) {
+		if counters := sysfsInterfaces(); counters != nil {
+			return counters
+		}
+		return qtaguidInterfaces()
+	}
+	if err != nil {
+		return nil
+	}
+	var counters []Counter
+	for _, line := range strings.Split(string(data), "\n") {
+		name, rest, ok := strings.Cut(line, ":")
+		fields := strings.Fields(rest)
+		if !ok || len(fields) < 11 {
+			continue // Header lines
+		}
+		value := func(i int) uint64 {
+			n, _ := strconv.ParseUint(fields[i], 10, 64)
+			return n
+		}
+		counters = append(counters, Counter{
+			Name:      strings.TrimSpace(name),
+			RxBytes:   value(0),
+			RxPackets: value(1),
+			RxErrors:  value(2),
+			TxBytes:   value(8),
+			TxPackets: value(9),
+			TxErrors:  value(10),
+		})
+	}
+	return counters
+}
+
+// sysfsInterfaces reads the counters of every interface from
+// /sys/class/net/<name>/statistics, nil when none is readable
+func sysfsInterfaces() []Counter {
+	dirs, _ := filepath.Glob("/sys/class/net/*/statistics")
+	var counters []Counter
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, "rx_bytes")); err != nil {
+			continue
+		}
+		value := func(name string) uint64 { return readUint(filepath.Join(dir, name)) }
+		counters = append(counters, Counter{
+			Name:      filepath.Base(filepath.Dir(dir)),
+			RxBytes:   value("rx_bytes"),
+			RxPackets: value("rx_packets"),
+			RxErrors:  value("rx_errors"),
+			TxBytes:   value("tx_bytes"),
+			TxPackets: value("tx_packets"),
+			TxErrors:  value("tx_errors"),
+		})
+	}
+	return counters
+}
+
+// qtaguidInterfaces reads the counters of every interface from the
+// iface_stat_fmt table of the xt_qtaguid module of Android 9 and earlier:
+// "ifname total_skb_rx_bytes total_skb_rx_packets total_skb_tx_bytes
+// total_skb_tx_packets ...". It counts no errors.
+func qtaguidInterfaces() []Counter {
+	data, err := os.ReadFile("/proc/net/xt_qtaguid/iface_stat_fmt")
+	if err != nil {
+		return nil
+	}
+	var counters []Counter
+	for _, line := range strings.Split(string(data), "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		value := func(i int) uint64 {
+			n, _ := strconv.ParseUint(fields[i], 10, 64)
+			return n
+		}
+		counters = append(counters, Counter{
+			Name:      fields[0],
+			RxBytes:   value(1),
+			RxPackets: value(2),
+			TxBytes:   value(3),
+			TxPackets: value(4),
+		})
+	}
+	return counters
+}
@@ -0,0 +1,53 @@
+package platform
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	iphlpapi                = windows.NewLazySystemDLL("iphlpapi.dll")
+	procGetIfTable2         = iphlpapi.NewProc("GetIfTable2")
+	procFreeMibTable        = iphlpapi.NewProc("FreeMibTable")
+	procGetExtendedTcpTable = iphlpapi.NewProc("GetExtendedTcpTable")
+	procGetExtendedUdpTable = iphlpapi.NewProc("GetExtendedUdpTable")
+)
+
+// mibIfTable2 is MIB_IF_TABLE2, its rows following the count
+type mibIfTable2 struct {
+	NumEntries uint32
+	Table      [1]windows.MibIfRow2
+}
+
+// filterInterface is the FilterInterface bit of a row's flags. Windows
+// lists the packet filters stacked on an adapter as interfaces of their
+// own, repeating its traffic.
+const filterInterface = 0x02
+
+// Interfaces reads the counters of every interface from GetIfTable2,
+// named by their alias such as "Ethernet" or "Wi-Fi"
+func Interfaces() []Counter {
+	var table *mibIfTable2
+	if r, _, _ := procGetIfTable2.Call(uintptr(unsafe.Pointer(&table))); r != 0 {
+		return nil
+	}
+	defer procFreeMibTable.Call(uintptr(unsafe.Pointer(table)))
+
+	var counters []Counter
+	for _, row := range unsafe.Slice(&table.Table[0], table.NumEntries) {
+		if row.InterfaceAndOperStatusFlags&filterInterface != 0 {
+			continue
+		}
+		counters = append(counters, Counter{
+			Name:      windows.UTF16ToString(row.Alias[:]),
+			RxBytes:   row.InOctets,
+			RxPackets: row.InUcastPkts + row.InNUcastPkts,
+			RxErrors:  row.InErrors,
+			TxBytes:   row.OutOctets,
+			TxPackets: row.OutUcastPkts + row.OutNUcastPkts,
+			TxErrors:  row.OutErrors,
+		})
+	}
+	return counters
+}
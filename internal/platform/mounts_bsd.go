@@ -0,0 +1,15 @@
+//go:build darwin || freebsd || openbsd
+
+package platform
+
+import "golang.org/x/sys/unix"
+
+// statfsKey is what getfsstat tells a filesystem by. Two mounts share one
+// only when they show the same filesystem, as a remount at a second path
+// does; ZFS datasets and APFS volumes of one pool differ in it. OpenBSD
+// zeroes the fsid for unprivileged users, which the sizes make up for.
+type statfsKey struct {
+	device        string
+	fsid          unix.Fsid
+	blocks, files uint64
+}
@@ -0,0 +1,35 @@
+package platform
+
+import "golang.org/x/sys/unix"
+
+// pseudoFilesystems lists filesystem types that never hold user data
+var pseudoFilesystems = map[string]bool{
+	"autofs": true, "devfs": true, "fdescfs": true, "linprocfs": true,
+	"linsysfs": true, "mqueuefs": true, "nullfs": true, "procfs": true,
+	"tmpfs": true,
+}
+
+// Mounts lists the real filesystems getfsstat knows
+func Mounts() ([]Mount, error) {
+	n, err := unix.Getfsstat(nil, unix.MNT_NOWAIT)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]unix.Statfs_t, n)
+	if n, err = unix.Getfsstat(stats, unix.MNT_NOWAIT); err != nil {
+		return nil, err
+	}
+	var mounts []Mount
+	seen := make(map[statfsKey]bool)
+	for _, st := range stats[:n] {
+		device := unix.ByteSliceToString(st.Mntfromname[:])
+		fsType := unix.ByteSliceToString(st.Fstypename[:])
+		key := statfsKey{device, st.Fsid, st.Blocks, st.Files}
+		if pseudoFilesystems[fsType] || seen[key] {
+			continue
+		}
+		seen[key] = true
+		mounts = append(mounts, Mount{Path: unix.ByteSliceToString(st.Mntonname[:]), Device: device, FSType: fsType})
+	}
+	return mounts, nil
+}
@@ -0,0 +1,28 @@
+//go:build !linux && !darwin && !windows && !freebsd && !openbsd && gopsutil
+
+package platform
+
+import "github.com/shirou/gopsutil/v4/disk"
+
+// Mounts reads the physical filesystems gopsutil finds
+func Mounts() ([]Mount, error) {
+	parts, err := disk.Partitions(false)
+	if err != nil {
+		return nil, err
+	}
+	mounts := make([]Mount, 0, len(parts))
+	for _, p := range parts {
+		mounts = append(mounts, Mount{Path: p.Mountpoint, Device: p.Device, FSType: p.Fstype})
+	}
+	return mounts, nil
+}
+
+// DiskUsage reads the usage of the filesystem holding path through
+// gopsutil
+func DiskUsage(path string) (Usage, error) {
+	u, err := disk.Usage(path)
+	if err != nil {
+		return Usage{}, err
+	}
+	return Usage{Total: u.Total, Free: u.Free}, nil
+}
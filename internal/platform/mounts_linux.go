@@ -0,0 +1,99 @@
+package platform
+
+import (
+	"bufio"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pseudoFilesystems lists filesystem types that never hold user data and
+// are hidden from the mount table
+var pseudoFilesystems = map[string]bool{
+	"autofs": true, "binfmt_misc": true, "bpf": true, "cgroup": true,
+	"cgroup2": true, "configfs": true, "debugfs": true, "devpts": true,
+	"devtmpfs": true, "efivarfs": true, "fusectl": true, "hugetlbfs": true,
+	"mqueue": true, "nsfs": true, "proc": true, "pstore": true,
+	"ramfs": true, "rpc_pipefs": true, "securityfs": true, "squashfs": true,
+	"sysfs": true, "tmpfs": true, "tracefs": true, "overlay": true,
+	"fuse.gvfsd-fuse": true, "fuse.portal": true,
+}
+
+// Mounts reads the real filesystems of /proc/self/mountinfo. Only bind
+// mounts are left out: those of a device and subtree already listed. The
+// btrfs subvolumes and ZFS datasets of one device mounted apart are each
+// their own row.
+func Mounts() ([]Mount, error) {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return procMounts()
+	}
+	defer file.Close()
+
+	var mounts []Mount
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// ID, parent ID, major:minor, root, mount point, options, optional
+		// fields up to "-", then type, source and superblock options
+		fields := strings.Fields(scanner.Text())
+		sep := slices.Index(fields, "-")
+		if sep < 5 || sep+2 >= len(fields) {
+			continue
+		}
+		fsType, device := fields[sep+1], fields[sep+2]
+		tree := fields[2] + " " + fields[3]
+		if pseudoFilesystems[fsType] || seen[tree] {
+			continue
+		}
+		seen[tree] = true
+		mounts = append(mounts, Mount{Path: unescapeMountPath(fields[4]), Device: device, FSType: fsType})
+	}
+	return mounts, nil
+}
+
+// procMounts reads the real filesystems of /proc/mounts, for kernels
+// without mountinfo. It cannot tell a bind mount from a subvolume, so
+// keeps both.
+func procMounts() ([]Mount, error) {
+	file, err := os.Open("/proc/mounts")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var mounts []Mount
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		device, path, fsType := fields[0], unescapeMountPath(fields[1]), fields[2]
+		if pseudoFilesystems[fsType] {
+			continue
+		}
+		mounts = append(mounts, Mount{Path: path, Device: device, FSType: fsType})
+	}
+	return mounts, nil
+}
+
+// unescapeMountPath decodes the octal escapes (\040 for space etc.) used in /proc/mounts and mountinfo
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, "\\") {
+		return path
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) {
+			if v, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package platform
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// pseudoFilesystems lists filesystem types that never hold user data
+var pseudoFilesystems = map[string]bool{"fdesc": true, "kernfs": true, "mfs": true, "procfs": true, "tmpfs": true}
+
+// Mounts lists the real filesystems getfsstat knows
+func Mounts() ([]Mount, error) {
+	n, err := unix.Getfsstat(nil, unix.MNT_NOWAIT)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]unix.Statfs_t, n)
+	if n, err = unix.Getfsstat(stats, unix.MNT_NOWAIT); err != nil {
+		return nil, err
+	}
+	var mounts []Mount
+	seen := make(map[statfsKey]bool)
+	for _, st := range stats[:n] {
+		device := unix.ByteSliceToString(st.F_mntfromname[:])
+		fsType := unix.ByteSliceToString(st.F_fstypename[:])
+		key := statfsKey{device, st.F_fsid, st.F_blocks, st.F_files}
+		if pseudoFilesystems[fsType] || seen[key] {
+			continue
+		}
+		seen[key] = true
+		mounts = append(mounts, Mount{Path: unix.ByteSliceToString(st.F_mntonname[:]), Device: device, FSType: fsType})
+	}
+	return mounts, nil
+}
+
+// DiskUsage reads the usage of the filesystem holding path with statfs
+func DiskUsage(path string) (Usage, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return Usage{}, &os.PathError{Op: "statfs", Path: path, Err: err}
+	}
+	free := uint64(max(stat.F_bavail, 0)) * uint64(stat.F_bsize)
+	return Usage{Total: stat.F_blocks * uint64(stat.F_bsize), Free: free}, nil
+}
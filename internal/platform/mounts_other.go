@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !windows && !freebsd && !openbsd && !gopsutil
+
+package platform
+
+import "errors"
+
+// Mounts has no reader on this OS yet, leaving the root filesystem
+func Mounts() ([]Mount, error) { return nil, errors.ErrUnsupported }
+
+// DiskUsage has no reader on this OS yet
+func DiskUsage(path string) (Usage, error) { return Usage{}, errors.ErrUnsupported }
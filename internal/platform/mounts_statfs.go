@@ -0,0 +1,21 @@
+//go:build linux || darwin || freebsd
+
+package platform
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// DiskUsage reads the usage of the filesystem holding path with statfs
+func DiskUsage(path string) (Usage, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return Usage{}, &os.PathError{Op: "statfs", Path: path, Err: err}
+	}
+	// The field types differ by OS, and Bavail goes negative on FreeBSD
+	// once the reserve is dipped into
+	free := uint64(max(int64(stat.Bavail), 0)) * uint64(stat.Bsize)
+	return Usage{Total: uint64(stat.Blocks) * uint64(stat.Bsize), Free: free}, nil
+}
@@ -0,0 +1,61 @@
+package platform
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// Mounts lists the drive letters holding a filesystem. Optical drives, which stall until a disc
+// spins up, and removable drives without media are left out.
+func Mounts() ([]Mount, error) {
+	var buf [512]uint16
+	n, err := windows.GetLogicalDriveStrings(uint32(len(buf)), &buf[0])
+	if err != nil {
+		return nil, err
+	}
+	var mounts []Mount
+	for _, root := range strings.Split(windows.UTF16ToString(buf[:n]), "\x00") {
+		if root == "" {
+			continue
+		}
+		p, err := windows.UTF16PtrFromString(root)
+		if err != nil {
+			continue
+		}
+		m := Mount{Path: root, Device: root}
+		switch windows.GetDriveType(p) {
+		case windows.DRIVE_FIXED, windows.DRIVE_REMOVABLE, windows.DRIVE_RAMDISK:
+			var label, fsType [windows.MAX_PATH + 1]uint16
+			if err := windows.GetVolumeInformation(p, &label[0], uint32(len(label)), nil, nil, nil, &fsType[0], uint32(len(fsType))); err != nil {
+				continue
+			}
+			if name := windows.UTF16ToString(label[:]); name != "" {
+				m.Device = name
+			}
+			m.FSType = windows.UTF16ToString(fsType[:])
+		case windows.DRIVE_REMOTE:
+			// Asking the volume of a share blocks like its usage would
+			m.FSType = "remote"
+		default:
+			continue
+		}
+		mounts = append(mounts, m)
+	}
+	return mounts, nil
+}
+
+// DiskUsage reads the usage of the volume holding path, as the user may
+// use it under their quota
+func DiskUsage(path string) (Usage, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return Usage{}, err
+	}
+	var free, total uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &free, &total, nil); err != nil {
+		return Usage{}, &os.PathError{Op: "GetDiskFreeSpaceEx", Path: path, Err: err}
+	}
+	return Usage{Total: total, Free: free}, nil
+}
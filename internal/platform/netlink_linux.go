@@ -0,0 +1,16 @@
+package platform
+
+import "syscall"
+
+// netlinkSockDiag is NETLINK_SOCK_DIAG, which the syscall package lacks
+const netlinkSockDiag = 4
+
+// Netlink reports whether a sock_diag netlink socket opens
+func Netlink() bool {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, netlinkSockDiag)
+	if err != nil {
+		return false
+	}
+	syscall.Close(fd)
+	return true
+}
@@ -0,0 +1,6 @@
+//go:build !linux
+
+package platform
+
+// Netlink is false where there is no netlink
+func Netlink() bool { return false }
@@ -0,0 +1,79 @@
+//go:build !linux && !darwin && !windows && !freebsd && !openbsd && gopsutil
+
+package platform
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	gonet "github.com/shirou/gopsutil/v4/net"
+)
+
+// Interfaces reads the counters of every interface through gopsutil
+func Interfaces() []Counter {
+	stats, err := gonet.IOCounters(true)
+	if err != nil {
+		return nil
+	}
+	counters := make([]Counter, 0, len(stats))
+	for _, s := range stats {
+		counters = append(counters, Counter{
+			Name:      s.Name,
+			RxBytes:   s.BytesRecv,
+			TxBytes:   s.BytesSent,
+			RxPackets: s.PacketsRecv,
+			TxPackets: s.PacketsSent,
+			RxErrors:  s.Errin,
+			TxErrors:  s.Errout,
+		})
+	}
+	return counters
+}
+
+// Connections lists every TCP and UDP socket gopsutil finds. gopsutil
+// already names TCP states as /proc/net/tcp does.
+func Connections() ([]Connection, error) {
+	var conns []Connection
+	var errs []error
+	for _, kind := range []string{"tcp", "udp"} {
+		stats, err := gonet.Connections(kind)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, s := range stats {
+			protocol := strings.ToUpper(kind)
+			if strings.Contains(s.Laddr.IP, ":") {
+				protocol += "6"
+			}
+			state := s.Status
+			if kind == "udp" {
+				state = "UNCONN"
+				if s.Raddr.Port != 0 {
+					state = "ESTABLISHED"
+				}
+			}
+			conns = append(conns, Connection{
+				Protocol:   protocol,
+				LocalAddr:  gopsutilAddr(s.Laddr),
+				RemoteAddr: gopsutilAddr(s.Raddr),
+				State:      state,
+			})
+		}
+	}
+	if len(errs) == 2 {
+		return nil, errors.Join(errs...)
+	}
+	return conns, nil
+}
+
+// gopsutilAddr writes an address as host:port, "*:*" for none, as
+// decoded from /proc/net
+func gopsutilAddr(a gonet.Addr) string {
+	if ip := net.ParseIP(a.IP); a.Port == 0 && (ip == nil || ip.IsUnspecified()) {
+		return "*:*"
+	}
+	return net.JoinHostPort(a.IP, strconv.FormatUint(uint64(a.Port), 10))
+}
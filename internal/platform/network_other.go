@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !windows && !freebsd && !openbsd && !gopsutil
+
+package platform
+
+import "errors"
+
+// Interfaces has no reader on this OS yet
+func Interfaces() []Counter { return nil }
+
+// Connections has no reader on this OS yet
+func Connections() ([]Connection, error) { return nil, errors.ErrUnsupported }
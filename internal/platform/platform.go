@@ -0,0 +1,142 @@
+// Package platform holds what advis reads from the OS for its snapshots,
+// one file per OS and source selected by build tags, behind the same
+// functions on every OS. The collectors of pkg/sysstat, pkg/netstat and
+// pkg/proc build their snapshots from these alone, so porting the panels
+// every OS shows is adding files here and nothing else.
+//
+// The Linux-only views of internal/sysmon still read /proc, /sys and
+// /dev/kmsg themselves: the NUMA nodes, hugepages, interrupts, cgroups,
+// kernel limits, storage arrays, SoC sensors and the kernel log. On other
+// OSes those files are missing and the views stay empty.
+//
+// The contract every port keeps:
+//
+//   - A figure the OS does not keep, such as the load average on Windows,
+//     is zero with a nil error, and the panel showing it stays blank.
+//     So is one Android keeps from apps, such as /proc/stat; caps tells
+//     the panels which those are.
+//   - A figure the OS keeps but that could not be read returns an error
+//     naming what failed, which the error log shows.
+//   - A source with no reader on the OS yet returns errors.ErrUnsupported,
+//     as the _other files do, and the collectors fall back to what the Go
+//     runtime tells.
+//   - Cumulative counters are returned as the kernel keeps them; turning
+//     them into rates between reads is left to the samplers.
+//   - Nothing here caches across calls except to spare slow lookups, such
+//     as account names, and every function is safe for concurrent use.
+//
+// Where the fields of the snapshots come from:
+//
+//   - sysstat.Info: Meminfo, LoadAverage, FileHandles and Entropy
+//   - sysstat.CPUSampler: CPUTimes
+//   - sysstat.Disk: Mounts, then DiskUsage for each mount
+//   - netstat interface rates: Interfaces
+//   - netstat connections: Connections
+//   - proc.Process: Processes
+//
+// And for the TUI itself: StatOf for the directory scan and the kernel
+// limits, SelfCPUTime and SelfRSS for advis's own budget, and Netlink and
+// CanConnect for the capability probe.
+//
+// Building with -tags gopsutil fills the OSes with no reader of their own,
+// such as NetBSD, illumos or macOS without cgo for the CPU times, with the
+// host, CPU, filesystem and network figures of gopsutil. Native readers
+// always win where they exist; processes stay unsupported.
+package platform
+
+import "time"
+
+// CPUTicks is the busy and total ticks of one CPU. CPUTimes keys them
+// "cpu" for all CPUs together and "cpuN" for CPU N.
+type CPUTicks struct {
+	Busy  uint64
+	Total uint64
+}
+
+// Mount is one real filesystem of the mount table
+type Mount struct {
+	Path   string
+	Device string
+	FSType string
+}
+
+// Usage is the size of a filesystem and the bytes left to unprivileged
+// users
+type Usage struct {
+	Total uint64
+	Free  uint64
+}
+
+// Counter is the cumulative traffic of a network interface
+type Counter struct {
+	Name      string `json:"name"`
+	RxBytes   uint64 `json:"rx_bytes"`
+	TxBytes   uint64 `json:"tx_bytes"`
+	RxPackets uint64 `json:"rx_packets"`
+	TxPackets uint64 `json:"tx_packets"`
+	RxErrors  uint64 `json:"rx_errors"`
+	TxErrors  uint64 `json:"tx_errors"`
+}
+
+// Protocols are the kernel's cumulative ICMP, TCP and UDP counters, as
+// "netstat -s" prints them
+type Protocols struct {
+	ICMPInDestUnreachs  uint64 `json:"icmp_in_dest_unreachs"`
+	ICMPOutDestUnreachs uint64 `json:"icmp_out_dest_unreachs"`
+	ICMPInErrors        uint64 `json:"icmp_in_errors"`
+	TCPActiveOpens      uint64 `json:"tcp_active_opens"`
+	TCPPassiveOpens     uint64 `json:"tcp_passive_opens"`
+	TCPAttemptFails     uint64 `json:"tcp_attempt_fails"`
+	TCPEstabResets      uint64 `json:"tcp_estab_resets"`
+	TCPOutRsts          uint64 `json:"tcp_out_rsts"`
+	TCPRetransSegs      uint64 `json:"tcp_retrans_segs"`
+	TCPInErrs           uint64 `json:"tcp_in_errs"`
+	UDPNoPorts          uint64 `json:"udp_no_ports"`
+	UDPInErrors         uint64 `json:"udp_in_errors"`
+}
+
+// Connection is one socket from the kernel's TCP or UDP table
+type Connection struct {
+	Protocol    string // "TCP", "TCP6", "UDP" or "UDP6"
+	LocalAddr   string
+	RemoteAddr  string
+	State       string
+	Namespace   string // Network namespace other than advis's own, named by a process in it
+	NamespaceID string // The namespace's inode, such as "net:[4026532281]", which outlasts the process naming it
+
+	// Bytes the peer acknowledged and bytes received, cumulative; read
+	// for TCP sockets of advis's own namespace on Linux only
+	BytesSent     uint64
+	BytesReceived uint64
+	HasBytes      bool // The byte counts were read
+}
+
+// Process is one process as the kernel tells it, with its cumulative
+// counters as of At
+type Process struct {
+	PID        int
+	PPID       int
+	Name       string
+	Cmdline    string // Arguments joined by spaces, empty for kernel threads
+	State      string
+	Memory     uint64 // Resident set size in bytes
+	UID        uint32
+	User       string        // Empty where the UID names the owner
+	FDs        int           // Open file descriptors, -1 when not readable
+	FDLimit    uint64        // Soft RLIMIT_NOFILE, 0 when unknown or unlimited
+	OOMScore   int           // Kernel badness score, higher is killed first; Linux only
+	OOMAdj     int           // oom_score_adj, -1000 (never) to 1000; Linux only
+	HasIO      bool          // The I/O counters were readable
+	ReadBytes  uint64        // Cumulative bytes read from storage
+	WriteBytes uint64        // Cumulative bytes written to storage
+	CPUTime    time.Duration // User and system time
+	At         time.Time     // When the counters were read
+}
+
+// FileStat is what the directory scan and the kernel limits need of a
+// file beyond os.FileInfo
+type FileStat struct {
+	Dev       uint64
+	Allocated uint64 // Bytes of the blocks allocated, less than the size for a sparse file
+	UID       uint32
+}
@@ -0,0 +1,13 @@
+//go:build (freebsd || openbsd) && !cgo
+
+package platform
+
+import (
+	"context"
+	"errors"
+)
+
+// Processes needs libkvm, which a build without cgo cannot call
+func Processes(ctx context.Context) ([]Process, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,79 @@
+package platform
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// states names the p_stat codes of <sys/proc.h> with the letters of
+// Linux's /proc/<pid>/stat
+var states = map[int8]string{1: "I", 2: "R", 3: "S", 4: "T", 5: "Z"}
+
+// task is what libproc tells of a process: its CPU time, resident
+// memory, disk I/O and open descriptors. Other users' processes only
+// tell it to root.
+type task struct {
+	cpu         time.Duration
+	rss         uint64
+	io          bool // The disk I/O counters were readable
+	read, write uint64
+	fds         int
+}
+
+// Processes lists every process from the kern.proc.all sysctl
+func Processes(ctx context.Context) ([]Process, error) {
+	kinfos, err := unix.SysctlKinfoProcSlice("kern.proc.all")
+	if err != nil {
+		return nil, err
+	}
+	procs := make([]Process, 0, len(kinfos))
+	for _, k := range kinfos {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pid := int(k.Proc.P_pid)
+		proc := Process{
+			PID:     pid,
+			PPID:    int(k.Eproc.Ppid),
+			Name:    unix.ByteSliceToString(k.Proc.P_comm[:]),
+			Cmdline: readCmdline(pid),
+			State:   states[k.Proc.P_stat],
+			UID:     k.Eproc.Ucred.Uid,
+			FDs:     -1,
+		}
+		proc.At = time.Now()
+		if t, ok := taskInfo(pid); ok {
+			proc.Memory, proc.FDs, proc.HasIO, proc.CPUTime = t.rss, t.fds, t.io, t.cpu
+			proc.ReadBytes, proc.WriteBytes = t.read, t.write
+		}
+		procs = append(procs, proc)
+	}
+	return procs, nil
+}
+
+// readCmdline returns the arguments of a process joined by spaces, from
+// kern.procargs2: argc, the executable path and its padding, then argv
+func readCmdline(pid int) string {
+	data, err := unix.SysctlRaw("kern.procargs2", pid)
+	if err != nil || len(data) < 4 {
+		return ""
+	}
+	argc := int(binary.LittleEndian.Uint32(data))
+	data = data[4:]
+	// Skip the executable path and the NULs aligning argv
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = bytes.TrimLeft(data[i:], "\x00")
+	}
+	args := make([]string, 0, argc)
+	for len(args) < argc && len(data) > 0 {
+		arg, rest, _ := bytes.Cut(data, []byte{0})
+		args = append(args, string(arg))
+		data = rest
+	}
+	return strings.Join(args, " ")
+}
@@ -0,0 +1,70 @@
+//go:build freebsd && cgo
+
+package platform
+
+/*
+#cgo LDFLAGS: -lkvm
+#include <sys/param.h>
+#include <sys/sysctl.h>
+#include <sys/user.h>
+#include <fcntl.h>
+#include <kvm.h>
+#include <limits.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+	"unsafe"
+)
+
+// states names the ki_stat codes of <sys/proc.h> with the letters of
+// Linux's /proc/<pid>/stat: SWAIT is an interrupt thread waiting for
+// work, SLOCK a thread blocked on a lock
+var states = map[int]string{1: "I", 2: "R", 3: "S", 4: "T", 5: "Z", 6: "S", 7: "D"}
+
+// Processes lists every process with kvm_getprocs, which reads the
+// kern.proc sysctl when kvm is opened on /dev/null rather than memory
+func Processes(ctx context.Context) ([]Process, error) {
+	devNull := C.CString("/dev/null")
+	defer C.free(unsafe.Pointer(devNull))
+	var errbuf [C._POSIX2_LINE_MAX]C.char
+	kd := C.kvm_openfiles(nil, devNull, nil, C.O_RDONLY, &errbuf[0])
+	if kd == nil {
+		return nil, fmt.Errorf("kvm_openfiles: %s", C.GoString(&errbuf[0]))
+	}
+	defer C.kvm_close(kd)
+
+	var n C.int
+	kp := C.kvm_getprocs(kd, C.KERN_PROC_PROC, 0, &n)
+	if kp == nil {
+		return nil, fmt.Errorf("kvm_getprocs: %s", C.GoString(C.kvm_geterr(kd)))
+	}
+	pageSize := uint64(os.Getpagesize())
+	kinfos := unsafe.Slice(kp, int(n))
+	procs := make([]Process, 0, len(kinfos))
+	for i := range kinfos {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		k := &kinfos[i]
+		proc := Process{
+			PID:     int(k.ki_pid),
+			PPID:    int(k.ki_ppid),
+			Name:    C.GoString(&k.ki_comm[0]),
+			Cmdline: kvmArgs(C.kvm_getargv(kd, k, 0)),
+			State:   states[int(k.ki_stat)],
+			Memory:  uint64(k.ki_rssize) * pageSize,
+			UID:     uint32(k.ki_uid),
+			FDs:     -1,
+			CPUTime: time.Duration(k.ki_runtime) * time.Microsecond,
+			At:      time.Now(),
+		}
+		procs = append(procs, proc)
+	}
+	return procs, nil
+}
@@ -0,0 +1,29 @@
+//go:build (freebsd || openbsd) && cgo
+
+package platform
+
+// #include <stddef.h>
+
+import "C"
+
+import "unsafe"
+
+// kvmArgs joins the NULL-terminated argv kvm_getargv returns, which
+// stays valid until the next call
+func kvmArgs(argv **C.char) string {
+	if argv == nil {
+		return ""
+	}
+	var args []byte
+	for i := uintptr(0); ; i++ {
+		arg := *(**C.char)(unsafe.Add(unsafe.Pointer(argv), i*unsafe.Sizeof(argv)))
+		if arg == nil {
+			break
+		}
+		if i > 0 {
+			args = append(args, ' ')
+		}
+		args = append(args, C.GoString(arg)...)
+	}
+	return string(args)
+}
@@ -0,0 +1,175 @@
+package platform
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// clockTicks is USER_HZ, the unit of the CPU times in /proc/<pid>/stat.
+// It is 100 on every mainstream Linux architecture.
+const clockTicks = 100
+
+// Processes reads every process of /proc
+func Processes(ctx context.Context) ([]Process, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize := uint64(os.Getpagesize())
+
+	procs := make([]Process, 0, len(entries))
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		proc, ticks, ok := readProcStat(pid, pageSize)
+		if !ok {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			if st, ok := info.Sys().(*syscall.Stat_t); ok {
+				proc.UID = st.Uid
+			}
+		}
+		proc.Cmdline = readCmdline(pid)
+		proc.FDs = countFDs(pid)
+		if proc.FDs >= 0 {
+			proc.FDLimit = readFDLimit(pid)
+		}
+		proc.OOMScore = readInt(fmt.Sprintf("/proc/%d/oom_score", pid))
+		proc.OOMAdj = readInt(fmt.Sprintf("/proc/%d/oom_score_adj", pid))
+
+		proc.At, proc.CPUTime = time.Now(), time.Duration(ticks)*time.Second/clockTicks
+		if read, write, ok := readProcIO(pid); ok {
+			proc.HasIO = true
+			proc.ReadBytes, proc.WriteBytes = read, write
+		}
+		procs = append(procs, proc)
+	}
+	return procs, nil
+}
+
+// countFDs returns the number of open file descriptors of a process, or
+// -1 when /proc/<pid>/fd is not readable
+func countFDs(pid int) int {
+	dir, err := os.Open(fmt.Sprintf("/proc/%d/fd", pid))
+	if err != nil {
+		return -1
+	}
+	defer dir.Close()
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return -1
+	}
+	return len(names)
+}
+
+// readFDLimit returns the soft open files limit of a process from
+// /proc/<pid>/limits, or 0 when unknown or unlimited
+func readFDLimit(pid int) uint64 {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/limits", pid))
+	if err != nil {
+		return 0
+	}
+	// "Max open files            1024                 524288               files"
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(line, "Max open files"); ok {
+			fields := strings.Fields(rest)
+			if len(fields) > 0 {
+				soft, _ := strconv.ParseUint(fields[0], 10, 64)
+				return soft
+			}
+		}
+	}
+	return 0
+}
+
+// readProcStat parses /proc/<pid>/stat, returning the process and its
+// total CPU time in clock ticks
+func readProcStat(pid int, pageSize uint64) (Process, uint64, bool) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return Process{}, 0, false
+	}
+	// The command name is wrapped in parentheses and may itself contain
+	// spaces or parentheses, so split around the last ')'
+	line := string(data)
+	open, end := strings.IndexByte(line, '('), strings.LastIndexByte(line, ')')
+	if open < 0 || end < open {
+		return Process{}, 0, false
+	}
+	fields := strings.Fields(line[end+1:])
+	if len(fields) < 22 {
+		return Process{}, 0, false
+	}
+
+	ppid, _ := strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	rss, _ := strconv.ParseUint(fields[21], 10, 64)
+
+	return Process{
+		PID:    pid,
+		PPID:   ppid,
+		Name:   line[open+1 : end],
+		State:  fields[0],
+		Memory: rss * pageSize,
+	}, utime + stime, true
+}
+
+// readCmdline returns the command line of a process with its arguments
+// joined by spaces
+func readCmdline(pid int) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+}
+
+// readProcIO returns the storage bytes read and written by a process.
+// /proc/<pid>/io is only readable for our own processes unless running as root.
+func readProcIO(pid int) (read, write uint64, ok bool) {
+	file, err := os.Open(fmt.Sprintf("/proc/%d/io", pid))
+	if err != nil {
+		return 0, 0, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ": ")
+		if !found {
+			continue
+		}
+		switch key {
+		case "read_bytes":
+			read, _ = strconv.ParseUint(value, 10, 64)
+		case "write_bytes":
+			write, _ = strconv.ParseUint(value, 10, 64)
+		}
+	}
+	return read, write, scanner.Err() == nil
+}
+
+// readInt reads a file holding a single signed number, returning 0 for
+// errors
+func readInt(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return n
+}
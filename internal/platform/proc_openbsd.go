@@ -0,0 +1,67 @@
+//go:build openbsd && cgo
+
+package platform
+
+/*
+#cgo LDFLAGS: -lkvm
+#include <sys/types.h>
+#include <sys/sysctl.h>
+#include <fcntl.h>
+#include <kvm.h>
+#include <limits.h>
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+	"unsafe"
+)
+
+// states names the p_stat codes of <sys/proc.h> with the letters of
+// Linux's /proc/<pid>/stat: SDEAD is a process being torn down, SONPROC
+// one running on a CPU
+var states = map[int]string{1: "I", 2: "R", 3: "S", 4: "T", 5: "Z", 6: "Z", 7: "R"}
+
+// Processes lists every process with kvm_getprocs, which reads the
+// kern.proc sysctl when kvm is opened without files
+func Processes(ctx context.Context) ([]Process, error) {
+	var errbuf [C._POSIX2_LINE_MAX]C.char
+	kd := C.kvm_openfiles(nil, nil, nil, C.KVM_NO_FILES, &errbuf[0])
+	if kd == nil {
+		return nil, fmt.Errorf("kvm_openfiles: %s", C.GoString(&errbuf[0]))
+	}
+	defer C.kvm_close(kd)
+
+	var n C.int
+	kp := C.kvm_getprocs(kd, C.KERN_PROC_ALL, 0, C.sizeof_struct_kinfo_proc, &n)
+	if kp == nil {
+		return nil, fmt.Errorf("kvm_getprocs: %s", C.GoString(C.kvm_geterr(kd)))
+	}
+	pageSize := uint64(os.Getpagesize())
+	kinfos := unsafe.Slice(kp, int(n))
+	procs := make([]Process, 0, len(kinfos))
+	for i := range kinfos {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		k := &kinfos[i]
+		proc := Process{
+			PID:     int(k.p_pid),
+			PPID:    int(k.p_ppid),
+			Name:    C.GoString(&k.p_comm[0]),
+			Cmdline: kvmArgs(C.kvm_getargv(kd, k, 0)),
+			State:   states[int(k.p_stat)],
+			Memory:  uint64(k.p_vm_rssize) * pageSize,
+			UID:     uint32(k.p_uid),
+			FDs:     -1,
+			At:      time.Now(),
+		}
+		proc.CPUTime = time.Duration(k.p_uutime_sec+k.p_ustime_sec)*time.Second +
+			time.Duration(k.p_uutime_usec+k.p_ustime_usec)*time.Microsecond
+		procs = append(procs, proc)
+	}
+	return procs, nil
+}
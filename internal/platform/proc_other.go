@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows && !freebsd && !openbsd
+
+package platform
+
+import (
+	"context"
+	"errors"
+)
+
+// Processes has no reader on this OS yet
+func Processes(ctx context.Context) ([]Process, error) {
+	return nil, errors.ErrUnsupported
+}
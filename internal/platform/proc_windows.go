@@ -0,0 +1,151 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	procGetProcessMemoryInfo  = kernel32.NewProc("K32GetProcessMemoryInfo")
+	procGetProcessIoCounters  = kernel32.NewProc("GetProcessIoCounters")
+	procGetProcessHandleCount = kernel32.NewProc("GetProcessHandleCount")
+)
+
+// processMemoryCounters is PROCESS_MEMORY_COUNTERS
+type processMemoryCounters struct {
+	CB                         uint32
+	PageFaultCount             uint32
+	PeakWorkingSetSize         uintptr
+	WorkingSetSize             uintptr
+	QuotaPeakPagedPoolUsage    uintptr
+	QuotaPagedPoolUsage        uintptr
+	QuotaPeakNonPagedPoolUsage uintptr
+	QuotaNonPagedPoolUsage     uintptr
+	PagefileUsage              uintptr
+	PeakPagefileUsage          uintptr
+}
+
+// accounts caches the account names of owner SIDs, as looking one up
+// may ask a domain controller
+var accounts = struct {
+	sync.Mutex
+	names map[string]string
+}{names: make(map[string]string)}
+
+// Processes lists every process from a Toolhelp snapshot
+func Processes(ctx context.Context) ([]Process, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var procs []Process
+	entry := windows.ProcessEntry32{Size: uint32(unsafe.Sizeof(windows.ProcessEntry32{}))}
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		proc := Process{
+			PID:  int(entry.ProcessID),
+			PPID: int(entry.ParentProcessID),
+			Name: windows.UTF16ToString(entry.ExeFile[:]),
+			User: "?",
+			FDs:  -1,
+			At:   time.Now(),
+		}
+		readProcess(&proc)
+		procs = append(procs, proc)
+	}
+	if !errors.Is(err, windows.ERROR_NO_MORE_FILES) {
+		return nil, err
+	}
+	return procs, nil
+}
+
+// readProcess fills in what a handle to the process tells: its times,
+// working set, I/O, handles, command line and owner. Other users' and
+// protected processes only open to administrators, and not all of them
+// then.
+func readProcess(proc *Process) {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(proc.PID))
+	if err != nil {
+		return
+	}
+	defer windows.CloseHandle(h)
+
+	var created, exited, kernel, user windows.Filetime
+	if windows.GetProcessTimes(h, &created, &exited, &kernel, &user) == nil {
+		proc.CPUTime = time.Duration(filetime(kernel)+filetime(user)) * 100
+	}
+	mem := processMemoryCounters{CB: uint32(unsafe.Sizeof(processMemoryCounters{}))}
+	if r, _, _ := procGetProcessMemoryInfo.Call(uintptr(h), uintptr(unsafe.Pointer(&mem)), uintptr(mem.CB)); r != 0 {
+		proc.Memory = uint64(mem.WorkingSetSize)
+	}
+	// The transfer counts take in network and device I/O as well as disk
+	var io windows.IO_COUNTERS
+	if r, _, _ := procGetProcessIoCounters.Call(uintptr(h), uintptr(unsafe.Pointer(&io))); r != 0 {
+		proc.HasIO = true
+		proc.ReadBytes, proc.WriteBytes = io.ReadTransferCount, io.WriteTransferCount
+	}
+	var handles uint32
+	if r, _, _ := procGetProcessHandleCount.Call(uintptr(h), uintptr(unsafe.Pointer(&handles))); r != 0 {
+		proc.FDs = int(handles)
+	}
+	proc.Cmdline = commandLine(h)
+	if owner := owner(h); owner != "" {
+		proc.User = owner
+	}
+}
+
+// filetime is a FILETIME duration in 100ns units
+func filetime(t windows.Filetime) int64 {
+	return int64(t.HighDateTime)<<32 | int64(t.LowDateTime)
+}
+
+// commandLine reads the command line of a process, which Windows keeps
+// as one string rather than arguments
+func commandLine(h windows.Handle) string {
+	var size uint32
+	windows.NtQueryInformationProcess(h, windows.ProcessCommandLineInformation, nil, 0, &size)
+	if size == 0 {
+		return ""
+	}
+	// A UNICODE_STRING and the text it points to, pointer-aligned
+	buf := make([]uintptr, (uintptr(size)+unsafe.Sizeof(uintptr(0))-1)/unsafe.Sizeof(uintptr(0)))
+	if err := windows.NtQueryInformationProcess(h, windows.ProcessCommandLineInformation, unsafe.Pointer(&buf[0]), size, &size); err != nil {
+		return ""
+	}
+	return (*windows.NTUnicodeString)(unsafe.Pointer(&buf[0])).String()
+}
+
+// owner is the account name of the user a process runs as, without its
+// domain as Task Manager shows it
+func owner(h windows.Handle) string {
+	var token windows.Token
+	if windows.OpenProcessToken(h, windows.TOKEN_QUERY, &token) != nil {
+		return ""
+	}
+	defer token.Close()
+	user, err := token.GetTokenUser()
+	if err != nil {
+		return ""
+	}
+	sid := user.User.Sid.String()
+	accounts.Lock()
+	defer accounts.Unlock()
+	if name, ok := accounts.names[sid]; ok {
+		return name
+	}
+	name := sid
+	if account, _, _, err := user.User.Sid.LookupAccount(""); err == nil {
+		name = account
+	}
+	accounts.names[sid] = name
+	return name
+}
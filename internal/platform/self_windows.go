@@ -0,0 +1,27 @@
+package platform
+
+import (
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// SelfCPUTime is the user plus system CPU time the process has used
+func SelfCPUTime() time.Duration {
+	var created, exited, kernel, user windows.Filetime
+	if err := windows.GetProcessTimes(windows.CurrentProcess(), &created, &exited, &kernel, &user); err != nil {
+		return 0
+	}
+	return time.Duration(filetime(kernel)+filetime(user)) * 100
+}
+
+// SelfRSS is the working set of the process
+func SelfRSS() uint64 {
+	mem := processMemoryCounters{CB: uint32(unsafe.Sizeof(processMemoryCounters{}))}
+	r, _, _ := procGetProcessMemoryInfo.Call(uintptr(windows.CurrentProcess()), uintptr(unsafe.Pointer(&mem)), uintptr(mem.CB))
+	if r == 0 {
+		return 0
+	}
+	return uint64(mem.WorkingSetSize)
+}
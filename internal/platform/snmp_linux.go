@@ -0,0 +1,57 @@
+package platform
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ProtocolCounters reads /proc/net/snmp, whose sections come as a line of
+// field names and a line of values, such as "Tcp: ActiveOpens ..." and
+// "Tcp: 1042 ...". It reports false when the file cannot be read.
+func ProtocolCounters() (Protocols, bool) {
+	file, err := os.Open("/proc/net/snmp")
+	if err != nil {
+		return Protocols{}, false
+	}
+	defer file.Close()
+
+	values := make(map[string]uint64)
+	names := make(map[string][]string) // By section, from its first line
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		section, rest, ok := strings.Cut(scanner.Text(), ": ")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if _, seen := names[section]; !seen {
+			names[section] = fields
+			continue
+		}
+		for i, f := range fields {
+			// Counters that may go negative, such as MaxConn, are not wanted
+			if v, err := strconv.ParseUint(f, 10, 64); err == nil && i < len(names[section]) {
+				values[section+"."+names[section][i]] = v
+			}
+		}
+	}
+	if scanner.Err() != nil || len(values) == 0 {
+		return Protocols{}, false
+	}
+	return Protocols{
+		ICMPInDestUnreachs:  values["Icmp.InDestUnreachs"],
+		ICMPOutDestUnreachs: values["Icmp.OutDestUnreachs"],
+		ICMPInErrors:        values["Icmp.InErrors"],
+		TCPActiveOpens:      values["Tcp.ActiveOpens"],
+		TCPPassiveOpens:     values["Tcp.PassiveOpens"],
+		TCPAttemptFails:     values["Tcp.AttemptFails"],
+		TCPEstabResets:      values["Tcp.EstabResets"],
+		TCPOutRsts:          values["Tcp.OutRsts"],
+		TCPRetransSegs:      values["Tcp.RetransSegs"],
+		TCPInErrs:           values["Tcp.InErrs"],
+		UDPNoPorts:          values["Udp.NoPorts"],
+		UDPInErrors:         values["Udp.InErrors"],
+	}, true
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package platform
+
+// ProtocolCounters reports false where the kernel's protocol counters are
+// not read
+func ProtocolCounters() (Protocols, bool) { return Protocols{}, false }
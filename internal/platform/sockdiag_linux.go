@@ -0,0 +1,143 @@
+package platform
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// sock_diag request and reply layouts, from linux/inet_diag.h
+const (
+	sockDiagByFamily = 20 // SOCK_DIAG_BY_FAMILY
+	inetDiagInfo     = 2  // INET_DIAG_INFO, the attribute holding struct tcp_info
+	inetDiagReqLen   = 56 // struct inet_diag_req_v2
+	inetDiagMsgLen   = 72 // struct inet_diag_msg
+
+	// Offsets of tcpi_bytes_acked and tcpi_bytes_received in struct
+	// tcp_info, which kernels before 4.2 end before
+	tcpiBytesAcked    = 120
+	tcpiBytesReceived = 128
+)
+
+// sockDiagTimeout bounds each wait for the kernel's reply
+const sockDiagTimeout = time.Second
+
+// tcpBytes are the bytes a TCP socket sent that its peer acknowledged and
+// the bytes it received
+type tcpBytes struct {
+	sent, received uint64
+}
+
+// tcpTraffic dumps the TCP sockets of advis's network namespace through
+// sock_diag, by tcpKey, for the byte counts /proc/net/tcp lacks
+func tcpTraffic() (map[string]tcpBytes, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, netlinkSockDiag)
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.Close(fd)
+	tv := syscall.NsecToTimeval(sockDiagTimeout.Nanoseconds())
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+		return nil, err
+	}
+
+	traffic := make(map[string]tcpBytes)
+	for _, family := range []uint8{syscall.AF_INET, syscall.AF_INET6} {
+		if err := dumpTCP(fd, family, traffic); err != nil {
+			return nil, err
+		}
+	}
+	return traffic, nil
+}
+
+// dumpTCP asks for the sockets of one address family with their
+// tcp_info, adding their byte counts to traffic
+func dumpTCP(fd int, family uint8, traffic map[string]tcpBytes) error {
+	req := make([]byte, syscall.NLMSG_HDRLEN+inetDiagReqLen)
+	binary.NativeEndian.PutUint32(req[0:], uint32(len(req)))
+	binary.NativeEndian.PutUint16(req[4:], sockDiagByFamily)
+	binary.NativeEndian.PutUint16(req[6:], syscall.NLM_F_REQUEST|syscall.NLM_F_DUMP)
+	binary.NativeEndian.PutUint32(req[8:], uint32(family)) // Sequence number
+	r := req[syscall.NLMSG_HDRLEN:]
+	r[0], r[1], r[2] = family, syscall.IPPROTO_TCP, 1<<(inetDiagInfo-1)
+	binary.NativeEndian.PutUint32(r[4:], ^uint32(0)) // Every state
+	if err := syscall.Sendto(fd, req, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return err
+	}
+
+	protocol := "TCP"
+	if family == syscall.AF_INET6 {
+		protocol = "TCP6"
+	}
+	buf := make([]byte, 64<<10)
+	for {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return err
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			switch msg.Header.Type {
+			case syscall.NLMSG_DONE:
+				return nil
+			case syscall.NLMSG_ERROR:
+				if len(msg.Data) >= 4 {
+					if errno := int32(binary.NativeEndian.Uint32(msg.Data)); errno != 0 {
+						return syscall.Errno(-errno)
+					}
+				}
+				return errors.New("sock_diag: error reply")
+			}
+			if key, b, ok := parseDiagMsg(protocol, msg.Data); ok {
+				traffic[key] = b
+			}
+		}
+	}
+}
+
+// parseDiagMsg reads the addresses and byte counts of one socket of a
+// sock_diag reply
+func parseDiagMsg(protocol string, data []byte) (string, tcpBytes, bool) {
+	if len(data) < inetDiagMsgLen {
+		return "", tcpBytes{}, false
+	}
+	size := net.IPv4len
+	if data[0] == syscall.AF_INET6 {
+		size = net.IPv6len
+	}
+	// Ports and addresses are in network byte order
+	sport, dport := binary.BigEndian.Uint16(data[4:]), binary.BigEndian.Uint16(data[6:])
+	src, dst := net.IP(data[8:8+size]), net.IP(data[24:24+size])
+	local := net.JoinHostPort(src.String(), strconv.Itoa(int(sport)))
+	remote := net.JoinHostPort(dst.String(), strconv.Itoa(int(dport)))
+
+	for attrs := data[inetDiagMsgLen:]; len(attrs) >= syscall.SizeofRtAttr; {
+		length := int(binary.NativeEndian.Uint16(attrs))
+		if length < syscall.SizeofRtAttr || length > len(attrs) {
+			break
+		}
+		if binary.NativeEndian.Uint16(attrs[2:]) == inetDiagInfo {
+			info := attrs[syscall.SizeofRtAttr:length]
+			if len(info) < tcpiBytesReceived+8 {
+				return "", tcpBytes{}, false
+			}
+			return tcpKey(protocol, local, remote), tcpBytes{
+				sent:     binary.NativeEndian.Uint64(info[tcpiBytesAcked:]),
+				received: binary.NativeEndian.Uint64(info[tcpiBytesReceived:]),
+			}, true
+		}
+		attrs = attrs[min((length+3)&^3, len(attrs)):]
+	}
+	return "", tcpBytes{}, false
+}
+
+// tcpKey identifies a TCP socket in both /proc/net/tcp and sock_diag
+func tcpKey(protocol, local, remote string) string {
+	return protocol + " " + local + " " + remote
+}
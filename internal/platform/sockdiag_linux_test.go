@@ -0,0 +1,95 @@
+package platform
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+	"testing"
+)
+
+// diagMsg is an inet_diag_msg of a socket from src:sport to dst:dport,
+// followed by attrs
+func diagMsg(family uint8, src, dst string, sport, dport uint16, attrs ...[]byte) []byte {
+	msg := make([]byte, inetDiagMsgLen)
+	msg[0], msg[1] = family, 1 // TCP_ESTABLISHED
+	binary.BigEndian.PutUint16(msg[4:], sport)
+	binary.BigEndian.PutUint16(msg[6:], dport)
+	for off, addr := range map[int]string{8: src, 24: dst} {
+		ip := net.ParseIP(addr)
+		if family == syscall.AF_INET {
+			ip = ip.To4()
+		}
+		copy(msg[off:], ip)
+	}
+	for _, a := range attrs {
+		msg = append(msg, a...)
+	}
+	return msg
+}
+
+// rtattr is an attribute of typ holding payload, padded to 4 bytes
+func rtattr(typ uint16, payload []byte) []byte {
+	a := make([]byte, syscall.SizeofRtAttr, syscall.SizeofRtAttr+len(payload)+3)
+	binary.NativeEndian.PutUint16(a, uint16(syscall.SizeofRtAttr+len(payload)))
+	binary.NativeEndian.PutUint16(a[2:], typ)
+	a = append(a, payload...)
+	for len(a)%4 != 0 {
+		a = append(a, 0)
+	}
+	return a
+}
+
+// tcpInfo is a struct tcp_info of size bytes with the byte counts given,
+// where it is long enough for them
+func tcpInfo(size int, acked, received uint64) []byte {
+	info := make([]byte, size)
+	if size >= tcpiBytesReceived+8 {
+		binary.NativeEndian.PutUint64(info[tcpiBytesAcked:], acked)
+		binary.NativeEndian.PutUint64(info[tcpiBytesReceived:], received)
+	}
+	return info
+}
+
+func TestParseDiagMsg(t *testing.T) {
+	info := rtattr(inetDiagInfo, tcpInfo(232, 1<<40, 12345))
+	for _, c := range []struct {
+		name     string
+		protocol string
+		data     []byte
+		key      string
+		ok       bool
+	}{
+		{"IPv4", "TCP", diagMsg(syscall.AF_INET, "10.0.0.2", "93.184.216.34", 41000, 443, info),
+			"TCP 10.0.0.2:41000 93.184.216.34:443", true},
+		{"IPv6", "TCP6", diagMsg(syscall.AF_INET6, "2001:db8::2", "2001:db8::1", 41000, 22, info),
+			"TCP6 [2001:db8::2]:41000 [2001:db8::1]:22", true},
+		{"after other attributes", "TCP", diagMsg(syscall.AF_INET, "127.0.0.1", "127.0.0.1", 1, 2,
+			rtattr(1, make([]byte, 16)), rtattr(5, []byte{1}), info), "TCP 127.0.0.1:1 127.0.0.1:2", true},
+		{"tcp_info before 4.2", "TCP", diagMsg(syscall.AF_INET, "10.0.0.2", "10.0.0.1", 1, 2,
+			rtattr(inetDiagInfo, tcpInfo(104, 0, 0))), "", false},
+		{"tcp_info cut short", "TCP", diagMsg(syscall.AF_INET, "10.0.0.2", "10.0.0.1", 1, 2,
+			rtattr(inetDiagInfo, tcpInfo(tcpiBytesReceived+4, 0, 0))), "", false},
+		{"no tcp_info", "TCP", diagMsg(syscall.AF_INET, "10.0.0.2", "10.0.0.1", 1, 2, rtattr(1, make([]byte, 16))), "", false},
+		{"attribute past the message", "TCP", diagMsg(syscall.AF_INET, "10.0.0.2", "10.0.0.1", 1, 2, info)[:inetDiagMsgLen+100], "", false},
+		{"attribute of zero length", "TCP", diagMsg(syscall.AF_INET, "10.0.0.2", "10.0.0.1", 1, 2, make([]byte, 8)), "", false},
+		{"message cut short", "TCP", diagMsg(syscall.AF_INET, "10.0.0.2", "10.0.0.1", 1, 2)[:inetDiagMsgLen-1], "", false},
+	} {
+		key, b, ok := parseDiagMsg(c.protocol, c.data)
+		if ok != c.ok || key != c.key {
+			t.Errorf("%s: %q %v, want %q %v", c.name, key, ok, c.key, c.ok)
+			continue
+		}
+		if ok && (b.sent != 1<<40 || b.received != 12345) {
+			t.Errorf("%s: bytes %+v, want %d sent and 12345 received", c.name, b, uint64(1<<40))
+		}
+	}
+}
+
+// The keys of sock_diag match those the socket table's addresses make
+func TestTCPKey(t *testing.T) {
+	key, _, _ := parseDiagMsg("TCP6", diagMsg(syscall.AF_INET6, "::ffff:10.0.0.2", "::1", 80, 5000,
+		rtattr(inetDiagInfo, tcpInfo(232, 0, 0))))
+	if want := tcpKey("TCP6", net.JoinHostPort("10.0.0.2", "80"), net.JoinHostPort("::1", "5000")); key != want {
+		t.Errorf("key %q, want %q", key, want)
+	}
+}
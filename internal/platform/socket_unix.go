@@ -0,0 +1,11 @@
+//go:build unix
+
+package platform
+
+import "syscall"
+
+// CanConnect reports whether this user may connect to a unix socket,
+// which needs write permission on it
+func CanConnect(socket string) bool {
+	return syscall.Access(socket, 2) == nil
+}
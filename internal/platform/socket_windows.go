@@ -0,0 +1,5 @@
+package platform
+
+// CanConnect is false: Docker and Podman listen on named pipes rather
+// than unix sockets on Windows, which the container panels cannot use
+func CanConnect(socket string) bool { return false }
@@ -0,0 +1,17 @@
+//go:build unix
+
+package platform
+
+import (
+	"os"
+	"syscall"
+)
+
+// StatOf is the stat of info, false where the OS keeps none
+func StatOf(info os.FileInfo) (FileStat, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return FileStat{}, false
+	}
+	return FileStat{Dev: uint64(st.Dev), Allocated: uint64(st.Blocks) * 512, UID: st.Uid}, true
+}
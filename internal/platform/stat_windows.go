@@ -0,0 +1,7 @@
+package platform
+
+import "os"
+
+// StatOf is false: Windows keeps no device numbers or owner UIDs, so the
+// directory scan counts apparent sizes and crosses into mounted volumes
+func StatOf(info os.FileInfo) (FileStat, bool) { return FileStat{}, false }
@@ -0,0 +1,48 @@
+//go:build darwin || freebsd || openbsd
+
+package platform
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// LoadAverage reads vm.loadavg, a struct loadavg: three fixed-point
+// averages scaled by fscale, a long
+func LoadAverage() (float64, error) {
+	data, err := unix.SysctlRaw("vm.loadavg")
+	if err != nil {
+		return 0, fmt.Errorf("sysctl vm.loadavg: %w", err)
+	}
+	var scale uint64
+	switch len(data) {
+	case 24: // A 64-bit long after padding
+		scale = binary.NativeEndian.Uint64(data[16:])
+	case 16:
+		scale = uint64(binary.NativeEndian.Uint32(data[12:]))
+	default:
+		return 0, fmt.Errorf("sysctl vm.loadavg: %d bytes, want 16 or 24", len(data))
+	}
+	if scale == 0 {
+		return 0, fmt.Errorf("sysctl vm.loadavg: zero scale")
+	}
+	return float64(binary.NativeEndian.Uint32(data)) / float64(scale), nil
+}
+
+// sysctlUint reads an unsigned sysctl, which the BSDs declare as 32 or
+// 64 bits from one name to the next and release to release
+func sysctlUint(name string) (uint64, error) {
+	data, err := unix.SysctlRaw(name)
+	if err != nil {
+		return 0, fmt.Errorf("sysctl %s: %w", name, err)
+	}
+	switch len(data) {
+	case 4:
+		return uint64(binary.NativeEndian.Uint32(data)), nil
+	case 8:
+		return binary.NativeEndian.Uint64(data), nil
+	}
+	return 0, fmt.Errorf("sysctl %s: %d bytes, want 4 or 8", name, len(data))
+}
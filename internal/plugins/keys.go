@@ -0,0 +1,44 @@
+package plugins
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// keyMap holds the key bindings of the plugins monitor. The key tags are
+// the names the "plugins" section of the config file overrides them by.
+type keyMap struct {
+	Quit    key.Binding `key:"quit"`
+	NextTab key.Binding `key:"next_tab"`
+	Tabs    key.Binding `key:"tabs"` // One key per plugin, in order
+	Run     key.Binding `key:"run"`
+	Help    key.Binding `key:"help"`
+}
+
+var keys = keyMap{
+	Quit:    key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+	NextTab: key.NewBinding(key.WithKeys("tab"), key.WithHelp("Tab", "cycle")),
+	Tabs:    key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"), key.WithHelp("1-9", "switch plugins")),
+	Run:     key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "run now")),
+	Help:    key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "all keys")),
+}
+
+// SetKeys applies the "plugins" key overrides of the config file
+func SetKeys(overrides map[string][]string) error {
+	return ui.Rebind(&keys, overrides)
+}
+
+// tabKey is the key that selects the i-th plugin, or "" past the last key
+func tabKey(i int) string {
+	if tabs := keys.Tabs.Keys(); i < len(tabs) {
+		return tabs[i]
+	}
+	return ""
+}
+
+// renderHelp is the help overlay listing every binding
+func (m model) renderHelp() string {
+	return ui.HelpOverlay("⌨️  Keys", []ui.KeyGroup{{Title: "Plugins", Bindings: []key.Binding{
+		keys.Run, ui.TrimKeys(keys.Tabs, len(m.plugins)), keys.NextTab, keys.Help, keys.Quit}}}, m.width) +
+		"\n" + dimStyle.Render("Press any key to close") + "\n"
+}
@@ -0,0 +1,267 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// Styles, set from the palette
+var titleStyle, headerStyle, barStyle, dimStyle, errorStyle lipgloss.Style
+
+func init() { ui.OnPalette(setStyles) }
+
+// setStyles styles the monitor in p's colors, keeping its own for the
+// roles p leaves empty
+func setStyles(p ui.Palette) {
+	titleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ui.ColorOr(p.Title, "#F4A261")).
+		Background(ui.ColorOr(p.TitleBg, "#282828")).
+		Padding(0, 1)
+
+	headerStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ui.ColorOr(p.Accent, "#06D6A0"))
+
+	barStyle = lipgloss.NewStyle().
+		Foreground(ui.ColorOr(p.Good, "#04B575"))
+
+	dimStyle = lipgloss.NewStyle().
+		Foreground(ui.ColorOr(p.Dim, "#777777"))
+
+	errorStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ui.ColorOr(p.Critical, "#FF6B6B"))
+}
+
+// tickInterval is how often due plugins are checked for
+const tickInterval = time.Second
+
+type tickMsg time.Time
+
+// resultMsg carries the outcome of one plugin run
+type resultMsg struct {
+	index int
+	out   Output
+	err   error
+}
+
+func tickCmd() tea.Cmd {
+	return tea.Tick(tickInterval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+func runCmd(index int, path string) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		out, err := Run(context.Background(), path)
+		debug.Timing("plugins: "+filepath.Base(path), start, err)
+		return resultMsg{index: index, out: out, err: err}
+	}
+}
+
+// model shows one tab per plugin. Each plugin runs on its own schedule;
+// a plugin still running when it falls due again is skipped.
+type model struct {
+	plugins  []*Plugin
+	tab      int
+	keysOpen bool // Show the key overlay instead of the plugin
+	width    int
+	height   int
+}
+
+// New returns the plugin monitor for the directory in Flags, or nil when
+// it holds no plugins
+func New() (tea.Model, error) {
+	plugins, err := Discover(*flagDir)
+	if err != nil || len(plugins) == 0 {
+		return nil, err
+	}
+	return model{plugins: plugins}, nil
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(tickCmd(), m.runDue(time.Now()))
+}
+
+// runDue starts every plugin whose next run is due and not in flight
+func (m model) runDue(now time.Time) tea.Cmd {
+	var cmds []tea.Cmd
+	for i, p := range m.plugins {
+		if p.running || now.Before(p.next) {
+			continue
+		}
+		p.running = true
+		cmds = append(cmds, runCmd(i, p.Path))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+
+	case tea.KeyMsg:
+		if m.keysOpen {
+			// Any key closes the overlay, and quitting still works
+			m.keysOpen = false
+			if !key.Matches(msg, keys.Quit) {
+				return m, nil
+			}
+		}
+		switch {
+		case key.Matches(msg, keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, keys.Help):
+			m.keysOpen = true
+		case key.Matches(msg, keys.NextTab):
+			m.tab = (m.tab + 1) % len(m.plugins)
+		case key.Matches(msg, keys.Run):
+			// Run the visible plugin now
+			m.plugins[m.tab].next = time.Time{}
+			return m, m.runDue(time.Now())
+		case key.Matches(msg, keys.Tabs):
+			if i := slices.Index(keys.Tabs.Keys(), msg.String()); i < len(m.plugins) {
+				m.tab = i
+			}
+		}
+
+	case tickMsg:
+		return m, tea.Batch(tickCmd(), m.runDue(time.Time(msg)))
+
+	case resultMsg:
+		p := m.plugins[msg.index]
+		p.running = false
+		p.Updated = time.Now()
+		p.Err = msg.err
+		if msg.err == nil {
+			p.Output = msg.out
+		}
+		p.next = p.Updated.Add(p.interval())
+	}
+	return m, nil
+}
+
+func (m model) View() string {
+	if m.width == 0 {
+		return "Loading plugins..."
+	}
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(ui.Icon("plugin")+"Plugins") + "\n\n")
+
+	var tabs []string
+	for i, p := range m.plugins {
+		if i == m.tab {
+			tabs = append(tabs, headerStyle.Render(fmt.Sprintf("[%s] %s", tabKey(i), p.Title())))
+		} else {
+			tabs = append(tabs, fmt.Sprintf(" %s  %s ", tabKey(i), p.Title()))
+		}
+	}
+	content.WriteString(strings.Join(tabs, " | ") + "\n\n")
+
+	if m.keysOpen {
+		content.WriteString(m.renderHelp())
+	} else {
+		p := m.plugins[m.tab]
+		content.WriteString(renderPlugin(p, m.width, max(m.height-8, 5)))
+	}
+
+	help := ui.HelpLine(keys.Run, ui.TrimKeys(keys.Tabs, len(m.plugins)), keys.NextTab, keys.Help, keys.Quit)
+	content.WriteString("\n" + dimStyle.Render(help))
+	return content.String()
+}
+
+// Panels contributes one panel per plugin to the combined dashboard
+func (m model) Panels(width int) []ui.Panel {
+	panels := make([]ui.Panel, len(m.plugins))
+	for i, p := range m.plugins {
+		panels[i] = ui.Panel{Title: ui.Icon("plugin") + p.Title(), Body: renderPlugin(p, width, 0), Widget: "plugin:" + p.Name}
+	}
+	return panels
+}
+
+// renderPlugin draws a plugin's metrics and table in width columns,
+// limiting the table to rows lines when rows is positive
+func renderPlugin(p *Plugin, width, rows int) string {
+	var content strings.Builder
+
+	if p.Err != nil {
+		content.WriteString(errorStyle.Render(ui.Truncate("⚠ "+p.Err.Error(), width)) + "\n")
+	}
+	if p.Updated.IsZero() {
+		content.WriteString(dimStyle.Render("Waiting for first run...") + "\n")
+		return content.String()
+	}
+
+	nameWidth := 0
+	for _, metric := range p.Output.Metrics {
+		nameWidth = max(nameWidth, ui.Width(metric.Name))
+	}
+	nameWidth = min(nameWidth, width/3)
+	for _, metric := range p.Output.Metrics {
+		value := ui.FormatValue(metric.Value, metric.Unit)
+		line := fmt.Sprintf("%s %12s", ui.Fit(metric.Name, nameWidth), value)
+		if barWidth := width - nameWidth - 15; metric.Max > 0 && barWidth >= 5 {
+			filled := int(min(max(metric.Value/metric.Max, 0), 1) * float64(barWidth))
+			line += "  " + barStyle.Render(ui.Blocks(filled)) + ui.Shades(barWidth-filled)
+		}
+		content.WriteString(line + "\n")
+	}
+
+	if len(p.Output.Columns) > 0 || len(p.Output.Rows) > 0 {
+		if len(p.Output.Metrics) > 0 {
+			content.WriteString("\n")
+		}
+		content.WriteString(renderTable(p.Output.Columns, p.Output.Rows, width, rows))
+	}
+	return content.String()
+}
+
+// renderTable lays out plugin rows in columns sized to their content
+func renderTable(columns []string, rows [][]string, width, limit int) string {
+	widths := make([]int, len(columns))
+	for i, c := range columns {
+		widths[i] = ui.Width(c)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], ui.Width(cell))
+		}
+	}
+
+	line := func(cells []string) string {
+		var b strings.Builder
+		for i, cell := range cells {
+			b.WriteString(ui.Fit(cell, widths[i]) + "  ")
+		}
+		return ui.Truncate(strings.TrimRight(b.String(), " "), width)
+	}
+
+	var content strings.Builder
+	if len(columns) > 0 {
+		content.WriteString(headerStyle.Render(line(columns)) + "\n")
+	}
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+	for _, row := range rows {
+		content.WriteString(line(row) + "\n")
+	}
+	return content.String()
+}
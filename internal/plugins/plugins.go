@@ -0,0 +1,188 @@
+// Package plugins runs user supplied panels. A plugin is any executable in
+// the plugin directory; advis runs it periodically and reads one JSON
+// document from its standard output:
+//
+//	{
+//	  "title": "Redis",
+//	  "interval": 5,
+//	  "metrics": [
+//	    {"name": "used memory", "value": 73400320, "unit": "bytes", "max": 268435456},
+//	    {"name": "hit rate", "value": 97.5, "unit": "%"},
+//	    {"name": "clients", "value": 42}
+//	  ],
+//	  "columns": ["KEYSPACE", "KEYS", "EXPIRES"],
+//	  "rows": [["db0", "1200", "37"]]
+//	}
+//
+// Every field is optional. interval is the number of seconds until the next
+// run. A metric with a max is drawn as a bar; unit "bytes" is formatted
+// with binary suffixes. A non-zero exit status or invalid JSON is shown in
+// the plugin's panel together with the first line of its standard error.
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Flags are the options of the plugin panels, parsed by the advis command
+// before New is called
+var Flags = flag.NewFlagSet("plugins", flag.ExitOnError)
+
+var flagDir = Flags.String("plugins", DefaultDir(),
+	"directory of executables that emit JSON panels (empty disables plugins)")
+
+const (
+	// defaultInterval is how often a plugin runs unless it asks otherwise
+	defaultInterval = 5 * time.Second
+	// minInterval stops a plugin from asking to be run in a busy loop
+	minInterval = time.Second
+	// runTimeout bounds a single run so a hung plugin cannot pile up
+	runTimeout = 10 * time.Second
+	// maxOutput caps what is read from a plugin's standard output
+	maxOutput = 1 << 20
+	// maxStderr caps what is kept of its standard error, of which only
+	// the first line is shown
+	maxStderr = 4 << 10
+	// waitDelay is how long the plugin's children may hold its output
+	// open once it exits or times out
+	waitDelay = 2 * time.Second
+)
+
+// Output is the document a plugin prints on each run
+type Output struct {
+	Title    string     `json:"title"`
+	Interval float64    `json:"interval"`
+	Metrics  []Metric   `json:"metrics"`
+	Columns  []string   `json:"columns"`
+	Rows     [][]string `json:"rows"`
+}
+
+// Metric is one named value of a plugin
+type Metric struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+	Max   float64 `json:"max"`
+}
+
+// Plugin is one executable and the result of its last run
+type Plugin struct {
+	Name    string // File name, used until the plugin reports a title
+	Path    string
+	Output  Output
+	Err     error
+	Updated time.Time // When the last run finished
+	next    time.Time // When the next run is due
+	running bool
+}
+
+// Title is the plugin's own title, or its file name
+func (p *Plugin) Title() string {
+	if p.Output.Title != "" {
+		return p.Output.Title
+	}
+	return p.Name
+}
+
+// interval is the delay the plugin asked for before its next run
+func (p *Plugin) interval() time.Duration {
+	if p.Output.Interval <= 0 {
+		return defaultInterval
+	}
+	return max(time.Duration(p.Output.Interval*float64(time.Second)), minInterval)
+}
+
+// DefaultDir is advis/plugins under the user's configuration directory, or
+// empty when that cannot be determined
+func DefaultDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "advis", "plugins")
+}
+
+// Discover lists the executables in dir, sorted by name. A missing
+// directory means no plugins rather than an error.
+func Discover(dir string) ([]*Plugin, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var plugins []*Plugin
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		// Follow symlinks so plugins can live elsewhere
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		plugins = append(plugins, &Plugin{Name: e.Name(), Path: path})
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, nil
+}
+
+// Run executes the plugin at path once and decodes its output
+func Run(ctx context.Context, path string) (Output, error) {
+	ctx, cancel := context.WithTimeout(ctx, runTimeout)
+	defer cancel()
+
+	stdout, stderr := &headBuffer{limit: maxOutput}, &headBuffer{limit: maxStderr}
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	// A child left running, such as a hung client a shell plugin started,
+	// keeps the output open past the timeout otherwise
+	cmd.WaitDelay = waitDelay
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return Output{}, fmt.Errorf("timed out after %v", runTimeout)
+		}
+		if line, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); line != "" {
+			return Output{}, fmt.Errorf("%v: %s", err, line)
+		}
+		return Output{}, err
+	}
+
+	var out Output
+	if err := json.Unmarshal(stdout.buf, &out); err != nil {
+		return Output{}, fmt.Errorf("invalid output: %w", err)
+	}
+	return out, nil
+}
+
+// headBuffer keeps the first limit bytes written to it and drops the rest,
+// so a plugin printing without end is never blocked writing
+type headBuffer struct {
+	buf   []byte
+	limit int
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p[:min(len(p), max(b.limit-len(b.buf), 0))]...)
+	return len(p), nil
+}
+
+func (b *headBuffer) String() string {
+	return string(b.buf)
+}
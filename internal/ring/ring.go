@@ -0,0 +1,76 @@
+// Package ring is the fixed-size buffer behind every history series the
+// monitors keep. Pushing onto a full buffer overwrites the oldest sample
+// in place, so a series costs one allocation for its whole life.
+package ring
+
+import "iter"
+
+// Buffer holds the last Cap values pushed, oldest first
+type Buffer[T any] struct {
+	items []T
+	start int // Index of the oldest value in items
+	n     int
+}
+
+// New returns an empty buffer keeping up to capacity values
+func New[T any](capacity int) *Buffer[T] {
+	return &Buffer[T]{items: make([]T, max(capacity, 1))}
+}
+
+// Push appends v, dropping the oldest value when the buffer is full
+func (b *Buffer[T]) Push(v T) {
+	if b.n < len(b.items) {
+		b.items[(b.start+b.n)%len(b.items)] = v
+		b.n++
+		return
+	}
+	b.items[b.start] = v
+	b.start = (b.start + 1) % len(b.items)
+}
+
+// Len is the number of values held
+func (b *Buffer[T]) Len() int { return b.n }
+
+// Cap is the number of values the buffer keeps
+func (b *Buffer[T]) Cap() int { return len(b.items) }
+
+// At returns the i-th value, 0 being the oldest
+func (b *Buffer[T]) At(i int) T {
+	if i < 0 || i >= b.n {
+		panic("ring: index out of range")
+	}
+	return b.items[(b.start+i)%len(b.items)]
+}
+
+// Last returns the newest value, or the zero value when empty
+func (b *Buffer[T]) Last() T {
+	if b.n == 0 {
+		var zero T
+		return zero
+	}
+	return b.At(b.n - 1)
+}
+
+// All yields the index and value of every value, oldest first
+func (b *Buffer[T]) All() iter.Seq2[int, T] {
+	return b.Range(0, b.n)
+}
+
+// Range yields the values from index start up to but not including end,
+// clamped to what the buffer holds
+func (b *Buffer[T]) Range(start, end int) iter.Seq2[int, T] {
+	start, end = max(start, 0), min(end, b.n)
+	return func(yield func(int, T) bool) {
+		for i := start; i < end; i++ {
+			if !yield(i, b.items[(b.start+i)%len(b.items)]) {
+				return
+			}
+		}
+	}
+}
+
+// Reset empties the buffer, keeping its storage
+func (b *Buffer[T]) Reset() {
+	clear(b.items)
+	b.start, b.n = 0, 0
+}
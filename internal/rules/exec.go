@@ -0,0 +1,123 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/alerting"
+	"github.com/s-archdev/Terminal_ADVIS/internal/audit"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+	"github.com/s-archdev/Terminal_ADVIS/internal/tail"
+	"go.starlark.net/starlark"
+)
+
+var (
+	flagExec = Flags.Bool("rules-exec", false,
+		"run the exec and exec_clear commands of the rules script's alerts as they fire and clear")
+	flagExecTimeout = Flags.Duration("rules-exec-timeout", 30*time.Second,
+		"how long an alert's command may run before it is killed")
+)
+
+// readOnly refuses the alerts' commands whatever -rules-exec says
+var readOnly bool
+
+// SetReadOnly refuses the alerts' commands, as -read-only does every
+// action changing the host
+func SetReadOnly(on bool) { readOnly = on }
+
+// hookWaitDelay is how long a command's children may hold its output
+// open once it exited or was killed
+const hookWaitDelay = 2 * time.Second
+
+// Hook is the outcome of running an alert's command
+type Hook struct {
+	Alert    string
+	Event    string // alerting.Fired or alerting.Cleared
+	Command  []string
+	Started  time.Time
+	Duration time.Duration
+	Output   string // Standard output and error together, their last tail.Size bytes
+	Err      error
+	AuditErr error // Of writing the command to the audit log
+}
+
+type hookMsg Hook
+
+// command turns an exec argument of alert into the command run: a string
+// is given to the shell, a list of strings is run as it is
+func command(v starlark.Value) ([]string, error) {
+	if s, ok := starlark.AsString(v); ok {
+		if runtime.GOOS == "windows" {
+			return []string{"cmd", "/C", s}, nil
+		}
+		return []string{"/bin/sh", "-c", s}, nil
+	}
+	list, ok := v.(starlark.Indexable)
+	if !ok {
+		return nil, fmt.Errorf("is a %s, want a string or a list of strings", v.Type())
+	}
+	var argv []string
+	for i := range list.Len() {
+		s, ok := starlark.AsString(list.Index(i))
+		if !ok {
+			return nil, fmt.Errorf("holds a %s, want strings", list.Index(i).Type())
+		}
+		argv = append(argv, s)
+	}
+	if len(argv) == 0 {
+		return nil, errors.New("is empty")
+	}
+	return argv, nil
+}
+
+// hookCmd runs the command of an alert that fired or cleared, with the
+// event in ADVIS_ variables of its environment
+func hookCmd(argv []string, e alerting.Event) tea.Cmd {
+	return func() tea.Msg {
+		h := Hook{Alert: e.Source, Event: e.Kind, Command: argv, Started: time.Now()}
+		ctx, cancel := context.WithTimeout(context.Background(), *flagExecTimeout)
+		defer cancel()
+
+		host, _ := os.Hostname()
+		var out tail.Buffer
+		cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+		cmd.Env = append(os.Environ(),
+			"ADVIS_ALERT="+e.Source,
+			"ADVIS_EVENT="+e.Kind,
+			"ADVIS_LEVEL="+e.Level,
+			"ADVIS_MESSAGE="+e.Message,
+			"ADVIS_TIME="+e.Time.Format(time.RFC3339),
+			"ADVIS_HOST="+host,
+		)
+		cmd.Stdout, cmd.Stderr = &out, &out
+		cmd.WaitDelay = hookWaitDelay
+		h.Err = cmd.Run()
+		if ctx.Err() != nil {
+			h.Err = fmt.Errorf("timed out after %v", *flagExecTimeout)
+		}
+		h.Duration = time.Since(h.Started)
+		h.Output = out.String()
+		debug.Timing(fmt.Sprintf("rules: %s command of %s", e.Kind, e.Source), h.Started, h.Err)
+
+		outcome := "done"
+		fields := map[string]string{"action": "exec", "target": e.Source, "command": strings.Join(argv, " "), "event": e.Kind}
+		if h.Err != nil {
+			outcome = "failed"
+			fields["error"] = h.Err.Error()
+		}
+		fields["outcome"] = outcome
+		alerting.Deliver([]alerting.Event{{Time: time.Now(), Kind: alerting.Action, Source: "rules",
+			Message: fmt.Sprintf("%s command of %s: %s", e.Kind, e.Source, outcome), Fields: fields}})
+		// The command may change the host, as the actions of the system
+		// monitor do, so it is logged with them
+		h.AuditErr = audit.Append(audit.New(time.Now(), e.Kind+" command", e.Source+": "+strings.Join(argv, " "), h.Err))
+		return hookMsg(h)
+	}
+}
@@ -0,0 +1,21 @@
+package rules
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// keyMap holds the key bindings of the rules monitor. The key tags are the
+// names the "rules" section of the config file overrides them by.
+type keyMap struct {
+	Quit key.Binding `key:"quit"`
+}
+
+var keys = keyMap{
+	Quit: key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+}
+
+// SetKeys applies the "rules" key overrides of the config file
+func SetKeys(overrides map[string][]string) error {
+	return ui.Rebind(&keys, overrides)
+}
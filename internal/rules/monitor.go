@@ -0,0 +1,461 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/s-archdev/Terminal_ADVIS/internal/alerting"
+	"github.com/s-archdev/Terminal_ADVIS/internal/budget"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+	"github.com/s-archdev/Terminal_ADVIS/internal/demo"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/sysstat"
+)
+
+// Styles, set from the palette
+var titleStyle, headerStyle, dimStyle, warningStyle, criticalStyle, errorStyle lipgloss.Style
+
+func init() { ui.OnPalette(setStyles) }
+
+// setStyles styles the monitor in p's colors, keeping its own for the
+// roles p leaves empty
+func setStyles(p ui.Palette) {
+	titleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ui.ColorOr(p.Title, "#E9C46A")).
+		Background(ui.ColorOr(p.TitleBg, "#282828")).
+		Padding(0, 1)
+
+	headerStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ui.ColorOr(p.Accent, "#06D6A0"))
+
+	dimStyle = lipgloss.NewStyle().
+		Foreground(ui.ColorOr(p.Dim, "#777777"))
+
+	warningStyle = lipgloss.NewStyle().
+		Foreground(ui.ColorOr(p.Warning, "#FBBF24"))
+
+	criticalStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ui.ColorOr(p.AlertFg, "#FFFFFF")).
+		Background(ui.ColorOr(p.AlertBg, "#C0392B"))
+
+	errorStyle = lipgloss.NewStyle().
+		Foreground(ui.ColorOr(p.Critical, "#FF6B6B"))
+}
+
+// tickInterval is how often the rules are evaluated
+const tickInterval = time.Second
+
+// collectTimeout bounds a single collection so a stuck source cannot
+// stall evaluation
+const collectTimeout = 2 * time.Second
+
+type tickMsg time.Time
+
+type collectMsg struct {
+	system  sysstat.Snapshot
+	network netstat.Snapshot
+	err     error
+}
+
+func tickCmd() tea.Cmd {
+	return tea.Tick(budget.Interval(tickInterval), func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+func collectCmd(system sysstat.Collector, network netstat.Collector) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+		defer cancel()
+		start := time.Now()
+		sys, sysErr := system.Collect(ctx)
+		net, netErr := network.Collect(ctx)
+		debug.Timing("rules: collection", start, errors.Join(sysErr, netErr))
+		return collectMsg{system: sys, network: net, err: errors.Join(sysErr, netErr)}
+	}
+}
+
+// model samples the system and network collectors on its own and shows
+// the derived metrics and the alerts firing
+type model struct {
+	path       string
+	rules      *Rules
+	system     sysstat.Collector
+	network    netstat.Collector
+	collecting bool
+	previous   netstat.Snapshot // Last network snapshot, for rates
+	result     Result
+	since      map[string]time.Time  // When each firing alert started
+	firing     map[string]Firing     // The latest of each, for when it clears
+	demo       bool                  // Keeps the made-up alerts from the alerting channels and commands
+	hooks      map[string]Hook       // The last command run of each alert
+	running    map[string]bool       // Alerts whose command is still running
+	queued     map[string]queuedHook // The command of each to run once that exits
+	skipped    map[string]string     // Why an alert's queued command was dropped
+	collectErr error
+	width      int
+	height     int
+}
+
+// New returns the rules monitor for the script in Flags, or nil when
+// there is no script
+func New() (tea.Model, error) {
+	// Rules only see interface counters, never the socket table
+	network := &netstat.System{}
+	network.SkipConnections(true)
+	return load(&sysstat.System{}, network, false)
+}
+
+// NewDemo returns the rules monitor evaluating the script against the
+// demo dataset
+func NewDemo(r *demo.Replay) (tea.Model, error) {
+	return load(r.System(tickInterval), r.Network(tickInterval), true)
+}
+
+func load(system sysstat.Collector, network netstat.Collector, replay bool) (tea.Model, error) {
+	rules, err := Load(*flagScript)
+	if err != nil || rules == nil {
+		return nil, err
+	}
+	return model{
+		path:       *flagScript,
+		rules:      rules,
+		system:     system,
+		network:    network,
+		collecting: true,
+		since:      make(map[string]time.Time),
+		firing:     make(map[string]Firing),
+		demo:       replay,
+		hooks:      make(map[string]Hook),
+		running:    make(map[string]bool),
+		queued:     make(map[string]queuedHook),
+		skipped:    make(map[string]string),
+	}, nil
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(tickCmd(), collectCmd(m.system, m.network))
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.Quit) {
+			return m, tea.Quit
+		}
+
+	case tickMsg:
+		if m.collecting {
+			debug.Logf("rules: tick dropped, collection still running")
+			return m, tickCmd()
+		}
+		m.collecting = true
+		return m, tea.Batch(tickCmd(), collectCmd(m.system, m.network))
+
+	case collectMsg:
+		m.collecting = false
+		m.collectErr = msg.err
+		return m, m.evaluate(msg.system, msg.network)
+
+	case hookMsg:
+		delete(m.running, msg.Alert)
+		m.hooks[msg.Alert] = Hook(msg)
+		if q, ok := m.queued[msg.Alert]; ok {
+			delete(m.queued, msg.Alert)
+			return m, m.runHook(q.argv, q.event)
+		}
+	}
+	return m, nil
+}
+
+// queuedHook is an alert's command waiting for its last one to exit
+type queuedHook struct {
+	argv  []string
+	event alerting.Event
+}
+
+// runHook runs an alert's command for event, or queues it while the
+// alert's last command runs so a fire and clear pair is never left half
+// done. An event undoing the one queued, as a clear after a fire, drops
+// both: the alert is back where the running command leaves it.
+func (m *model) runHook(argv []string, e alerting.Event) tea.Cmd {
+	if !m.running[e.Source] {
+		m.running[e.Source] = true
+		delete(m.skipped, e.Source)
+		return hookCmd(argv, e)
+	}
+	if q, ok := m.queued[e.Source]; ok && q.event.Kind != e.Kind {
+		delete(m.queued, e.Source)
+		m.skipped[e.Source] = fmt.Sprintf("%s %s then %s while a command ran, so neither command run",
+			e.Time.Format("15:04:05"), q.event.Kind, e.Kind)
+		return nil
+	}
+	m.queued[e.Source] = queuedHook{argv, e}
+	return nil
+}
+
+// evaluate runs the rules against a new sample and tracks how long each
+// alert has been firing, notifying of those that started to and handing
+// those that started or stopped to the alerting channels and their
+// commands
+func (m *model) evaluate(system sysstat.Snapshot, network netstat.Snapshot) tea.Cmd {
+	rates := make(map[string][2]float64, len(network.Interfaces))
+	if elapsed := network.Time.Sub(m.previous.Time).Seconds(); !m.previous.Time.IsZero() && elapsed > 0 {
+		last := make(map[string]netstat.Counter, len(m.previous.Interfaces))
+		for _, c := range m.previous.Interfaces {
+			last[c.Name] = c
+		}
+		for _, c := range network.Interfaces {
+			if p, ok := last[c.Name]; ok && c.RxBytes >= p.RxBytes && c.TxBytes >= p.TxBytes {
+				rates[c.Name] = [2]float64{
+					float64(c.RxBytes-p.RxBytes) / elapsed,
+					float64(c.TxBytes-p.TxBytes) / elapsed,
+				}
+			}
+		}
+	}
+	m.previous = network
+
+	start := time.Now()
+	m.result = m.rules.Eval(Sample{System: system, Interfaces: network.Interfaces, Rates: rates})
+	if debug.Enabled() {
+		var errs []error
+		for _, v := range m.result.Metrics {
+			errs = append(errs, v.Err)
+		}
+		debug.Timing("rules: evaluation", start, errors.Join(append(errs, m.result.Errors...)...))
+	}
+
+	var notify tea.Cmd
+	var events []alerting.Event
+	event := func(kind string, a Firing) {
+		events = append(events, alerting.Event{Time: system.Time, Kind: kind, Level: a.Level, Source: a.Name, Message: a.Message, Key: a.Name})
+	}
+	firing := make(map[string]bool, len(m.result.Alerts))
+	for _, a := range m.result.Alerts {
+		firing[a.Name] = true
+		m.firing[a.Name] = a
+		if _, ok := m.since[a.Name]; !ok {
+			m.since[a.Name] = system.Time
+			event(alerting.Fired, a)
+			level := ui.LevelWarning
+			if a.Level == LevelCritical {
+				level = ui.LevelCritical
+			}
+			if notify == nil {
+				notify = ui.Notify(a.Name, level, system.Time)
+			}
+		}
+	}
+	for name := range m.since {
+		if !firing[name] {
+			event(alerting.Cleared, m.firing[name])
+			delete(m.since, name)
+			delete(m.firing, name)
+		}
+	}
+	if m.demo {
+		return notify
+	}
+	alerting.Deliver(events)
+	cmds := []tea.Cmd{notify}
+	for _, e := range events {
+		fired, cleared := m.rules.Commands(e.Source)
+		argv := fired
+		if e.Kind == alerting.Cleared {
+			argv = cleared
+		}
+		if argv != nil && m.execEnabled() {
+			cmds = append(cmds, m.runHook(argv, e))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// execEnabled reports whether the alerts' commands are run
+func (m model) execEnabled() bool {
+	return *flagExec && !readOnly && !m.demo
+}
+
+func (m model) View() string {
+	if m.width == 0 {
+		return "Loading rules..."
+	}
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(ui.Icon("rules")+"Rules") + " " + dimStyle.Render(m.path) + "\n\n")
+
+	content.WriteString(headerStyle.Render(ui.Icon("alerts")+"Alerts") + "\n")
+	content.WriteString(m.renderAlerts(m.width))
+
+	content.WriteString("\n" + headerStyle.Render(ui.Icon("metrics")+"Derived Metrics") + "\n")
+	content.WriteString(m.renderMetrics(m.width))
+
+	if m.rules.HasCommands() {
+		content.WriteString("\n" + headerStyle.Render(ui.Icon("commands")+"Commands") + "\n")
+		content.WriteString(m.renderHooks(m.width))
+	}
+
+	if errs := m.errors(); len(errs) > 0 {
+		content.WriteString("\n" + headerStyle.Render(ui.Icon("errors")+"Errors") + "\n")
+		for _, err := range errs {
+			content.WriteString(errorStyle.Render(ui.Truncate(err.Error(), m.width)) + "\n")
+		}
+	}
+
+	content.WriteString("\n" + dimStyle.Render("Edit the script and restart to reload | "+ui.HelpLine(keys.Quit)))
+	return content.String()
+}
+
+// Panels contributes the alerts and derived metrics to the combined
+// dashboard
+func (m model) Panels(width int) []ui.Panel {
+	body := m.renderAlerts(width) + "\n" + m.renderMetrics(width)
+	if errs := m.errors(); len(errs) > 0 {
+		body += errorStyle.Render(fmt.Sprintf("%d rule errors", len(errs))) + "\n"
+	}
+	return []ui.Panel{{Title: ui.Icon("rules") + "Rules", Body: body, Widget: "rules"}}
+}
+
+func (m model) renderAlerts(width int) string {
+	if len(m.result.Alerts) == 0 {
+		return dimStyle.Render("No alerts firing") + "\n"
+	}
+	var content strings.Builder
+	now := m.previous.Time
+	for _, a := range m.result.Alerts {
+		line := a.Name
+		if a.Message != "" {
+			line += ": " + a.Message
+		}
+		if since, ok := m.since[a.Name]; ok && now.Sub(since) >= time.Second {
+			line += fmt.Sprintf(" (%v)", now.Sub(since).Truncate(time.Second))
+		}
+		if a.Level == LevelCritical {
+			content.WriteString(criticalStyle.Render(ui.Truncate(ui.Icon("critical")+line, width)) + "\n")
+		} else {
+			content.WriteString(warningStyle.Render(ui.Truncate(ui.Icon("warning")+line, width)) + "\n")
+		}
+	}
+	return content.String()
+}
+
+// renderHooks shows the last command run of each alert, its last line of
+// output or its failure, and the commands waiting on one still running or
+// dropped
+func (m model) renderHooks(width int) string {
+	switch {
+	case m.demo:
+		return dimStyle.Render("The demo runs no commands") + "\n"
+	case readOnly:
+		return dimStyle.Render("Read-only: commands are disabled") + "\n"
+	case !*flagExec:
+		return dimStyle.Render("Commands are off; -rules-exec runs them") + "\n"
+	case len(m.hooks) == 0 && len(m.running) == 0 && len(m.skipped) == 0:
+		return dimStyle.Render("No commands run yet") + "\n"
+	}
+	var content strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(m.running)) {
+		line := name + ": running"
+		if q, ok := m.queued[name]; ok {
+			line += fmt.Sprintf(", its %s command queued since %s", q.event.Kind, q.event.Time.Format("15:04:05"))
+		}
+		content.WriteString(ui.Truncate(line, width) + "\n")
+	}
+	for _, name := range slices.Sorted(maps.Keys(m.skipped)) {
+		content.WriteString(warningStyle.Render(ui.Truncate(name+": "+m.skipped[name], width)) + "\n")
+	}
+	for _, name := range slices.Sorted(maps.Keys(m.hooks)) {
+		h := m.hooks[name]
+		line := fmt.Sprintf("%s %s %s: ", h.Started.Format("15:04:05"), h.Alert, h.Event)
+		if h.Err != nil {
+			content.WriteString(errorStyle.Render(ui.Truncate(line+h.Err.Error(), width)) + "\n")
+		} else {
+			content.WriteString(ui.Truncate(line+fmt.Sprintf("done in %v", h.Duration.Round(time.Millisecond)), width) + "\n")
+		}
+		if out := strings.TrimSpace(h.Output); out != "" {
+			content.WriteString(dimStyle.Render(ui.Truncate("  "+out[strings.LastIndex(out, "\n")+1:], width)) + "\n")
+		}
+		if h.AuditErr != nil {
+			content.WriteString(warningStyle.Render(ui.Truncate("  writing the audit log: "+h.AuditErr.Error(), width)) + "\n")
+		}
+	}
+	return content.String()
+}
+
+func (m model) renderMetrics(width int) string {
+	if len(m.result.Metrics) == 0 {
+		return dimStyle.Render("No metrics defined") + "\n"
+	}
+	nameWidth := 0
+	for _, v := range m.result.Metrics {
+		nameWidth = max(nameWidth, ui.Width(v.Name))
+	}
+	nameWidth = min(nameWidth, width/2)
+
+	var content strings.Builder
+	for _, v := range m.result.Metrics {
+		value := "-"
+		if v.Err == nil && !math.IsNaN(v.Value) {
+			value = ui.FormatValue(v.Value, v.Unit)
+		}
+		content.WriteString(fmt.Sprintf("%s %14s\n", ui.Fit(v.Name, nameWidth), value))
+	}
+	return content.String()
+}
+
+// errors lists collection, metric and alert failures from the last
+// evaluation
+func (m model) errors() []error {
+	var errs []error
+	if m.collectErr != nil {
+		errs = append(errs, m.collectErr)
+	}
+	for _, v := range m.result.Metrics {
+		if v.Err != nil {
+			errs = append(errs, v.Err)
+		}
+	}
+	return append(errs, m.result.Errors...)
+}
+
+// Readings describes the script's metrics and firing alerts to the
+// screen reader mode
+func (m model) Readings() []ui.Reading {
+	var r []ui.Reading
+	for _, v := range m.result.Metrics {
+		value := "unknown"
+		if v.Err == nil && !math.IsNaN(v.Value) {
+			value = ui.FormatValue(v.Value, v.Unit)
+		}
+		r = append(r, ui.Reading{Label: "Rule metric " + v.Name, Value: value})
+	}
+	for _, a := range m.result.Alerts {
+		label := "Rule warning"
+		if a.Level == LevelCritical {
+			label = "Rule critical"
+		}
+		value := a.Name
+		if a.Message != "" {
+			value += ", " + a.Message
+		}
+		r = append(r, ui.Reading{Label: label, Value: value})
+	}
+	return r
+}
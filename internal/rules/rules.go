@@ -0,0 +1,401 @@
+// Package rules evaluates a user Starlark script on every sample to derive
+// metrics and raise custom alerts. The script registers them with two
+// builtins:
+//
+//	metric("wan_down", lambda m: m.eth0.down + m.wg0.down, unit="bytes/s")
+//	alert("WAN saturated", lambda m: m.wan_down > 50e6, level="critical")
+//	alert("root filling", lambda m: m.disk["/"] > 90 and "root is %d%% full" % m.disk["/"])
+//
+// m holds the latest sample:
+//
+//	cpu, load           utilization in percent, 1 minute load average
+//	mem_used, mem_total bytes; mem_percent in percent
+//	disk                mount path to percent used
+//	net                 interface name to a struct of down and up in bytes
+//	                    per second and rx and tx byte totals
+//
+// Interfaces are also attributes of m when their name is an identifier,
+// and every metric is an attribute of m for the metrics and alerts defined
+// after it. An alert fires while its function returns a true value; a
+// string is shown as the alert message.
+//
+// A rate hovering at a threshold would fire and clear an alert on every
+// other sample, so an alert may take a separate clear condition, which
+// keeps it firing until that holds, and how long its condition, or its
+// clear condition, must hold before it changes:
+//
+//	alert("WAN saturated", lambda m: m.wan_down > 50e6, clear=lambda m: m.wan_down < 40e6, after="30s", clear_after="2m")
+//
+// With -rules-exec, an alert runs exec as it fires and exec_clear as it
+// clears, a string through the shell or a list as the command and its
+// arguments, the event in ADVIS_ALERT, ADVIS_EVENT, ADVIS_LEVEL,
+// ADVIS_MESSAGE, ADVIS_TIME and ADVIS_HOST:
+//
+//	alert("nginx down", lambda m: m.nginx_up == 0, after="1m", exec=["systemctl", "restart", "nginx"])
+package rules
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/sysstat"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// Flags are the options of the rules monitor, parsed by the advis command
+// before New is called
+var Flags = flag.NewFlagSet("rules", flag.ExitOnError)
+
+var flagScript = Flags.String("rules", DefaultPath(),
+	"Starlark script defining derived metrics and alerts (empty disables rules)")
+
+// maxSteps bounds each metric or alert function so a runaway loop in the
+// script cannot freeze the monitor
+const maxSteps = 100_000
+
+// DefaultPath is advis/rules.star under the user's configuration
+// directory, or empty when that cannot be determined
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "advis", "rules.star")
+}
+
+// Rules are the metrics and alerts registered by a script, in the order
+// it defined them
+type Rules struct {
+	metrics []metricDef
+	alerts  []alertDef
+	state   map[string]*alertState // By alert name
+}
+
+type metricDef struct {
+	name string
+	unit string
+	fn   starlark.Callable
+}
+
+type alertDef struct {
+	name       string
+	level      string
+	fn         starlark.Callable
+	clear      starlark.Callable // Nil to clear when fn stops holding
+	after      time.Duration     // How long fn must hold before the alert fires
+	clearAfter time.Duration     // How long the clear condition must hold before it clears
+	exec       []string          // Run as the alert fires, nil for nothing
+	execClear  []string          // Run as it clears
+}
+
+// alertState is where an alert stands between evaluations
+type alertState struct {
+	firing  bool
+	message string
+	pending time.Time // When the condition to change began to hold, zero while it does not
+}
+
+// Alert levels
+const (
+	LevelWarning  = "warning"
+	LevelCritical = "critical"
+)
+
+// Load runs the script at path to collect its rules. A missing script
+// means no rules rather than an error.
+func Load(path string) (*Rules, error) {
+	if path == "" {
+		return nil, nil
+	}
+	src, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Rules{state: make(map[string]*alertState)}
+	names := make(map[string]bool)
+	alertNames := make(map[string]bool) // Alerts keep their state by name
+	predeclared := starlark.StringDict{
+		"metric": starlark.NewBuiltin("metric", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var def metricDef
+			if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &def.name, "fn", &def.fn, "unit?", &def.unit); err != nil {
+				return nil, err
+			}
+			if !isIdentifier(def.name) {
+				return nil, fmt.Errorf("%s: %q is not an identifier", b.Name(), def.name)
+			}
+			if reserved[def.name] {
+				return nil, fmt.Errorf("%s: %q is a built-in attribute of m", b.Name(), def.name)
+			}
+			if names[def.name] {
+				return nil, fmt.Errorf("%s: %q defined twice", b.Name(), def.name)
+			}
+			names[def.name] = true
+			r.metrics = append(r.metrics, def)
+			return starlark.None, nil
+		}),
+		"alert": starlark.NewBuiltin("alert", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			def := alertDef{level: LevelWarning}
+			var clear, run, runClear starlark.Value = starlark.None, starlark.None, starlark.None
+			var after, clearAfter string
+			if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &def.name, "fn", &def.fn, "level?", &def.level,
+				"clear?", &clear, "after?", &after, "clear_after?", &clearAfter, "exec?", &run, "exec_clear?", &runClear); err != nil {
+				return nil, err
+			}
+			if alertNames[def.name] {
+				return nil, fmt.Errorf("%s: %q defined twice", b.Name(), def.name)
+			}
+			alertNames[def.name] = true
+			if def.level != LevelWarning && def.level != LevelCritical {
+				return nil, fmt.Errorf("%s: level %q, want %q or %q", b.Name(), def.level, LevelWarning, LevelCritical)
+			}
+			if clear != starlark.None {
+				fn, ok := clear.(starlark.Callable)
+				if !ok {
+					return nil, fmt.Errorf("%s: clear is a %s, want a function", b.Name(), clear.Type())
+				}
+				def.clear = fn
+			}
+			for _, d := range []struct {
+				name string
+				s    string
+				to   *time.Duration
+			}{{"after", after, &def.after}, {"clear_after", clearAfter, &def.clearAfter}} {
+				if d.s == "" {
+					continue
+				}
+				v, err := time.ParseDuration(d.s)
+				if err != nil || v < 0 {
+					return nil, fmt.Errorf("%s: %s %q is not a duration such as \"30s\"", b.Name(), d.name, d.s)
+				}
+				*d.to = v
+			}
+			for _, c := range []struct {
+				name string
+				v    starlark.Value
+				to   *[]string
+			}{{"exec", run, &def.exec}, {"exec_clear", runClear, &def.execClear}} {
+				if c.v == starlark.None {
+					continue
+				}
+				argv, err := command(c.v)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %s %w", b.Name(), c.name, err)
+				}
+				*c.to = argv
+			}
+			r.alerts = append(r.alerts, def)
+			return starlark.None, nil
+		}),
+	}
+	thread := &starlark.Thread{Name: "load"}
+	if _, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, path, src, predeclared); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Sample is the data one evaluation sees
+type Sample struct {
+	System     sysstat.Snapshot
+	Interfaces []netstat.Counter
+	Rates      map[string][2]float64 // Interface name to down and up bytes per second
+}
+
+// Value is the result of one derived metric
+type Value struct {
+	Name  string
+	Unit  string
+	Value float64
+	Err   error
+}
+
+// Commands returns the command run as the alert called name fires, or as
+// it clears, nil for none
+func (r *Rules) Commands(name string) (fired, cleared []string) {
+	for _, def := range r.alerts {
+		if def.name == name {
+			return def.exec, def.execClear
+		}
+	}
+	return nil, nil
+}
+
+// HasCommands reports whether any alert runs a command
+func (r *Rules) HasCommands() bool {
+	for _, def := range r.alerts {
+		if def.exec != nil || def.execClear != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Firing is an alert whose condition held
+type Firing struct {
+	Name    string
+	Level   string
+	Message string
+}
+
+// Result is the outcome of evaluating every rule against a sample
+type Result struct {
+	Metrics []Value
+	Alerts  []Firing
+	Errors  []error // Alerts whose function failed
+}
+
+// Eval computes the metrics in order, then checks the alerts. An alert
+// fires once its condition has held for its after duration and clears
+// once its clear condition has held for its clear_after one, so Eval is
+// given the samples in order.
+func (r *Rules) Eval(s Sample) Result {
+	var res Result
+	fields := sampleFields(s)
+
+	for _, def := range r.metrics {
+		v := Value{Name: def.name, Unit: def.unit, Value: math.NaN()}
+		out, err := call(def.fn, fields)
+		if err == nil {
+			if f, ok := starlark.AsFloat(out); ok {
+				v.Value = f
+			} else {
+				err = fmt.Errorf("returned %s, want a number", out.Type())
+			}
+		}
+		if err != nil {
+			v.Err = fmt.Errorf("metric %s: %w", def.name, err)
+		} else {
+			fields[def.name] = starlark.Float(v.Value)
+		}
+		res.Metrics = append(res.Metrics, v)
+	}
+
+	for _, def := range r.alerts {
+		st := r.state[def.name]
+		if st == nil {
+			st = &alertState{}
+			r.state[def.name] = st
+		}
+		if err := st.update(def, fields, s.System.Time); err != nil {
+			res.Errors = append(res.Errors, fmt.Errorf("alert %s: %w", def.name, err))
+		}
+		if st.firing {
+			res.Alerts = append(res.Alerts, Firing{Name: def.name, Level: def.level, Message: st.message})
+		}
+	}
+	return res
+}
+
+// update moves the alert of def on by a sample taken at now. A failing
+// function leaves it where it stands.
+func (st *alertState) update(def alertDef, fields starlark.StringDict, now time.Time) error {
+	out, err := call(def.fn, fields)
+	if err != nil {
+		return err
+	}
+	holds := bool(out.Truth())
+	if msg, ok := starlark.AsString(out); ok && holds {
+		st.message = msg
+	}
+
+	change, wait := holds, def.after
+	if st.firing {
+		change, wait = !holds, def.clearAfter
+		if def.clear != nil {
+			out, err := call(def.clear, fields)
+			if err != nil {
+				return fmt.Errorf("clear: %w", err)
+			}
+			change = bool(out.Truth())
+		}
+	}
+	switch {
+	case !change:
+		st.pending = time.Time{}
+	case st.pending.IsZero() && wait > 0:
+		st.pending = now
+	case now.Sub(st.pending) >= wait:
+		st.firing, st.pending = !st.firing, time.Time{}
+		if !st.firing {
+			st.message = ""
+		}
+	}
+	return nil
+}
+
+// call runs fn with the sample as its only argument on a fresh thread
+func call(fn starlark.Callable, fields starlark.StringDict) (starlark.Value, error) {
+	thread := &starlark.Thread{Name: "eval"}
+	thread.SetMaxExecutionSteps(maxSteps)
+	m := starlarkstruct.FromStringDict(starlarkstruct.Default, fields)
+	return starlark.Call(thread, fn, starlark.Tuple{m}, nil)
+}
+
+// reserved are the attributes of m a metric cannot replace
+var reserved = map[string]bool{
+	"cpu": true, "load": true, "mem_used": true, "mem_total": true,
+	"mem_percent": true, "disk": true, "net": true,
+}
+
+// sampleFields lays the sample out as the attributes of m
+func sampleFields(s Sample) starlark.StringDict {
+	info := s.System.Info
+	memPercent := 0.0
+	if info.MemTotal > 0 {
+		memPercent = float64(info.MemUsed) / float64(info.MemTotal) * 100
+	}
+	fields := starlark.StringDict{
+		"cpu":         starlark.Float(s.System.CPU),
+		"load":        starlark.Float(info.LoadAverage),
+		"mem_used":    starlark.Float(info.MemUsed),
+		"mem_total":   starlark.Float(info.MemTotal),
+		"mem_percent": starlark.Float(memPercent),
+	}
+
+	disk := starlark.NewDict(len(s.System.Mounts))
+	for _, d := range s.System.Mounts {
+		if d.Total > 0 {
+			disk.SetKey(starlark.String(d.Path), starlark.Float(float64(d.Used)/float64(d.Total)*100))
+		}
+	}
+	fields["disk"] = disk
+
+	net := starlark.NewDict(len(s.Interfaces))
+	for _, c := range s.Interfaces {
+		rate := s.Rates[c.Name]
+		iface := starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+			"down": starlark.Float(rate[0]),
+			"up":   starlark.Float(rate[1]),
+			"rx":   starlark.Float(c.RxBytes),
+			"tx":   starlark.Float(c.TxBytes),
+		})
+		net.SetKey(starlark.String(c.Name), iface)
+		if _, taken := fields[c.Name]; !taken && isIdentifier(c.Name) {
+			fields[c.Name] = iface
+		}
+	}
+	fields["net"] = net
+	return fields
+}
+
+// isIdentifier reports whether name can be used as an attribute of m
+func isIdentifier(name string) bool {
+	expr, err := syntax.ParseExpr("", name, 0)
+	if err != nil {
+		return false
+	}
+	_, ok := expr.(*syntax.Ident)
+	return ok
+}
@@ -0,0 +1,195 @@
+package rules
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/sysstat"
+)
+
+// loadScript loads script as a rules file
+func loadScript(t *testing.T, script string) *Rules {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.star")
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// cpuSample is a sample at start+at with the CPU at cpu percent
+func cpuSample(start time.Time, at time.Duration, cpu float64) Sample {
+	return Sample{System: sysstat.Snapshot{Time: start.Add(at), CPU: cpu}}
+}
+
+func TestAlertHysteresis(t *testing.T) {
+	type step struct {
+		at     time.Duration
+		cpu    float64
+		firing bool
+	}
+	tests := []struct {
+		name   string
+		script string
+		steps  []step
+	}{
+		{
+			name:   "fires and clears with its condition",
+			script: `alert("hot", lambda m: m.cpu > 90)`,
+			steps:  []step{{0, 95, true}, {time.Second, 85, false}, {2 * time.Second, 91, true}},
+		},
+		{
+			name:   "clear condition keeps it firing in the band",
+			script: `alert("hot", lambda m: m.cpu > 90, clear=lambda m: m.cpu < 80)`,
+			steps:  []step{{0, 95, true}, {time.Second, 85, true}, {2 * time.Second, 79, false}, {3 * time.Second, 85, false}},
+		},
+		{
+			name:   "after waits for the condition to hold",
+			script: `alert("hot", lambda m: m.cpu > 90, after="10s")`,
+			steps: []step{
+				{0, 95, false}, {5 * time.Second, 95, false}, {6 * time.Second, 50, false},
+				{7 * time.Second, 95, false}, {17 * time.Second, 95, true},
+			},
+		},
+		{
+			name:   "clear_after waits for the clear condition to hold",
+			script: `alert("hot", lambda m: m.cpu > 90, clear=lambda m: m.cpu < 80, clear_after="20s")`,
+			steps: []step{
+				{0, 95, true}, {time.Second, 70, true}, {10 * time.Second, 85, true},
+				{11 * time.Second, 70, true}, {31 * time.Second, 70, false},
+			},
+		},
+	}
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := loadScript(t, tt.script)
+			for _, s := range tt.steps {
+				res := r.Eval(cpuSample(start, s.at, s.cpu))
+				if len(res.Errors) > 0 {
+					t.Fatalf("at %v: %v", s.at, res.Errors)
+				}
+				if firing := len(res.Alerts) > 0; firing != s.firing {
+					t.Errorf("at %v with CPU at %.0f%%: firing = %v, want %v", s.at, s.cpu, firing, s.firing)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{"alert defined twice", "alert(\"a\", lambda m: True)\nalert(\"a\", lambda m: False)"},
+		{"unknown level", `alert("a", lambda m: True, level="fatal")`},
+		{"invalid after", `alert("a", lambda m: True, after="soon")`},
+		{"negative clear_after", `alert("a", lambda m: True, clear_after="-1s")`},
+		{"clear not a function", `alert("a", lambda m: True, clear=1)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.star")
+			if err := os.WriteFile(path, []byte(tt.script), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(path); err == nil {
+				t.Error("Load succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	sample := Sample{
+		System: sysstat.Snapshot{
+			CPU:    42,
+			Info:   sysstat.Info{LoadAverage: 1.5, MemUsed: 1 << 30, MemTotal: 4 << 30},
+			Mounts: []sysstat.Disk{{Path: "/", Total: 100, Used: 93}, {Path: "/boot", Total: 100, Used: 20}},
+		},
+		Interfaces: []netstat.Counter{{Name: "eth0", RxBytes: 1000, TxBytes: 500}, {Name: "wg-vpn", RxBytes: 10}},
+		Rates:      map[string][2]float64{"eth0": {300, 100}, "wg-vpn": {5, 1}},
+	}
+	type metric struct {
+		name  string
+		value float64 // NaN for a failed metric
+	}
+	tests := []struct {
+		name    string
+		script  string
+		metrics []metric
+		alerts  []Firing
+		errors  int
+	}{
+		{
+			name:    "sample attributes",
+			script:  "metric(\"mem\", lambda m: m.mem_percent)\nmetric(\"io\", lambda m: m.eth0.down + m.net[\"wg-vpn\"].up)\nmetric(\"total\", lambda m: m.eth0.rx + m.eth0.tx + getattr(m, \"load\"))",
+			metrics: []metric{{"mem", 25}, {"io", 301}, {"total", 1501.5}},
+		},
+		{
+			name:    "metrics seen by later rules",
+			script:  "metric(\"busy\", lambda m: m.cpu * 2)\nalert(\"busy\", lambda m: m.busy > 80, level=\"critical\")",
+			metrics: []metric{{"busy", 84}},
+			alerts:  []Firing{{Name: "busy", Level: LevelCritical}},
+		},
+		{
+			name:   "message from the alert function",
+			script: `alert("root filling", lambda m: m.disk["/"] > 90 and "root is %d%% full" % m.disk["/"])`,
+			alerts: []Firing{{Name: "root filling", Level: LevelWarning, Message: "root is 93% full"}},
+		},
+		{
+			name:   "quiet alert",
+			script: `alert("boot filling", lambda m: m.disk["/boot"] > 90)`,
+		},
+		{
+			name:    "failing metric",
+			script:  "metric(\"bad\", lambda m: m.nope)\nmetric(\"text\", lambda m: \"high\")",
+			metrics: []metric{{"bad", math.NaN()}, {"text", math.NaN()}},
+		},
+		{
+			name:   "failing alert",
+			script: `alert("bad", lambda m: m.disk["/srv"] > 90)`,
+			errors: 1,
+		},
+		{
+			name:   "runaway loop stopped",
+			script: "def spin(m):\n    n = 0\n    for i in range(1000000):\n        n += i\n    return n > 0\nalert(\"spin\", spin)",
+			errors: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := loadScript(t, tt.script).Eval(sample)
+			if len(res.Metrics) != len(tt.metrics) {
+				t.Fatalf("got %d metrics, want %d", len(res.Metrics), len(tt.metrics))
+			}
+			for i, want := range tt.metrics {
+				got := res.Metrics[i]
+				failed := math.IsNaN(want.value)
+				if got.Name != want.name || (got.Err != nil) != failed || !failed && got.Value != want.value {
+					t.Errorf("metric %d = %s %v (error %v), want %s %v", i, got.Name, got.Value, got.Err, want.name, want.value)
+				}
+			}
+			if len(res.Alerts) != len(tt.alerts) {
+				t.Fatalf("alerts = %+v, want %+v", res.Alerts, tt.alerts)
+			}
+			for i, want := range tt.alerts {
+				if res.Alerts[i] != want {
+					t.Errorf("alert %d = %+v, want %+v", i, res.Alerts[i], want)
+				}
+			}
+			if len(res.Errors) != tt.errors {
+				t.Errorf("errors = %v, want %d", res.Errors, tt.errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Package server is what every listening mode of advis serves through, so
+// each is secured the same way: a bearer token, TLS with the certificate
+// of the config file's "listen" section, and the loopback interface unless
+// an address says otherwise. An address on another interface is refused
+// without a token, since the figures tell a lot about a host.
+package server
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+)
+
+// Options are the config file's "listen" section
+type Options struct {
+	Token     string `json:"token"`      // Bearer token clients must send
+	TokenFile string `json:"token_file"` // File holding the token, to keep it out of the config
+	TLSCert   string `json:"tls_cert"`   // PEM certificate chain; with TLSKey serves HTTPS
+	TLSKey    string `json:"tls_key"`
+}
+
+var (
+	mu      sync.Mutex
+	token   string
+	tlsConf *tls.Config
+)
+
+// Configure applies the config file's "listen" section to the listeners
+// started after it
+func Configure(o Options) error {
+	t := o.Token
+	if o.TokenFile != "" {
+		if t != "" {
+			return errors.New("listen: token and token_file are both set")
+		}
+		data, err := os.ReadFile(o.TokenFile)
+		if err != nil {
+			return fmt.Errorf("listen: %w", err)
+		}
+		if t = strings.TrimSpace(string(data)); t == "" {
+			return fmt.Errorf("listen: %s is empty", o.TokenFile)
+		}
+	}
+	var conf *tls.Config
+	switch {
+	case (o.TLSCert == "") != (o.TLSKey == ""):
+		return errors.New("listen: tls_cert and tls_key go together")
+	case o.TLSCert != "":
+		cert, err := tls.LoadX509KeyPair(o.TLSCert, o.TLSKey)
+		if err != nil {
+			return fmt.Errorf("listen: %w", err)
+		}
+		conf = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	token, tlsConf = t, conf
+	return nil
+}
+
+// Serve serves h on addr in the background for the mode called name. An
+// address without a host, such as ":9100", binds the loopback interface;
+// any other host needs a token. The error is for starting; later ones
+// go to the debug log.
+func Serve(name, addr string, h http.Handler) (net.Addr, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	mu.Lock()
+	t, conf := token, tlsConf
+	mu.Unlock()
+	if t == "" && !isLoopback(host) {
+		return nil, fmt.Errorf("%s is not a loopback address; set a token in the config file's \"listen\" to serve on it", host)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, err
+	}
+	if conf != nil {
+		listener = tls.NewListener(listener, conf)
+	}
+	if t != "" {
+		h = requireToken(t, h)
+	}
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 10 * time.Second}
+	debug.Logf("%s listening on %s (TLS %v, token %v)", name, listener.Addr(), conf != nil, t != "")
+	go func() {
+		err := srv.Serve(listener)
+		debug.Logf("%s stopped: %v", name, err)
+	}()
+	return listener.Addr(), nil
+}
+
+// isLoopback reports whether host names the loopback interface
+func isLoopback(host string) bool {
+	ip := net.ParseIP(host)
+	return host == "localhost" || ip != nil && ip.IsLoopback()
+}
+
+// requireToken passes on the requests bearing token and turns away the rest
+func requireToken(token string, h http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="advis"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
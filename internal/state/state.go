@@ -0,0 +1,131 @@
+// Package state carries a session of the monitors over to the next: the
+// day's totals and peaks, the alert history, the tabs and the dashboard
+// layout on screen. Each monitor saves its own section of state.json in
+// the data directory when advis quits and restores it when it starts,
+// unless -fresh is given.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Flags holds whether to restore the last session, parsed by the advis
+// command before the monitors are created
+var Flags = flag.NewFlagSet("state", flag.ExitOnError)
+
+var flagFresh = Flags.Bool("fresh", false,
+	"start without the totals, peaks, alerts, tabs and layout saved by the last session")
+
+// Keeper is a monitor with state to carry over to the next session
+type Keeper interface {
+	// KeepState returns the sections to save, by name
+	KeepState() map[string]any
+}
+
+// file is the content of state.json
+type file struct {
+	Saved    time.Time                  `json:"saved"`
+	Sections map[string]json.RawMessage `json:"sections"`
+}
+
+// Dir is the data directory, where the history and the state are kept:
+// advis under XDG_STATE_HOME, or ~/.local/state/advis
+func Dir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "advis")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "advis")
+	}
+	return filepath.Join(home, ".local", "state", "advis")
+}
+
+// Path is the state file in Dir
+func Path() string {
+	return filepath.Join(Dir(), "state.json")
+}
+
+// last is the state file as advis started, read on first use
+var last = sync.OnceValues(func() (file, error) {
+	return read(Path())
+})
+
+func read(path string) (file, error) {
+	var f file
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return f, err
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// Restore decodes the section called name of the last session into v and
+// returns when that session saved it. It returns false with -fresh, or
+// when there is no such section or it does not decode.
+func Restore(name string, v any) (time.Time, bool) {
+	if *flagFresh {
+		return time.Time{}, false
+	}
+	f, err := last()
+	raw, ok := f.Sections[name]
+	if err != nil || !ok || json.Unmarshal(raw, v) != nil {
+		return time.Time{}, false
+	}
+	return f.Saved, true
+}
+
+// SameDay reports whether saved was earlier today, which the day's totals
+// and peaks are kept for
+func SameDay(saved time.Time) bool {
+	y1, m1, d1 := saved.Local().Date()
+	y2, m2, d2 := time.Now().Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
+
+// Save writes sections over those of the same name in the state file,
+// keeping the sections of monitors that did not run this session
+func Save(sections map[string]any) error {
+	path := Path()
+	f, err := read(path)
+	if err != nil {
+		f = file{} // A damaged file is replaced
+	}
+	if f.Sections == nil {
+		f.Sections = make(map[string]json.RawMessage)
+	}
+	for name, v := range sections {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		f.Sections[name] = raw
+	}
+	f.Saved = time.Now()
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// Written aside and renamed, so a crash leaves the last state whole
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
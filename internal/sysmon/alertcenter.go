@@ -0,0 +1,303 @@
+package sysmon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/alerting"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// maxAlertEvents bounds the alert center's list; the history files keep
+// everything
+const maxAlertEvents = 500
+
+var flagAlertSilence = Flags.Duration("alert-silence", 30*time.Minute,
+	"how long silencing an alert source in the alert center lasts")
+
+// alertEvent is one alert from when it fired until it cleared
+type alertEvent struct {
+	Alert
+	Fired   time.Time
+	Cleared time.Time // Zero while active
+	Acked   bool
+}
+
+// alertCenter keeps every alert fired this session for review, the ones
+// acknowledged, which keep quiet until they clear, and the sources
+// silenced for a while. It is shared by the copies Update makes.
+type alertCenter struct {
+	events   []*alertEvent // Oldest first
+	active   map[string]*alertEvent
+	silenced map[string]time.Time // Source to the end of its silence
+	open     bool
+	cursor   int // Index into the newest-first list
+}
+
+func newAlertCenter() *alertCenter {
+	return &alertCenter{active: make(map[string]*alertEvent), silenced: make(map[string]time.Time)}
+}
+
+// alertKey identifies an alert across ticks, by its message for those of
+// snapshots taken before alerts had a subject
+func alertKey(a Alert) string {
+	if a.Subject == "" {
+		return a.Source + "\x00\x00" + a.Message
+	}
+	return a.Source + "\x00" + a.Subject
+}
+
+// alertRecord is a line of the alert history files
+type alertRecord struct {
+	Time    time.Time `json:"time"`
+	Event   string    `json:"event"` // fired, cleared, acked, silenced or unsilenced
+	Level   string    `json:"level,omitempty"`
+	Source  string    `json:"source"`
+	Subject string    `json:"subject,omitempty"`
+	Message string    `json:"message,omitempty"`
+}
+
+// observe updates the events from the alerts active at now and returns
+// the history records of what changed
+func (c *alertCenter) observe(now time.Time, alerts []Alert) []alertRecord {
+	var records []alertRecord
+	seen := make(map[string]bool, len(alerts))
+	for _, a := range alerts {
+		k := alertKey(a)
+		seen[k] = true
+		if e, ok := c.active[k]; ok {
+			if a.Level > e.Level {
+				// Fired anew at its new level, which channels that only
+				// take critical alerts wait for, and asking again for the
+				// attention an acknowledgement put off
+				e.Acked = false
+				records = append(records, recordOf(now, "fired", a))
+			}
+			e.Alert = a // Keep the latest figures
+			continue
+		}
+		e := &alertEvent{Alert: a, Fired: now}
+		c.active[k] = e
+		c.events = append(c.events, e)
+		records = append(records, recordOf(now, "fired", a))
+	}
+	for k, e := range c.active {
+		if !seen[k] {
+			e.Cleared = now
+			delete(c.active, k)
+			records = append(records, recordOf(now, "cleared", e.Alert))
+		}
+	}
+	if extra := len(c.events) - maxAlertEvents; extra > 0 {
+		c.events = slices.Delete(c.events, 0, extra)
+	}
+	for source, until := range c.silenced {
+		if now.After(until) {
+			delete(c.silenced, source)
+		}
+	}
+	return records
+}
+
+// recordOf is the history record of an event on a
+func recordOf(now time.Time, event string, a Alert) alertRecord {
+	level := "warning"
+	if a.Level == alertCritical {
+		level = "critical"
+	}
+	return alertRecord{Time: now, Event: event, Level: level, Source: a.Source, Subject: a.Subject, Message: a.Message}
+}
+
+// quiet reports whether a needs no attention: acknowledged, or from a
+// silenced source
+func (c *alertCenter) quiet(a Alert, now time.Time) bool {
+	if until, ok := c.silenced[a.Source]; ok && now.Before(until) {
+		return true
+	}
+	e, ok := c.active[alertKey(a)]
+	return ok && e.Acked
+}
+
+// newestFirst is the list the center shows
+func (c *alertCenter) newestFirst() []*alertEvent {
+	list := slices.Clone(c.events)
+	slices.Reverse(list)
+	return list
+}
+
+// alertsFile is the history file of a day's alert events, next to the
+// snapshots
+func alertsFile(day time.Time) string {
+	return filepath.Join(historyDir(), "alerts-"+day.Format("2006-01-02")+".jsonl")
+}
+
+// alertsPersistedMsg reports a failed write of the alert history
+type alertsPersistedMsg struct{ err error }
+
+// persistAlertsCmd appends records to their day's alert history
+func persistAlertsCmd(records []alertRecord) tea.Cmd {
+	if len(records) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := os.MkdirAll(historyDir(), 0o755); err != nil {
+			return alertsPersistedMsg{err}
+		}
+		file, err := os.OpenFile(alertsFile(records[0].Time), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return alertsPersistedMsg{err}
+		}
+		defer file.Close()
+		enc := json.NewEncoder(file)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return alertsPersistedMsg{err}
+			}
+		}
+		return alertsPersistedMsg{}
+	}
+}
+
+// observeAlerts feeds the tick's alerts to the center, notifies of those
+// that fired and hands those that fired or cleared to the alerting
+// channels. The demo's alerts are made up, so they stay out of the history
+// and the channels.
+func (m model) observeAlerts() tea.Cmd {
+	records := m.center.observe(m.lastTick, m.alerts)
+	notify := notifyCmd(records)
+	if m.source == "demo" {
+		return notify
+	}
+	deliverAlerts(records)
+	return tea.Batch(notify, persistAlertsCmd(records))
+}
+
+// deliverAlerts hands the records of alerts that fired or cleared to the
+// alerting channels
+func deliverAlerts(records []alertRecord) {
+	var events []alerting.Event
+	for _, r := range records {
+		if r.Event == "fired" || r.Event == "cleared" {
+			events = append(events, alerting.Event{Time: r.Time, Kind: r.Event, Level: r.Level, Source: r.Source, Message: r.Message,
+				Key: alertKey(Alert{Source: r.Source, Subject: r.Subject, Message: r.Message})})
+		}
+	}
+	alerting.Deliver(events)
+}
+
+// notifyCmd rings or flashes once for the alerts of records that fired,
+// however many did at once
+func notifyCmd(records []alertRecord) tea.Cmd {
+	for _, r := range records {
+		if r.Event != "fired" {
+			continue
+		}
+		level := ui.LevelWarning
+		if r.Level == "critical" {
+			level = ui.LevelCritical
+		}
+		if cmd := ui.Notify(r.Source, level, r.Time); cmd != nil {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// attention is the active alerts neither acknowledged nor silenced, those
+// the banner and the status bar show
+func (m model) attention() []Alert {
+	now := time.Now()
+	return slices.DeleteFunc(slices.Clone(m.alerts), func(a Alert) bool { return m.center.quiet(a, now) })
+}
+
+// updateAlertCenterKeys handles the keys of the open alert center
+func (m model) updateAlertCenterKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := m.center
+	list := c.newestFirst()
+	switch {
+	case key.Matches(msg, keys.Quit):
+		m.journal.stop()
+		return m, tea.Quit
+	case key.Matches(msg, keys.AlertCenter, keys.Dismiss):
+		c.open = false
+	case keys.Moves(msg):
+		c.cursor = keys.Move(msg, c.cursor, len(list), m.height)
+	case key.Matches(msg, keys.Ack) && c.cursor < len(list):
+		if e := list[c.cursor]; e.Cleared.IsZero() && !e.Acked {
+			e.Acked = true
+			return m, m.persistAction("acked", e.Alert)
+		}
+	case key.Matches(msg, keys.Silence) && c.cursor < len(list):
+		e := list[c.cursor]
+		if _, ok := c.silenced[e.Source]; ok {
+			delete(c.silenced, e.Source)
+			return m, m.persistAction("unsilenced", e.Alert)
+		}
+		c.silenced[e.Source] = time.Now().Add(*flagAlertSilence)
+		return m, m.persistAction("silenced", e.Alert)
+	}
+	return m, nil
+}
+
+// persistAction records an acknowledgement or silence in the history
+func (m model) persistAction(event string, a Alert) tea.Cmd {
+	if m.source == "demo" {
+		return nil
+	}
+	return persistAlertsCmd([]alertRecord{recordOf(time.Now(), event, a)})
+}
+
+// renderAlertCenter lists every alert of the session, newest first
+func (m model) renderAlertCenter() string {
+	c := m.center
+	var b strings.Builder
+	b.WriteString(headerStyle.Render(ui.Icon("bell")+"Alert Center") + "\n\n")
+	now := time.Now()
+	var silenced []string
+	for source, until := range c.silenced {
+		silenced = append(silenced, fmt.Sprintf("%s for %s", source, until.Sub(now).Round(time.Minute)))
+	}
+	if len(silenced) > 0 {
+		slices.Sort(silenced)
+		b.WriteString(dimStyle.Render("Silenced: "+strings.Join(silenced, ", ")) + "\n\n")
+	}
+
+	list := c.newestFirst()
+	if len(list) == 0 {
+		b.WriteString(infoStyle.Render("No alerts fired since advis started") + "\n")
+	}
+	rows := max(m.height-12, 3)
+	start := max(min(c.cursor-rows/2, len(list)-rows), 0)
+	for i, e := range list[start:min(start+rows, len(list))] {
+		state := "active"
+		switch {
+		case !e.Cleared.IsZero():
+			state = "cleared " + e.Cleared.Format("15:04:05")
+		case e.Acked:
+			state = "acked"
+		}
+		icon, style := ui.Icon("warning"), infoStyle
+		if e.Level == alertCritical {
+			icon, style = ui.Icon("critical"), alertStyle
+		}
+		if !e.Cleared.IsZero() || e.Acked {
+			style = dimStyle
+		}
+		line := fmt.Sprintf("%s %s%s %-19s %s", e.Fired.Format("15:04:05"), icon, ui.Fit(e.Source, 10), state, e.Message)
+		line = ui.Truncate(line, max(m.width-2, 10))
+		if start+i == c.cursor {
+			b.WriteString(headerStyle.Render("▶ "+line) + "\n")
+		} else {
+			b.WriteString("  " + style.Render(line) + "\n")
+		}
+	}
+	b.WriteString("\n" + infoStyle.Render(ui.HelpLine(keys.Up, keys.Down, keys.Ack, keys.Silence, keys.AlertCenter)) + "\n")
+	return b.String()
+}
@@ -0,0 +1,76 @@
+package sysmon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/alerting"
+)
+
+func TestAlertCenterKeepsSubjectsApart(t *testing.T) {
+	c := newAlertCenter()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	alerts := []Alert{
+		{Level: alertWarning, Source: "fds", Subject: "1234", Message: "nginx (1234) has 900 of 1024 file descriptors open"},
+		{Level: alertWarning, Source: "fds", Subject: "5678", Message: "nginx (5678) has 950 of 1024 file descriptors open"},
+	}
+	if records := c.observe(now, alerts); len(records) != 2 {
+		t.Fatalf("first tick recorded %v, want both alerts fired", records)
+	}
+
+	// The figures change while the alerts stay the same ones
+	alerts[0].Message = "nginx (1234) has 910 of 1024 file descriptors open"
+	if records := c.observe(now.Add(time.Second), alerts); len(records) != 0 {
+		t.Errorf("second tick recorded %v, want nothing", records)
+	}
+	records := c.observe(now.Add(2*time.Second), alerts[1:])
+	if len(records) != 1 || records[0].Event != "cleared" || records[0].Subject != "1234" {
+		t.Errorf("third tick recorded %v, want 1234 cleared", records)
+	}
+}
+
+func TestAlertEscalationReachesIncidents(t *testing.T) {
+	var mu sync.Mutex
+	var severities []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Action  string `json:"event_action"`
+			Payload struct {
+				Severity string `json:"severity"`
+			} `json:"payload"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		mu.Lock()
+		severities = append(severities, body.Action+" "+body.Payload.Severity)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+	if err := alerting.Configure(alerting.Config{PagerDuty: &alerting.PagerDuty{RoutingKey: "key", URL: srv.URL}}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newAlertCenter()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := Alert{Level: alertWarning, Source: "fds", Subject: "1234", Message: "nginx (1234) has 900 of 1024 file descriptors open"}
+	deliverAlerts(c.observe(now, []Alert{a}))
+	a.Level, a.Message = alertCritical, "nginx (1234) has 1000 of 1024 file descriptors open"
+	records := c.observe(now.Add(time.Second), []Alert{a})
+	if len(records) != 1 || records[0].Event != "fired" || records[0].Level != "critical" {
+		t.Errorf("escalation recorded %v, want it fired at critical", records)
+	}
+	deliverAlerts(records)
+	alerting.Close(5 * time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(severities) != 1 || severities[0] != "trigger critical" {
+		t.Errorf("pagerduty received %v, want one critical trigger", severities)
+	}
+}
@@ -0,0 +1,177 @@
+package sysmon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/server"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/proc"
+)
+
+var flagAPI = Flags.String("api", "",
+	`serve the REST API on this address, such as ":9273" for the loopback interface; see the config file's "listen"`)
+
+// maxHistoryRange bounds the history an interface query reads
+const maxHistoryRange = 31 * 24 * time.Hour
+
+// published is what the API serves: the latest figures of the running
+// monitor or of the snapshot collector
+type published struct {
+	snap  Snapshot
+	procs []proc.Process
+}
+
+var (
+	latest  atomic.Pointer[published]
+	serving atomic.Bool // Worth publishing to
+)
+
+// publish hands the figures to the API, when it is served
+func publish(snap Snapshot, procs []proc.Process) {
+	if serving.Load() {
+		latest.Store(&published{snap: snap, procs: procs})
+	}
+}
+
+// publish hands the monitor's figures to the API, when it is served
+func (m model) publish() {
+	if serving.Load() {
+		publish(m.snapshot(), slices.Clone(m.procs))
+	}
+}
+
+// ServeAPI serves the REST API on the -api address, when given
+func ServeAPI() error {
+	return serveAPI(*flagAPI)
+}
+
+// serveAPI serves the REST API on addr, unless it is empty:
+//
+//	GET /api/v1/snapshot                            the latest snapshot, with the network counters as of the request
+//	GET /api/v1/processes?sort=cpu&limit=20         every process, or the top ones by cpu or memory
+//	GET /api/v1/interfaces/{name}/history?range=1h  the persisted counters and rates of an interface
+//
+// The history comes from the snapshots "advis snapshot" keeps, such as
+// the service "advis install" sets up.
+func serveAPI(addr string) error {
+	if addr == "" {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/snapshot", apiSnapshot)
+	mux.HandleFunc("GET /api/v1/processes", apiProcesses)
+	mux.HandleFunc("GET /api/v1/interfaces/{name}/history", apiHistory)
+	serving.Store(true)
+	_, err := server.Serve("api", addr, mux)
+	return err
+}
+
+func apiSnapshot(w http.ResponseWriter, r *http.Request) {
+	p := latest.Load()
+	if p == nil {
+		http.Error(w, "no sample yet", http.StatusServiceUnavailable)
+		return
+	}
+	snap := p.snap
+	snap.Network = netstat.Interfaces()
+	writeJSON(w, snap)
+}
+
+func apiProcesses(w http.ResponseWriter, r *http.Request) {
+	p := latest.Load()
+	if p == nil {
+		http.Error(w, "no sample yet", http.StatusServiceUnavailable)
+		return
+	}
+	procs := slices.Clone(p.procs)
+	switch r.FormValue("sort") {
+	case "":
+	case "cpu":
+		sortProcesses(procs, sortByCPU)
+	case "memory":
+		sortProcesses(procs, sortByMemory)
+	default:
+		http.Error(w, "sort is cpu or memory", http.StatusBadRequest)
+		return
+	}
+	if s := r.FormValue("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			http.Error(w, "limit is a count of processes", http.StatusBadRequest)
+			return
+		}
+		procs = procs[:min(n, len(procs))]
+	}
+	writeJSON(w, procs)
+}
+
+// historyPoint is an interface's counters in one persisted snapshot, with
+// the rates since the one before
+type historyPoint struct {
+	Time    time.Time `json:"time"`
+	RxBytes uint64    `json:"rx_bytes"`
+	TxBytes uint64    `json:"tx_bytes"`
+	RxRate  float64   `json:"rx_bytes_per_second"`
+	TxRate  float64   `json:"tx_bytes_per_second"`
+}
+
+func apiHistory(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	span := time.Hour
+	if s := r.FormValue("range"); s != "" {
+		var err error
+		if span, err = time.ParseDuration(s); err != nil || span <= 0 || span > maxHistoryRange {
+			http.Error(w, "range is a duration such as 1h, up to 31 days", http.StatusBadRequest)
+			return
+		}
+	}
+	points, err := interfaceHistory(name, time.Now().Add(-span))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if points == nil {
+		http.Error(w, fmt.Sprintf("no history of %s", name), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, points)
+}
+
+// interfaceHistory reads the counters of the interface called name from
+// the snapshots persisted since from
+func interfaceHistory(name string, from time.Time) ([]historyPoint, error) {
+	var points []historyPoint
+	var prev *historyPoint
+	err := eachSnapshot(from, time.Now(), func(snap *Snapshot) {
+		for _, c := range snap.Network {
+			if c.Name != name {
+				continue
+			}
+			p := historyPoint{Time: snap.Time, RxBytes: c.RxBytes, TxBytes: c.TxBytes}
+			// A counter going back is a reboot or a reset, not a rate
+			if prev != nil && p.Time.After(prev.Time) && p.RxBytes >= prev.RxBytes && p.TxBytes >= prev.TxBytes {
+				elapsed := p.Time.Sub(prev.Time).Seconds()
+				p.RxRate = float64(p.RxBytes-prev.RxBytes) / elapsed
+				p.TxRate = float64(p.TxBytes-prev.TxBytes) / elapsed
+			}
+			prev = &p
+			if !snap.Time.Before(from) {
+				points = append(points, p)
+			}
+		}
+	})
+	return points, err
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
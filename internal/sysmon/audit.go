@@ -0,0 +1,171 @@
+package sysmon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/alerting"
+	"github.com/s-archdev/Terminal_ADVIS/internal/audit"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// maxAuditEntries bounds the entries the action log overlay reads back;
+// the file keeps everything
+const maxAuditEntries = 500
+
+// auditLog is the action log overlay, shared by the copies Update makes
+type auditLog struct {
+	open    bool
+	cursor  int           // Index into the newest-first list
+	entries []audit.Entry // Oldest first
+	err     error         // Of reading the file
+}
+
+// auditMsg carries an entry written to the audit log
+type auditMsg struct {
+	entry audit.Entry
+	err   error
+}
+
+// auditLoadedMsg carries the audit log read back for the overlay
+type auditLoadedMsg struct {
+	entries []audit.Entry
+	err     error
+}
+
+// auditCmd appends the outcome of action on target, rerun as root or not,
+// to the audit log, and hands it to the alerting channels that log
+// actions. The demo acts on nothing, so it records nothing.
+func (m model) auditCmd(action, target string, result error, asRoot bool) tea.Cmd {
+	if m.source == "demo" {
+		return nil
+	}
+	if asRoot {
+		action += " as root"
+	}
+	e := audit.New(time.Now(), action, target, result)
+	msg := fmt.Sprintf("%s %s: %s", e.Action, e.Target, e.Outcome)
+	if e.Error != "" {
+		msg += ": " + e.Error
+	}
+	alerting.Deliver([]alerting.Event{{Time: e.Time, Kind: alerting.Action, Source: "sys", Message: msg,
+		Fields: map[string]string{"user": e.User, "action": e.Action, "target": e.Target, "outcome": e.Outcome}}})
+	return appendAuditCmd(e)
+}
+
+// appendAuditCmd appends e to the audit log
+func appendAuditCmd(e audit.Entry) tea.Cmd {
+	return func() tea.Msg {
+		return auditMsg{e, audit.Append(e)}
+	}
+}
+
+// loadAuditCmd reads back the latest entries of the audit log
+func loadAuditCmd() tea.Cmd {
+	return func() tea.Msg {
+		file, err := os.Open(audit.File())
+		if errors.Is(err, fs.ErrNotExist) {
+			return auditLoadedMsg{}
+		}
+		if err != nil {
+			return auditLoadedMsg{err: err}
+		}
+		defer file.Close()
+		var entries []audit.Entry
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var e audit.Entry
+			if json.Unmarshal(scanner.Bytes(), &e) == nil {
+				entries = append(entries, e)
+			}
+		}
+		if extra := len(entries) - maxAuditEntries; extra > 0 {
+			entries = slices.Delete(entries, 0, extra)
+		}
+		return auditLoadedMsg{entries: entries, err: scanner.Err()}
+	}
+}
+
+// newestFirst is the list the overlay shows
+func (a *auditLog) newestFirst() []audit.Entry {
+	list := slices.Clone(a.entries)
+	slices.Reverse(list)
+	return list
+}
+
+// export is the log as tab separated lines with a header, for pasting
+// into a spreadsheet or a ticket
+func (a *auditLog) export() string {
+	var b strings.Builder
+	b.WriteString("time\tuser\taction\ttarget\toutcome\terror\n")
+	for _, e := range a.entries {
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.Format(time.RFC3339), e.User, e.Action, e.Target, e.Outcome, e.Error)
+	}
+	return b.String()
+}
+
+// updateAuditKeys handles the keys of the open action log
+func (m model) updateAuditKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	a := m.audit
+	switch {
+	case key.Matches(msg, keys.Quit):
+		m.journal.stop()
+		return m, tea.Quit
+	case key.Matches(msg, keys.AuditLog, keys.Dismiss):
+		a.open = false
+	case keys.Moves(msg):
+		a.cursor = keys.Move(msg, a.cursor, len(a.entries), m.height)
+	case key.Matches(msg, keys.CopyTable):
+		return m, ui.Copy(a.export(), "action log")
+	}
+	return m, nil
+}
+
+// renderAuditLog lists the actions taken from the monitor, newest first
+func (m model) renderAuditLog() string {
+	a := m.audit
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("Action Log") + "\n")
+	b.WriteString(dimStyle.Render("Kept in "+audit.File()) + "\n\n")
+	if a.err != nil {
+		b.WriteString(alertStyle.Render("Reading the log: "+a.err.Error()) + "\n")
+	}
+	list := a.newestFirst()
+	if len(list) == 0 {
+		b.WriteString(infoStyle.Render("No actions taken yet") + "\n")
+	}
+	rows := max(m.height-12, 3)
+	start := max(min(a.cursor-rows/2, len(list)-rows), 0)
+	for i, e := range list[start:min(start+rows, len(list))] {
+		style := infoStyle
+		outcome := e.Outcome
+		if e.Error != "" {
+			style, outcome = alertStyle, outcome+": "+e.Error
+		}
+		line := fmt.Sprintf("%s %-10s %-18s %s  %s", e.Time.Format("2006-01-02 15:04:05"), ui.Fit(e.User, 10), e.Action, e.Target, outcome)
+		line = ui.Truncate(line, max(m.width-2, 10))
+		if start+i == a.cursor {
+			b.WriteString(headerStyle.Render("▶ "+line) + "\n")
+		} else {
+			b.WriteString("  " + style.Render(line) + "\n")
+		}
+	}
+	b.WriteString("\n" + infoStyle.Render(ui.HelpLine(keys.Up, keys.Down, exportAudit(), keys.AuditLog)) + "\n")
+	return b.String()
+}
+
+// exportAudit is the copy binding as the action log offers it
+func exportAudit() key.Binding {
+	b := keys.CopyTable
+	b.SetHelp(b.Help().Key, "copy log")
+	return b
+}
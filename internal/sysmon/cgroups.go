@@ -0,0 +1,107 @@
+package sysmon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+)
+
+// cgroupRoot is where the unified cgroup v2 hierarchy is mounted
+const cgroupRoot = "/sys/fs/cgroup"
+
+func cgroupsCmd(s *cgroupSampler) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+		defer cancel()
+		start := time.Now()
+		root, err := s.sample(ctx)
+		debug.Timing("sysmon: cgroup walk", start, err)
+		return cgroupsMsg{root: root, err: err}
+	}
+}
+
+// sample walks the cgroup hierarchy and returns it with rates computed
+// against the previous walk, or nil when cgroup v2 is not mounted. A walk
+// cut short by ctx leaves the previous one to compute rates against.
+func (s *cgroupSampler) sample(ctx context.Context) (*cgroupNode, error) {
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		return nil, nil
+	}
+
+	counters := make(map[string]cgroupCounters)
+
+	var walk func(dir, rel string) *cgroupNode
+	walk = func(dir, rel string) *cgroupNode {
+		n := &cgroupNode{Path: rel, Name: filepath.Base(rel)}
+		if rel == "/" {
+			n.Name = "/"
+		}
+
+		c := cgroupCounters{at: time.Now(), cpuUsec: readKeyedValue(filepath.Join(dir, "cpu.stat"), "usage_usec")}
+		c.readBytes, c.writeBytes = readIOStat(filepath.Join(dir, "io.stat"))
+		n.Memory = readUintFile(filepath.Join(dir, "memory.current"))
+		n.MemoryMax = readUintFile(filepath.Join(dir, "memory.max"))
+		if data, err := os.ReadFile(filepath.Join(dir, "cgroup.procs")); err == nil {
+			n.Procs = strings.Count(string(data), "\n")
+		}
+
+		if prev, seen := s.prev[rel]; seen && c.at.After(prev.at) {
+			elapsed := c.at.Sub(prev.at).Seconds()
+			n.CPU = float64(c.cpuUsec-min(prev.cpuUsec, c.cpuUsec)) / 1e6 / elapsed * 100
+			n.ReadRate = float64(c.readBytes-min(prev.readBytes, c.readBytes)) / elapsed
+			n.WriteRate = float64(c.writeBytes-min(prev.writeBytes, c.writeBytes)) / elapsed
+		}
+		counters[rel] = c
+
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if e.IsDir() && ctx.Err() == nil {
+				n.Children = append(n.Children, walk(filepath.Join(dir, e.Name()), filepath.Join(rel, e.Name())))
+			}
+		}
+		return n
+	}
+	root := walk(cgroupRoot, "/")
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// The root cgroup has no cpu.stat or memory.current of its own; sum its children
+	if root.Memory == 0 {
+		for _, c := range root.Children {
+			root.CPU += c.CPU
+			root.Memory += c.Memory
+			root.ReadRate += c.ReadRate
+			root.WriteRate += c.WriteRate
+		}
+	}
+
+	s.prev = counters
+	return root, nil
+}
+
+// readIOStat sums rbytes and wbytes over all devices in a cgroup io.stat
+// file ("8:0 rbytes=1234 wbytes=5678 rios=1 wios=2 ...")
+func readIOStat(path string) (read, write uint64) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0
+	}
+	for _, field := range strings.Fields(string(data)) {
+		key, value, _ := strings.Cut(field, "=")
+		n, _ := strconv.ParseUint(value, 10, 64)
+		switch key {
+		case "rbytes":
+			read += n
+		case "wbytes":
+			write += n
+		}
+	}
+	return read, write
+}
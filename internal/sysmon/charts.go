@@ -0,0 +1,153 @@
+package sysmon
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// The size of a report chart in SVG units, the plot inset to leave room
+// for the labels
+const (
+	chartWidth   = 720
+	chartHeight  = 180
+	chartTop     = 20
+	chartLeft    = 70
+	chartBottom  = 24
+	chartPoints  = 360 // Most steps drawn across a chart
+	chartSeriesN = 4   // Most mounts or interfaces charted
+)
+
+// chartColors are the series' colors, in order
+var chartColors = []string{"#2980b9", "#c0392b", "#27ae60", "#8e44ad"}
+
+// Chart is a report chart, rendered as inline SVG so the report stays a
+// single file that mail clients show as is
+type Chart struct {
+	Title string
+	SVG   template.HTML
+}
+
+// chartSeries is a line of a chart
+type chartSeries struct {
+	name   string
+	points []queryPoint
+}
+
+// reportCharts charts CPU, memory, disk and network use over snaps, which
+// cover from to to: the disks and interfaces are the busiest few of sum
+func reportCharts(snaps []Snapshot, sum DailySummary, from, to time.Time) []Chart {
+	step := max((to.Sub(from) / chartPoints).Truncate(time.Second), time.Second)
+	series := func(name string, q query) chartSeries {
+		q.step = step
+		acc := q.accumulate(from, to, from)
+		for i := range snaps {
+			acc.add(&snaps[i])
+		}
+		return chartSeries{name: name, points: acc.done()}
+	}
+
+	var memTotal uint64
+	for _, s := range snaps {
+		memTotal = max(memTotal, s.MemTotal)
+	}
+	charts := []Chart{
+		{"CPU", chartSVG(from, to, step, 100, percentUnit, series("CPU", query{metric: "cpu"}))},
+		{"Memory", chartSVG(from, to, step, float64(memTotal), bytesUnit, series("used", query{metric: "mem"}))},
+	}
+
+	// Mounts differ in size, so they share a chart as a percentage of theirs
+	var disks []chartSeries
+	for _, m := range sum.Mounts {
+		if m.Total == 0 || len(disks) == chartSeriesN {
+			continue
+		}
+		d := series(m.Path, query{metric: "disk", mount: m.Path})
+		for i := range d.points {
+			d.points[i].Value = d.points[i].Value / float64(m.Total) * 100
+		}
+		disks = append(disks, d)
+	}
+	if len(disks) > 0 {
+		charts = append(charts, Chart{"Disk use", chartSVG(from, to, step, 100, percentUnit, disks...)})
+	}
+
+	for i, t := range sum.Network {
+		if i == chartSeriesN || t.RxBytes+t.TxBytes == 0 {
+			break
+		}
+		charts = append(charts, Chart{"Network " + t.Name, chartSVG(from, to, step, 0, rateUnit,
+			series("down", query{metric: "down", iface: t.Name}), series("up", query{metric: "up", iface: t.Name}))})
+	}
+	return charts
+}
+
+// chartSVG draws series as lines over time from from to to, scaled to
+// ceiling or to their peak when that is higher. A gap of more than two
+// steps without points, such as the host being down, breaks a line.
+func chartSVG(from, to time.Time, step time.Duration, ceiling float64, unit func(float64) string, series ...chartSeries) template.HTML {
+	top := ceiling
+	for _, s := range series {
+		for _, p := range s.points {
+			top = math.Max(top, p.Value)
+		}
+	}
+	if top <= 0 {
+		top = 1
+	}
+	plotW, plotH := float64(chartWidth-chartLeft-10), float64(chartHeight-chartBottom-chartTop)
+	span := to.Sub(from).Seconds()
+	x := func(t time.Time) float64 { return chartLeft + max(t.Sub(from).Seconds(), 0)/span*plotW }
+	y := func(v float64) float64 { return chartTop + plotH - v/top*plotH }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" font-size="11" font-family="sans-serif">`,
+		chartWidth, chartHeight, chartWidth, chartHeight)
+	for _, frac := range []float64{0, 0.5, 1} {
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#ddd"/>`, chartLeft, y(top*frac), chartWidth-10, y(top*frac))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" fill="#666">%s</text>`, chartLeft-6, y(top*frac)+4, html.EscapeString(unit(top*frac)))
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" fill="#666">%s</text>`, chartLeft, chartHeight-6, from.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end" fill="#666">%s</text>`, chartWidth-10, chartHeight-6, to.Format("2006-01-02 15:04"))
+
+	for i, s := range series {
+		color := chartColors[i%len(chartColors)]
+		var line []queryPoint
+		draw := func() {
+			switch len(line) {
+			case 0:
+			case 1:
+				fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="1.5" fill="%s"/>`, x(line[0].Time), y(line[0].Value), color)
+			default:
+				fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="`, color)
+				for k, p := range line {
+					if k > 0 {
+						b.WriteByte(' ')
+					}
+					fmt.Fprintf(&b, "%.1f,%.1f", x(p.Time), y(p.Value))
+				}
+				b.WriteString(`"/>`)
+			}
+			line = line[:0]
+		}
+		for j, p := range s.points {
+			if j > 0 && p.Time.Sub(s.points[j-1].Time) > 2*step {
+				draw()
+			}
+			line = append(line, p)
+		}
+		draw()
+		if len(series) > 1 {
+			lx := chartLeft + i*150
+			fmt.Fprintf(&b, `<rect x="%d" y="5" width="10" height="3" fill="%s"/><text x="%d" y="11" fill="#444">%s</text>`,
+				lx, color, lx+14, html.EscapeString(ui.Truncate(s.name, 20)))
+		}
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
@@ -0,0 +1,323 @@
+package sysmon
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/config"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// compareBars is how many cells the change graph takes on either side
+const compareBars = 20
+
+// timeSpan is a time range of the history, as -a and -b give it
+type timeSpan struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// parseSpan parses a time range: a day such as 2026-10-01, a start and a
+// length such as 2026-10-01T08:00/6h, or a start and an end such as
+// 2026-10-01..2026-10-03, in local time
+func parseSpan(s string) (timeSpan, error) {
+	at := func(s string) (time.Time, error) {
+		for _, layout := range []string{"2006-01-02T15:04", "2006-01-02"} {
+			if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("invalid time %q, such as 2026-10-01 or 2026-10-01T08:00", s)
+	}
+	var span timeSpan
+	var err error
+	if start, length, ok := strings.Cut(s, "/"); ok {
+		if span.From, err = at(start); err != nil {
+			return span, err
+		}
+		d, err := parseAge(length)
+		if err != nil {
+			return span, err
+		}
+		span.To = span.From.Add(d)
+	} else if start, end, ok := strings.Cut(s, ".."); ok {
+		if span.From, err = at(start); err != nil {
+			return span, err
+		}
+		if span.To, err = at(end); err != nil {
+			return span, err
+		}
+	} else {
+		if span.From, err = at(s); err != nil {
+			return span, err
+		}
+		span.To = span.From.AddDate(0, 0, 1)
+	}
+	if !span.To.After(span.From) {
+		return span, fmt.Errorf("%q ends before it starts", s)
+	}
+	return span, nil
+}
+
+// comparison is a metric over two time ranges, step by step from the
+// start of each
+type comparison struct {
+	Metric string        `json:"metric"`
+	Step   time.Duration `json:"step_ns"`
+	A      compareSide   `json:"a"`
+	B      compareSide   `json:"b"`
+	Rows   []compareRow  `json:"rows"`
+	q      query
+}
+
+// compareSide is one of the ranges compared, summarized
+type compareSide struct {
+	timeSpan
+	Mean float64 `json:"mean"`
+	Peak float64 `json:"peak"`
+}
+
+// compareRow is a step of both ranges at the same offset from their
+// starts; a range without a value there has nil
+type compareRow struct {
+	Offset time.Duration `json:"offset_ns"`
+	A      *float64      `json:"a"`
+	B      *float64      `json:"b"`
+	Delta  *float64      `json:"delta"` // B less A
+}
+
+// compare reads q over the ranges a and b, by step
+func compare(q query, a, b timeSpan) (comparison, error) {
+	c := comparison{Metric: q.metric, Step: q.step, q: q}
+	var rows int
+	sides := []*compareSide{&c.A, &c.B}
+	values := make([]map[int]float64, 2)
+	for i, span := range []timeSpan{a, b} {
+		points, err := q.between(span.From, span.To, span.From)
+		if err != nil {
+			return c, err
+		}
+		side := sides[i]
+		side.timeSpan = span
+		side.Peak = math.Inf(-1)
+		values[i] = make(map[int]float64)
+		steps := int((span.To.Sub(span.From) + q.step - 1) / q.step)
+		for _, p := range points {
+			// The steps start at the range's start; rounding keeps a day
+			// step across a daylight saving change on its row. A snapshot
+			// right at the end starts a step past the range.
+			if row := int((p.Time.Sub(span.From) + q.step/2) / q.step); row < steps {
+				values[i][row] = p.Value
+				side.Mean += p.Value
+				side.Peak = math.Max(side.Peak, p.Value)
+			}
+		}
+		if n := len(values[i]); n > 0 {
+			side.Mean /= float64(n)
+		} else {
+			side.Peak = 0
+		}
+		rows = max(rows, steps)
+	}
+	if len(values[0]) == 0 && len(values[1]) == 0 {
+		return c, fmt.Errorf("no %s in the history of either range (run the snapshot subcommand to collect it)", q.metric)
+	}
+	for row := range rows {
+		r := compareRow{Offset: time.Duration(row) * q.step}
+		if v, ok := values[0][row]; ok {
+			r.A = &v
+		}
+		if v, ok := values[1][row]; ok {
+			r.B = &v
+		}
+		if r.A != nil && r.B != nil {
+			d := *r.B - *r.A
+			r.Delta = &d
+		}
+		c.Rows = append(c.Rows, r)
+	}
+	return c, nil
+}
+
+// RunCompare implements the compare subcommand, setting a metric over two
+// time ranges of the history side by side, such as the traffic of the week
+// before a router's firmware update and of the week after:
+//
+//	advis compare "iface=wan0 metric=down step=1h" -a 2026-10-01/7d -b 2026-10-08/7d
+func RunCompare(args []string) error {
+	fs := flag.NewFlagSet("advis compare", flag.ContinueOnError)
+	a := fs.String("a", "", "first time range: a day such as 2026-10-01, 2026-10-01T08:00/6h or 2026-10-01..2026-10-03")
+	b := fs.String("b", "", "second time range, as -a")
+	format := fs.String("format", "text", "output format: text, csv or json")
+	out := fs.String("out", "", "write the comparison to this file (default stdout)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: advis compare "key=value ..." -a RANGE -b RANGE [flags]
+
+The keys are those of advis query; step defaults to a 48th of the longer
+range. Without -b the range of -a is compared, as B, with the one just
+before it as A.
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+	if err := config.SetFlagsFromEnv(fs); err != nil {
+		return err
+	}
+	expr, err := parseAround(fs, args)
+	if err != nil {
+		return err
+	}
+	q, err := parseQuery(expr)
+	if err != nil {
+		return err
+	}
+	if *format != "text" && *format != "csv" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if *a == "" {
+		return errors.New("-a gives the first range to compare")
+	}
+	spanA, err := parseSpan(*a)
+	if err != nil {
+		return fmt.Errorf("-a: %w", err)
+	}
+	var spanB timeSpan
+	if *b != "" {
+		if spanB, err = parseSpan(*b); err != nil {
+			return fmt.Errorf("-b: %w", err)
+		}
+	} else {
+		// The range of -a is the one after, so the change is what it brought
+		spanA, spanB = timeSpan{From: spanA.From.Add(-spanA.To.Sub(spanA.From)), To: spanA.From}, spanA
+	}
+	if q.step == 0 {
+		longest := max(spanA.To.Sub(spanA.From), spanB.To.Sub(spanB.From))
+		q.step = max((longest / 48).Truncate(time.Minute), time.Minute)
+	}
+	c, err := compare(q, spanA, spanB)
+	if err != nil {
+		return err
+	}
+	return writeReportFile(*out, *format, time.Now(), func(w io.Writer) error {
+		return renderComparison(w, *format, c)
+	})
+}
+
+// renderComparison writes a comparison in the requested format: the text
+// one summarizes both ranges, draws them as sparklines, and lists the steps
+// with the change graphed either side of a center line
+func renderComparison(w io.Writer, format string, c comparison) error {
+	cell := func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	}
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(c)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"offset", "a_time", "a", "b_time", "b", "delta"})
+		for _, r := range c.Rows {
+			cw.Write([]string{r.Offset.String(),
+				c.A.From.Add(r.Offset).Format(time.RFC3339), cell(r.A),
+				c.B.From.Add(r.Offset).Format(time.RFC3339), cell(r.B), cell(r.Delta)})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	unit := queryMetrics[c.Metric].unit
+	value := func(v *float64) string {
+		if v == nil {
+			return "-"
+		}
+		return unit(*v)
+	}
+	signed := func(v float64) string {
+		if v < 0 {
+			return "-" + unit(-v)
+		}
+		return "+" + unit(v)
+	}
+	var b strings.Builder
+	of := c.q.iface + c.q.mount
+	if of != "" {
+		of = " of " + of
+	}
+	fmt.Fprintf(&b, "%s%s, by %s\n\n", c.Metric, of, c.Step)
+	for _, side := range []struct {
+		name string
+		compareSide
+	}{{"A", c.A}, {"B", c.B}} {
+		fmt.Fprintf(&b, "%s  %s to %s  mean %s  peak %s\n", side.name,
+			side.From.Format("2006-01-02 15:04"), side.To.Format("2006-01-02 15:04"), unit(side.Mean), unit(side.Peak))
+	}
+	if c.A.Mean != 0 {
+		fmt.Fprintf(&b, "   B's mean is %+.1f%% on A's\n", (c.B.Mean-c.A.Mean)/c.A.Mean*100)
+	}
+
+	ceiling := math.Max(c.A.Peak, c.B.Peak)
+	var spanA, spanB []float64
+	var widest float64
+	for _, r := range c.Rows {
+		spanA, spanB = append(spanA, deref(r.A)), append(spanB, deref(r.B))
+		if r.Delta != nil {
+			widest = math.Max(widest, math.Abs(*r.Delta))
+		}
+	}
+	fmt.Fprintf(&b, "\nA %s\nB %s\n\n", ui.Sparkline(spanA, len(spanA), ceiling), ui.Sparkline(spanB, len(spanB), ceiling))
+
+	fmt.Fprintf(&b, "%-9s %-12s %-12s %-12s %s\n", "OFFSET", "A", "B", "CHANGE", "")
+	for _, r := range c.Rows {
+		var change, bar string
+		if r.Delta != nil {
+			change = signed(*r.Delta)
+			n := 0
+			if widest > 0 {
+				n = int(math.Round(math.Abs(*r.Delta) / widest * compareBars))
+			}
+			left, right := strings.Repeat(" ", compareBars), ""
+			if *r.Delta < 0 {
+				left = strings.Repeat(" ", compareBars-n) + strings.Repeat("█", n)
+			} else {
+				right = strings.Repeat("█", n)
+			}
+			bar = left + "│" + right
+		}
+		fmt.Fprintf(&b, "%-9s %-12s %-12s %-12s %s\n", formatOffset(r.Offset), value(r.A), value(r.B), change, bar)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// deref is the value v points at, zero for none
+func deref(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// formatOffset writes an offset from the start of a range as +days hh:mm
+func formatOffset(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	d -= time.Duration(days) * 24 * time.Hour
+	s := fmt.Sprintf("+%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+	if days > 0 {
+		s = fmt.Sprintf("+%dd%s", days, s[1:])
+	}
+	return s
+}
@@ -0,0 +1,284 @@
+package sysmon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/caps"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+)
+
+// containerRuntime talks to the Docker Engine API, which Podman also
+// serves, over a unix socket
+type containerRuntime struct {
+	name   string
+	socket string
+	client *http.Client
+
+	mu   sync.Mutex
+	prev map[string]containerCounters
+}
+
+// containerCounters are the cumulative cgroup and network counters of a
+// container at the last poll
+type containerCounters struct {
+	at         time.Time
+	cpuUsec    uint64
+	readBytes  uint64
+	writeBytes uint64
+	rxBytes    uint64
+	txBytes    uint64
+}
+
+// apiTimeout bounds a request reading the runtime's state
+const apiTimeout = 5 * time.Second
+
+// stopGrace is how long the runtime gives a container to exit after
+// SIGTERM on a stop or restart before it kills it, the runtimes' default,
+// and actionTimeout bounds the whole action: the wait, the kill and the
+// start again
+const (
+	stopGrace     = 10 * time.Second
+	actionTimeout = stopGrace + 20*time.Second
+)
+
+// detectContainerRuntime returns the Docker or Podman socket found at
+// startup, or nil
+func detectContainerRuntime() *containerRuntime {
+	socket := caps.Get().ContainerSocket
+	if socket == "" {
+		return nil
+	}
+	name := "docker"
+	if strings.Contains(socket, "podman") {
+		name = "podman"
+	}
+	return &containerRuntime{
+		name:   name,
+		socket: socket,
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+}
+
+// apiCall sends a request to the runtime API and decodes the JSON reply
+// into out, within apiTimeout
+func (rt *containerRuntime) apiCall(method, path string, out any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	return rt.apiCallContext(ctx, method, path, out)
+}
+
+// action stops or restarts a container, waiting out its grace period
+func (rt *containerRuntime) action(id, action string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+	defer cancel()
+	path := fmt.Sprintf("/containers/%s/%s?t=%d", id, action, int(stopGrace.Seconds()))
+	return rt.apiCallContext(ctx, http.MethodPost, path, nil)
+}
+
+func (rt *containerRuntime) apiCallContext(ctx context.Context, method, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, "http://"+rt.name+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := rt.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotModified {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		return fmt.Errorf("%s", apiErr.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func containersCmd(rt *containerRuntime) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		containers, err := rt.list()
+		debug.Timing("sysmon: "+rt.name+" containers", start, err)
+		return containersMsg{containers: containers, err: err}
+	}
+}
+
+func containerActionCmd(rt *containerRuntime, c Container, action string) tea.Cmd {
+	return func() tea.Msg {
+		err := rt.action(c.ID, action)
+		return containerActionMsg{action: action, id: c.ID, name: c.Name, err: err}
+	}
+}
+
+// list returns the running containers with rates computed from their
+// cgroup and network namespace counters
+func (rt *containerRuntime) list() ([]Container, error) {
+	var summaries []struct {
+		ID     string   `json:"Id"`
+		Names  []string `json:"Names"`
+		Image  string   `json:"Image"`
+		State  string   `json:"State"`
+		Status string   `json:"Status"`
+	}
+	if err := rt.apiCall(http.MethodGet, "/containers/json", &summaries); err != nil {
+		return nil, err
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	counters := make(map[string]containerCounters, len(summaries))
+	containers := make([]Container, 0, len(summaries))
+	for _, sum := range summaries {
+		c := Container{
+			ID:     sum.ID,
+			Name:   strings.TrimPrefix(strings.Join(sum.Names, ","), "/"),
+			Image:  sum.Image,
+			State:  sum.State,
+			Status: sum.Status,
+		}
+
+		var inspect struct {
+			State struct {
+				Pid int `json:"Pid"`
+			} `json:"State"`
+		}
+		if err := rt.apiCall(http.MethodGet, "/containers/"+sum.ID+"/json", &inspect); err == nil {
+			c.PID = inspect.State.Pid
+		}
+
+		if cur, ok := readContainerCounters(c.PID, &c); ok {
+			c.HasStats = true
+			if prev, seen := rt.prev[c.ID]; seen {
+				elapsed := cur.at.Sub(prev.at).Seconds()
+				if elapsed > 0 {
+					c.CPU = float64(cur.cpuUsec-min(prev.cpuUsec, cur.cpuUsec)) / 1e6 / elapsed * 100
+					c.ReadRate = float64(cur.readBytes-min(prev.readBytes, cur.readBytes)) / elapsed
+					c.WriteRate = float64(cur.writeBytes-min(prev.writeBytes, cur.writeBytes)) / elapsed
+					c.RxRate = float64(cur.rxBytes-min(prev.rxBytes, cur.rxBytes)) / elapsed
+					c.TxRate = float64(cur.txBytes-min(prev.txBytes, cur.txBytes)) / elapsed
+				}
+			}
+			counters[c.ID] = cur
+		}
+		containers = append(containers, c)
+	}
+	rt.prev = counters
+
+	sort.Slice(containers, func(i, j int) bool {
+		return containers[i].Name < containers[j].Name
+	})
+	return containers, nil
+}
+
+// readContainerCounters reads the cgroup v2 counters of the cgroup that pid
+// lives in, plus the network counters of its namespace, filling in the
+// container's memory usage and limit
+func readContainerCounters(pid int, c *Container) (containerCounters, bool) {
+	cur := containerCounters{at: time.Now()}
+	if pid <= 0 {
+		return cur, false
+	}
+
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return cur, false
+	}
+	// cgroup v2 has a single "0::/path" line
+	var cgroup string
+	for _, line := range strings.Split(string(data), "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			cgroup = filepath.Join(cgroupRoot, path)
+		}
+	}
+	if cgroup == "" {
+		return cur, false
+	}
+
+	cur.cpuUsec = readKeyedValue(filepath.Join(cgroup, "cpu.stat"), "usage_usec")
+	c.Memory = readUintFile(filepath.Join(cgroup, "memory.current"))
+	c.MemoryLimit = readUintFile(filepath.Join(cgroup, "memory.max"))
+
+	cur.readBytes, cur.writeBytes = readIOStat(filepath.Join(cgroup, "io.stat"))
+
+	// /proc/<pid>/net/dev shows the container's own network namespace
+	if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/net/dev", pid)); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			name, rest, ok := strings.Cut(line, ":")
+			fields := strings.Fields(rest)
+			if !ok || strings.TrimSpace(name) == "lo" || len(fields) < 9 {
+				continue
+			}
+			rx, _ := strconv.ParseUint(fields[0], 10, 64)
+			tx, _ := strconv.ParseUint(fields[8], 10, 64)
+			cur.rxBytes += rx
+			cur.txBytes += tx
+		}
+	}
+	return cur, true
+}
+
+// readUintFile reads a file holding a single number, returning 0 for
+// errors and for "max"
+func readUintFile(path string) uint64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	return n
+}
+
+// readIntFile reads a file holding a single signed number, returning 0
+// for errors
+func readIntFile(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return n
+}
+
+// readKeyedValue returns the value for key in a "key value" per line file
+// such as cpu.stat
+func readKeyedValue(path, key string) uint64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if k, v, ok := strings.Cut(line, " "); ok && k == key {
+			n, _ := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,156 @@
+package sysmon
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/caps"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/proc"
+)
+
+// dashboardMounts and dashboardProcs cap the rows of the dashboard panels
+const (
+	dashboardMounts = 6
+	dashboardProcs  = 8
+)
+
+// Panels contributes CPU, memory, disk and process panels to the combined
+// dashboard of "advis all", and SoC sensors to layouts asking for them
+func (m model) Panels(width int) []ui.Panel {
+	return m.frames.panels.Get([2]uint64{m.rev, uint64(width)}, func() []ui.Panel {
+		return m.renderPanels(width)
+	})
+}
+
+func (m model) renderPanels(width int) []ui.Panel {
+	barWidth := max(width-14, 10)
+
+	var cpu strings.Builder
+	if caps.Get().NoCPU {
+		cpu.WriteString("CPU usage not available on Android\n")
+	} else {
+		cpu.WriteString(fmt.Sprintf("Total %s %5.1f%%\n", createProgressBar("cpu", int(m.cpuTotal), barWidth), m.cpuTotal))
+		history := make([]float64, m.timeline.Len())
+		for i, t := range m.timeline.All() {
+			history[i] = t.CPU
+		}
+		cpu.WriteString(barStyle.Render(ui.Sparkline(history, width, 100)) + "\n")
+	}
+	cpu.WriteString(fmt.Sprintf("Load %.2f on %d CPUs\n", m.sysInfo.LoadAverage, m.sysInfo.CPUs))
+	// One heat cell per core, wrapped to the panel
+	for start := 0; start < len(m.cores); start += width {
+		for _, usage := range m.cores[start:min(start+width, len(m.cores))] {
+			cpu.WriteString(heatStyle(usage).Render("█"))
+		}
+		cpu.WriteString("\n")
+	}
+
+	var mem strings.Builder
+	if m.sysInfo.MemTotal > 0 {
+		percent := float64(m.sysInfo.MemUsed) / float64(m.sysInfo.MemTotal) * 100
+		mem.WriteString(fmt.Sprintf("Used  %s %5.1f%%\n", createProgressBar("memory", int(percent), barWidth), percent))
+		mem.WriteString(fmt.Sprintf("%s of %s, %s free\n",
+			ui.FormatBytes(m.sysInfo.MemUsed), ui.FormatBytes(m.sysInfo.MemTotal), ui.FormatBytes(m.sysInfo.MemFree)))
+	} else {
+		mem.WriteString("Memory information not available\n")
+	}
+	if len(m.leakSuspect) > 0 {
+		mem.WriteString(alertStyle.Render(fmt.Sprintf("%d leak suspect(s)", len(m.leakSuspect))) + "\n")
+	}
+
+	var disk strings.Builder
+	var readRate, writeRate float64
+	for _, p := range m.procs {
+		readRate += p.ReadRate
+		writeRate += p.WriteRate
+	}
+	disk.WriteString(fmt.Sprintf("Read %s/s  Write %s/s", ui.FormatBytes(uint64(readRate)), ui.FormatBytes(uint64(writeRate))))
+	if !caps.Get().OtherProcesses() {
+		// Other users' I/O counters need root, so the sum covers ours only
+		disk.WriteString(dimStyle.Render(" (own processes)"))
+	}
+	disk.WriteString("\n")
+	shown := 0
+	for _, d := range m.mounts {
+		if d.Total == 0 || shown == dashboardMounts {
+			continue
+		}
+		percent := float64(d.Used) / float64(d.Total) * 100
+		disk.WriteString(fmt.Sprintf("%s %s %5.1f%%\n",
+			ui.Fit(d.Path, 14), createProgressBar("disk", int(percent), max(width-22, 5)), percent))
+		shown++
+	}
+
+	var procs strings.Builder
+	top := make([]proc.Process, len(m.procs))
+	copy(top, m.procs)
+	sortProcesses(top, sortByCPU)
+	procs.WriteString(dimStyle.Render(fmt.Sprintf("%-7s %6s %9s %s", "PID", "CPU%", "MEM", "NAME")) + "\n")
+	for _, p := range top[:min(dashboardProcs, len(top))] {
+		procs.WriteString(fmt.Sprintf("%-7d %6.1f %9s %s\n", p.PID, p.CPU, ui.FormatBytes(p.Memory), ui.Truncate(p.Name, max(width-26, 8))))
+	}
+	if attention := m.attention(); len(attention) > 0 {
+		procs.WriteString(alertStyle.Render(fmt.Sprintf("%d active alert(s)", len(attention))) + "\n")
+	}
+
+	sensors := dimStyle.Render("No SoC sensors on this host") + "\n"
+	if m.soc.Available {
+		// The section's heading is the panel title here
+		_, sensors, _ = strings.Cut(strings.TrimPrefix(m.renderSoC(), "\n"), "\n")
+	}
+
+	return []ui.Panel{
+		{Title: ui.Icon("cpu") + "CPU", Body: cpu.String(), Widget: "cpu"},
+		{Title: ui.Icon("memory") + "Memory", Body: mem.String(), Widget: "mem"},
+		{Title: ui.Icon("disk") + "Disk", Body: disk.String(), Widget: "disk"},
+		{Title: ui.Icon("processes") + "Processes", Body: procs.String(), Widget: "procs"},
+		{Title: ui.Icon("sensors") + "Sensors", Body: sensors, Widget: "sensors", Extra: true},
+	}
+}
+
+// readerProcs caps the processes the screen reader mode reads out
+const readerProcs = 3
+
+// Readings describes CPU, memory, disks, the busiest processes and the
+// active alerts to the screen reader mode
+func (m model) Readings() []ui.Reading {
+	r := []ui.Reading{
+		{Label: "CPU", Value: ui.FormatFloat(m.cpuTotal, 1) + "%"},
+		{Label: "Load average", Value: fmt.Sprintf("%s on %d CPUs", ui.FormatFloat(m.sysInfo.LoadAverage, 2), m.sysInfo.CPUs)},
+	}
+	if m.sysInfo.MemTotal > 0 {
+		percent := float64(m.sysInfo.MemUsed) / float64(m.sysInfo.MemTotal) * 100
+		r = append(r, ui.Reading{Label: "Memory", Value: fmt.Sprintf("%s%% used, %s of %s",
+			ui.FormatFloat(percent, 1), ui.FormatBytes(m.sysInfo.MemUsed), ui.FormatBytes(m.sysInfo.MemTotal))})
+	}
+	shown := 0
+	for _, d := range m.mounts {
+		if d.Total == 0 || shown == dashboardMounts {
+			continue
+		}
+		percent := float64(d.Used) / float64(d.Total) * 100
+		r = append(r, ui.Reading{Label: "Disk " + d.Path,
+			Value: fmt.Sprintf("%s%% used, %s free", ui.FormatFloat(percent, 1), ui.FormatBytes(d.Free))})
+		shown++
+	}
+	top := slices.Clone(m.procs)
+	sortProcesses(top, sortByCPU)
+	for i, p := range top[:min(readerProcs, len(top))] {
+		r = append(r, ui.Reading{Label: fmt.Sprintf("Process %d", i+1),
+			Value: fmt.Sprintf("%s, PID %d, %s%% CPU, %s", p.Name, p.PID, ui.FormatFloat(p.CPU, 1), ui.FormatBytes(p.Memory))})
+	}
+	attention := m.attention()
+	if len(attention) == 0 {
+		return append(r, ui.Reading{Label: "Alerts", Value: "none"})
+	}
+	for _, a := range attention {
+		label := "Warning"
+		if a.Level == alertCritical {
+			label = "Critical"
+		}
+		r = append(r, ui.Reading{Label: label, Value: a.Source + ", " + a.Message})
+	}
+	return r
+}
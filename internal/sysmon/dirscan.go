@@ -0,0 +1,119 @@
+package sysmon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/platform"
+)
+
+func scanDirCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		root, err := scanTree(path)
+		return dirScanMsg{root: root, elapsed: time.Since(start), err: err}
+	}
+}
+
+func deleteFileCmd(e *dirEntry) tea.Cmd {
+	return func() tea.Msg {
+		return fileDeletedMsg{entry: e, err: os.Remove(e.Path)}
+	}
+}
+
+// scanTree walks path concurrently and returns its size tree. Like ncdu -x,
+// it does not follow symlinks or cross into other filesystems.
+func scanTree(path string) (*dirEntry, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", path)
+	}
+	var dev uint64
+	if st, ok := platform.StatOf(info); ok {
+		dev = st.Dev
+	}
+
+	root := &dirEntry{Name: path, Path: path, IsDir: true}
+	w := &dirWalker{dev: dev, sem: make(chan struct{}, runtime.NumCPU()*4)}
+	w.walk(root)
+	w.wg.Wait()
+	root.sum()
+	return root, nil
+}
+
+// dirWalker scans directories in parallel, bounded by sem
+type dirWalker struct {
+	dev uint64
+	sem chan struct{}
+	wg  sync.WaitGroup
+}
+
+// walk fills in the direct children of dir and descends into
+// subdirectories, on a new goroutine when a slot is free
+func (w *dirWalker) walk(dir *dirEntry) {
+	entries, err := os.ReadDir(dir.Path)
+	if err != nil {
+		dir.Errors++
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		child := &dirEntry{
+			Name:   entry.Name(),
+			Path:   filepath.Join(dir.Path, entry.Name()),
+			IsDir:  info.IsDir(),
+			Parent: dir,
+		}
+		if st, ok := platform.StatOf(info); ok {
+			child.Size = st.Allocated
+			child.MountPoint = child.IsDir && st.Dev != w.dev
+		} else {
+			child.Size = uint64(info.Size())
+		}
+		dir.Children = append(dir.Children, child)
+	}
+
+	for _, child := range dir.Children {
+		if !child.IsDir || child.MountPoint {
+			continue
+		}
+		select {
+		case w.sem <- struct{}{}:
+			w.wg.Add(1)
+			go func(c *dirEntry) {
+				defer w.wg.Done()
+				defer func() { <-w.sem }()
+				w.walk(c)
+			}(child)
+		default:
+			w.walk(child)
+		}
+	}
+}
+
+// sum totals sizes, item counts and errors bottom-up and sorts children by size
+func (e *dirEntry) sum() {
+	for _, c := range e.Children {
+		if c.IsDir && !c.MountPoint {
+			c.sum()
+		}
+		e.Size += c.Size
+		e.Items += c.Items + 1
+		e.Errors += c.Errors
+	}
+	sort.Slice(e.Children, func(i, j int) bool {
+		return e.Children[i].Size > e.Children[j].Size
+	})
+}
@@ -0,0 +1,67 @@
+package sysmon
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/baseline"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/proc"
+)
+
+// maxDriftNames is how many new processes a drift alert names
+const maxDriftNames = 5
+
+// newProcess reports whether p did not run in the baseline. Kernel
+// threads come and go by the numbers in their names, so they never count.
+func (m model) newProcess(p proc.Process) bool {
+	return m.baseline != nil && p.Cmdline != "" && m.baseline.NewProcess(p.Name)
+}
+
+// driftAlerts are the ways the host drifted from the baseline: processes
+// that did not run then, and CPU or memory use beyond the tolerance
+func (m model) driftAlerts() []Alert {
+	b := m.baseline
+	if b == nil {
+		return nil
+	}
+	var alerts []Alert
+	var names []string
+	for _, p := range m.procs {
+		if m.newProcess(p) && !slices.Contains(names, p.Name) {
+			names = append(names, p.Name)
+		}
+	}
+	if len(names) > 0 {
+		slices.Sort(names)
+		list := strings.Join(names[:min(len(names), maxDriftNames)], ", ")
+		if len(names) > maxDriftNames {
+			list += fmt.Sprintf(" and %d more", len(names)-maxDriftNames)
+		}
+		alerts = append(alerts, Alert{
+			Level:   alertWarning,
+			Source:  "baseline",
+			Subject: "processes",
+			Message: fmt.Sprintf("%d processes not in the baseline: %s", len(names), list),
+		})
+	}
+	if b.CPUAbove(m.cpuTotal) {
+		alerts = append(alerts, Alert{
+			Level:   alertWarning,
+			Source:  "baseline",
+			Subject: "cpu",
+			Message: fmt.Sprintf("CPU at %.0f%%, %.0f%% in the baseline", m.cpuTotal, b.CPU),
+		})
+	}
+	if b.MemoryAbove(m.sysInfo.MemUsed) {
+		alerts = append(alerts, Alert{
+			Level:   alertWarning,
+			Source:  "baseline",
+			Subject: "memory",
+			Message: fmt.Sprintf("memory use at %s, %s in the baseline (tolerance %.0f%%)",
+				ui.FormatBytes(m.sysInfo.MemUsed), ui.FormatBytes(b.MemUsed), baseline.Tolerance()),
+		})
+	}
+	return alerts
+}
@@ -0,0 +1,207 @@
+package sysmon
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/gorilla"
+)
+
+// packedMagic opens a packed history file, its last byte the version
+const packedMagic = "advisgz\x01"
+
+// packedFile returns the packed history file of a day, which a day's
+// JSON lines become once the day is over
+func packedFile(day time.Time) string {
+	return filepath.Join(historyDir(), "snapshots-"+day.Format("2006-01-02")+".tsz")
+}
+
+// packedSeries are the series of a packed file, by figure
+type packedSeries struct {
+	ints   map[string]*gorilla.Ints
+	floats map[string]*gorilla.Floats
+}
+
+func newPackedSeries() *packedSeries {
+	return &packedSeries{ints: make(map[string]*gorilla.Ints), floats: make(map[string]*gorilla.Floats)}
+}
+
+func (p *packedSeries) int(key string) *gorilla.Ints {
+	s := p.ints[key]
+	if s == nil {
+		s = &gorilla.Ints{}
+		p.ints[key] = s
+	}
+	return s
+}
+
+func (p *packedSeries) float(key string) *gorilla.Floats {
+	s := p.floats[key]
+	if s == nil {
+		s = &gorilla.Floats{}
+		p.floats[key] = s
+	}
+	return s
+}
+
+// figures visits the figures of snap in stream order, handing each to
+// integer or float with its series
+func (p *packedSeries) figures(snap *Snapshot, integer func(*gorilla.Ints, *uint64), float func(*gorilla.Floats, *float64)) {
+	float(p.float("cpu"), &snap.CPU)
+	float(p.float("load"), &snap.Load)
+	integer(p.int("mem_total"), &snap.MemTotal)
+	integer(p.int("mem_used"), &snap.MemUsed)
+	for i := range snap.Network {
+		c := &snap.Network[i]
+		key := "net/" + c.Name + "/"
+		for _, f := range []struct {
+			name string
+			v    *uint64
+		}{{"rx_bytes", &c.RxBytes}, {"tx_bytes", &c.TxBytes}, {"rx_packets", &c.RxPackets},
+			{"tx_packets", &c.TxPackets}, {"rx_errors", &c.RxErrors}, {"tx_errors", &c.TxErrors}} {
+			integer(p.int(key+f.name), f.v)
+		}
+	}
+	for i := range snap.Mounts {
+		d := &snap.Mounts[i]
+		key := "mount/" + d.Path + "/"
+		integer(p.int(key+"total"), &d.Total)
+		integer(p.int(key+"used"), &d.Used)
+		integer(p.int(key+"free"), &d.Free)
+	}
+}
+
+// packSnapshots encodes snaps as a packed history file: the magic, the
+// count of snapshots, the length of the figures and the figures as one
+// Gorilla stream, then the rest of each snapshot as a JSON line, deflated.
+// The figures of a snapshot are its time in milliseconds, CPU, load and
+// memory, then the counters of each interface and the usage of each mount
+// in the order of its line. Counters and timestamps rarely change pace
+// and gauges rarely move far, so a snapshot's figures take a few bytes,
+// and the lines repeat themselves and deflate well.
+func packSnapshots(snaps []Snapshot) ([]byte, error) {
+	var figures gorilla.Writer
+	var times gorilla.Ints
+	series := newPackedSeries()
+	var lines bytes.Buffer
+	zw, err := flate.NewWriter(&lines, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(zw)
+	for _, snap := range snaps {
+		times.Write(&figures, snap.Time.UnixMilli())
+		// The line keeps the names and the rest, the figures zeroed so
+		// they deflate away
+		rest := snap
+		rest.Time = time.Time{}
+		rest.Network = slices.Clone(snap.Network)
+		rest.Mounts = slices.Clone(snap.Mounts)
+		series.figures(&rest, func(s *gorilla.Ints, v *uint64) {
+			s.Write(&figures, int64(*v))
+			*v = 0
+		}, func(s *gorilla.Floats, v *float64) {
+			s.Write(&figures, *v)
+			*v = 0
+		})
+		if err := enc.Encode(rest); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	out := []byte(packedMagic)
+	out = binary.AppendUvarint(out, uint64(len(snaps)))
+	out = binary.AppendUvarint(out, uint64(len(figures.Bytes())))
+	out = append(out, figures.Bytes()...)
+	return append(out, lines.Bytes()...), nil
+}
+
+// unpackSnapshots decodes a packed history file
+func unpackSnapshots(data []byte) ([]Snapshot, error) {
+	rest, ok := bytes.CutPrefix(data, []byte(packedMagic))
+	if !ok {
+		return nil, errors.New("not a packed history file, or of a newer advis")
+	}
+	count, n := binary.Uvarint(rest)
+	if n <= 0 {
+		return nil, gorilla.ErrShort
+	}
+	rest = rest[n:]
+	size, n := binary.Uvarint(rest)
+	if n <= 0 || uint64(len(rest)-n) < size {
+		return nil, gorilla.ErrShort
+	}
+	figures := gorilla.NewReader(rest[n : n+int(size)])
+	dec := json.NewDecoder(flate.NewReader(bytes.NewReader(rest[n+int(size):])))
+
+	var times gorilla.Ints
+	series := newPackedSeries()
+	snaps := make([]Snapshot, 0, min(count, size))
+	var failed error
+	for range count {
+		var snap Snapshot
+		if err := dec.Decode(&snap); err != nil {
+			return snaps, err
+		}
+		ms, err := times.Read(figures)
+		if err != nil {
+			return snaps, err
+		}
+		snap.Time = time.UnixMilli(ms)
+		series.figures(&snap, func(s *gorilla.Ints, v *uint64) {
+			if failed == nil {
+				var i int64
+				i, failed = s.Read(figures)
+				*v = uint64(i)
+			}
+		}, func(s *gorilla.Floats, v *float64) {
+			if failed == nil {
+				*v, failed = s.Read(figures)
+			}
+		})
+		if failed != nil {
+			return snaps, failed
+		}
+		snaps = append(snaps, snap)
+	}
+	return snaps, nil
+}
+
+// writePacked writes snaps as the packed file of day, in place of its
+// JSON lines
+func writePacked(day time.Time, snaps []Snapshot) error {
+	data, err := packSnapshots(snaps)
+	if err != nil {
+		return err
+	}
+	if err := writeAside(packedFile(day), data); err != nil {
+		return err
+	}
+	if err := os.Remove(historyFile(day)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// readPacked reads the packed file at path
+func readPacked(path string) ([]Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	snaps, err := unpackSnapshots(data)
+	if err != nil {
+		return snaps, fmt.Errorf("%s: %w", path, err)
+	}
+	return snaps, nil
+}
@@ -0,0 +1,131 @@
+package sysmon
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/proc"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/sysstat"
+)
+
+// daySnapshots makes n snapshots a minute apart, as a day of history
+// holds them. A VPN interface and a USB disk come and go halfway through,
+// and the counters of eth0 wrap.
+func daySnapshots(n int) []Snapshot {
+	rng := rand.New(rand.NewPCG(11, 12))
+	start := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	eth := netstat.Counter{Name: "eth0", RxBytes: math.MaxUint64 - uint64(n/2)*1<<20}
+	snaps := make([]Snapshot, n)
+	for i := range snaps {
+		eth.RxBytes += 1<<20 + uint64(rng.IntN(1<<12))
+		eth.TxBytes += 1<<18 + uint64(rng.IntN(1<<10))
+		eth.RxPackets += 900 + uint64(rng.IntN(50))
+		eth.TxPackets += 300 + uint64(rng.IntN(20))
+		s := Snapshot{
+			Time:     start.Add(time.Duration(i)*time.Minute + time.Duration(rng.IntN(50))*time.Millisecond),
+			Host:     "db1",
+			Kernel:   "6.8.0",
+			CPU:      math.Round(rng.Float64()*400) / 10,
+			Load:     math.Round(rng.Float64()*200) / 100,
+			MemTotal: 16 << 30,
+			MemUsed:  8<<30 + uint64(rng.IntN(1<<20))*4096,
+			Network:  []netstat.Counter{{Name: "lo", RxBytes: uint64(i) * 512, TxBytes: uint64(i) * 512}, eth},
+			Mounts: []sysstat.Disk{{Path: "/", Device: "/dev/sda1", FSType: "ext4",
+				Total: 100 << 30, Used: 40<<30 + uint64(i)*4096, Free: 60<<30 - uint64(i)*4096}},
+			TopCPU: []proc.Process{{PID: 1234, Name: "postgres", CPU: 12.5, FDs: -1}},
+		}
+		if i > n/3 && i < 2*n/3 {
+			s.Network = append(s.Network, netstat.Counter{Name: "tun0", RxBytes: uint64(i) * 100})
+			s.Mounts = append(s.Mounts, sysstat.Disk{Path: "/media/usb", Device: "/dev/sdb1", FSType: "vfat",
+				Total: 32 << 30, Used: uint64(i) << 20, Free: 32<<30 - uint64(i)<<20})
+		}
+		if i == n-1 {
+			s.Alerts = []Alert{{Level: alertWarning, Source: "disk", Message: "/ is 40% full"}}
+			s.Mounts[0].Hung = true
+		}
+		snaps[i] = s
+	}
+	return snaps
+}
+
+func TestPackSnapshotsRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 1440} {
+		want := daySnapshots(n)
+		data, err := packSnapshots(want)
+		if err != nil {
+			t.Fatalf("%d snapshots: pack: %v", n, err)
+		}
+		got, err := unpackSnapshots(data)
+		if err != nil {
+			t.Fatalf("%d snapshots: unpack: %v", n, err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%d snapshots: unpacked %d", n, len(got))
+		}
+		for i := range want {
+			if !got[i].Time.Equal(want[i].Time) {
+				t.Fatalf("snapshot %d: time %v, want %v", i, got[i].Time, want[i].Time)
+			}
+			g, w := got[i], want[i]
+			g.Time, w.Time = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(g, w) {
+				t.Fatalf("snapshot %d:\n got %+v\nwant %+v", i, g, w)
+			}
+		}
+	}
+}
+
+func TestUnpackSnapshotsDamaged(t *testing.T) {
+	data, err := packSnapshots(daySnapshots(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unpackSnapshots([]byte("{\"time\":1}\n")); err == nil {
+		t.Error("JSON lines unpacked as a packed file")
+	}
+	for _, n := range []int{len(packedMagic), len(packedMagic) + 3, len(data) / 2} {
+		if _, err := unpackSnapshots(data[:n]); err == nil {
+			t.Errorf("file cut at %d of %d bytes unpacked without an error", n, len(data))
+		}
+	}
+}
+
+// BenchmarkPackSnapshots packs a day of minute snapshots, reporting the
+// bytes each costs packed and as the JSON line it replaces
+func BenchmarkPackSnapshots(b *testing.B) {
+	snaps := daySnapshots(1440)
+	var lines int
+	for _, s := range snaps {
+		line, _ := json.Marshal(s)
+		lines += len(line) + 1
+	}
+	b.ReportAllocs()
+	var size int
+	for range b.N {
+		data, err := packSnapshots(snaps)
+		if err != nil {
+			b.Fatal(err)
+		}
+		size = len(data)
+	}
+	b.ReportMetric(float64(size)/float64(len(snaps)), "bytes/snapshot")
+	b.ReportMetric(float64(lines)/float64(len(snaps)), "json-bytes/snapshot")
+}
+
+func BenchmarkUnpackSnapshots(b *testing.B) {
+	data, err := packSnapshots(daySnapshots(1440))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for range b.N {
+		if _, err := unpackSnapshots(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
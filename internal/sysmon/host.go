@@ -0,0 +1,209 @@
+package sysmon
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/s-archdev/Terminal_ADVIS/pkg/sysstat"
+)
+
+// getHostInfo gathers the static host description shown on the System tab
+func getHostInfo() HostInfo {
+	h := HostInfo{Distro: runtime.GOOS, Kernel: "unknown", CPUModel: "unknown"}
+	h.Hostname, _ = os.Hostname()
+
+	if data, err := os.ReadFile("/etc/os-release"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if v, ok := strings.CutPrefix(line, "PRETTY_NAME="); ok {
+				h.Distro = strings.Trim(v, `"'`)
+			}
+		}
+	}
+	if data, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		h.Kernel = strings.TrimSpace(string(data))
+	}
+
+	readCPUInfo(&h)
+	h.Caches = readCPUCaches()
+	h.MemoryTotal = sysstat.ReadMeminfo()["MemTotal"]
+	h.MemoryModules = readMemoryModules()
+	h.Virtualization = detectVirtualization()
+	return h
+}
+
+// readCPUInfo fills in the CPU model and socket/core counts from /proc/cpuinfo
+func readCPUInfo(h *HostInfo) {
+	file, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	sockets := make(map[string]bool)
+	cores := make(map[string]bool)
+	var socket string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "model name", "Model", "cpu model":
+			if h.CPUModel == "unknown" {
+				h.CPUModel = value
+			}
+		case "physical id":
+			socket = value
+			sockets[value] = true
+		case "core id":
+			cores[socket+"/"+value] = true
+		}
+	}
+	h.CPUSockets = len(sockets)
+	h.CPUCores = len(cores)
+}
+
+// readCPUCaches lists the cache levels of cpu0 from sysfs
+func readCPUCaches() []string {
+	dirs, _ := filepath.Glob("/sys/devices/system/cpu/cpu0/cache/index*")
+	var caches []string
+	for _, dir := range dirs {
+		read := func(name string) string {
+			data, _ := os.ReadFile(filepath.Join(dir, name))
+			return strings.TrimSpace(string(data))
+		}
+		level, kind, size := read("level"), read("type"), read("size")
+		if level == "" || size == "" {
+			continue
+		}
+		name := "L" + level
+		switch kind {
+		case "Data":
+			name += "d"
+		case "Instruction":
+			name += "i"
+		}
+		caches = append(caches, name+" "+size)
+	}
+	return caches
+}
+
+// dmiMemoryTypes maps SMBIOS type 17 memory type codes to names
+var dmiMemoryTypes = map[byte]string{
+	0x12: "DDR", 0x13: "DDR2", 0x18: "DDR3", 0x1A: "DDR4", 0x1B: "LPDDR",
+	0x1C: "LPDDR2", 0x1D: "LPDDR3", 0x1E: "LPDDR4", 0x22: "DDR5", 0x23: "LPDDR5",
+}
+
+// readMemoryModules parses the SMBIOS type 17 (memory device) entries the
+// kernel exposes in /sys/firmware/dmi/entries. They are usually root-only.
+func readMemoryModules() []MemoryModule {
+	dirs, _ := filepath.Glob("/sys/firmware/dmi/entries/17-*")
+	var modules []MemoryModule
+	for _, dir := range dirs {
+		raw, err := os.ReadFile(filepath.Join(dir, "raw"))
+		if err != nil || len(raw) < 0x17 {
+			continue
+		}
+		formatted := int(raw[1])
+		if formatted > len(raw) {
+			continue
+		}
+		strs := strings.Split(string(raw[formatted:]), "\x00")
+		str := func(index byte) string {
+			if index == 0 || int(index) > len(strs) {
+				return ""
+			}
+			return strings.TrimSpace(strs[index-1])
+		}
+		word := func(off int) uint64 {
+			if off+1 >= formatted {
+				return 0
+			}
+			return uint64(raw[off]) | uint64(raw[off+1])<<8
+		}
+
+		// Size: 0 means an empty slot, bit 15 selects KiB units and
+		// 0x7FFF defers to the 32-bit extended size in MiB
+		size := word(0x0C)
+		switch {
+		case size == 0 || size == 0xFFFF:
+			continue
+		case size == 0x7FFF && formatted >= 0x20:
+			size = (uint64(raw[0x1C]) | uint64(raw[0x1D])<<8 | uint64(raw[0x1E])<<16 | uint64(raw[0x1F])<<24) << 20
+		case size&0x8000 != 0:
+			size = (size & 0x7FFF) << 10
+		default:
+			size <<= 20
+		}
+
+		mod := MemoryModule{
+			Locator:      str(raw[0x10]),
+			Size:         size,
+			Type:         dmiMemoryTypes[raw[0x12]],
+			SpeedMTs:     int(word(0x15)),
+			Manufacturer: str(raw[0x17]),
+		}
+		if formatted > 0x1A {
+			mod.PartNumber = str(raw[0x1A])
+		}
+		modules = append(modules, mod)
+	}
+	return modules
+}
+
+// detectVirtualization reports the hypervisor or container runtime we run
+// under, if any
+func detectVirtualization() string {
+	// Containers first: they usually also run on a hypervisor
+	switch {
+	case fileExists("/.dockerenv"):
+		return "container (docker)"
+	case fileExists("/run/.containerenv"):
+		return "container (podman)"
+	}
+	if data, err := os.ReadFile("/run/systemd/container"); err == nil {
+		return "container (" + strings.TrimSpace(string(data)) + ")"
+	}
+	if data, err := os.ReadFile("/proc/1/cgroup"); err == nil {
+		for _, name := range []string{"kubepods", "docker", "lxc", "containerd"} {
+			if strings.Contains(string(data), name) {
+				return "container (" + name + ")"
+			}
+		}
+	}
+	if data, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil &&
+		strings.Contains(strings.ToLower(string(data)), "microsoft") {
+		return "WSL"
+	}
+
+	// Hypervisors identify themselves in the DMI vendor strings
+	var dmi string
+	for _, name := range []string{"sys_vendor", "product_name", "bios_vendor"} {
+		data, _ := os.ReadFile("/sys/class/dmi/id/" + name)
+		dmi += strings.ToLower(string(data)) + " "
+	}
+	for _, vm := range []struct{ match, name string }{
+		{"kvm", "KVM"}, {"qemu", "QEMU"}, {"vmware", "VMware"},
+		{"virtualbox", "VirtualBox"}, {"microsoft corporation", "Hyper-V"},
+		{"xen", "Xen"}, {"amazon ec2", "AWS EC2"}, {"google compute", "Google Compute Engine"},
+		{"parallels", "Parallels"}, {"bochs", "Bochs"},
+	} {
+		if strings.Contains(dmi, vm.match) {
+			return "VM (" + vm.name + ")"
+		}
+	}
+	if data, err := os.ReadFile("/proc/cpuinfo"); err == nil && strings.Contains(string(data), " hypervisor") {
+		return "VM (unknown hypervisor)"
+	}
+	return "none (bare metal)"
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,56 @@
+package sysmon
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/s-archdev/Terminal_ADVIS/pkg/sysstat"
+)
+
+// getHugepageInfo reads hugetlb pools, THP settings and allocation counters
+func getHugepageInfo() HugepageInfo {
+	var h HugepageInfo
+
+	dirs, _ := filepath.Glob("/sys/kernel/mm/hugepages/hugepages-*kB")
+	for _, dir := range dirs {
+		size, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(dir), "hugepages-"), "kB"), 10, 64)
+		if err != nil {
+			continue
+		}
+		h.Pools = append(h.Pools, HugepagePool{
+			PageSize: size * 1024,
+			Total:    readUintFile(filepath.Join(dir, "nr_hugepages")),
+			Free:     readUintFile(filepath.Join(dir, "free_hugepages")),
+			Reserved: readUintFile(filepath.Join(dir, "resv_hugepages")),
+			Surplus:  readUintFile(filepath.Join(dir, "surplus_hugepages")),
+		})
+	}
+	sort.Slice(h.Pools, func(i, j int) bool { return h.Pools[i].PageSize < h.Pools[j].PageSize })
+
+	h.THPEnabled = selectedMode("/sys/kernel/mm/transparent_hugepage/enabled")
+	h.THPDefrag = selectedMode("/sys/kernel/mm/transparent_hugepage/defrag")
+	h.AnonHuge = sysstat.ReadMeminfo()["AnonHugePages"]
+
+	h.THPFaultAlloc = readKeyedValue("/proc/vmstat", "thp_fault_alloc")
+	h.THPFaultFallback = readKeyedValue("/proc/vmstat", "thp_fault_fallback")
+	h.THPCollapseFail = readKeyedValue("/proc/vmstat", "thp_collapse_alloc_failed")
+	h.HugetlbAllocFail = readKeyedValue("/proc/vmstat", "htlb_buddy_alloc_fail")
+	return h
+}
+
+// selectedMode returns the bracketed choice of a sysfs multiple-choice
+// file such as "always [madvise] never"
+func selectedMode(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	text := string(data)
+	if start, end := strings.IndexByte(text, '['), strings.IndexByte(text, ']'); start >= 0 && end > start {
+		return text[start+1 : end]
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,130 @@
+package sysmon
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+)
+
+const (
+	maxIRQSources     = 15
+	irqImbalanceShare = 90.0 // Percent of a source's interrupts on one CPU
+	irqImbalanceRate  = 1000.0
+)
+
+func interruptsCmd(s *irqSampler) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+		defer cancel()
+		start := time.Now()
+		stats, err := s.sample(ctx)
+		debug.Timing("sysmon: interrupts", start, err)
+		return interruptsMsg{stats: stats, err: err}
+	}
+}
+
+// sample reads /proc/interrupts and /proc/softirqs. A read cut short by
+// ctx leaves the previous one to compute rates against.
+func (s *irqSampler) sample(ctx context.Context) (InterruptStats, error) {
+	now := time.Now()
+	elapsed := now.Sub(s.last).Seconds()
+
+	hard, cpus := readInterruptCounters("/proc/interrupts")
+	soft, _ := readInterruptCounters("/proc/softirqs")
+	if err := ctx.Err(); err != nil {
+		return InterruptStats{}, err
+	}
+	stats := InterruptStats{CPUs: cpus, PerCPU: make([]float64, cpus)}
+
+	stats.Sources = irqRates(hard, s.prev, elapsed)
+	for _, src := range stats.Sources {
+		for cpu, r := range src.PerCPU {
+			stats.PerCPU[cpu] += r
+		}
+	}
+	stats.Softirqs = irqRates(soft, s.prevSoft, elapsed)
+	sort.SliceStable(stats.Sources, func(i, j int) bool { return stats.Sources[i].Rate > stats.Sources[j].Rate })
+
+	s.prev = make(map[string][]uint64, len(hard))
+	for _, c := range hard {
+		s.prev[c.name] = c.counts
+	}
+	s.prevSoft = make(map[string][]uint64, len(soft))
+	for _, c := range soft {
+		s.prevSoft[c.name] = c.counts
+	}
+	s.last = now
+	return stats, nil
+}
+
+// irqCounter is the raw per-CPU counts of one interrupt source
+type irqCounter struct {
+	name        string
+	description string
+	counts      []uint64
+}
+
+// irqRates converts counters into per-second rates against the previous
+// sample; sources seen for the first time get zero rates
+func irqRates(counters []irqCounter, prev map[string][]uint64, elapsed float64) []IRQSource {
+	sources := make([]IRQSource, 0, len(counters))
+	for _, c := range counters {
+		src := IRQSource{Name: c.name, Description: c.description, PerCPU: make([]float64, len(c.counts))}
+		if old, seen := prev[c.name]; seen && elapsed > 0 {
+			for cpu, n := range c.counts {
+				if cpu < len(old) && n >= old[cpu] {
+					src.PerCPU[cpu] = float64(n-old[cpu]) / elapsed
+					src.Rate += src.PerCPU[cpu]
+				}
+			}
+		}
+		sources = append(sources, src)
+	}
+	return sources
+}
+
+// readInterruptCounters parses the /proc/interrupts layout shared by
+// /proc/softirqs: a header naming the CPUs, then "NAME: n n n ... description"
+func readInterruptCounters(path string) ([]irqCounter, int) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0
+	}
+	lines := strings.Split(string(data), "\n")
+	if len(lines) == 0 {
+		return nil, 0
+	}
+	cpus := len(strings.Fields(lines[0]))
+
+	var counters []irqCounter
+	for _, line := range lines[1:] {
+		name, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		c := irqCounter{name: strings.TrimSpace(name), counts: make([]uint64, cpus)}
+		n := 0
+		for n < cpus && n < len(fields) {
+			v, err := strconv.ParseUint(fields[n], 10, 64)
+			if err != nil {
+				break
+			}
+			c.counts[n] = v
+			n++
+		}
+		// ERR and MIS carry a single total rather than per-CPU counts
+		if n < cpus && n <= 1 {
+			continue
+		}
+		c.description = strings.Join(fields[n:], " ")
+		counters = append(counters, c)
+	}
+	return counters, cpus
+}
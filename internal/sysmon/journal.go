@@ -0,0 +1,126 @@
+package sysmon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+)
+
+// journalTail follows journalctl output in the background
+type journalTail struct {
+	cancel  context.CancelFunc
+	entries chan journalEntry
+	err     error // Set before entries is closed
+}
+
+// startJournal runs journalctl in follow mode, starting with the most
+// recent 200 lines of the unit (or the whole journal)
+func startJournal(unit string) (*journalTail, error) {
+	args := []string{"--follow", "--output=json", "--lines=200", "--no-pager"}
+	if unit != "" {
+		args = append(args, "--unit", unit)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	t := &journalTail{cancel: cancel, entries: make(chan journalEntry, 256)}
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			e, ok := parseJournalLine(scanner.Bytes())
+			if !ok {
+				debug.Logf("journal: unparseable line %.200q", scanner.Bytes())
+				continue
+			}
+			select {
+			case t.entries <- e:
+			case <-ctx.Done():
+			}
+		}
+		if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+			t.err = err
+		}
+		close(t.entries)
+	}()
+	return t, nil
+}
+
+// waitJournalCmd waits for the next entries, batching whatever else is
+// already buffered so a burst of lines costs a single redraw
+func waitJournalCmd(t *journalTail) tea.Cmd {
+	return func() tea.Msg {
+		e, ok := <-t.entries
+		if !ok {
+			return journalClosedMsg{tail: t, err: t.err}
+		}
+		batch := []journalEntry{e}
+		for len(batch) < 200 {
+			select {
+			case e, ok := <-t.entries:
+				if !ok {
+					return journalMsg{tail: t, entries: batch}
+				}
+				batch = append(batch, e)
+			default:
+				return journalMsg{tail: t, entries: batch}
+			}
+		}
+		return journalMsg{tail: t, entries: batch}
+	}
+}
+
+// parseJournalLine decodes one line of `journalctl --output=json`
+func parseJournalLine(line []byte) (journalEntry, bool) {
+	var fields map[string]any
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return journalEntry{}, false
+	}
+
+	e := journalEntry{Priority: 6}
+	if v, ok := fields["__REALTIME_TIMESTAMP"].(string); ok {
+		if usec, err := strconv.ParseInt(v, 10, 64); err == nil {
+			e.Time = time.UnixMicro(usec)
+		}
+	}
+	if v, ok := fields["PRIORITY"].(string); ok {
+		e.Priority, _ = strconv.Atoi(v)
+	}
+	for _, key := range []string{"SYSLOG_IDENTIFIER", "_SYSTEMD_UNIT", "_COMM"} {
+		if v, ok := fields[key].(string); ok {
+			e.Source = v
+			break
+		}
+	}
+	switch msg := fields["MESSAGE"].(type) {
+	case string:
+		e.Message = msg
+	case []any:
+		// Non-UTF-8 messages are encoded as an array of bytes
+		b := make([]byte, 0, len(msg))
+		for _, v := range msg {
+			if n, ok := v.(float64); ok {
+				b = append(b, byte(n))
+			}
+		}
+		e.Message = strings.ToValidUTF8(string(b), "�")
+	}
+	return e, true
+}
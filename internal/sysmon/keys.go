@@ -0,0 +1,232 @@
+package sysmon
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// keyMap holds every key binding of the system monitor. The key tags are
+// the names the "sys" section of the config file overrides them by.
+type keyMap struct {
+	Quit       key.Binding `key:"quit"`
+	NextTab    key.Binding `key:"next_tab"`
+	Tabs       key.Binding `key:"tabs"` // One key per tab, in tab bar order
+	HideTab    key.Binding `key:"hide_tab"`
+	ShowTabs   key.Binding `key:"show_tabs"`
+	CopyRow    key.Binding `key:"copy_row"`
+	CopyTable  key.Binding `key:"copy_table"`
+	Errors     key.Binding `key:"errors"`
+	Screenshot key.Binding `key:"screenshot"`
+	Help       key.Binding `key:"help"`
+
+	// Prompts and text inputs
+	Confirm key.Binding `key:"confirm"`
+	Cancel  key.Binding `key:"cancel"`
+	Accept  key.Binding `key:"accept"`
+	Dismiss key.Binding `key:"dismiss"`
+
+	// Alert center
+	AlertCenter key.Binding `key:"alert_center"`
+	Ack         key.Binding `key:"ack"`
+	Silence     key.Binding `key:"silence"`
+
+	// Action log
+	AuditLog key.Binding `key:"audit_log"`
+
+	// Journal pane
+	Logs        key.Binding `key:"logs"`
+	LogsBack    key.Binding `key:"logs_back"`
+	LogsForward key.Binding `key:"logs_forward"`
+	LogsFilter  key.Binding `key:"logs_filter"`
+
+	// Tabs
+	Pin      key.Binding `key:"pin"`
+	Sort     key.Binding `key:"sort"`
+	ByUser   key.Binding `key:"by_user"`
+	Stuck    key.Binding `key:"stuck"`
+	Graph    key.Binding `key:"graph"`
+	Scan     key.Binding `key:"scan"`
+	EditPath key.Binding `key:"edit_path"`
+	Open     key.Binding `key:"open"`
+	Parent   key.Binding `key:"parent"`
+	Delete   key.Binding `key:"delete"`
+	VMs      key.Binding `key:"vms"`
+	Start    key.Binding `key:"start"`
+	Stop     key.Binding `key:"stop"`
+	Restart  key.Binding `key:"restart"`
+	Failed   key.Binding `key:"failed"`
+	Expand   key.Binding `key:"expand"`
+
+	ui.NavKeyMap
+	ui.SplitKeyMap
+}
+
+var keys = keyMap{
+	Quit:       key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+	NextTab:    key.NewBinding(key.WithKeys("tab"), key.WithHelp("Tab", "cycle")),
+	Tabs:       key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9", "0", "W"), key.WithHelp("1-0/W", "switch tabs")),
+	HideTab:    key.NewBinding(key.WithKeys("H"), key.WithHelp("H", "hide tab")),
+	ShowTabs:   key.NewBinding(key.WithKeys("U"), key.WithHelp("U", "show hidden tabs")),
+	CopyRow:    key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy row")),
+	CopyTable:  key.NewBinding(key.WithKeys("Y"), key.WithHelp("Y", "copy table")),
+	Errors:     key.NewBinding(key.WithKeys("!"), key.WithHelp("!", "error details")),
+	Screenshot: key.NewBinding(key.WithKeys("P"), key.WithHelp("P", "screenshot")),
+	Help:       key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "all keys")),
+
+	Confirm: key.NewBinding(key.WithKeys("y", "Y"), key.WithHelp("y", "confirm")),
+	Cancel:  key.NewBinding(key.WithKeys("n", "N", "esc", "ctrl+c"), key.WithHelp("N", "cancel")),
+	Accept:  key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "accept")),
+	Dismiss: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "dismiss")),
+
+	AlertCenter: key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "alert center")),
+	Ack:         key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "acknowledge")),
+	Silence:     key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "silence source")),
+
+	AuditLog: key.NewBinding(key.WithKeys("T"), key.WithHelp("T", "action log")),
+
+	Logs:        key.NewBinding(key.WithKeys("L"), key.WithHelp("L", "logs")),
+	LogsBack:    key.NewBinding(key.WithKeys("["), key.WithHelp("[", "scroll logs")),
+	LogsForward: key.NewBinding(key.WithKeys("]"), key.WithHelp("]", "scroll logs")),
+	LogsFilter:  key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+
+	Pin:      key.NewBinding(key.WithKeys("p", " "), key.WithHelp("p", "pin to overview")),
+	Sort:     key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "sort column")),
+	ByUser:   key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "group by user")),
+	Stuck:    key.NewBinding(key.WithKeys("z"), key.WithHelp("z", "zombie/D only")),
+	Graph:    key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "top-5 graph")),
+	Scan:     key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "scan")),
+	EditPath: key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit path")),
+	Open:     key.NewBinding(key.WithKeys("enter", "right", "l"), key.WithHelp("enter", "open")),
+	Parent:   key.NewBinding(key.WithKeys("backspace", "left", "h"), key.WithHelp("⌫", "up")),
+	Delete:   key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete file")),
+	VMs:      key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "VMs")),
+	Start:    key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "start")),
+	Stop:     key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "stop")),
+	Restart:  key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "restart")),
+	Failed:   key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "failed only")),
+	Expand:   key.NewBinding(key.WithKeys("enter", " "), key.WithHelp("enter", "expand/collapse")),
+
+	NavKeyMap:   ui.NewNavKeyMap(),
+	SplitKeyMap: ui.NewSplitKeyMap(),
+}
+
+// SetKeys applies the "sys" key overrides of the config file
+func SetKeys(overrides map[string][]string) error {
+	return ui.Rebind(&keys, overrides)
+}
+
+// tabKey is the key that selects a tab, by default the number of its
+// place on the tab bar with the tenth on 0
+func (m model) tabKey(tab int) string {
+	if i, tabs := m.tabs.Position(tab), keys.Tabs.Keys(); i >= 0 && i < len(tabs) {
+		return tabs[i]
+	}
+	return ""
+}
+
+// tabIndex is the tab a key of the Tabs binding selects, or -1
+func (m model) tabIndex(k string) int {
+	return m.tabs.At(slices.Index(keys.Tabs.Keys(), k))
+}
+
+// tabBindings are the bindings specific to the current tab
+func (m model) tabBindings() []key.Binding {
+	switch m.tab {
+	case tabDisk:
+		return []key.Binding{keys.Up, keys.Down, keys.Pin}
+	case tabProcess:
+		return []key.Binding{keys.Up, keys.Down, keys.Sort, keys.ByUser, keys.Stuck, keys.Graph}
+	case tabDirScan:
+		return []key.Binding{keys.Scan, keys.EditPath, keys.Open, keys.Parent, keys.Sort, keys.Delete}
+	case tabContainers:
+		vms := keys.VMs
+		if m.vmView {
+			vms.SetHelp(vms.Help().Key, "containers")
+		}
+		if *flagContainerActions && !m.vmView {
+			return []key.Binding{keys.Up, keys.Down, keys.Stop, keys.Restart, vms}
+		}
+		return []key.Binding{keys.Up, keys.Down, vms}
+	case tabServices:
+		unitLogs := keys.Logs
+		unitLogs.SetHelp(unitLogs.Help().Key, "unit logs")
+		return []key.Binding{keys.Up, keys.Down, keys.Failed, keys.Start, keys.Stop, keys.Restart, unitLogs}
+	case tabCgroups:
+		return []key.Binding{keys.Up, keys.Down, keys.Expand, keys.Sort}
+	}
+	return nil
+}
+
+// help is the footer line for the current tab, built from the active
+// bindings
+func (m model) help() string {
+	tab := m.tabBindings()
+	if m.journal.open {
+		tab = append(tab, keys.LogsBack, keys.LogsForward, keys.LogsFilter)
+	} else if m.tab != tabServices {
+		tab = append(tab, keys.Logs)
+	}
+	if len(m.alerts) > 0 {
+		tab = append(tab, keys.AlertCenter)
+	}
+	// Where actions are taken, their log
+	if m.tab == tabDirScan || m.tab == tabContainers || m.tab == tabServices {
+		tab = append(tab, keys.AuditLog)
+	}
+	if m.split.Active() {
+		tab = append(tab, keys.NextPane, keys.ClosePane)
+	}
+	if m.split.Zoomed {
+		tab = append(tab, zoomOut())
+	}
+	return ui.HelpLine(append([]key.Binding{keys.CopyRow, keys.CopyTable},
+		append(tab, ui.TrimKeys(keys.Tabs, len(m.tabs.Shown())), keys.NextTab, m.showTabs(), keys.Help, keys.Quit)...)...)
+}
+
+// showTabs is the binding restoring hidden tabs, offered only while some
+// are
+func (m model) showTabs() key.Binding {
+	b := keys.ShowTabs
+	b.SetEnabled(len(m.tabs.Hidden) > 0)
+	return b
+}
+
+// zoomOut is the zoom binding as the footer offers it while zoomed
+func zoomOut() key.Binding {
+	b := keys.Zoom
+	b.SetHelp(b.Help().Key, "unzoom")
+	return b
+}
+
+// renderHelp is the help overlay: every binding of the current tab and
+// those working everywhere
+func (m model) renderHelp() string {
+	groups := []ui.KeyGroup{{Title: tabNames[m.tab], Bindings: m.tabBindings()}}
+	switch m.tab {
+	case tabDirScan:
+		groups = append(groups, ui.KeyGroup{Title: "Path input",
+			Bindings: []key.Binding{keys.Accept, keys.Dismiss}})
+		fallthrough
+	case tabContainers, tabServices:
+		groups = append(groups, ui.KeyGroup{Title: "Confirmation",
+			Bindings: []key.Binding{keys.Confirm, keys.Cancel}})
+	}
+	groups = append(groups,
+		ui.KeyGroup{Title: "Tables", Bindings: keys.NavKeyMap.Bindings()},
+		ui.KeyGroup{Title: "Alert center", Bindings: []key.Binding{
+			keys.AlertCenter, keys.Up, keys.Down, keys.Ack, keys.Silence, keys.Dismiss}},
+		ui.KeyGroup{Title: "Action log", Bindings: []key.Binding{
+			keys.AuditLog, keys.Up, keys.Down, exportAudit(), keys.Dismiss}},
+		ui.KeyGroup{Title: "Journal", Bindings: []key.Binding{
+			keys.Logs, keys.LogsBack, keys.LogsForward, keys.LogsFilter, keys.Accept, keys.Dismiss}},
+		ui.KeyGroup{Title: "Panes", Bindings: []key.Binding{
+			keys.Stacked, keys.SideBySide, keys.NextPane, keys.PrevPane, keys.ClosePane, keys.Grow, keys.Shrink, keys.Zoom}},
+		ui.KeyGroup{Title: "Everywhere", Bindings: []key.Binding{
+			keys.CopyRow, keys.CopyTable, ui.TrimKeys(keys.Tabs, len(m.tabs.Shown())), keys.NextTab,
+			keys.HideTab, keys.ShowTabs,
+			keys.Errors, keys.Screenshot, keys.Help, keys.Quit}},
+	)
+	return ui.HelpOverlay("⌨️  Keys", groups, m.width) + "\n" + infoStyle.Render("Press any key to close") + "\n"
+}
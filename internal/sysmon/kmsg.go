@@ -0,0 +1,134 @@
+package sysmon
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+)
+
+const (
+	// maxKernelEvents caps how many matched kernel messages are kept
+	maxKernelEvents = 200
+	// kernelAlertDuration is how long a kernel event stays in the alert banner
+	kernelAlertDuration = 10 * time.Minute
+)
+
+// kernelPatterns classifies kernel messages worth alerting on
+var kernelPatterns = []struct {
+	kind  string
+	level int
+	re    *regexp.Regexp
+}{
+	{"OOM kill", alertCritical, regexp.MustCompile(`Out of memory: Killed process|oom-kill:|invoked oom-killer`)},
+	{"I/O error", alertCritical, regexp.MustCompile(`I/O error|critical medium error|Medium Error|ata\d+.*failed command`)},
+	{"read-only remount", alertCritical, regexp.MustCompile(`(?i)remounting filesystem read-only|shutting down filesystem|forced shutdown`)},
+	{"thermal", alertWarning, regexp.MustCompile(`(?i)temperature above threshold|clock throttled|thermal.*critical`)},
+}
+
+// kmsgTail reads /dev/kmsg in the background
+type kmsgTail struct {
+	events chan kernelEvent
+}
+
+// startKmsg opens /dev/kmsg and starts matching records from the start of
+// the ring buffer
+func startKmsg() (*kmsgTail, error) {
+	file, err := os.Open("/dev/kmsg")
+	if err != nil {
+		return nil, err
+	}
+
+	boot := bootTime()
+	t := &kmsgTail{events: make(chan kernelEvent, 64)}
+	go func() {
+		defer file.Close()
+		buf := make([]byte, 8192)
+		for {
+			// Every read returns exactly one record
+			n, err := file.Read(buf)
+			if errors.Is(err, syscall.EPIPE) {
+				debug.Logf("kmsg: records overwritten before they were read")
+				continue
+			}
+			if err != nil {
+				close(t.events)
+				return
+			}
+			if e, ok := parseKmsgRecord(string(buf[:n]), boot); ok {
+				t.events <- e
+			}
+		}
+	}()
+	return t, nil
+}
+
+// waitKmsgCmd waits for the next matched kernel events, batching any
+// that are already buffered
+func waitKmsgCmd(t *kmsgTail) tea.Cmd {
+	return func() tea.Msg {
+		e, ok := <-t.events
+		if !ok {
+			return nil
+		}
+		batch := kernelMsg{e}
+		for {
+			select {
+			case e, ok := <-t.events:
+				if !ok {
+					return batch
+				}
+				batch = append(batch, e)
+			default:
+				return batch
+			}
+		}
+	}
+}
+
+// parseKmsgRecord matches a "prio,seq,usec,flags;message" record against
+// the kernelPatterns
+func parseKmsgRecord(record string, boot time.Time) (kernelEvent, bool) {
+	header, message, ok := strings.Cut(record, ";")
+	if !ok {
+		return kernelEvent{}, false
+	}
+	// Continuation lines carry key=value metadata
+	message, _, _ = strings.Cut(message, "\n")
+
+	for _, p := range kernelPatterns {
+		if !p.re.MatchString(message) {
+			continue
+		}
+		e := kernelEvent{Time: time.Now(), Kind: p.kind, Level: p.level, Message: message}
+		if fields := strings.Split(header, ","); len(fields) >= 3 {
+			if usec, err := strconv.ParseInt(fields[2], 10, 64); err == nil && !boot.IsZero() {
+				e.Time = boot.Add(time.Duration(usec) * time.Microsecond)
+			}
+		}
+		return e, true
+	}
+	return kernelEvent{}, false
+}
+
+// bootTime reads the boot time from the btime line of /proc/stat
+func bootTime() time.Time {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if v, ok := strings.CutPrefix(line, "btime "); ok {
+			if sec, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return time.Unix(sec, 0)
+			}
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,93 @@
+package sysmon
+
+import (
+	"sort"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/ring"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/proc"
+)
+
+const (
+	leakSampleInterval = 30 * time.Second
+	leakMinSamples     = 10 // Samples needed before judging a process
+	leakMaxSamples     = 20
+)
+
+// update records an RSS sample per process every leakSampleInterval and
+// returns the processes that grew at every sample at or above -leak-rate
+func (t *leakTracker) update(procs []proc.Process, now time.Time) []LeakSuspect {
+	if now.Sub(t.last) < leakSampleInterval {
+		return t.suspects
+	}
+	t.last = now
+
+	seen := make(map[int]bool, len(procs))
+	for _, p := range procs {
+		seen[p.PID] = true
+		h := t.history[p.PID]
+		if h == nil || h.name != p.Name {
+			h = &rssHistory{name: p.Name, samples: ring.New[rssSample](leakMaxSamples)}
+			t.history[p.PID] = h
+		}
+		h.samples.Push(rssSample{time: now, rss: p.Memory})
+	}
+	for pid := range t.history {
+		if !seen[pid] {
+			delete(t.history, pid)
+		}
+	}
+
+	threshold := *flagLeakRate * 1024 * 1024 / 60
+	t.suspects = nil
+	for pid, h := range t.history {
+		if h.samples.Len() < leakMinSamples || !nonDecreasing(h) {
+			continue
+		}
+		first, last := h.samples.At(0), h.samples.Last()
+		if slope := rssSlope(h); last.rss > first.rss && slope >= threshold {
+			t.suspects = append(t.suspects, LeakSuspect{
+				PID:    pid,
+				Name:   h.name,
+				RSS:    last.rss,
+				Growth: last.rss - first.rss,
+				Slope:  slope,
+				Window: last.time.Sub(first.time),
+			})
+		}
+	}
+	sort.Slice(t.suspects, func(i, j int) bool { return t.suspects[i].Slope > t.suspects[j].Slope })
+	return t.suspects
+}
+
+// nonDecreasing reports whether no sample is smaller than the one before
+func nonDecreasing(h *rssHistory) bool {
+	var prev uint64
+	for _, s := range h.samples.All() {
+		if s.rss < prev {
+			return false
+		}
+		prev = s.rss
+	}
+	return true
+}
+
+// rssSlope fits a least-squares line through the samples, in bytes per second
+func rssSlope(h *rssHistory) float64 {
+	n := float64(h.samples.Len())
+	start := h.samples.At(0).time
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range h.samples.All() {
+		x := s.time.Sub(start).Seconds()
+		y := float64(s.rss)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denom
+}
@@ -0,0 +1,166 @@
+package sysmon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+)
+
+// libvirtURI is the connection virsh uses; read-only access to the system
+// daemon is enough for statistics
+const libvirtURI = "qemu:///system"
+
+// libvirtClient collects domain statistics through virsh, which avoids
+// linking against libvirt with cgo
+type libvirtClient struct {
+	virsh string
+	uri   string
+
+	mu   sync.Mutex
+	prev map[string]vmCounters
+	last time.Time
+}
+
+// vmCounters are the cumulative counters of a domain at the last poll
+type vmCounters struct {
+	cpuTime            uint64 // Nanoseconds
+	readBytes, written uint64
+	rxBytes, txBytes   uint64
+}
+
+// detectLibvirt returns a client when virsh is installed, or nil
+func detectLibvirt() *libvirtClient {
+	path, err := exec.LookPath("virsh")
+	if err != nil {
+		return nil
+	}
+	uri := libvirtURI
+	if env := os.Getenv("LIBVIRT_DEFAULT_URI"); env != "" {
+		uri = env
+	}
+	return &libvirtClient{virsh: path, uri: uri}
+}
+
+func vmsCmd(c *libvirtClient) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		vms, err := c.list()
+		debug.Timing("sysmon: virsh domstats", start, err)
+		return vmsMsg{vms: vms, err: err}
+	}
+}
+
+// list returns the running domains with rates since the previous call
+func (c *libvirtClient) list() ([]VM, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, c.virsh, "--readonly", "--connect", c.uri,
+		"domstats", "--list-running", "--raw", "--state", "--cpu-total", "--balloon", "--vcpu", "--interface", "--block").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("virsh: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("virsh: %w", err)
+	}
+	stats := parseDomstats(string(out))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	elapsed := now.Sub(c.last).Seconds()
+	counters := make(map[string]vmCounters, len(stats))
+
+	vms := make([]VM, 0, len(stats))
+	for _, name := range sortedKeys(stats) {
+		st := stats[name]
+		value := func(key string) uint64 {
+			n, _ := strconv.ParseUint(st[key], 10, 64)
+			return n
+		}
+		// Devices are numbered fields such as "block.0.rd.bytes"
+		sum := func(prefix, suffix string) uint64 {
+			var total uint64
+			for i := uint64(0); i < value(prefix+".count"); i++ {
+				total += value(fmt.Sprintf("%s.%d.%s", prefix, i, suffix))
+			}
+			return total
+		}
+
+		vm := VM{
+			Name:          name,
+			VCPUs:         int(value("vcpu.current")),
+			BalloonActual: value("balloon.current") * 1024,
+			BalloonMax:    value("balloon.maximum") * 1024,
+			RSS:           value("balloon.rss") * 1024,
+		}
+		cur := vmCounters{
+			cpuTime:   value("cpu.time"),
+			readBytes: sum("block", "rd.bytes"),
+			written:   sum("block", "wr.bytes"),
+			rxBytes:   sum("net", "rx.bytes"),
+			txBytes:   sum("net", "tx.bytes"),
+		}
+		if prev, ok := c.prev[name]; ok && elapsed > 0 {
+			rate := func(cur, prev uint64) float64 {
+				if cur < prev {
+					return 0
+				}
+				return float64(cur-prev) / elapsed
+			}
+			vm.CPU = rate(cur.cpuTime, prev.cpuTime) / 1e9 * 100
+			vm.ReadRate = rate(cur.readBytes, prev.readBytes)
+			vm.WriteRate = rate(cur.written, prev.written)
+			vm.RxRate = rate(cur.rxBytes, prev.rxBytes)
+			vm.TxRate = rate(cur.txBytes, prev.txBytes)
+		}
+		counters[name] = cur
+		vms = append(vms, vm)
+	}
+	c.prev = counters
+	c.last = now
+	return vms, nil
+}
+
+// parseDomstats splits "virsh domstats --raw" output into key=value maps
+// per domain:
+//
+//	Domain: 'web1'
+//	  state.state=1
+//	  cpu.time=123456789
+func parseDomstats(out string) map[string]map[string]string {
+	domains := make(map[string]map[string]string)
+	var cur map[string]string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if name, ok := strings.CutPrefix(line, "Domain:"); ok {
+			cur = make(map[string]string)
+			domains[strings.Trim(strings.TrimSpace(name), `'"`)] = cur
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok && cur != nil {
+			cur[key] = value
+		}
+	}
+	return domains
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
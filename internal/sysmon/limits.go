@@ -0,0 +1,139 @@
+package sysmon
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/platform"
+)
+
+const (
+	limitsPollInterval = 10 * time.Second
+	limitAlertPercent  = 80.0
+)
+
+// limitsCmd measures kernel limit consumption off the UI goroutine, since
+// it reads the maps and fdinfo of every process
+func limitsCmd() tea.Cmd {
+	return func() tea.Msg {
+		return limitsMsg(readKernelLimits())
+	}
+}
+
+// readKernelLimits compares pid_max, threads-max, vm.max_map_count and
+// the inotify limits against their current use
+func readKernelLimits() []KernelLimit {
+	// Every thread takes a PID; /proc/loadavg counts them as "running/total"
+	var threads uint64
+	if data, err := os.ReadFile("/proc/loadavg"); err == nil {
+		if fields := strings.Fields(string(data)); len(fields) >= 4 {
+			_, total, _ := strings.Cut(fields[3], "/")
+			threads, _ = strconv.ParseUint(total, 10, 64)
+		}
+	}
+	limits := []KernelLimit{
+		{Name: "kernel.pid_max", Used: threads, Max: readUintFile("/proc/sys/kernel/pid_max")},
+		{Name: "kernel.threads-max", Used: threads, Max: readUintFile("/proc/sys/kernel/threads-max")},
+	}
+
+	entries, _ := os.ReadDir("/proc")
+	var maxMaps uint64
+	mapsOwner := ""
+	watches := make(map[uint32]uint64)
+	instances := make(map[uint32]uint64)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		dir := filepath.Join("/proc", entry.Name())
+		if n := countLines(filepath.Join(dir, "maps")); n > maxMaps {
+			maxMaps = n
+			mapsOwner = fmt.Sprintf("%s (%d)", readComm(pid), pid)
+		}
+
+		var uid uint32
+		if info, err := entry.Info(); err == nil {
+			if st, ok := platform.StatOf(info); ok {
+				uid = st.UID
+			}
+		}
+		fds, _ := os.ReadDir(filepath.Join(dir, "fd"))
+		for _, fd := range fds {
+			if target, _ := os.Readlink(filepath.Join(dir, "fd", fd.Name())); target != "anon_inode:inotify" {
+				continue
+			}
+			instances[uid]++
+			watches[uid] += countInotifyWatches(filepath.Join(dir, "fdinfo", fd.Name()))
+		}
+	}
+	limits = append(limits, KernelLimit{
+		Name: "vm.max_map_count", Used: maxMaps, Max: readUintFile("/proc/sys/vm/max_map_count"), Detail: mapsOwner,
+	})
+
+	busiestUser := func(counts map[uint32]uint64) (uint64, string) {
+		var most uint64
+		name := ""
+		for uid, n := range counts {
+			if n > most {
+				most = n
+				name = strconv.FormatUint(uint64(uid), 10)
+				if u, err := user.LookupId(name); err == nil {
+					name = u.Username
+				}
+				name = "user " + name
+			}
+		}
+		return most, name
+	}
+	used, who := busiestUser(watches)
+	limits = append(limits, KernelLimit{
+		Name: "fs.inotify.max_user_watches", Used: used, Max: readUintFile("/proc/sys/fs/inotify/max_user_watches"), Detail: who,
+	})
+	used, who = busiestUser(instances)
+	limits = append(limits, KernelLimit{
+		Name: "fs.inotify.max_user_instances", Used: used, Max: readUintFile("/proc/sys/fs/inotify/max_user_instances"), Detail: who,
+	})
+	return limits
+}
+
+// countLines returns the number of lines in a file, 0 when unreadable
+func countLines(path string) uint64 {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	var n uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		n++
+	}
+	return n
+}
+
+// countInotifyWatches counts the "inotify wd:" lines of an inotify fdinfo
+func countInotifyWatches(path string) uint64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	return uint64(strings.Count(string(data), "inotify wd:"))
+}
+
+// readComm returns the command name of a process
+func readComm(pid int) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return "?"
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,89 @@
+package sysmon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+)
+
+// memoryCmd reads the NUMA nodes and hugepage pools the Memory tab shows
+func memoryCmd(s *numaSampler) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+		defer cancel()
+		start := time.Now()
+		nodes, err := s.sample(ctx)
+		msg := memoryMsg{numa: nodes, err: err}
+		if err == nil {
+			msg.hugepages = getHugepageInfo()
+		}
+		debug.Timing("sysmon: NUMA and hugepages", start, err)
+		return msg
+	}
+}
+
+// sample reads every node under /sys/devices/system/node. A read cut
+// short by ctx leaves the previous one to compute rates against.
+func (s *numaSampler) sample(ctx context.Context) ([]NUMANode, error) {
+	dirs, _ := filepath.Glob("/sys/devices/system/node/node[0-9]*")
+	now := time.Now()
+	elapsed := now.Sub(s.last).Seconds()
+	misses := make(map[int]uint64, len(dirs))
+
+	var nodes []NUMANode
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "node"))
+		if err != nil {
+			continue
+		}
+		n := NUMANode{ID: id}
+		if data, err := os.ReadFile(filepath.Join(dir, "cpulist")); err == nil {
+			n.CPUs = strings.TrimSpace(string(data))
+		}
+
+		// meminfo lines look like "Node 0 MemTotal:       32768 kB"
+		if data, err := os.ReadFile(filepath.Join(dir, "meminfo")); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				fields := strings.Fields(line)
+				if len(fields) < 4 {
+					continue
+				}
+				value, _ := strconv.ParseUint(fields[3], 10, 64)
+				switch fields[2] {
+				case "MemTotal:":
+					n.MemTotal = value * 1024
+				case "MemFree:":
+					n.MemFree = value * 1024
+				}
+			}
+		}
+
+		stat := filepath.Join(dir, "numastat")
+		n.Hit = readKeyedValue(stat, "numa_hit")
+		n.Miss = readKeyedValue(stat, "numa_miss")
+		n.Foreign = readKeyedValue(stat, "numa_foreign")
+		n.LocalNode = readKeyedValue(stat, "local_node")
+		n.OtherNode = readKeyedValue(stat, "other_node")
+
+		if prev, seen := s.prevMiss[id]; seen && elapsed > 0 && n.Miss >= prev {
+			n.MissRate = float64(n.Miss-prev) / elapsed
+		}
+		misses[id] = n.Miss
+		nodes = append(nodes, n)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	s.prevMiss = misses
+	s.last = now
+	return nodes, nil
+}
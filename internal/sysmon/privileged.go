@@ -0,0 +1,67 @@
+package sysmon
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/caps"
+)
+
+// RunPrivileged implements "advis privileged", the one action the monitor
+// reruns as root through sudo or pkexec when it was denied, so the
+// monitor itself never has to run as root:
+//
+//	advis privileged delete-file PATH
+//	advis privileged service start|stop|restart NAME
+//	advis privileged container stop|restart ID
+func RunPrivileged(args []string) error {
+	switch {
+	case len(args) == 2 && args[0] == "delete-file":
+		return os.Remove(args[1])
+	case len(args) == 3 && args[0] == "service" && slices.Contains([]string{"start", "stop", "restart"}, args[1]):
+		return (&systemdClient{}).unitAction(args[2], args[1])
+	case len(args) == 3 && args[0] == "container" && slices.Contains([]string{"stop", "restart"}, args[1]):
+		rt := detectContainerRuntime()
+		if rt == nil {
+			return errors.New("no Docker or Podman socket found")
+		}
+		return rt.action(args[2], args[1])
+	}
+	return fmt.Errorf("unknown action %q", strings.Join(args, " "))
+}
+
+// denied reports whether err is the OS or systemd refusing this user
+func denied(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, fs.ErrPermission) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "AccessDenied") || strings.Contains(msg, "InteractiveAuthorizationRequired")
+}
+
+// offerRoot asks, once an action failed with err, whether to rerun it as
+// root through "advis privileged" with args. done turns the outcome of
+// the rerun into the message the action reports with.
+func (m *model) offerRoot(err error, what string, done func(error) tea.Msg, args ...string) {
+	if !denied(err) || *flagReadOnly || m.source == "demo" {
+		return
+	}
+	cmd, err := caps.Elevate(append([]string{"privileged"}, args...)...)
+	if err != nil {
+		m.flash = what + " needs root, and " + err.Error()
+		return
+	}
+	m.confirm = &confirmPrompt{
+		message: what + " was denied. Retry as root through " + filepath.Base(cmd.Args[0]) + "?",
+		action:  tea.ExecProcess(cmd, func(err error) tea.Msg { return done(err) }),
+	}
+}
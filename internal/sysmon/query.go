@@ -0,0 +1,327 @@
+package sysmon
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/config"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+)
+
+// queryMetrics are the figures a query reads from the history, each
+// taking the snapshot before for the rates. unit formats a value for the
+// text output.
+var queryMetrics = map[string]struct {
+	needs string // The key naming what the metric is of, if any
+	unit  func(float64) string
+	value func(q query, prev, cur *Snapshot) (float64, bool)
+}{
+	"cpu": {unit: percentUnit, value: func(_ query, _, cur *Snapshot) (float64, bool) { return cur.CPU, true }},
+	"load": {unit: func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) },
+		value: func(_ query, _, cur *Snapshot) (float64, bool) { return cur.Load, true }},
+	"mem": {unit: bytesUnit, value: func(_ query, _, cur *Snapshot) (float64, bool) { return float64(cur.MemUsed), true }},
+	"disk": {needs: "mount", unit: bytesUnit, value: func(q query, _, cur *Snapshot) (float64, bool) {
+		for _, d := range cur.Mounts {
+			if d.Path == q.mount {
+				return float64(d.Used), true
+			}
+		}
+		return 0, false
+	}},
+	"down": {needs: "iface", unit: rateUnit, value: func(q query, prev, cur *Snapshot) (float64, bool) {
+		return interfaceRate(q.iface, prev, cur, func(c netstat.Counter) uint64 { return c.RxBytes })
+	}},
+	"up": {needs: "iface", unit: rateUnit, value: func(q query, prev, cur *Snapshot) (float64, bool) {
+		return interfaceRate(q.iface, prev, cur, func(c netstat.Counter) uint64 { return c.TxBytes })
+	}},
+}
+
+func percentUnit(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) + "%" }
+func bytesUnit(v float64) string   { return ui.FormatBytes(uint64(v)) }
+func rateUnit(v float64) string    { return ui.FormatBytes(uint64(v)) + "/s" }
+
+// interfaceRate is the rate of the counter of iface between two snapshots.
+// A counter going back is a reboot or a reset, not a rate.
+func interfaceRate(iface string, prev, cur *Snapshot, counter func(netstat.Counter) uint64) (float64, bool) {
+	if prev == nil || !cur.Time.After(prev.Time) {
+		return 0, false
+	}
+	find := func(s *Snapshot) (netstat.Counter, bool) {
+		for _, c := range s.Network {
+			if c.Name == iface {
+				return c, true
+			}
+		}
+		return netstat.Counter{}, false
+	}
+	a, ok1 := find(prev)
+	b, ok2 := find(cur)
+	if !ok1 || !ok2 || counter(b) < counter(a) {
+		return 0, false
+	}
+	return float64(counter(b)-counter(a)) / cur.Time.Sub(prev.Time).Seconds(), true
+}
+
+// query is a parsed query expression, such as
+// "iface=wlan0 metric=down range=24h step=5m"
+type query struct {
+	metric string
+	iface  string
+	mount  string
+	span   time.Duration
+	step   time.Duration // Zero for every snapshot
+}
+
+// parseQuery parses the space separated key=value pairs of a query
+func parseQuery(expr string) (query, error) {
+	q := query{span: 24 * time.Hour}
+	for _, field := range strings.Fields(expr) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return q, fmt.Errorf("%q is not key=value", field)
+		}
+		var err error
+		switch key {
+		case "metric":
+			q.metric = value
+		case "iface":
+			q.iface = value
+		case "mount":
+			q.mount = value
+		case "range":
+			q.span, err = parseAge(value)
+		case "step":
+			q.step, err = parseAge(value)
+		default:
+			return q, fmt.Errorf("unknown key %q (known: metric, iface, mount, range, step)", key)
+		}
+		if err != nil {
+			return q, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	m, ok := queryMetrics[q.metric]
+	switch {
+	case q.metric == "":
+		return q, errors.New("no metric= given")
+	case !ok:
+		return q, fmt.Errorf("unknown metric %q (known: cpu, down, disk, load, mem, up)", q.metric)
+	case m.needs == "iface" && q.iface == "":
+		return q, fmt.Errorf("metric %s needs iface=", q.metric)
+	case m.needs == "mount" && q.mount == "":
+		return q, fmt.Errorf("metric %s needs mount=", q.metric)
+	case q.span <= 0:
+		return q, errors.New("range is zero")
+	}
+	return q, nil
+}
+
+// queryPoint is a value of a query's result, the mean of a step
+type queryPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// run reads the points of q from the history, up to now. Its steps are
+// on the local clock, counted from the midnight before the range starts,
+// so that those of 5m fall on the five minutes and those of 1d on days.
+func (q query) run(now time.Time) ([]queryPoint, error) {
+	from := now.Add(-q.span)
+	return q.between(from, now, time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location()))
+}
+
+// between reads the points of q from the history, from from to to, its
+// steps counted from anchor
+func (q query) between(from, to, anchor time.Time) ([]queryPoint, error) {
+	acc := q.accumulate(from, to, anchor)
+	err := eachSnapshot(from, to, acc.add)
+	return acc.done(), err
+}
+
+// accumulate returns an accumulator of the points of q from from to to,
+// fed the snapshots in order. Its steps start at anchor, no later than
+// from, and every step after.
+func (q query) accumulate(from, to, anchor time.Time) *queryAccumulator {
+	return &queryAccumulator{q: q, from: from, to: to, anchor: anchor}
+}
+
+// queryAccumulator reduces snapshots to the points of a query, averaging
+// those of each step
+type queryAccumulator struct {
+	q        query
+	from, to time.Time
+	anchor   time.Time // Start of the first step
+	prev     *Snapshot
+	sum      float64
+	n        int
+	bucket   time.Time
+	points   []queryPoint
+}
+
+// add takes the next snapshot
+func (a *queryAccumulator) add(snap *Snapshot) {
+	v, ok := queryMetrics[a.q.metric].value(a.q, a.prev, snap)
+	a.prev = snap
+	if !ok || snap.Time.Before(a.from) || snap.Time.After(a.to) {
+		return
+	}
+	if a.q.step == 0 {
+		a.points = append(a.points, queryPoint{Time: snap.Time, Value: v})
+		return
+	}
+	if b := a.stepOf(snap.Time); !b.Equal(a.bucket) {
+		a.flush()
+		a.bucket = b
+	}
+	a.sum += v
+	a.n++
+}
+
+// stepOf is the start of the step holding t. Steps of whole days are
+// calendar days of the anchor's zone, 23 or 25 hours across a change of
+// daylight saving time.
+func (a *queryAccumulator) stepOf(t time.Time) time.Time {
+	const day = 24 * time.Hour
+	if a.q.step%day != 0 {
+		return a.anchor.Add(t.Sub(a.anchor) / a.q.step * a.q.step)
+	}
+	days := int(a.q.step / day)
+	t = t.In(a.anchor.Location())
+	n := int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).
+		Sub(time.Date(a.anchor.Year(), a.anchor.Month(), a.anchor.Day(), 0, 0, 0, 0, time.UTC)) / day)
+	k := n - n%days
+	if a.anchor.AddDate(0, 0, k).After(t) {
+		k -= days
+	}
+	return a.anchor.AddDate(0, 0, k)
+}
+
+func (a *queryAccumulator) flush() {
+	if a.n > 0 {
+		a.points = append(a.points, queryPoint{Time: a.bucket, Value: a.sum / float64(a.n)})
+	}
+	a.sum, a.n = 0, 0
+}
+
+// done returns the points, the last step included
+func (a *queryAccumulator) done() []queryPoint {
+	a.flush()
+	return a.points
+}
+
+// eachSnapshot hands fn the snapshots persisted from the day of from to
+// that of to, oldest first
+func eachSnapshot(from, to time.Time, fn func(*Snapshot)) error {
+	// The history files are by local day
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	for day := start; !day.After(to); day = day.AddDate(0, 0, 1) {
+		snaps, err := loadSnapshots(day)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for i := range snaps {
+			fn(&snaps[i])
+		}
+	}
+	return nil
+}
+
+// RunQuery implements the query subcommand, printing a metric from the
+// persisted history for scripts and spreadsheets:
+//
+//	advis query "iface=wlan0 metric=down range=24h step=5m" -format csv
+func RunQuery(args []string) error {
+	fs := flag.NewFlagSet("advis query", flag.ContinueOnError)
+	format := fs.String("format", "text", "output format: text, csv or json")
+	out := fs.String("out", "", "write the result to this file (default stdout)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: advis query "key=value ..." [flags]
+
+Keys:
+  metric  cpu, load, mem, disk (with mount=) or down and up (with iface=)
+  iface   interface of down and up
+  mount   mount point of disk
+  range   how far back to read, such as 24h or 7d (default 24h)
+  step    average over intervals this long, such as 5m (default every snapshot)
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+	if err := config.SetFlagsFromEnv(fs); err != nil {
+		return err
+	}
+	expr, err := parseAround(fs, args)
+	if err != nil {
+		return err
+	}
+	q, err := parseQuery(expr)
+	if err != nil {
+		return err
+	}
+	if *format != "text" && *format != "csv" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	points, err := q.run(time.Now())
+	if err != nil {
+		return err
+	}
+	if points == nil {
+		return fmt.Errorf("no %s in the history of the last %s (run the snapshot subcommand to collect it)", q.metric, q.span)
+	}
+
+	return writeReportFile(*out, *format, time.Now(), func(w io.Writer) error {
+		return renderQuery(w, *format, q, points)
+	})
+}
+
+// parseAround parses the flags of args, which may come before or after
+// the expression, and returns the expression
+func parseAround(fs *flag.FlagSet, args []string) (string, error) {
+	var expr []string
+	for len(args) > 0 {
+		if err := fs.Parse(args); err != nil {
+			return "", err
+		}
+		if args = fs.Args(); len(args) > 0 {
+			expr, args = append(expr, args[0]), args[1:]
+		}
+	}
+	return strings.Join(expr, " "), nil
+}
+
+// renderQuery writes the points of q in the requested format
+func renderQuery(w io.Writer, format string, q query, points []queryPoint) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(points)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"time", q.metric})
+		for _, p := range points {
+			cw.Write([]string{p.Time.Format(time.RFC3339), strconv.FormatFloat(p.Value, 'f', -1, 64)})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	var b strings.Builder
+	unit := queryMetrics[q.metric].unit
+	for _, p := range points {
+		fmt.Fprintf(&b, "%s  %s\n", p.Time.Format("2006-01-02 15:04:05"), unit(p.Value))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,117 @@
+package sysmon
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+// writeHistory saves snaps as the history files of their days, in a state
+// directory of the test's own
+func writeHistory(t *testing.T, snaps []Snapshot) {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	if err := os.MkdirAll(historyDir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range snaps {
+		f, err := os.OpenFile(historyFile(s.Time), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.NewEncoder(f).Encode(s); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+}
+
+// halfHourly makes a snapshot every half hour of hours from start, its
+// CPU the hours since start
+func halfHourly(start time.Time, hours int) []Snapshot {
+	var snaps []Snapshot
+	for i := range hours*2 + 1 {
+		at := start.Add(time.Duration(i) * 30 * time.Minute)
+		snaps = append(snaps, Snapshot{Time: at, CPU: at.Sub(start).Hours()})
+	}
+	return snaps
+}
+
+// A range starting off the step's boundary still has a row per step,
+// each the mean of the snapshots from the range's start on
+func TestCompareStepsFromRangeStart(t *testing.T) {
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	writeHistory(t, append(halfHourly(day, 8), halfHourly(day.AddDate(0, 0, 1), 8)...))
+
+	q := query{metric: "cpu", step: 3*time.Hour + 30*time.Minute}
+	a := timeSpan{From: day.Add(30 * time.Minute), To: day.Add(7*time.Hour + 30*time.Minute)}
+	b := timeSpan{From: a.From.AddDate(0, 0, 1), To: a.To.AddDate(0, 0, 1)}
+	c, err := compare(q, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 00:30 to 03:30, then 04:00 to 07:00; the snapshot at 07:30 ends
+	// the range
+	want := []float64{2, 5.5}
+	if len(c.Rows) != len(want) {
+		t.Fatalf("%d rows, want %d", len(c.Rows), len(want))
+	}
+	for i, r := range c.Rows {
+		if r.A == nil || r.B == nil {
+			t.Fatalf("row %d misses a side", i)
+		}
+		if *r.A != want[i] || *r.B != want[i] {
+			t.Errorf("row %d = %v, %v, want %v", i, *r.A, *r.B, want[i])
+		}
+		if r.Offset != time.Duration(i)*q.step {
+			t.Errorf("row %d offset = %v", i, r.Offset)
+		}
+	}
+	if mean := (want[0] + want[1]) / 2; c.A.Mean != mean {
+		t.Errorf("mean = %v, want %v", c.A.Mean, mean)
+	}
+}
+
+// Steps of a day are calendar days of the local zone, the one daylight
+// saving time ends 25 hours long
+func TestQueryDayStepsLocal(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	from := time.Date(2026, 10, 31, 0, 0, 0, 0, loc)
+	to := time.Date(2026, 11, 3, 0, 0, 0, 0, loc).Add(-time.Hour)
+	acc := query{metric: "cpu", step: 24 * time.Hour}.accumulate(from, to, from)
+	for at := from; !at.After(to); at = at.Add(time.Hour) {
+		acc.add(&Snapshot{Time: at, CPU: 1})
+	}
+	points := acc.done()
+	if len(points) != 3 {
+		t.Fatalf("%d points, want 3: %v", len(points), points)
+	}
+	for i, p := range points {
+		if want := from.AddDate(0, 0, i); !p.Time.Equal(want) {
+			t.Errorf("point %d at %v, want %v", i, p.Time, want)
+		}
+	}
+}
+
+// A query's steps fall on the local clock whatever time it runs at
+func TestQueryStepsOnTheClock(t *testing.T) {
+	start := time.Date(2026, 10, 16, 0, 0, 0, 0, time.Local)
+	writeHistory(t, halfHourly(start, 12))
+	now := start.Add(11*time.Hour + 47*time.Minute)
+	points, err := query{metric: "cpu", span: 6 * time.Hour, step: 2 * time.Hour}.run(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) == 0 {
+		t.Fatal("no points")
+	}
+	for _, p := range points {
+		if p.Time.Sub(start)%(2*time.Hour) != 0 {
+			t.Errorf("step at %v, not on the 2h of the clock", p.Time.Format(time.Kitchen))
+		}
+	}
+}
@@ -0,0 +1,692 @@
+package sysmon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/alerting"
+	"github.com/s-archdev/Terminal_ADVIS/internal/baseline"
+	"github.com/s-archdev/Terminal_ADVIS/internal/config"
+	"github.com/s-archdev/Terminal_ADVIS/internal/server"
+	"github.com/s-archdev/Terminal_ADVIS/internal/state"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/netstat"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/proc"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/sysstat"
+)
+
+// alertingGrace is how long the snapshot subcommand waits at exit for the
+// alerting channels to send what they hold
+const alertingGrace = 30 * time.Second
+
+// Snapshot is a point-in-time capture of system and network state, as
+// written by the snapshot subcommand and kept in the daily history files
+type Snapshot struct {
+	Time      time.Time         `json:"time"`
+	Host      string            `json:"host"`
+	Kernel    string            `json:"kernel"`
+	CPU       float64           `json:"cpu_percent"`
+	Load      float64           `json:"load"`
+	MemTotal  uint64            `json:"mem_total"`
+	MemUsed   uint64            `json:"mem_used"`
+	Mounts    []sysstat.Disk    `json:"mounts"`
+	Network   []netstat.Counter `json:"network"`
+	TopCPU    []proc.Process    `json:"top_cpu"`
+	TopMemory []proc.Process    `json:"top_memory"`
+	Alerts    []Alert           `json:"alerts"`
+}
+
+// snapshotTopN is how many processes each snapshot keeps per metric
+const snapshotTopN = 5
+
+// takeSnapshot samples everything twice, a second apart, so CPU figures
+// are current rather than averages since boot. It returns every process
+// besides the snapshot's top ones.
+func takeSnapshot() (Snapshot, []proc.Process) {
+	cpus := &sysstat.CPUSampler{}
+	procs := &proc.Sampler{}
+	cpus.Sample()
+	procs.Sample()
+	time.Sleep(time.Second)
+
+	// A hung mount is recorded as such rather than stalling the snapshot
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+	mounts, _ := sysstat.MountsContext(ctx)
+
+	m := model{
+		host:     getHostInfo(),
+		lastTick: time.Now(),
+		mounts:   mounts,
+		sysInfo:  sysstat.ReadInfo(),
+		procs:    procs.Sample(),
+		arrays:   readMdstat(),
+		limits:   readKernelLimits(),
+		baseline: baseline.Get(),
+	}
+	m.cpuTotal, m.cores = cpus.Sample()
+	m.countStuckProcesses()
+	m.alerts = m.checkAlerts()
+	snap := m.snapshot()
+	snap.Network = netstat.Interfaces()
+	return snap, m.procs
+}
+
+// snapshot is the model's figures as a Snapshot, without the network
+// counters, which the model does not keep
+func (m model) snapshot() Snapshot {
+	snap := Snapshot{
+		Time:     m.lastTick,
+		Host:     m.host.Hostname,
+		Kernel:   m.host.Kernel,
+		CPU:      m.cpuTotal,
+		Load:     m.sysInfo.LoadAverage,
+		MemTotal: m.sysInfo.MemTotal,
+		MemUsed:  m.sysInfo.MemUsed,
+		Mounts:   m.mounts,
+		Alerts:   m.alerts,
+	}
+	// Sorted aside, the model's list keeps the order on screen
+	procs := slices.Clone(m.procs)
+	sortProcesses(procs, sortByCPU)
+	snap.TopCPU = append(snap.TopCPU, procs[:min(snapshotTopN, len(procs))]...)
+	sortProcesses(procs, sortByMemory)
+	snap.TopMemory = append(snap.TopMemory, procs[:min(snapshotTopN, len(procs))]...)
+	return snap
+}
+
+// historyDir is where snapshots are persisted, one JSON lines file per day
+func historyDir() string {
+	return state.Dir()
+}
+
+// historyFile returns the history file holding snapshots of a day
+func historyFile(day time.Time) string {
+	return filepath.Join(historyDir(), "snapshots-"+day.Format("2006-01-02")+".jsonl")
+}
+
+// persistSnapshot appends a snapshot to its day's history file
+func persistSnapshot(snap Snapshot) error {
+	if err := os.MkdirAll(historyDir(), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(historyFile(snap.Time), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return json.NewEncoder(file).Encode(snap)
+}
+
+// loadSnapshots reads every snapshot persisted for a day, from its JSON
+// lines while the day is being written and from its packed file after
+func loadSnapshots(day time.Time) ([]Snapshot, error) {
+	snaps, err := readSnapshots(historyFile(day))
+	if errors.Is(err, os.ErrNotExist) {
+		if packed, perr := readPacked(packedFile(day)); !errors.Is(perr, os.ErrNotExist) {
+			return packed, perr
+		}
+	}
+	return snaps, err
+}
+
+// readSnapshots reads every snapshot of a history file
+func readSnapshots(path string) ([]Snapshot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var snaps []Snapshot
+	dec := json.NewDecoder(file)
+	for {
+		var snap Snapshot
+		if err := dec.Decode(&snap); err == io.EOF {
+			break
+		} else if err != nil {
+			return snaps, fmt.Errorf("%s: %w", file.Name(), err)
+		}
+		snaps = append(snaps, snap)
+	}
+	return snaps, nil
+}
+
+// RunSnapshot implements the snapshot subcommand. Without -at or -every
+// it takes a single snapshot and exits, which suits cron; otherwise it
+// keeps running and takes one at every scheduled time.
+func RunSnapshot(args []string) error {
+	fs := flag.NewFlagSet("advis snapshot", flag.ContinueOnError)
+	format := fs.String("format", "text", "report format: text, json or html")
+	out := fs.String("out", "", "write reports to this file, or to timestamped files in this directory (default stdout)")
+	at := fs.String("at", "", "comma separated times of day to take snapshots, e.g. 06:00,18:00")
+	every := fs.Duration("every", 0, "take a snapshot at this interval")
+	quiet := fs.Bool("quiet", false, "only add the snapshots to the history, writing no report, as a collector service does")
+	api := fs.String("api", "", `serve the REST API on this address between snapshots, such as ":9273"; see the config file's "listen"`)
+	config.Flags.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	if err := config.SetFlagsFromEnv(fs, config.Flags); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !validFormat(*format) {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	times, err := parseTimesOfDay(*at)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Read()
+	if err != nil {
+		return err
+	}
+	keep, err := parseRetention(cfg.History)
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.Path(), err)
+	}
+	if err := alerting.Configure(cfg.Alerting); err != nil {
+		return fmt.Errorf("%s: %w", config.Path(), err)
+	}
+	defer alerting.Close(alertingGrace)
+
+	// The alerts of the last snapshot are those already sent, so a run
+	// from cron, or a restarted collector, sends only what changed since
+	center := newAlertCenter()
+	if alerting.Enabled() {
+		if snaps, err := loadSnapshots(time.Now()); err == nil && len(snaps) > 0 {
+			last := snaps[len(snaps)-1]
+			center.observe(last.Time, last.Alerts)
+		}
+	}
+	if *api != "" {
+		if len(times) == 0 && *every == 0 {
+			return errors.New("-api needs -at or -every to keep running")
+		}
+		if err := server.Configure(cfg.Listen); err != nil {
+			return err
+		}
+		if err := serveAPI(*api); err != nil {
+			return err
+		}
+	}
+
+	var compacted time.Time
+	write := func() error {
+		snap, procs := takeSnapshot()
+		publish(snap, procs)
+		deliverAlerts(center.observe(snap.Time, snap.Alerts))
+		if err := alerting.Failures(); err != nil {
+			fmt.Fprintf(os.Stderr, "snapshot: alerting: %v\n", err)
+		}
+		if err := persistSnapshot(snap); err != nil {
+			fmt.Fprintf(os.Stderr, "snapshot: persisting history: %v\n", err)
+		}
+		if time.Since(compacted) >= compactEvery {
+			compacted = time.Now()
+			if err := compactHistory(compacted, keep); err != nil {
+				fmt.Fprintf(os.Stderr, "snapshot: compacting history: %v\n", err)
+			}
+		}
+		if *quiet {
+			return nil
+		}
+		return writeReportFile(*out, *format, snap.Time, func(w io.Writer) error {
+			return renderSnapshot(w, *format, snap)
+		})
+	}
+	if len(times) == 0 && *every == 0 {
+		return write()
+	}
+	for {
+		time.Sleep(time.Until(nextSnapshotTime(time.Now(), times, *every)))
+		if err := write(); err != nil {
+			fmt.Fprintf(os.Stderr, "snapshot: %v\n", err)
+		}
+	}
+}
+
+// RunReport implements the report subcommand, summarizing one day of
+// persisted snapshots, or the span up to now given with -range. The HTML
+// report charts the span, and is a single file fit to mail:
+//
+//	advis report -range 7d -out report.html
+func RunReport(args []string) error {
+	fs := flag.NewFlagSet("advis report", flag.ContinueOnError)
+	format := fs.String("format", "text", "report format: text, json or html (default from the -out file's extension, else text)")
+	out := fs.String("out", "", "write the report to this file or directory (default stdout)")
+	date := fs.String("date", "", "day to summarize as YYYY-MM-DD (default yesterday)")
+	span := fs.String("range", "", "summarize this long up to now, such as 7d or 12h, instead of a day")
+	if err := config.SetFlagsFromEnv(fs); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !isFlagSet(fs, "format") {
+		if ext := strings.TrimPrefix(filepath.Ext(*out), "."); ext == "html" || ext == "json" {
+			*format = ext
+		}
+	}
+	if !validFormat(*format) {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if *date != "" && *span != "" {
+		return errors.New("-date and -range are exclusive")
+	}
+
+	if *span != "" {
+		d, err := parseAge(*span)
+		if err != nil || d == 0 {
+			return fmt.Errorf("invalid -range %q, such as 7d or 12h", *span)
+		}
+		to := time.Now()
+		from := to.Add(-d)
+		var snaps []Snapshot
+		if err := eachSnapshot(from, to, func(snap *Snapshot) {
+			if !snap.Time.Before(from) {
+				snaps = append(snaps, *snap)
+			}
+		}); err != nil && len(snaps) == 0 {
+			return err
+		}
+		if len(snaps) == 0 {
+			return fmt.Errorf("no snapshots in the last %s in %s (run the snapshot subcommand to collect them)", *span, historyDir())
+		}
+		summary := summarizeDay(from, snaps)
+		summary.Date, summary.To = from.Format("2006-01-02 15:04"), to.Format("2006-01-02 15:04")
+		if *format == "html" {
+			summary.Charts = reportCharts(snaps, summary, from, to)
+		}
+		return writeReportFile(*out, *format, to, func(w io.Writer) error {
+			return renderDailySummary(w, *format, summary)
+		})
+	}
+
+	day := time.Now().AddDate(0, 0, -1)
+	if *date != "" {
+		var err error
+		if day, err = time.ParseInLocation("2006-01-02", *date, time.Local); err != nil {
+			return fmt.Errorf("invalid -date: %w", err)
+		}
+	}
+
+	snaps, err := loadSnapshots(day)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no snapshots for %s in %s (run the snapshot subcommand to collect them)", day.Format("2006-01-02"), historyDir())
+	} else if err != nil && len(snaps) == 0 {
+		return err
+	}
+	summary := summarizeDay(day, snaps)
+	if *format == "html" {
+		start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+		summary.Charts = reportCharts(snaps, summary, start, start.AddDate(0, 0, 1))
+	}
+	return writeReportFile(*out, *format, day, func(w io.Writer) error {
+		return renderDailySummary(w, *format, summary)
+	})
+}
+
+func validFormat(format string) bool {
+	return format == "text" || format == "json" || format == "html"
+}
+
+// isFlagSet reports whether the flag name was given on the command line
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) { set = set || f.Name == name })
+	return set
+}
+
+// parseTimesOfDay parses "06:00,18:30" into offsets from midnight
+func parseTimesOfDay(list string) ([]time.Duration, error) {
+	var times []time.Duration
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		t, err := time.Parse("15:04", item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time of day %q", item)
+		}
+		times = append(times, time.Duration(t.Hour())*time.Hour+time.Duration(t.Minute())*time.Minute)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times, nil
+}
+
+// nextSnapshotTime returns the earliest scheduled time after now
+func nextSnapshotTime(now time.Time, times []time.Duration, every time.Duration) time.Time {
+	var next time.Time
+	if every > 0 {
+		next = now.Truncate(every).Add(every)
+	}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for day := 0; day <= 1; day++ {
+		for _, offset := range times {
+			t := midnight.AddDate(0, 0, day).Add(offset)
+			if t.After(now) && (next.IsZero() || t.Before(next)) {
+				next = t
+			}
+		}
+	}
+	return next
+}
+
+// writeReportFile sends a report to stdout, a file, or a timestamped file
+// when out is a directory
+func writeReportFile(out, format string, t time.Time, render func(io.Writer) error) error {
+	if out == "" {
+		return render(os.Stdout)
+	}
+	if info, err := os.Stat(out); err == nil && info.IsDir() {
+		ext := map[string]string{"text": "txt", "json": "json", "html": "html", "csv": "csv"}[format]
+		out = filepath.Join(out, "advis-"+t.Format("2006-01-02T150405")+"."+ext)
+	}
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := render(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// renderSnapshot writes a single snapshot in the requested format
+func renderSnapshot(w io.Writer, format string, snap Snapshot) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snap)
+	case "html":
+		return snapshotTemplate.Execute(w, snap)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Snapshot of %s at %s\n", snap.Host, snap.Time.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "Kernel %s\n\n", snap.Kernel)
+	fmt.Fprintf(&b, "CPU %.1f%%, load %.2f, memory %s / %s\n\n", snap.CPU, snap.Load, ui.FormatBytes(snap.MemUsed), ui.FormatBytes(snap.MemTotal))
+	fmt.Fprintf(&b, "%-30s %-12s %-12s %s\n", "MOUNT", "USED", "SIZE", "USE%")
+	for _, d := range snap.Mounts {
+		if d.Total > 0 {
+			fmt.Fprintf(&b, "%s %-12s %-12s %.1f%%\n", ui.Fit(d.Path, 30), ui.FormatBytes(d.Used), ui.FormatBytes(d.Total), float64(d.Used)/float64(d.Total)*100)
+		}
+	}
+	fmt.Fprintf(&b, "\n%-16s %-12s %-12s %s\n", "INTERFACE", "RX", "TX", "ERRORS")
+	for _, n := range snap.Network {
+		fmt.Fprintf(&b, "%-16s %-12s %-12s %d\n", n.Name, ui.FormatBytes(n.RxBytes), ui.FormatBytes(n.TxBytes), n.RxErrors+n.TxErrors)
+	}
+	fmt.Fprintf(&b, "\nTop CPU\n")
+	for _, p := range snap.TopCPU {
+		fmt.Fprintf(&b, "  %-8d %s %.1f%%\n", p.PID, ui.Fit(p.Name, 20), p.CPU)
+	}
+	fmt.Fprintf(&b, "Top memory\n")
+	for _, p := range snap.TopMemory {
+		fmt.Fprintf(&b, "  %-8d %s %s\n", p.PID, ui.Fit(p.Name, 20), ui.FormatBytes(p.Memory))
+	}
+	if len(snap.Alerts) > 0 {
+		fmt.Fprintf(&b, "\nAlerts\n")
+		for _, a := range snap.Alerts {
+			fmt.Fprintf(&b, "  [%s] %s\n", a.Source, a.Message)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// DailySummary aggregates one day of snapshots
+type DailySummary struct {
+	Date      string         `json:"date"`
+	Host      string         `json:"host"`
+	Snapshots int            `json:"snapshots"`
+	CPU       MetricSummary  `json:"cpu_percent"`
+	Load      MetricSummary  `json:"load"`
+	Memory    MetricSummary  `json:"mem_used"`
+	Mounts    []MountGrowth  `json:"mounts"`
+	Network   []TrafficTotal `json:"network"`
+	TopCPU    []string       `json:"top_cpu"`      // Most frequent top CPU consumers
+	TopMemory []string       `json:"top_memory"`   // Most frequent top memory consumers
+	Alerts    []string       `json:"alerts"`       // Distinct alert messages
+	To        string         `json:"to,omitempty"` // End of a -range summary, which starts at Date
+	Charts    []Chart        `json:"-"`            // For the HTML report
+}
+
+// MetricSummary is the range and mean of a metric over a day
+type MetricSummary struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+}
+
+// MountGrowth is a filesystem's usage at the first and last snapshot
+type MountGrowth struct {
+	Path  string `json:"path"`
+	First uint64 `json:"first_used"`
+	Last  uint64 `json:"last_used"`
+	Total uint64 `json:"total"`
+}
+
+// TrafficTotal is the traffic an interface carried between the first
+// and last snapshot of the day
+type TrafficTotal struct {
+	Name    string `json:"name"`
+	RxBytes uint64 `json:"rx_bytes"`
+	TxBytes uint64 `json:"tx_bytes"`
+}
+
+// summarizeDay reduces a day of snapshots to a DailySummary
+func summarizeDay(day time.Time, snaps []Snapshot) DailySummary {
+	sum := DailySummary{Date: day.Format("2006-01-02"), Snapshots: len(snaps)}
+	if len(snaps) == 0 {
+		return sum
+	}
+	sum.Host = snaps[0].Host
+
+	metric := func(value func(Snapshot) float64) MetricSummary {
+		m := MetricSummary{Min: math.Inf(1), Max: math.Inf(-1)}
+		for _, s := range snaps {
+			v := value(s)
+			m.Min = math.Min(m.Min, v)
+			m.Max = math.Max(m.Max, v)
+			m.Avg += v / float64(len(snaps))
+		}
+		return m
+	}
+	sum.CPU = metric(func(s Snapshot) float64 { return s.CPU })
+	sum.Load = metric(func(s Snapshot) float64 { return s.Load })
+	sum.Memory = metric(func(s Snapshot) float64 { return float64(s.MemUsed) })
+
+	first, last := snaps[0], snaps[len(snaps)-1]
+	for _, d := range last.Mounts {
+		g := MountGrowth{Path: d.Path, First: d.Used, Last: d.Used, Total: d.Total}
+		for _, f := range first.Mounts {
+			if f.Path == d.Path {
+				g.First = f.Used
+			}
+		}
+		sum.Mounts = append(sum.Mounts, g)
+	}
+
+	// Counters reset on reboot, so add up the positive deltas
+	traffic := make(map[string]*TrafficTotal)
+	for i := 1; i < len(snaps); i++ {
+		prev := make(map[string]netstat.Counter)
+		for _, n := range snaps[i-1].Network {
+			prev[n.Name] = n
+		}
+		for _, n := range snaps[i].Network {
+			t := traffic[n.Name]
+			if t == nil {
+				t = &TrafficTotal{Name: n.Name}
+				traffic[n.Name] = t
+			}
+			if p, ok := prev[n.Name]; ok && n.RxBytes >= p.RxBytes && n.TxBytes >= p.TxBytes {
+				t.RxBytes += n.RxBytes - p.RxBytes
+				t.TxBytes += n.TxBytes - p.TxBytes
+			}
+		}
+	}
+	for _, t := range traffic {
+		sum.Network = append(sum.Network, *t)
+	}
+	sort.Slice(sum.Network, func(i, j int) bool {
+		return sum.Network[i].RxBytes+sum.Network[i].TxBytes > sum.Network[j].RxBytes+sum.Network[j].TxBytes
+	})
+
+	frequent := func(procs func(Snapshot) []proc.Process) []string {
+		counts := make(map[string]int)
+		for _, s := range snaps {
+			for _, p := range procs(s) {
+				counts[p.Name]++
+			}
+		}
+		names := make([]string, 0, len(counts))
+		for name := range counts {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if counts[names[i]] != counts[names[j]] {
+				return counts[names[i]] > counts[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		return names[:min(snapshotTopN, len(names))]
+	}
+	sum.TopCPU = frequent(func(s Snapshot) []proc.Process { return s.TopCPU })
+	sum.TopMemory = frequent(func(s Snapshot) []proc.Process { return s.TopMemory })
+
+	seen := make(map[string]bool)
+	for _, s := range snaps {
+		for _, a := range s.Alerts {
+			msg := fmt.Sprintf("[%s] %s", a.Source, a.Message)
+			if !seen[msg] {
+				seen[msg] = true
+				sum.Alerts = append(sum.Alerts, msg)
+			}
+		}
+	}
+	return sum
+}
+
+// renderDailySummary writes a daily summary in the requested format
+func renderDailySummary(w io.Writer, format string, sum DailySummary) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sum)
+	case "html":
+		return summaryTemplate.Execute(w, sum)
+	}
+
+	var b strings.Builder
+	if sum.To != "" {
+		fmt.Fprintf(&b, "Summary for %s from %s to %s (%d snapshots)\n\n", sum.Host, sum.Date, sum.To, sum.Snapshots)
+	} else {
+		fmt.Fprintf(&b, "Daily summary for %s on %s (%d snapshots)\n\n", sum.Host, sum.Date, sum.Snapshots)
+	}
+	fmt.Fprintf(&b, "%-8s %-12s %-12s %s\n", "", "MIN", "AVG", "MAX")
+	fmt.Fprintf(&b, "%-8s %-12.1f %-12.1f %.1f\n", "CPU %", sum.CPU.Min, sum.CPU.Avg, sum.CPU.Max)
+	fmt.Fprintf(&b, "%-8s %-12.2f %-12.2f %.2f\n", "Load", sum.Load.Min, sum.Load.Avg, sum.Load.Max)
+	fmt.Fprintf(&b, "%-8s %-12s %-12s %s\n\n", "Memory",
+		ui.FormatBytes(uint64(sum.Memory.Min)), ui.FormatBytes(uint64(sum.Memory.Avg)), ui.FormatBytes(uint64(sum.Memory.Max)))
+	fmt.Fprintf(&b, "%-30s %-12s %-12s %s\n", "MOUNT", "START", "END", "SIZE")
+	for _, g := range sum.Mounts {
+		fmt.Fprintf(&b, "%s %-12s %-12s %s\n", ui.Fit(g.Path, 30), ui.FormatBytes(g.First), ui.FormatBytes(g.Last), ui.FormatBytes(g.Total))
+	}
+	fmt.Fprintf(&b, "\n%-16s %-12s %s\n", "INTERFACE", "RX", "TX")
+	for _, t := range sum.Network {
+		fmt.Fprintf(&b, "%-16s %-12s %s\n", t.Name, ui.FormatBytes(t.RxBytes), ui.FormatBytes(t.TxBytes))
+	}
+	fmt.Fprintf(&b, "\nMost often top CPU: %s\n", strings.Join(sum.TopCPU, ", "))
+	fmt.Fprintf(&b, "Most often top memory: %s\n", strings.Join(sum.TopMemory, ", "))
+	if len(sum.Alerts) > 0 {
+		fmt.Fprintf(&b, "\nAlerts seen\n")
+		for _, a := range sum.Alerts {
+			fmt.Fprintf(&b, "  %s\n", a)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var reportFuncs = template.FuncMap{
+	"bytes": ui.FormatBytes,
+	"percent": func(used, total uint64) string {
+		return fmt.Sprintf("%.1f%%", float64(used)/float64(max(total, 1))*100)
+	},
+	"ubytes": func(f float64) string { return ui.FormatBytes(uint64(f)) },
+	"time":   func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+}
+
+const reportStyle = `<style>
+body { font-family: sans-serif; margin: 2em; color: #222 }
+table { border-collapse: collapse; margin-bottom: 1.5em }
+th, td { padding: 0.25em 0.75em; border-bottom: 1px solid #ddd; text-align: left }
+th { background: #f3f3f3 }
+.alert { color: #c0392b }
+</style>`
+
+var snapshotTemplate = template.Must(template.New("snapshot").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Host}} snapshot {{time .Time}}</title>` + reportStyle + `</head><body>
+<h1>{{.Host}}</h1>
+<p>{{time .Time}} &middot; kernel {{.Kernel}}</p>
+<p>CPU {{printf "%.1f" .CPU}}% &middot; load {{printf "%.2f" .Load}} &middot; memory {{bytes .MemUsed}} / {{bytes .MemTotal}}</p>
+<h2>Filesystems</h2>
+<table><tr><th>Mount</th><th>Used</th><th>Size</th><th>Use</th></tr>
+{{range .Mounts}}{{if .Total}}<tr><td>{{.Path}}</td><td>{{bytes .Used}}</td><td>{{bytes .Total}}</td><td>{{percent .Used .Total}}</td></tr>{{end}}
+{{end}}</table>
+<h2>Network</h2>
+<table><tr><th>Interface</th><th>RX</th><th>TX</th><th>Errors</th></tr>
+{{range .Network}}<tr><td>{{.Name}}</td><td>{{bytes .RxBytes}}</td><td>{{bytes .TxBytes}}</td><td>{{.RxErrors}} / {{.TxErrors}}</td></tr>
+{{end}}</table>
+<h2>Top processes</h2>
+<table><tr><th>PID</th><th>Name</th><th>CPU</th><th>Memory</th></tr>
+{{range .TopCPU}}<tr><td>{{.PID}}</td><td>{{.Name}}</td><td>{{printf "%.1f" .CPU}}%</td><td>{{bytes .Memory}}</td></tr>
+{{end}}{{range .TopMemory}}<tr><td>{{.PID}}</td><td>{{.Name}}</td><td>{{printf "%.1f" .CPU}}%</td><td>{{bytes .Memory}}</td></tr>
+{{end}}</table>
+{{if .Alerts}}<h2>Alerts</h2><ul>{{range .Alerts}}<li class="alert">[{{.Source}}] {{.Message}}</li>{{end}}</ul>{{end}}
+</body></html>
+`))
+
+var summaryTemplate = template.Must(template.New("summary").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Host}} {{if .To}}summary {{.Date}} to {{.To}}{{else}}daily summary {{.Date}}{{end}}</title>` + reportStyle + `</head><body>
+<h1>{{.Host}} &middot; {{.Date}}{{if .To}} to {{.To}}{{end}}</h1>
+<p>{{.Snapshots}} snapshots</p>
+<table><tr><th></th><th>Min</th><th>Avg</th><th>Max</th></tr>
+<tr><td>CPU</td><td>{{printf "%.1f" .CPU.Min}}%</td><td>{{printf "%.1f" .CPU.Avg}}%</td><td>{{printf "%.1f" .CPU.Max}}%</td></tr>
+<tr><td>Load</td><td>{{printf "%.2f" .Load.Min}}</td><td>{{printf "%.2f" .Load.Avg}}</td><td>{{printf "%.2f" .Load.Max}}</td></tr>
+<tr><td>Memory</td><td>{{ubytes .Memory.Min}}</td><td>{{ubytes .Memory.Avg}}</td><td>{{ubytes .Memory.Max}}</td></tr>
+</table>
+<h2>Filesystems</h2>
+<table><tr><th>Mount</th><th>Start</th><th>End</th><th>Size</th></tr>
+{{range .Mounts}}<tr><td>{{.Path}}</td><td>{{bytes .First}}</td><td>{{bytes .Last}}</td><td>{{bytes .Total}}</td></tr>
+{{end}}</table>
+<h2>Network</h2>
+<table><tr><th>Interface</th><th>RX</th><th>TX</th></tr>
+{{range .Network}}<tr><td>{{.Name}}</td><td>{{bytes .RxBytes}}</td><td>{{bytes .TxBytes}}</td></tr>
+{{end}}</table>
+{{range .Charts}}<h2>{{.Title}}</h2>
+{{.SVG}}
+{{end}}<p>Most often top CPU: {{range $i, $n := .TopCPU}}{{if $i}}, {{end}}{{$n}}{{end}}</p>
+<p>Most often top memory: {{range $i, $n := .TopMemory}}{{if $i}}, {{end}}{{$n}}{{end}}</p>
+{{if .Alerts}}<h2>Alerts seen</h2><ul>{{range .Alerts}}<li class="alert">{{.}}</li>{{end}}</ul>{{end}}
+</body></html>
+`))
@@ -0,0 +1,272 @@
+package sysmon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/config"
+)
+
+// compactEvery is how often a running collector compacts the history
+const compactEvery = time.Hour
+
+// retention is the config file's "history" section, parsed: snapshots
+// are kept as taken for raw, as 1-minute averages until minutely, hourly
+// beyond, and the oldest days go once the history outgrows maxSize
+type retention struct {
+	raw      time.Duration
+	minutely time.Duration
+	maxSize  uint64 // Zero for no cap
+}
+
+var defaultRetention = retention{raw: 24 * time.Hour, minutely: 30 * 24 * time.Hour, maxSize: 1 << 30}
+
+// parseRetention reads the "history" section, the defaults standing in for
+// what it leaves out
+func parseRetention(h config.History) (retention, error) {
+	r := defaultRetention
+	var err error
+	if h.Raw != "" {
+		if r.raw, err = parseAge(h.Raw); err != nil {
+			return r, fmt.Errorf("history.raw: %w", err)
+		}
+	}
+	if h.Minutely != "" {
+		if r.minutely, err = parseAge(h.Minutely); err != nil {
+			return r, fmt.Errorf("history.minutely: %w", err)
+		}
+	}
+	if r.minutely < r.raw {
+		return r, fmt.Errorf("history.minutely %s is shorter than history.raw %s", r.minutely, r.raw)
+	}
+	if h.MaxSize != "" {
+		if r.maxSize, err = parseSize(h.MaxSize); err != nil {
+			return r, fmt.Errorf("history.max_size: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// parseAge parses a duration such as "36h", or a number of days such as
+// "30d"
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
+
+// resolution is how finely a day's snapshots are kept once the whole day
+// is older than age, zero for as taken
+func (r retention) resolution(age time.Duration) time.Duration {
+	switch {
+	case age >= r.minutely:
+		return time.Hour
+	case age >= r.raw:
+		return time.Minute
+	}
+	return 0
+}
+
+// compactedFile records the resolution each day of the history was
+// compacted to, so a day is rewritten once per tier rather than every run
+func compactedFile() string {
+	return filepath.Join(historyDir(), "compacted.json")
+}
+
+// compactHistory packs the days that are over, downsampling those that
+// crossed a retention tier, and then removes the oldest days while the
+// history is over the size cap. Today is never touched, as the collector
+// is appending to it. JSON lines left by an earlier advis, or by a
+// collector that stopped, are packed the same way.
+func compactHistory(now time.Time, r retention) error {
+	compacted := make(map[string]string)
+	data, err := os.ReadFile(compactedFile())
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		json.Unmarshal(data, &compacted) // A damaged index only costs a rewrite
+	}
+
+	days, err := historyDays()
+	if err != nil {
+		return err
+	}
+	today := now.Format("2006-01-02")
+	var errs []error
+	for _, day := range days {
+		date := day.Format("2006-01-02")
+		if date == today {
+			continue
+		}
+		res := r.resolution(now.Sub(day.AddDate(0, 0, 1)))
+		_, err := os.Stat(historyFile(day))
+		unpacked := err == nil
+		if done, err := time.ParseDuration(compacted[date]); !unpacked && (res == 0 || err == nil && done >= res) {
+			continue
+		}
+		snaps, err := loadSnapshots(day)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // Only alerts that day
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if res > 0 {
+			snaps = downsample(snaps, res)
+		}
+		if err := writePacked(day, snaps); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if res > 0 {
+			compacted[date] = res.String()
+		}
+	}
+
+	removed, err := capHistory(days, today, r.maxSize)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, day := range removed {
+		delete(compacted, day.Format("2006-01-02"))
+	}
+	if data, err := json.MarshalIndent(compacted, "", "  "); err == nil {
+		errs = append(errs, writeAside(compactedFile(), data))
+	}
+	return errors.Join(errs...)
+}
+
+// historyDays returns the days with a snapshot or alert history file,
+// oldest first
+func historyDays() ([]time.Time, error) {
+	entries, err := os.ReadDir(historyDir())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var days []time.Time
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if ext != ".jsonl" && ext != ".tsz" {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), ext)
+		for _, prefix := range []string{"snapshots-", "alerts-"} {
+			date, ok := strings.CutPrefix(name, prefix)
+			if !ok {
+				continue
+			}
+			if day, err := time.ParseInLocation("2006-01-02", date, time.Local); err == nil && !slices.Contains(days, day) {
+				days = append(days, day)
+			}
+		}
+	}
+	slices.SortFunc(days, func(a, b time.Time) int { return a.Compare(b) })
+	return days, nil
+}
+
+// capHistory removes the files of the oldest days, never today's, while
+// those of days take more than maxSize, and returns the days removed
+func capHistory(days []time.Time, today string, maxSize uint64) ([]time.Time, error) {
+	if maxSize == 0 {
+		return nil, nil
+	}
+	files := func(day time.Time) []string { return []string{historyFile(day), packedFile(day), alertsFile(day)} }
+	var total uint64
+	for _, day := range days {
+		for _, path := range files(day) {
+			if info, err := os.Stat(path); err == nil {
+				total += uint64(info.Size())
+			}
+		}
+	}
+	var removed []time.Time
+	for _, day := range days {
+		if total <= maxSize || day.Format("2006-01-02") == today {
+			break
+		}
+		for _, path := range files(day) {
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if err := os.Remove(path); err != nil {
+				return removed, err
+			}
+			total -= uint64(info.Size())
+		}
+		removed = append(removed, day)
+	}
+	return removed, nil
+}
+
+// downsample merges the snapshots of each res long interval into one at
+// the time of its last: CPU, load and memory in use are averaged, the
+// counters, mounts and top processes are the last ones, and the alerts
+// are those seen at any of them. The counters staying cumulative keeps
+// rates between the merged snapshots right.
+func downsample(snaps []Snapshot, res time.Duration) []Snapshot {
+	var kept []Snapshot
+	for i := 0; i < len(snaps); {
+		bucket := snaps[i].Time.Truncate(res)
+		j := i + 1
+		for j < len(snaps) && snaps[j].Time.Truncate(res).Equal(bucket) {
+			j++
+		}
+		group := snaps[i:j]
+		merged := group[len(group)-1]
+		if len(group) > 1 {
+			merged.CPU, merged.Load, merged.MemUsed = 0, 0, 0
+			var mem float64
+			seen := make(map[string]bool)
+			merged.Alerts = nil
+			for _, s := range group {
+				merged.CPU += s.CPU / float64(len(group))
+				merged.Load += s.Load / float64(len(group))
+				mem += float64(s.MemUsed) / float64(len(group))
+				for _, a := range s.Alerts {
+					if key := a.Source + "\x00" + a.Message; !seen[key] {
+						seen[key] = true
+						merged.Alerts = append(merged.Alerts, a)
+					}
+				}
+			}
+			merged.MemUsed = uint64(mem)
+		}
+		kept = append(kept, merged)
+		i = j
+	}
+	return kept
+}
+
+// writeAside writes data to path through a file renamed over it, so a
+// crash leaves the old content whole
+func writeAside(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
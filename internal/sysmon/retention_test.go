@@ -0,0 +1,197 @@
+package sysmon
+
+import (
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/config"
+)
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		name    string
+		history config.History
+		want    retention
+		wantErr bool
+	}{
+		{name: "defaults", want: defaultRetention},
+		{name: "hours and days", history: config.History{Raw: "36h", Minutely: "7d"},
+			want: retention{raw: 36 * time.Hour, minutely: 7 * 24 * time.Hour, maxSize: defaultRetention.maxSize}},
+		{name: "size cap", history: config.History{MaxSize: "512M"},
+			want: retention{raw: defaultRetention.raw, minutely: defaultRetention.minutely, maxSize: 512 << 20}},
+		{name: "no size cap", history: config.History{MaxSize: "0"},
+			want: retention{raw: defaultRetention.raw, minutely: defaultRetention.minutely}},
+		{name: "invalid raw", history: config.History{Raw: "a day"}, wantErr: true},
+		{name: "negative days", history: config.History{Minutely: "-3d"}, wantErr: true},
+		{name: "minutely shorter than raw", history: config.History{Raw: "48h", Minutely: "1d"}, wantErr: true},
+		{name: "invalid size", history: config.History{MaxSize: "lots"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRetention(tt.history)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want one: %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetentionResolution(t *testing.T) {
+	r := retention{raw: 24 * time.Hour, minutely: 30 * 24 * time.Hour}
+	tests := []struct {
+		age  time.Duration
+		want time.Duration
+	}{
+		{time.Hour, 0},
+		{24 * time.Hour, time.Minute},
+		{10 * 24 * time.Hour, time.Minute},
+		{30 * 24 * time.Hour, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := r.resolution(tt.age); got != tt.want {
+			t.Errorf("resolution(%v) = %v, want %v", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	disk := Alert{Level: alertWarning, Source: "disk", Subject: "/", Message: "/ is 91% full"}
+	cpu := Alert{Level: alertCritical, Source: "cpu", Subject: "total", Message: "CPU at 99%"}
+	snaps := []Snapshot{
+		{Time: start, CPU: 10, Load: 1, MemUsed: 100, Alerts: []Alert{disk}},
+		{Time: start.Add(20 * time.Second), CPU: 20, Load: 2, MemUsed: 200, Alerts: []Alert{disk, cpu}},
+		{Time: start.Add(40 * time.Second), CPU: 30, Load: 3, MemUsed: 300},
+		{Time: start.Add(time.Minute), CPU: 50, Load: 5, MemUsed: 500},
+	}
+	tests := []struct {
+		name   string
+		res    time.Duration
+		times  []time.Time
+		cpu    []float64
+		alerts []int
+	}{
+		{"minutely", time.Minute, []time.Time{start.Add(40 * time.Second), start.Add(time.Minute)}, []float64{20, 50}, []int{2, 0}},
+		{"hourly", time.Hour, []time.Time{start.Add(time.Minute)}, []float64{27.5}, []int{2}},
+		{"finer than taken", time.Second, []time.Time{start, start.Add(20 * time.Second), start.Add(40 * time.Second), start.Add(time.Minute)},
+			[]float64{10, 20, 30, 50}, []int{1, 2, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := downsample(slices.Clone(snaps), tt.res)
+			if len(got) != len(tt.times) {
+				t.Fatalf("got %d snapshots, want %d", len(got), len(tt.times))
+			}
+			for i, s := range got {
+				if !s.Time.Equal(tt.times[i]) || s.CPU != tt.cpu[i] || len(s.Alerts) != tt.alerts[i] {
+					t.Errorf("snapshot %d at %s: CPU %v and %d alerts, want at %s CPU %v and %d alerts",
+						i, s.Time.Format(time.TimeOnly), s.CPU, len(s.Alerts), tt.times[i].Format(time.TimeOnly), tt.cpu[i], tt.alerts[i])
+				}
+			}
+		})
+	}
+}
+
+// writeDays writes a snapshot history file of size bytes for each of days,
+// in a state directory of the test's own
+func writeDays(t *testing.T, days []time.Time, size int) {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	if err := os.MkdirAll(historyDir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, day := range days {
+		if err := os.WriteFile(historyFile(day), make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCapHistory(t *testing.T) {
+	first := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	days := []time.Time{first, first.AddDate(0, 0, 1), first.AddDate(0, 0, 2)}
+	tests := []struct {
+		name    string
+		maxSize uint64
+		today   string
+		removed int
+	}{
+		{"under the cap", 300, "2026-03-03", 0},
+		{"no cap", 0, "2026-03-03", 0},
+		{"oldest removed", 250, "2026-03-03", 1},
+		{"all but today", 50, "2026-03-03", 2},
+		{"today kept whatever its size", 50, "2026-03-01", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeDays(t, days, 100)
+			removed, err := capHistory(days, tt.today, tt.maxSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(removed, days[:tt.removed]) {
+				t.Errorf("removed %v, want %v", removed, days[:tt.removed])
+			}
+			for i, day := range days {
+				_, err := os.Stat(historyFile(day))
+				if kept := err == nil; kept != (i >= tt.removed) {
+					t.Errorf("file of %s kept: %v", day.Format(time.DateOnly), kept)
+				}
+			}
+		})
+	}
+}
+
+// everyTenSeconds makes a snapshot every ten seconds of the first two
+// minutes of day
+func everyTenSeconds(day time.Time) []Snapshot {
+	var snaps []Snapshot
+	for i := range 12 {
+		snaps = append(snaps, Snapshot{Time: day.Add(time.Duration(i) * 10 * time.Second), CPU: float64(i)})
+	}
+	return snaps
+}
+
+func TestCompactHistory(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.Local)
+	today := time.Date(2026, 3, 31, 0, 0, 0, 0, time.Local)
+	yesterday, lastWeek, lastMonth := today.AddDate(0, 0, -1), today.AddDate(0, 0, -7), today.AddDate(0, 0, -30)
+	var snaps []Snapshot
+	for _, day := range []time.Time{lastMonth, lastWeek, yesterday, today} {
+		snaps = append(snaps, everyTenSeconds(day)...)
+	}
+	writeHistory(t, snaps)
+
+	r := retention{raw: 24 * time.Hour, minutely: 7 * 24 * time.Hour}
+	for run := range 2 {
+		if err := compactHistory(now, r); err != nil {
+			t.Fatalf("run %d: %v", run, err)
+		}
+		tests := []struct {
+			day    time.Time
+			want   int  // Snapshots kept
+			packed bool // Into a .tsz file
+		}{
+			{lastMonth, 1, true},
+			{lastWeek, 2, true},
+			{yesterday, 12, true},
+			{today, 12, false},
+		}
+		for _, tt := range tests {
+			got, err := loadSnapshots(tt.day)
+			if err != nil {
+				t.Fatalf("run %d: %v", run, err)
+			}
+			_, err = os.Stat(packedFile(tt.day))
+			if len(got) != tt.want || (err == nil) != tt.packed {
+				t.Errorf("run %d: %s has %d snapshots, packed %v; want %d, packed %v",
+					run, tt.day.Format(time.DateOnly), len(got), err == nil, tt.want, tt.packed)
+			}
+		}
+	}
+}
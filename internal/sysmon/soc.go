@@ -0,0 +1,119 @@
+package sysmon
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// isARM limits SoC polling to the architectures single board computers use
+var isARM = runtime.GOARCH == "arm" || runtime.GOARCH == "arm64"
+
+const socPollInterval = 5 * time.Second
+
+// socFlags decodes the Raspberry Pi firmware get_throttled bits; the low
+// bits are current conditions, the high bits latch once they happened
+var socFlags = []struct {
+	name          string
+	now, occurred uint32
+}{
+	{"under-voltage", 1 << 0, 1 << 16},
+	{"frequency capped", 1 << 1, 1 << 17},
+	{"throttled", 1 << 2, 1 << 18},
+	{"soft temperature limit", 1 << 3, 1 << 19},
+}
+
+func socCmd() tea.Cmd {
+	return func() tea.Msg {
+		return socMsg(readSoCStatus())
+	}
+}
+
+// readSoCStatus reads the throttle flags from sysfs or vcgencmd, plus the
+// SoC temperature and clock
+func readSoCStatus() SoCStatus {
+	var soc SoCStatus
+
+	if flags, ok := readThrottleFlags(); ok {
+		soc.Flags, soc.HasFlags = flags, true
+	} else if undervolt, ok := readRPiVoltAlarm(); ok {
+		// Without firmware flags the rpi_volt hwmon still reports
+		// under-voltage
+		soc.HasFlags = true
+		if undervolt {
+			soc.Flags = socFlags[0].now | socFlags[0].occurred
+		}
+	}
+
+	zones, _ := filepath.Glob("/sys/class/thermal/thermal_zone*")
+	for _, zone := range zones {
+		data, _ := os.ReadFile(filepath.Join(zone, "type"))
+		if kind := strings.TrimSpace(string(data)); strings.Contains(kind, "cpu") || strings.Contains(kind, "soc") {
+			soc.TempC = float64(readIntFile(filepath.Join(zone, "temp"))) / 1000
+			break
+		}
+	}
+
+	cpufreq := "/sys/devices/system/cpu/cpu0/cpufreq"
+	soc.FreqMHz = readUintFile(filepath.Join(cpufreq, "scaling_cur_freq")) / 1000
+	soc.MaxMHz = readUintFile(filepath.Join(cpufreq, "cpuinfo_max_freq")) / 1000
+
+	soc.Available = soc.HasFlags || soc.TempC > 0
+	return soc
+}
+
+// readThrottleFlags returns the firmware get_throttled value
+func readThrottleFlags() (uint32, bool) {
+	if data, err := os.ReadFile("/sys/devices/platform/soc/soc:firmware/get_throttled"); err == nil {
+		n, err := strconv.ParseUint(strings.TrimSpace(string(data)), 16, 32)
+		return uint32(n), err == nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	// Prints "throttled=0x50005"
+	out, err := exec.CommandContext(ctx, "vcgencmd", "get_throttled").Output()
+	if err != nil {
+		return 0, false
+	}
+	_, value, _ := strings.Cut(strings.TrimSpace(string(out)), "=")
+	n, err := strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 32)
+	return uint32(n), err == nil
+}
+
+// readRPiVoltAlarm reads the under-voltage alarm of the rpi_volt hwmon
+func readRPiVoltAlarm() (bool, bool) {
+	dirs, _ := filepath.Glob("/sys/class/hwmon/hwmon*")
+	for _, dir := range dirs {
+		name, _ := os.ReadFile(filepath.Join(dir, "name"))
+		if strings.TrimSpace(string(name)) != "rpi_volt" {
+			continue
+		}
+		return readUintFile(filepath.Join(dir, "in0_lcrit_alarm")) == 1, true
+	}
+	return false, false
+}
+
+// alerts reports current throttling as critical and past events as
+// warnings, since a marginal power supply shows up intermittently
+func (soc SoCStatus) alerts() []Alert {
+	if !soc.HasFlags {
+		return nil
+	}
+	var alerts []Alert
+	for _, f := range socFlags {
+		switch {
+		case soc.Flags&f.now != 0:
+			alerts = append(alerts, Alert{Level: alertCritical, Source: "soc", Subject: f.name, Message: f.name + " detected now"})
+		case soc.Flags&f.occurred != 0:
+			alerts = append(alerts, Alert{Level: alertWarning, Source: "soc", Subject: f.name, Message: f.name + " has occurred since boot"})
+		}
+	}
+	return alerts
+}
@@ -0,0 +1,72 @@
+package sysmon
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/state"
+)
+
+// savedState is the system monitor's section of the state file
+type savedState struct {
+	Tab      string               `json:"tab"`
+	Pinned   []string             `json:"pinned"` // Mount points on the System tab
+	Alerts   []*alertEvent        `json:"alerts"` // The alert center's, oldest first
+	Silenced map[string]time.Time `json:"silenced"`
+}
+
+// restoreState picks up where the last session left off. The alert
+// center keeps the day's alerts; those still active then count as cleared
+// when it quit, and fire anew if they still hold.
+func (m *model) restoreState() {
+	var s savedState
+	saved, ok := state.Restore("sys", &s)
+	if !ok {
+		return
+	}
+	if i := slices.Index(tabIDs, s.Tab); i >= 0 && m.tabs.Position(i) >= 0 {
+		m.tab = i
+	}
+	if s.Pinned != nil {
+		m.pinned = make(map[string]bool, len(s.Pinned))
+		for _, path := range s.Pinned {
+			m.pinned[path] = true
+		}
+	}
+	if !state.SameDay(saved) {
+		return
+	}
+	for _, e := range s.Alerts {
+		if e.Cleared.IsZero() {
+			e.Cleared = saved
+		}
+		m.center.events = append(m.center.events, e)
+	}
+	now := time.Now()
+	for source, until := range s.Silenced {
+		if until.After(now) {
+			m.center.silenced[source] = until
+		}
+	}
+}
+
+// KeepState implements state.Keeper
+func (m model) KeepState() map[string]any {
+	if m.source != "live" {
+		return nil
+	}
+	pinned := []string{} // Empty rather than null, which would restore the default pin
+	for path, on := range m.pinned {
+		if on {
+			pinned = append(pinned, path)
+		}
+	}
+	slices.Sort(pinned)
+	return map[string]any{"sys": savedState{
+		Tab:      tabIDs[m.tab],
+		Pinned:   pinned,
+		Alerts:   m.center.events,
+		Silenced: maps.Clone(m.center.silenced),
+	}}
+}
@@ -0,0 +1,190 @@
+package sysmon
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/sysstat"
+)
+
+// storagePollInterval is how often mdraid, ZFS and btrfs status is refreshed
+const storagePollInterval = 10 * time.Second
+
+func storageCmd(mounts []sysstat.Disk) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		defer debug.Timing("sysmon: storage status", time.Now(), nil)
+
+		arrays := readMdstat()
+		arrays = append(arrays, readZpoolStatus(ctx)...)
+		// Each subvolume mounted is a mount of its own, of the same device
+		probed := make(map[string]bool)
+		for _, d := range mounts {
+			if d.FSType == "btrfs" && !probed[d.Device] {
+				probed[d.Device] = true
+				arrays = append(arrays, readBtrfsStatus(ctx, d))
+			}
+		}
+		return storageMsg(arrays)
+	}
+}
+
+var (
+	mdMembersRe  = regexp.MustCompile(`\[(\d+)/(\d+)\] \[([U_]+)\]`)
+	mdProgressRe = regexp.MustCompile(`(resync|recovery|reshape|check|repair)\s*=\s*([\d.]+)%`)
+	percentRe    = regexp.MustCompile(`([\d.]+)%`)
+)
+
+// readMdstat parses /proc/mdstat into one entry per md array
+func readMdstat() []StorageArray {
+	file, err := os.Open("/proc/mdstat")
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	return parseMdstat(file)
+}
+
+// parseMdstat parses the content of /proc/mdstat
+func parseMdstat(r io.Reader) []StorageArray {
+	var arrays []StorageArray
+	var cur *StorageArray
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+
+		// "md0 : active raid1 sdb1[1] sda1[0](F)"
+		if len(fields) >= 3 && strings.HasPrefix(fields[0], "md") && fields[1] == ":" {
+			arrays = append(arrays, StorageArray{Kind: "mdraid", Name: fields[0], State: fields[2]})
+			cur = &arrays[len(arrays)-1]
+			if len(fields) >= 4 && strings.HasPrefix(fields[3], "raid") {
+				cur.Level = fields[3]
+			}
+			if strings.Contains(line, "(F)") {
+				cur.Degraded = true
+				cur.Detail = "failed member device"
+			}
+			if cur.State != "active" {
+				cur.Degraded = true
+			}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		// "976630336 blocks super 1.2 [2/1] [U_]"
+		if match := mdMembersRe.FindStringSubmatch(line); match != nil {
+			cur.Devices = "[" + match[3] + "]"
+			if match[1] != match[2] || strings.Contains(match[3], "_") {
+				cur.Degraded = true
+				cur.State = "degraded"
+			}
+		}
+		// "[==>......]  recovery = 12.6% (...) finish=80.1min"
+		if match := mdProgressRe.FindStringSubmatch(line); match != nil {
+			cur.Operation = match[1]
+			cur.Progress, _ = strconv.ParseFloat(match[2], 64)
+		}
+		if strings.TrimSpace(line) == "" {
+			cur = nil
+		}
+	}
+	return arrays
+}
+
+// readZpoolStatus parses `zpool status` output, if ZFS is installed
+func readZpoolStatus(ctx context.Context) []StorageArray {
+	out, err := exec.CommandContext(ctx, "zpool", "status").Output()
+	if err != nil {
+		return nil
+	}
+	return parseZpoolStatus(string(out))
+}
+
+// parseZpoolStatus parses the output of `zpool status`
+func parseZpoolStatus(out string) []StorageArray {
+	var pools []StorageArray
+	var cur *StorageArray
+	for _, line := range strings.Split(out, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		value = strings.TrimSpace(value)
+		switch {
+		case found && key == "pool":
+			pools = append(pools, StorageArray{Kind: "zfs", Name: value})
+			cur = &pools[len(pools)-1]
+		case cur == nil:
+		case found && key == "state":
+			cur.State = value
+			cur.Degraded = value != "ONLINE"
+		case found && key == "scan":
+			if strings.Contains(value, "in progress") {
+				cur.Operation = strings.Fields(value)[0]
+			}
+		case found && key == "errors":
+			if value != "No known data errors" {
+				cur.Degraded = true
+				cur.Detail = value
+			}
+		case cur.Operation != "" && strings.Contains(line, "% done"):
+			if match := percentRe.FindStringSubmatch(line); match != nil {
+				cur.Progress, _ = strconv.ParseFloat(match[1], 64)
+			}
+		}
+	}
+	return pools
+}
+
+// readBtrfsStatus reports device error counters and scrub progress for a
+// btrfs mount. Both commands need root; without it the state is "unknown".
+func readBtrfsStatus(ctx context.Context, d sysstat.Disk) StorageArray {
+	fs := StorageArray{Kind: "btrfs", Name: d.Path, State: "unknown"}
+
+	out, err := exec.CommandContext(ctx, "btrfs", "device", "stats", d.Path).Output()
+	if err != nil && len(out) == 0 {
+		return fs
+	}
+	// "[/dev/sda].write_io_errs    0"
+	var errs uint64
+	devices := make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if dev, _, ok := strings.Cut(fields[0], "]."); ok {
+			devices[strings.TrimPrefix(dev, "[")] = true
+		}
+		n, _ := strconv.ParseUint(fields[1], 10, 64)
+		errs += n
+	}
+	fs.State = "ok"
+	fs.Devices = fmt.Sprintf("%d devs", len(devices))
+	if errs > 0 {
+		fs.State = "errors"
+		fs.Degraded = true
+		fs.Detail = fmt.Sprintf("%d device errors (btrfs device stats %s)", errs, d.Path)
+	}
+
+	if out, err := exec.CommandContext(ctx, "btrfs", "scrub", "status", d.Path).Output(); err == nil {
+		text := string(out)
+		if strings.Contains(text, "running") {
+			fs.Operation = "scrub"
+			if match := percentRe.FindStringSubmatch(text); match != nil {
+				fs.Progress, _ = strconv.ParseFloat(match[1], 64)
+			}
+		}
+	}
+	return fs
+}
@@ -0,0 +1,125 @@
+package sysmon
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseMdstat(t *testing.T) {
+	tests := []struct {
+		name   string
+		mdstat string
+		want   []StorageArray
+	}{
+		{
+			name: "healthy mirror",
+			mdstat: `Personalities : [raid1]
+md0 : active raid1 sdb1[1] sda1[0]
+      976630336 blocks super 1.2 [2/2] [UU]
+      bitmap: 0/8 pages [0KB], 65536KB chunk
+
+unused devices: <none>
+`,
+			want: []StorageArray{{Kind: "mdraid", Name: "md0", Level: "raid1", State: "active", Devices: "[UU]"}},
+		},
+		{
+			name: "failed member recovering",
+			mdstat: `Personalities : [raid1] [raid6] [raid5] [raid4]
+md0 : active raid1 sdb1[1](F) sda1[0]
+      976630336 blocks super 1.2 [2/1] [U_]
+
+md1 : active raid5 sde1[3] sdd1[1] sdc1[0]
+      1953260544 blocks super 1.2 level 5, 512k chunk, algorithm 2 [3/2] [UU_]
+      [==>..................]  recovery = 12.6% (123456/976630272) finish=80.1min speed=176543K/sec
+
+unused devices: <none>
+`,
+			want: []StorageArray{
+				{Kind: "mdraid", Name: "md0", Level: "raid1", State: "degraded", Devices: "[U_]", Degraded: true, Detail: "failed member device"},
+				{Kind: "mdraid", Name: "md1", Level: "raid5", State: "degraded", Devices: "[UU_]", Degraded: true, Operation: "recovery", Progress: 12.6},
+			},
+		},
+		{
+			name: "inactive",
+			mdstat: `Personalities :
+md127 : inactive sdb[0](S)
+      976631512 blocks super 1.2
+
+unused devices: <none>
+`,
+			want: []StorageArray{{Kind: "mdraid", Name: "md127", State: "inactive", Degraded: true}},
+		},
+		{
+			name:   "no arrays",
+			mdstat: "Personalities :\nunused devices: <none>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseMdstat(strings.NewReader(tt.mdstat)); !slices.Equal(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseZpoolStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		want   []StorageArray
+	}{
+		{
+			name: "online",
+			status: `  pool: tank
+ state: ONLINE
+  scan: scrub repaired 0B in 00:10:12 with 0 errors on Sun Mar  1 00:34:13 2026
+config:
+
+	NAME        STATE     READ WRITE CKSUM
+	tank        ONLINE       0     0     0
+	  mirror-0  ONLINE       0     0     0
+	    sda     ONLINE       0     0     0
+	    sdb     ONLINE       0     0     0
+
+errors: No known data errors
+`,
+			want: []StorageArray{{Kind: "zfs", Name: "tank", State: "ONLINE"}},
+		},
+		{
+			name: "degraded resilvering, and a pool with errors",
+			status: `  pool: tank
+ state: DEGRADED
+status: One or more devices is currently being resilvered.
+  scan: resilver in progress since Sun Mar  1 10:00:00 2026
+	1.20T scanned at 500M/s, 600G issued at 250M/s, 2.00T total
+	300G resilvered, 30.00% done, 01:35:00 to go
+config:
+
+	NAME        STATE     READ WRITE CKSUM
+	tank        DEGRADED     0     0     0
+
+errors: No known data errors
+
+  pool: scratch
+ state: ONLINE
+config:
+
+errors: 2 data errors, use '-v' for a list
+`,
+			want: []StorageArray{
+				{Kind: "zfs", Name: "tank", State: "DEGRADED", Degraded: true, Operation: "resilver", Progress: 30},
+				{Kind: "zfs", Name: "scratch", State: "ONLINE", Degraded: true, Detail: "2 data errors, use '-v' for a list"},
+			},
+		},
+		{name: "no pools", status: "no pools available\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseZpoolStatus(tt.status); !slices.Equal(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,1546 @@
+// Package sysmon is the system monitor: the tabbed CPU, memory, process,
+// storage, container, service and kernel views, and the snapshot and report
+// commands built on the same collectors.
+package sysmon
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/s-archdev/Terminal_ADVIS/internal/alerting"
+	"github.com/s-archdev/Terminal_ADVIS/internal/baseline"
+	"github.com/s-archdev/Terminal_ADVIS/internal/budget"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+	"github.com/s-archdev/Terminal_ADVIS/internal/demo"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ring"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/internal/watchdog"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/proc"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/sysstat"
+)
+
+// Flags are the options of the system monitor, parsed by the advis command
+// before New is called
+var Flags = flag.NewFlagSet("sys", flag.ExitOnError)
+
+var (
+	flagContainerActions = Flags.Bool("container-actions", false,
+		"allow stopping and restarting containers from the Containers tab")
+	flagDStateThreshold = Flags.Int("dstate-alert", 5,
+		"alert when at least this many processes are in uninterruptible sleep (D state)")
+	flagDStateDuration = Flags.Duration("dstate-duration", 30*time.Second,
+		"how long the D state count must stay elevated before alerting")
+	flagFDAlert = Flags.Float64("fd-alert", 80,
+		"alert when a process or the system uses this percentage of its file descriptor limit")
+	flagLeakRate = Flags.Float64("leak-rate", 1,
+		"flag processes whose RSS grows steadily by at least this many MiB per minute as leak suspects")
+	flagHistory = Flags.Duration("history", 10*time.Minute,
+		"how much history to keep for the load timeline and the top processes graph")
+	flagLazyProcs = Flags.Bool("lazy-procs", false,
+		"only scan processes while the Process tab or dashboard is visible (process alerts pause meanwhile)")
+	flagLazySensors = Flags.Bool("lazy-sensors", false,
+		"only read SoC throttling and temperature while the System tab is visible")
+	flagReadOnly = Flags.Bool("read-only", false,
+		"refuse every action that changes the host, such as deleting files or stopping services and containers, whatever the privileges")
+	flagWatchdogRecover = Flags.Bool("watchdog-recover", false,
+		"run the recovery commands of the config file's watchdog services once they are down; leave off where \"advis watchdog\" supervises them")
+	flagWatch watchRules
+)
+
+func init() {
+	Flags.Var(&flagWatch, "watch",
+		"watch a process by name, alerting when it exits, restarts or exceeds limits;\n"+
+			"repeatable, e.g. -watch postgres -watch 'nginx:cpu=80,mem=512M'")
+}
+
+// Styles, set from the palette
+var titleStyle, barStyle, usedBarStyle, infoStyle, headerStyle, dimStyle, alertStyle lipgloss.Style
+
+func init() { ui.OnPalette(setStyles) }
+
+// setStyles styles the monitor in p's colors, keeping its own for the
+// roles p leaves empty, along with the heatmap, graph and row fragments
+// built from the styles
+func setStyles(p ui.Palette) {
+	titleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ui.ColorOr(p.Title, "#7D56F4")).
+		Background(ui.ColorOr(p.TitleBg, "#282828")).
+		Padding(0, 1)
+
+	barStyle = lipgloss.NewStyle().
+		Foreground(ui.ColorOr(p.Good, "#04B575"))
+
+	usedBarStyle = lipgloss.NewStyle().
+		Foreground(ui.ColorOr(p.Critical, "#FF6B6B"))
+
+	infoStyle = lipgloss.NewStyle().
+		Foreground(ui.ColorOr(p.Info, "#FBBF24")).
+		Padding(0, 1)
+
+	headerStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ui.ColorOr(p.Accent, "#06D6A0"))
+
+	dimStyle = lipgloss.NewStyle().
+		Foreground(ui.ColorOr(p.Dim, "#777777"))
+
+	alertStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ui.ColorOr(p.AlertFg, "#FFFFFF")).
+		Background(ui.ColorOr(p.AlertBg, "#C0392B")).
+		Padding(0, 1)
+
+	heatLevels = []heatLevel{
+		{10, dimStyle},
+		{30, barStyle},
+		{60, lipgloss.NewStyle().Foreground(ui.ColorOr(p.Warning, "#FBBF24"))},
+		// A palette's warning color in bold, as it has no shade between
+		// warning and critical
+		{85, lipgloss.NewStyle().Foreground(ui.ColorOr(p.Warning, "#FF8C42")).Bold(p.Warning != "")},
+		{101, usedBarStyle},
+	}
+	topColors = []lipgloss.Color{"#FF6B6B", "#FBBF24", "#04B575", "#4EA8DE", "#B388EB"}
+	for i, c := range p.Series[:min(len(p.Series), len(topColors))] {
+		topColors[i] = lipgloss.Color(c)
+	}
+	cursorMarker = &ui.Styled{Style: headerStyle}
+	alertCell = &ui.Styled{Style: usedBarStyle}
+	driftCell = &ui.Styled{Style: lipgloss.NewStyle().Foreground(ui.ColorOr(p.Warning, "#FBBF24"))}
+}
+
+// Model represents the state of our application
+type model struct {
+	width    int
+	height   int
+	rev      uint64       // Bumped by every message that can change the view
+	frames   *frameCache  // Shared by the copies Update makes
+	errs     *ui.ErrorLog // Collector failures, shared like frames
+	mounts   []sysstat.Disk
+	sysInfo  sysstat.Info
+	host     HostInfo
+	lastTick time.Time
+	tab      int       // Current tab, one of the tab* constants
+	tabs     ui.TabSet // Tabs on the tab bar
+	scrollX  int       // Columns wide tables are scrolled sideways by
+
+	diskCursor int             // Selected row in the mount table
+	pinned     map[string]bool // Mount points shown on the System tab
+
+	system        sysstat.Collector
+	systemPolling bool // A systemCmd is in flight
+	processes     proc.Collector
+	procPolling   bool // A processesCmd is in flight
+	procs         []proc.Process
+	procsAt       time.Time    // When procs was scanned, zero before the first scan
+	oomVictims    map[int]bool // PIDs the OOM killer would pick first
+	cores         []float64    // Utilization of each logical CPU in percent
+	cpuTotal      float64
+
+	procSort   int // One of the sortBy* constants
+	procCursor int
+	procByUser bool // Show per-user totals instead of processes
+	procStuck  bool // Only list zombie and D state processes
+	procGraph  bool // Show the top consumers over time instead of the table
+	topHistory *ring.Buffer[topSample]
+
+	watched     []watchState
+	leaks       *leakTracker
+	leakSuspect []LeakSuspect
+
+	zombies     int       // Processes in state Z
+	dState      int       // Processes in state D (uninterruptible sleep)
+	dStateSince time.Time // When dState first reached the alert threshold
+
+	arrays       []StorageArray
+	arraysPolled time.Time
+
+	limits       []KernelLimit
+	limitsPolled time.Time
+
+	soc       SoCStatus
+	socPolled time.Time
+
+	containers       []Container
+	containerRT      *containerRuntime // nil when no runtime socket was found
+	containerCursor  int
+	containerPolling bool           // A containersCmd is in flight
+	vmView           bool           // The Containers tab shows libvirt guests instead
+	libvirt          *libvirtClient // nil when virsh is not installed
+	vms              []VM
+	vmCursor         int
+	vmPolling        bool
+	containerStatus  string // Result of the last action or poll error
+
+	services       []Service
+	systemd        *systemdClient
+	serviceCursor  int
+	serviceFailed  bool // Only list failed units
+	servicePolling bool // A servicesCmd is in flight
+	servicePolled  time.Time
+	serviceStatus  string // Result of the last action or poll error
+
+	journal journalPane
+
+	numaNodes     []NUMANode
+	numaSampler   *numaSampler
+	hugepages     HugepageInfo
+	memoryPolling bool // A memoryCmd is in flight
+
+	interrupts InterruptStats
+	irqSampler *irqSampler
+	irqPolling bool // An interruptsCmd is in flight
+
+	watchdog       *watchdog.Supervisor // nil when no services are supervised
+	watchdogStatus []watchdog.Status
+
+	cgroups        *cgroupNode // Root of the cgroup v2 hierarchy
+	cgroupSampler  *cgroupSampler
+	cgroupPolling  bool // A cgroupsCmd is in flight
+	cgroupCursor   int
+	cgroupSort     int             // One of the sortBy* constants (PID sorts by name)
+	cgroupExpanded map[string]bool // Expanded state keyed by cgroup path
+
+	kmsg         *kmsgTail // nil when /dev/kmsg is not readable
+	kmsgErr      error
+	kernelEvents []kernelEvent // Newest last
+	timeline     *ring.Buffer[timelineSample]
+
+	alerts   []Alert // Active alerts, recomputed every tick
+	center   *alertCenter
+	audit    *auditLog
+	flash    string // One-off status shown in the footer until the next key
+	chord    ui.KeyChord
+	keysOpen bool // Show the key overlay instead of the tab
+	split    ui.Split
+	shown    ui.VisibilityMsg
+	source   string // Shown in the status bar: live or demo
+
+	scan    dirScanState
+	confirm *confirmPrompt // Pending yes/no question, if any
+
+	baseline *baseline.Baseline // Drift is highlighted from, nil for none
+}
+
+// Alert severities
+const (
+	alertWarning = iota
+	alertCritical
+)
+
+// Alert is a condition shown in the banner below the title
+type Alert struct {
+	Level   int
+	Source  string
+	Subject string // What of the source it is about, such as a PID or a mount, which with the source identifies it across ticks
+	Message string
+}
+
+// Tabs
+const (
+	tabSystem = iota
+	tabDisk
+	tabProcess
+	tabDirScan
+	tabContainers
+	tabServices
+	tabKernel
+	tabCgroups
+	tabMemory
+	tabInterrupts
+	tabWatchdog
+)
+
+var tabNames = []string{"System Info", "Disk Usage", "Process Tree", "Dir Scan", "Containers", "Services", "Kernel", "Cgroups", "Memory", "Interrupts", "Watchdog"}
+
+// tabIDs are how the config file names the tabs
+var tabIDs = []string{"system", "disk", "processes", "dirscan", "containers", "services", "kernel", "cgroups", "memory", "interrupts", "watchdog"}
+
+// tabSet is the tabs the config file enables, every one by default
+var tabSet, _ = ui.NewTabSet(tabIDs, nil)
+
+// SetTabs applies the "sys" list of the config file's "tabs": the tabs to
+// show, in order
+func SetTabs(names []string) error {
+	s, err := ui.NewTabSet(tabIDs, names)
+	if err != nil {
+		return err
+	}
+	tabSet = s
+	return nil
+}
+
+// ReadOnly reports whether -read-only refuses the actions changing the
+// host
+func ReadOnly() bool { return *flagReadOnly }
+
+// confirmPrompt is a yes/no question that must be answered before a
+// destructive action runs
+type confirmPrompt struct {
+	message string
+	action  tea.Cmd
+}
+
+// confirmAction asks before running action, the only way a destructive
+// action runs, unless -read-only refuses it outright
+func (m *model) confirmAction(message string, action tea.Cmd) {
+	if *flagReadOnly {
+		m.flash = "Read-only: actions are disabled"
+		return
+	}
+	m.confirm = &confirmPrompt{message: message, action: action}
+}
+
+// dirScanState holds the directory size analyzer state
+type dirScanState struct {
+	path     string    // Directory to scan
+	root     *dirEntry // Result of the last scan
+	current  *dirEntry // Directory currently being browsed
+	cursor   int       // Selected child of current
+	sortBy   int       // 0: size, 1: name, 2: item count
+	scanning bool      // A scan is in progress
+	started  time.Time // When the running or last scan started
+	elapsed  time.Duration
+	err      error
+	editing  bool // Path input has focus
+	input    textinput.Model
+}
+
+// journalPane is the log pane shown below the active tab
+type journalPane struct {
+	open    bool
+	unit    string // Unit to follow, "" for the whole journal
+	tail    *journalTail
+	entries []journalEntry
+	offset  int // Lines scrolled back from the newest entry
+	filter  string
+	editing bool // Filter input has focus
+	input   textinput.Model
+	err     error
+}
+
+// kernelEvent is a kernel log message matching one of the kernelPatterns
+type kernelEvent struct {
+	Time    time.Time
+	Kind    string
+	Level   int // Alert severity
+	Message string
+	Context string // Metrics at the time the event was seen
+}
+
+// SoCStatus is the power and thermal state of an ARM single board computer
+type SoCStatus struct {
+	Available bool    // Any SoC sensor was found
+	Flags     uint32  // Raspberry Pi firmware throttle bits, see socFlags
+	HasFlags  bool    // Flags came from the firmware
+	TempC     float64 // SoC temperature, 0 when unknown
+	FreqMHz   uint64
+	MaxMHz    uint64
+}
+
+// KernelLimit is a system-wide kernel limit and how much of it is used
+type KernelLimit struct {
+	Name   string
+	Used   uint64
+	Max    uint64
+	Detail string // Who is closest to the limit, for per-process or per-user limits
+}
+
+// NUMANode is a NUMA node with its memory usage and allocation counters
+type NUMANode struct {
+	ID        int
+	CPUs      string // CPU list, e.g. "0-15,32-47"
+	MemTotal  uint64
+	MemFree   uint64
+	Hit       uint64 // Allocations satisfied on the intended node
+	Miss      uint64 // Allocations that landed here but were intended elsewhere
+	Foreign   uint64 // Allocations intended here that landed elsewhere
+	LocalNode uint64 // Allocations by a process running on this node
+	OtherNode uint64 // Allocations here by a process on another node
+	MissRate  float64
+}
+
+// HugepageInfo describes the hugetlbfs pools and transparent hugepages
+type HugepageInfo struct {
+	Pools      []HugepagePool
+	THPEnabled string // Selected mode of transparent_hugepage/enabled
+	THPDefrag  string
+	AnonHuge   uint64 // Anonymous memory backed by transparent hugepages
+
+	// Allocation counters from /proc/vmstat
+	THPFaultAlloc    uint64
+	THPFaultFallback uint64 // THP faults that fell back to small pages
+	THPCollapseFail  uint64 // khugepaged collapse allocation failures
+	HugetlbAllocFail uint64 // Failed attempts to grow a hugetlb pool
+}
+
+// HugepagePool is the persistent hugepage pool of one page size
+type HugepagePool struct {
+	PageSize uint64
+	Total    uint64
+	Free     uint64
+	Reserved uint64
+	Surplus  uint64
+}
+
+// numaSampler computes numa_miss rates between samples
+type numaSampler struct {
+	prevMiss map[int]uint64
+	last     time.Time
+}
+
+// InterruptStats holds per-CPU hard and soft interrupt rates
+type InterruptStats struct {
+	CPUs     int
+	PerCPU   []float64   // Hard interrupts per second on each CPU
+	Sources  []IRQSource // Hard IRQs, busiest first
+	Softirqs []IRQSource
+}
+
+// IRQSource is one line of /proc/interrupts or /proc/softirqs
+type IRQSource struct {
+	Name        string // IRQ number or mnemonic such as "LOC" or "NET_RX"
+	Description string // Controller, trigger and device names
+	PerCPU      []float64
+	Rate        float64 // Sum over all CPUs, per second
+}
+
+// busiestCPU returns the CPU handling most of this source and its share
+func (s IRQSource) busiestCPU() (int, float64) {
+	cpu := 0
+	for i, r := range s.PerCPU {
+		if r > s.PerCPU[cpu] {
+			cpu = i
+		}
+	}
+	if s.Rate == 0 || len(s.PerCPU) == 0 {
+		return cpu, 0
+	}
+	return cpu, s.PerCPU[cpu] / s.Rate * 100
+}
+
+// irqSampler turns interrupt counters into rates between samples
+type irqSampler struct {
+	prev     map[string][]uint64
+	prevSoft map[string][]uint64
+	last     time.Time
+}
+
+// cgroupNode is a cgroup v2 directory with its resource usage
+type cgroupNode struct {
+	Path      string // Relative to the cgroup mount, "/" for the root
+	Name      string
+	CPU       float64 // Percent of one core
+	Memory    uint64
+	MemoryMax uint64  // 0 when unlimited
+	ReadRate  float64 // Bytes per second
+	WriteRate float64
+	Procs     int // Processes directly in this cgroup
+	Children  []*cgroupNode
+}
+
+// cgroupSampler turns cumulative cgroup counters into per-second rates
+type cgroupSampler struct {
+	prev map[string]cgroupCounters
+}
+
+// cgroupCounters are the cumulative counters of a cgroup at the last scan
+type cgroupCounters struct {
+	at         time.Time // When they were read, for rates over the real interval
+	cpuUsec    uint64
+	readBytes  uint64
+	writeBytes uint64
+}
+
+// timelineSample is one point of the load timeline on the Kernel tab and
+// the CPU history on the dashboard
+type timelineSample struct {
+	Time time.Time
+	Load float64
+	CPU  float64 // Overall utilization in percent
+}
+
+// journalEntry is a single journal record
+type journalEntry struct {
+	Time     time.Time
+	Priority int // syslog priority, 0 (emerg) to 7 (debug)
+	Source   string
+	Message  string
+}
+
+// dirEntry is a file or directory in the scanned tree
+type dirEntry struct {
+	Name       string
+	Path       string
+	Size       uint64 // Disk usage in bytes (including children)
+	Items      int    // Number of files and directories below this one
+	Errors     int    // Unreadable directories below this one
+	IsDir      bool
+	MountPoint bool // A directory where another filesystem is mounted, not descended into
+	Parent     *dirEntry
+	Children   []*dirEntry
+}
+
+// StorageArray is an mdraid array, ZFS pool or btrfs filesystem
+type StorageArray struct {
+	Kind      string // "mdraid", "zfs" or "btrfs"
+	Name      string
+	Level     string // RAID level, when known
+	State     string
+	Devices   string  // Member status, e.g. "[UU_]" for mdraid
+	Degraded  bool    // Redundancy is reduced or devices report errors
+	Operation string  // Running resync/recovery/scrub, if any
+	Progress  float64 // Percent done of Operation
+	Detail    string
+}
+
+// Container is a running Docker or Podman container with resource rates
+type Container struct {
+	ID          string
+	Name        string
+	Image       string
+	State       string
+	Status      string
+	PID         int
+	CPU         float64 // Percent of one core
+	Memory      uint64
+	MemoryLimit uint64  // 0 when unlimited
+	ReadRate    float64 // Block I/O bytes per second
+	WriteRate   float64
+	RxRate      float64 // Network bytes per second
+	TxRate      float64
+	HasStats    bool // cgroup counters were readable
+}
+
+// VM is a running libvirt domain with resource rates
+type VM struct {
+	Name          string
+	VCPUs         int
+	CPU           float64 // Percent of one core
+	BalloonActual uint64  // Memory currently given to the guest by the balloon
+	BalloonMax    uint64
+	RSS           uint64 // Host memory used by the QEMU process
+	ReadRate      float64
+	WriteRate     float64
+	RxRate        float64
+	TxRate        float64
+}
+
+// Service is a systemd service unit with its resource accounting
+type Service struct {
+	Name        string
+	Description string
+	ActiveState string // active, inactive, failed, activating...
+	SubState    string // running, exited, dead...
+	Memory      uint64
+	HasMemory   bool    // MemoryAccounting is enabled
+	CPU         float64 // Percent of one core
+	HasCPU      bool    // CPUAccounting is enabled
+	Restarts    uint32  // NRestarts as reported by systemd
+	NewRestarts uint32  // Restarts since the monitor started
+}
+
+// HostInfo describes the machine and OS; it is read once at startup
+type HostInfo struct {
+	Hostname       string
+	Distro         string
+	Kernel         string
+	CPUModel       string
+	CPUSockets     int
+	CPUCores       int      // Physical cores
+	Caches         []string // e.g. "L2 1 MiB"
+	MemoryTotal    uint64
+	MemoryModules  []MemoryModule
+	Virtualization string
+}
+
+// MemoryModule is an installed RAM stick as reported by DMI
+type MemoryModule struct {
+	Locator      string
+	Size         uint64
+	Type         string
+	SpeedMTs     int
+	Manufacturer string
+	PartNumber   string
+}
+
+// watchRule supervises every process with a given name
+type watchRule struct {
+	Name      string
+	MaxCPU    float64 // Percent of one core summed over matches, 0 for no limit
+	MaxMemory uint64  // Bytes of RSS summed over matches, 0 for no limit
+}
+
+// watchRules implements flag.Value for repeated -watch flags
+type watchRules []watchRule
+
+func (w *watchRules) String() string {
+	var names []string
+	for _, r := range *w {
+		names = append(names, r.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+// Set parses "name[:cpu=PERCENT][,mem=SIZE]"
+func (w *watchRules) Set(value string) error {
+	name, limits, _ := strings.Cut(value, ":")
+	rule := watchRule{Name: strings.TrimSpace(name)}
+	if rule.Name == "" {
+		return errors.New("missing process name")
+	}
+	for _, limit := range strings.Split(limits, ",") {
+		if limit == "" {
+			continue
+		}
+		key, val, _ := strings.Cut(limit, "=")
+		var err error
+		switch key {
+		case "cpu":
+			rule.MaxCPU, err = strconv.ParseFloat(strings.TrimSuffix(val, "%"), 64)
+		case "mem":
+			rule.MaxMemory, err = parseSize(val)
+		default:
+			return fmt.Errorf("unknown limit %q (want cpu or mem)", key)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	*w = append(*w, rule)
+	return nil
+}
+
+// watchState tracks one watchRule across ticks
+type watchState struct {
+	Rule        watchRule
+	Procs       int
+	CPU         float64
+	Memory      uint64
+	Seen        bool      // Has been running since startup
+	Restarts    int       // Times the main processes were replaced
+	LastRestart time.Time // Zero when it never restarted
+	roots       map[int]bool
+}
+
+// topSample is the heaviest processes at one tick, keyed by "name (pid)"
+type topSample struct {
+	Time   time.Time
+	CPU    map[string]float64
+	Memory map[string]float64
+}
+
+// LeakSuspect is a process whose resident memory keeps growing
+type LeakSuspect struct {
+	PID    int
+	Name   string
+	RSS    uint64
+	Growth uint64        // Bytes gained over the tracked window
+	Slope  float64       // Least-squares growth in bytes per second
+	Window time.Duration // How long the growth has been observed
+}
+
+// leakTracker keeps a short RSS history per process to spot steady growth
+type leakTracker struct {
+	history  map[int]*rssHistory
+	last     time.Time
+	suspects []LeakSuspect
+}
+
+// rssHistory is the RSS samples of one process, oldest first
+type rssHistory struct {
+	name    string
+	samples *ring.Buffer[rssSample]
+}
+
+type rssSample struct {
+	time time.Time
+	rss  uint64
+}
+
+// UserUsage is the combined resource usage of all processes of one user
+type UserUsage struct {
+	User      string
+	Procs     int
+	CPU       float64
+	Memory    uint64
+	ReadRate  float64
+	WriteRate float64
+	FDs       int // Sum over the processes whose fds were readable
+}
+
+// Process table sort columns
+const (
+	sortByCPU = iota
+	sortByMemory
+	sortByRead
+	sortByWrite
+	sortByFDs
+	sortByOOM
+	sortByPID
+)
+
+var procSortNames = []string{"CPU", "MEM", "READ", "WRITE", "FDS", "OOM", "PID"}
+
+// Messages for the tea program
+type tickMsg time.Time
+
+type dirScanMsg struct {
+	root    *dirEntry
+	elapsed time.Duration
+	err     error
+}
+
+type storageMsg []StorageArray
+
+type limitsMsg []KernelLimit
+
+type socMsg SoCStatus
+
+type journalMsg struct {
+	tail    *journalTail
+	entries []journalEntry
+}
+
+type kernelMsg []kernelEvent
+
+type journalClosedMsg struct {
+	tail *journalTail
+	err  error
+}
+
+type vmsMsg struct {
+	vms []VM
+	err error
+}
+
+type memoryMsg struct {
+	numa      []NUMANode
+	hugepages HugepageInfo
+	err       error
+}
+
+type interruptsMsg struct {
+	stats InterruptStats
+	err   error
+}
+
+type cgroupsMsg struct {
+	root *cgroupNode
+	err  error
+}
+
+type containersMsg struct {
+	containers []Container
+	err        error
+}
+
+type servicesMsg struct {
+	services []Service
+	err      error
+}
+
+type serviceActionMsg struct {
+	action string
+	name   string
+	err    error
+	asRoot bool // Rerun through "advis privileged"
+}
+
+type containerActionMsg struct {
+	action string
+	id     string
+	name   string
+	err    error
+	asRoot bool
+}
+
+type fileDeletedMsg struct {
+	entry  *dirEntry
+	err    error
+	asRoot bool
+}
+
+// tickInterval is how often the system and processes are sampled
+const tickInterval = time.Second
+
+// historyLength is how many per-tick samples the timelines keep
+func historyLength() int {
+	return max(int(*flagHistory/tickInterval), 2)
+}
+
+func tickCmd() tea.Cmd {
+	return tea.Tick(budget.Interval(tickInterval), func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// collectTimeout bounds each collection. A source slower than this, such
+// as a hung NFS mount, delivers what it has and cannot hold up the others.
+const collectTimeout = 2 * time.Second
+
+type systemMsg struct {
+	snap sysstat.Snapshot
+	err  error
+}
+
+type processesMsg struct {
+	procs []proc.Process
+	at    time.Time // When the scan finished, for rates over real time
+	err   error
+}
+
+func systemCmd(c sysstat.Collector) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+		defer cancel()
+		start := time.Now()
+		snap, err := c.Collect(ctx)
+		debug.Timing("sysmon: system collection", start, err)
+		return systemMsg{snap: snap, err: err}
+	}
+}
+
+func processesCmd(c proc.Collector) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+		defer cancel()
+		start := time.Now()
+		procs, err := c.Collect(ctx)
+		debug.Timing(fmt.Sprintf("sysmon: process scan (%d)", len(procs)), start, err)
+		return processesMsg{procs: procs, at: time.Now(), err: err}
+	}
+}
+
+// New returns the system monitor, configured from Flags
+func New() tea.Model {
+	m := initialModel(&sysstat.System{}, &proc.Sampler{}, "live")
+	m.restoreState()
+	m.baseline = baseline.Get()
+	m.watchdog = startWatchdog()
+	return m
+}
+
+// NewDemo returns the system monitor playing back the demo dataset
+func NewDemo(r *demo.Replay) tea.Model {
+	return initialModel(r.System(tickInterval), r.Processes(tickInterval), "demo")
+}
+
+// Initialize the model
+func initialModel(system sysstat.Collector, processes proc.Collector, source string) model {
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = "/"
+	}
+	input := textinput.New()
+	input.Prompt = "Scan path: "
+	input.CharLimit = 4096
+
+	filter := textinput.New()
+	filter.Prompt = "Filter: "
+
+	kmsg, kmsgErr := startKmsg()
+
+	m := model{
+		host:     getHostInfo(),
+		kmsg:     kmsg,
+		kmsgErr:  kmsgErr,
+		lastTick: time.Now(),
+		tab:      tabSet.Order[0],
+		tabs:     tabSet,
+		pinned:   map[string]bool{"/": true},
+		shown:    ui.VisibilityMsg{Full: true},
+		source:   source,
+		center:   newAlertCenter(),
+		audit:    &auditLog{},
+		frames:   &frameCache{},
+		errs:     &ui.ErrorLog{},
+
+		system:        system,
+		systemPolling: true,
+		processes:     processes,
+		procPolling:   true,
+		leaks:         &leakTracker{history: make(map[int]*rssHistory)},
+		timeline:      ring.New[timelineSample](historyLength()),
+		topHistory:    ring.New[topSample](historyLength()),
+		watched:       newWatchStates(flagWatch),
+
+		numaSampler:    &numaSampler{},
+		irqSampler:     &irqSampler{},
+		cgroupSampler:  &cgroupSampler{},
+		cgroupSort:     sortByMemory,
+		cgroupExpanded: map[string]bool{"/": true},
+		scan:           dirScanState{path: cwd, input: input},
+		journal:        journalPane{input: filter},
+
+		containerRT: detectContainerRuntime(),
+		libvirt:     detectLibvirt(),
+		systemd:     &systemdClient{},
+	}
+	// Hypervisor hosts without a container runtime open on their guests
+	m.vmView = m.containerRT == nil && m.libvirt != nil
+	return m
+}
+
+// Init runs any intial IO
+func (m model) Init() tea.Cmd {
+	cmds := []tea.Cmd{tickCmd(), systemCmd(m.system), processesCmd(m.processes)}
+	if m.kmsg != nil {
+		cmds = append(cmds, waitKmsgCmd(m.kmsg))
+	}
+	return tea.Batch(cmds...)
+}
+
+// Update handles messages
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Assume the message changes what is shown; the default case below
+	// takes this back for messages meant for other monitors
+	m.rev++
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		// A pending confirmation swallows all keys until answered
+		if m.confirm != nil {
+			switch {
+			case key.Matches(msg, keys.Confirm):
+				cmd := m.confirm.action
+				m.confirm = nil
+				return m, cmd
+			case key.Matches(msg, keys.Cancel):
+				m.confirm = nil
+			}
+			return m, nil
+		}
+		if m.scan.editing {
+			return m.updateScanInput(msg)
+		}
+		if m.journal.editing {
+			return m.updateJournalFilter(msg)
+		}
+		// "gg" waits on its second key; one that does not complete it
+		// comes with the held one
+		pressed := m.chord.Feed(msg, keys.Top)
+		var cmds []tea.Cmd
+		var next tea.Model = m
+		for _, k := range pressed {
+			var cmd tea.Cmd
+			next, cmd = next.(model).updateKey(k)
+			cmds = append(cmds, cmd)
+		}
+		return next, tea.Batch(cmds...)
+
+	case dirScanMsg:
+		m.scan.scanning = false
+		m.scan.elapsed = msg.elapsed
+		m.scan.err = msg.err
+		if msg.err == nil {
+			m.scan.root = msg.root
+			m.scan.current = msg.root
+			m.scan.cursor = 0
+			m.scan.sortChildren()
+		}
+
+	case fileDeletedMsg:
+		if msg.err != nil {
+			m.scan.err = msg.err
+		} else {
+			m.scan.err = nil
+			m.scan.remove(msg.entry)
+		}
+		if !msg.asRoot {
+			m.offerRoot(msg.err, "Deleting "+msg.entry.Name, func(err error) tea.Msg {
+				return fileDeletedMsg{entry: msg.entry, err: err, asRoot: true}
+			}, "delete-file", msg.entry.Path)
+		}
+		return m, m.auditCmd("delete file", msg.entry.Path, msg.err, msg.asRoot)
+
+	case auditMsg:
+		m.errs.Add("audit log", msg.err, msg.entry.Time)
+		if msg.err == nil {
+			m.audit.entries = append(m.audit.entries, msg.entry)
+		}
+
+	case auditLoadedMsg:
+		m.audit.entries, m.audit.err = msg.entries, msg.err
+
+	case journalMsg:
+		if msg.tail != m.journal.tail {
+			return m, nil // Output of a stopped tail
+		}
+		m.journal.append(msg.entries)
+		return m, waitJournalCmd(msg.tail)
+
+	case kernelMsg:
+		metrics := m.metricContext()
+		for _, e := range msg {
+			// Only annotate events that happened while we were watching
+			if time.Since(e.Time) < 5*time.Second {
+				e.Context = metrics
+			}
+			m.kernelEvents = append(m.kernelEvents, e)
+		}
+		if extra := len(m.kernelEvents) - maxKernelEvents; extra > 0 {
+			m.kernelEvents = append([]kernelEvent(nil), m.kernelEvents[extra:]...)
+		}
+		m.alerts = m.checkAlerts()
+		return m, waitKmsgCmd(m.kmsg)
+
+	case journalClosedMsg:
+		if msg.tail == m.journal.tail {
+			m.journal.err = msg.err
+			m.journal.tail = nil
+			m.errs.Add("journalctl", msg.err, time.Now())
+		}
+
+	case ui.ClipboardMsg:
+		m.flash = msg.Status()
+
+	case ui.ScreenshotMsg:
+		m.flash = msg.Status()
+
+	case alertsPersistedMsg:
+		m.errs.Add("alert history", msg.err, time.Now())
+
+	case storageMsg:
+		m.arrays = msg
+		m.alerts = m.checkAlerts()
+
+	case limitsMsg:
+		m.limits = msg
+		m.alerts = m.checkAlerts()
+
+	case socMsg:
+		m.soc = SoCStatus(msg)
+		m.alerts = m.checkAlerts()
+
+	case memoryMsg:
+		m.memoryPolling = false
+		m.errs.Add("memory", msg.err, time.Now())
+		if msg.err == nil {
+			m.numaNodes, m.hugepages = msg.numa, msg.hugepages
+		}
+
+	case interruptsMsg:
+		m.irqPolling = false
+		m.errs.Add("interrupts", msg.err, time.Now())
+		if msg.err == nil {
+			m.interrupts = msg.stats
+		}
+
+	case cgroupsMsg:
+		m.cgroupPolling = false
+		m.errs.Add("cgroups", msg.err, time.Now())
+		// An abandoned walk keeps the previous tree rather than blanking it
+		if msg.err == nil {
+			m.cgroups = msg.root
+			sortCgroups(m.cgroups, m.cgroupSort)
+			if rows := m.visibleCgroups(); m.cgroupCursor >= len(rows) {
+				m.cgroupCursor = max(len(rows)-1, 0)
+			}
+		}
+
+	case containersMsg:
+		m.containerPolling = false
+		m.containers = msg.containers
+		if msg.err != nil {
+			m.containerStatus = msg.err.Error()
+			m.errs.Add("containers", msg.err, time.Now())
+		}
+		if m.containerCursor >= len(m.containers) {
+			m.containerCursor = max(len(m.containers)-1, 0)
+		}
+
+	case vmsMsg:
+		m.vmPolling = false
+		m.vms = msg.vms
+		if msg.err != nil {
+			m.containerStatus = msg.err.Error()
+			m.errs.Add("virsh", msg.err, time.Now())
+		}
+		if m.vmCursor >= len(m.vms) {
+			m.vmCursor = max(len(m.vms)-1, 0)
+		}
+
+	case servicesMsg:
+		m.servicePolling = false
+		m.services = msg.services
+		if msg.err != nil {
+			m.serviceStatus = msg.err.Error()
+			m.errs.Add("systemd", msg.err, time.Now())
+		}
+		if m.serviceCursor >= len(m.visibleServices()) {
+			m.serviceCursor = max(len(m.visibleServices())-1, 0)
+		}
+		m.alerts = m.checkAlerts()
+
+	case serviceActionMsg:
+		if msg.err != nil {
+			m.serviceStatus = fmt.Sprintf("%s %s failed: %v", msg.action, msg.name, msg.err)
+		} else {
+			m.serviceStatus = fmt.Sprintf("%s %s: queued", msg.action, msg.name)
+		}
+		if !msg.asRoot {
+			m.offerRoot(msg.err, "Service "+msg.action, func(err error) tea.Msg {
+				return serviceActionMsg{action: msg.action, name: msg.name, err: err, asRoot: true}
+			}, "service", msg.action, msg.name)
+		}
+		return m, m.auditCmd(msg.action+" service", msg.name, msg.err, msg.asRoot)
+
+	case containerActionMsg:
+		if msg.err != nil {
+			m.containerStatus = fmt.Sprintf("%s %s failed: %v", msg.action, msg.name, msg.err)
+		} else {
+			m.containerStatus = fmt.Sprintf("%s %s: done", msg.action, msg.name)
+		}
+		if !msg.asRoot {
+			m.offerRoot(msg.err, "Container "+msg.action, func(err error) tea.Msg {
+				return containerActionMsg{action: msg.action, id: msg.id, name: msg.name, err: err, asRoot: true}
+			}, "container", msg.action, msg.id)
+		}
+		return m, m.auditCmd(msg.action+" container", msg.name, msg.err, msg.asRoot)
+
+	case ui.VisibilityMsg:
+		m.shown = msg
+
+	case systemMsg:
+		m.systemPolling = false
+		m.errs.Add("system", msg.err, msg.snap.Time)
+		// A snapshot with hung mounts still carries everything else
+		if !msg.snap.Time.IsZero() {
+			m.applySystem(msg.snap)
+		}
+		m.alerts = m.checkAlerts()
+		m.publish()
+
+	case processesMsg:
+		m.procPolling = false
+		m.errs.Add("processes", msg.err, msg.at)
+		// An abandoned scan keeps the previous list rather than blanking it
+		if msg.err == nil {
+			m.applyProcesses(msg.procs, msg.at)
+		}
+		m.alerts = m.checkAlerts()
+		m.publish()
+
+	case tickMsg:
+		m.lastTick = time.Time(msg)
+		cmds := []tea.Cmd{tickCmd(), m.observeAlerts()}
+		if err := alerting.Failures(); err != nil {
+			m.errs.Add("alerting", err, m.lastTick)
+		}
+		if !m.systemPolling {
+			m.systemPolling = true
+			cmds = append(cmds, systemCmd(m.system))
+		} else {
+			debug.Logf("sysmon: tick dropped, system collection still running")
+		}
+		if !m.procPolling && (!*flagLazyProcs || m.shown.Dashboard || m.onScreen(tabProcess)) {
+			m.procPolling = true
+			cmds = append(cmds, processesCmd(m.processes))
+		} else if m.procPolling {
+			debug.Logf("sysmon: tick dropped, process scan still running")
+		}
+
+		if m.showing(tabMemory) && !m.memoryPolling {
+			m.memoryPolling = true
+			cmds = append(cmds, memoryCmd(m.numaSampler))
+		}
+		if m.showing(tabInterrupts) && !m.irqPolling {
+			m.irqPolling = true
+			cmds = append(cmds, interruptsCmd(m.irqSampler))
+		}
+		if m.watchdog != nil {
+			m.watchdogStatus = m.watchdog.Statuses()
+		}
+
+		// Walking the whole hierarchy is only worth it while it is on screen
+		if m.showing(tabCgroups) && !m.cgroupPolling {
+			m.cgroupPolling = true
+			cmds = append(cmds, cgroupsCmd(m.cgroupSampler))
+		}
+
+		// Pool status shells out, so poll it less often and off the UI goroutine
+		if time.Since(m.arraysPolled) >= storagePollInterval {
+			m.arraysPolled = time.Now()
+			cmds = append(cmds, storageCmd(m.mounts))
+		}
+		if time.Since(m.limitsPolled) >= limitsPollInterval {
+			m.limitsPolled = time.Now()
+			cmds = append(cmds, limitsCmd())
+		}
+		if isARM && time.Since(m.socPolled) >= socPollInterval && (!*flagLazySensors || m.onScreen(tabSystem) || m.shown.Shows("sensors")) {
+			m.socPolled = time.Now()
+			cmds = append(cmds, socCmd())
+		}
+		if m.containerRT != nil && !m.containerPolling {
+			m.containerPolling = true
+			cmds = append(cmds, containersCmd(m.containerRT))
+		}
+		if m.libvirt != nil && !m.vmPolling {
+			m.vmPolling = true
+			cmds = append(cmds, vmsCmd(m.libvirt))
+		}
+		if !m.servicePolling && time.Since(m.servicePolled) >= servicePollInterval {
+			m.servicePolling = true
+			m.servicePolled = time.Now()
+			cmds = append(cmds, servicesCmd(m.systemd))
+		}
+		return m, tea.Batch(cmds...)
+
+	default:
+		m.rev--
+	}
+	return m, nil
+}
+
+// updateKey handles a key press outside of prompts and text inputs
+func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.flash = ""
+	if m.keysOpen {
+		// Any key closes the overlay, and quitting still works
+		m.keysOpen = false
+		if !key.Matches(msg, keys.Quit) {
+			return m, nil
+		}
+	}
+	if m.center.open {
+		return m.updateAlertCenterKeys(msg)
+	}
+	if m.audit.open {
+		return m.updateAuditKeys(msg)
+	}
+
+	switch {
+	case key.Matches(msg, keys.Quit):
+		m.journal.stop()
+		return m, tea.Quit
+	case key.Matches(msg, keys.Logs):
+		return m.toggleJournal()
+	case key.Matches(msg, keys.Errors):
+		m.errs.Expanded = !m.errs.Expanded
+	case key.Matches(msg, keys.Help):
+		m.keysOpen = true
+	case key.Matches(msg, keys.AlertCenter):
+		m.center.open, m.center.cursor = true, 0
+	case key.Matches(msg, keys.AuditLog):
+		m.audit.open, m.audit.cursor = true, 0
+		return m, loadAuditCmd()
+	case key.Matches(msg, keys.Screenshot):
+		return m, ui.Screenshot(m.View())
+	case key.Matches(msg, keys.CopyRow):
+		if line := m.selectedLine(); line != "" {
+			return m, ui.Copy(line, "selected row")
+		}
+	case key.Matches(msg, keys.CopyTable):
+		return m, ui.Copy(strings.TrimRight(ansi.Strip(m.renderTab()), "\n")+"\n", "visible table")
+	case key.Matches(msg, keys.LogsBack, keys.LogsForward, keys.LogsFilter) && m.journal.open:
+		return m.updateJournalKeys(msg)
+	case key.Matches(msg, keys.NextTab):
+		m.tab = m.tabs.Next(m.tab)
+		m.split.SetTab(m.tab)
+	case key.Matches(msg, keys.Tabs) && m.tabIndex(msg.String()) >= 0:
+		m.tab = m.tabIndex(msg.String())
+		m.split.SetTab(m.tab)
+	case keys.Scrolls(msg):
+		m.scrollX = keys.ScrollOffset(msg, m.scrollX, ui.Widest(m.wideTable())-m.width)
+	case key.Matches(msg, keys.HideTab):
+		if next, ok := m.tabs.Hide(m.tab); ok {
+			m.tab = next
+			m.split.SetTab(m.tab)
+		} else {
+			m.flash = "The last tab stays shown"
+		}
+	case key.Matches(msg, keys.ShowTabs):
+		m.tabs.Unhide()
+	default:
+		// The directory tree keeps h and l for moving through it
+		if m.tab != tabDirScan || !key.Matches(msg, keys.Open, keys.Parent) {
+			if tab, ok := m.split.Update(msg, keys.SplitKeyMap, m.tab); ok {
+				m.tab = tab
+				return m, nil
+			}
+		}
+		switch m.tab {
+		case tabDisk:
+			m.updateDiskKeys(msg)
+		case tabProcess:
+			m.updateProcessKeys(msg)
+		case tabDirScan:
+			return m.updateDirScanKeys(msg)
+		case tabContainers:
+			m.updateContainerKeys(msg)
+		case tabServices:
+			m.updateServiceKeys(msg)
+		case tabCgroups:
+			m.updateCgroupKeys(msg)
+		}
+	}
+	return m, nil
+}
+
+// wideTable is the current tab's table that scrolls sideways, at full
+// width, or empty when it has none
+func (m model) wideTable() string {
+	if m.tab == tabProcess && len(m.procs) > 0 && !m.procByUser && !m.procGraph {
+		return m.renderProcessTable(m.visibleProcs())
+	}
+	return ""
+}
+
+// onScreen reports whether tab is being looked at
+func (m model) onScreen(tab int) bool {
+	return m.shown.Full && m.showing(tab)
+}
+
+// showing reports whether tab is the current one or in a pane
+func (m model) showing(tab int) bool {
+	return m.tab == tab || m.split.Shows(tab)
+}
+
+// applySystem takes in a system snapshot. Mounts whose statfs hung keep
+// the usage last seen so the table does not drop to zero.
+func (m *model) applySystem(snap sysstat.Snapshot) {
+	last := make(map[string]sysstat.Disk, len(m.mounts))
+	for _, d := range m.mounts {
+		last[d.Path] = d
+	}
+	for i, d := range snap.Mounts {
+		if prev, ok := last[d.Path]; ok && d.Hung {
+			snap.Mounts[i].Total, snap.Mounts[i].Used, snap.Mounts[i].Free = prev.Total, prev.Used, prev.Free
+		}
+	}
+
+	m.mounts, m.sysInfo = snap.Mounts, snap.Info
+	m.cpuTotal, m.cores = snap.CPU, snap.Cores
+	if m.diskCursor >= len(m.mounts) {
+		m.diskCursor = max(len(m.mounts)-1, 0)
+	}
+	m.timeline.Push(timelineSample{Time: snap.Time, Load: m.sysInfo.LoadAverage, CPU: m.cpuTotal})
+}
+
+// applyProcesses takes in a process scan and updates everything derived
+// from it
+func (m *model) applyProcesses(procs []proc.Process, at time.Time) {
+	m.procs, m.procsAt = procs, at
+	m.oomVictims = likelyOOMVictims(m.procs)
+	sortProcesses(m.procs, m.procSort)
+	if visible := m.visibleProcs(); m.procCursor >= len(visible) {
+		m.procCursor = max(len(visible)-1, 0)
+	}
+	m.countStuckProcesses()
+	for i := range m.watched {
+		m.watched[i].update(m.procs, at)
+	}
+	m.leakSuspect = m.leaks.update(m.procs, at)
+	m.topHistory.Push(sampleTopProcesses(m.procs, at))
+}
+
+// procsCurrent reports whether the process list is from a recent scan
+func (m model) procsCurrent() bool {
+	return !m.procsAt.IsZero() && m.lastTick.Sub(m.procsAt) <= 2*budget.Interval(tickInterval)+collectTimeout
+}
+
+// frameCache holds the last rendering of each component of the view.
+// They are redrawn only after a message changed the model, so the frames
+// "advis all" renders for other monitors' messages cost nothing.
+type frameCache struct {
+	view   ui.Memo[uint64, string]
+	panels ui.Memo[[2]uint64, []ui.Panel] // Revision and panel width
+	row    []byte                         // Reused for each table row
+}
+
+// View renders the UI
+func (m model) View() string {
+	if m.width == 0 {
+		return "Loading..."
+	}
+	view := m.frames.view.Get(m.rev, m.renderView)
+	// Our own usage changes without any message to this monitor
+	if usage := budget.Status(); usage != "" && m.confirm == nil {
+		view += "\n" + dimStyle.Render(usage)
+	}
+	return view
+}
+
+// renderView draws everything but the usage line
+func (m model) renderView() string {
+	var content strings.Builder
+
+	// Header
+	title := titleStyle.Render(ui.Icon("monitor") + "Go Terminal System Monitor")
+	content.WriteString(title + "\n\n")
+	content.WriteString(renderAlerts(m.attention()))
+
+	// Tab navigation
+	var tabStrings []string
+	for _, i := range m.tabs.Shown() {
+		if i == m.tab {
+			tabStrings = append(tabStrings, headerStyle.Render(fmt.Sprintf("[%s] %s", m.tabKey(i), tabNames[i])))
+		} else {
+			tabStrings = append(tabStrings, fmt.Sprintf(" %s  %s ", m.tabKey(i), tabNames[i]))
+		}
+	}
+	content.WriteString(strings.Join(tabStrings, " | ") + "\n\n")
+
+	// The footer comes first so split panes know the height left to them
+	var footer strings.Builder
+	if m.confirm != nil {
+		footer.WriteString("\n" + usedBarStyle.Render(m.confirm.message+" ["+keys.Confirm.Help().Key+"/"+keys.Cancel.Help().Key+"]"))
+	} else {
+		if m.errs.Expanded {
+			footer.WriteString("\n" + headerStyle.Render(ui.Icon("errors")+"Collector Errors") + "\n")
+			footer.WriteString(m.errs.Render(m.width, 8))
+		}
+		if m.flash != "" {
+			footer.WriteString("\n" + barStyle.Render(m.flash))
+		}
+		if status := m.errs.Status(m.width, keys.Errors); status != "" {
+			footer.WriteString("\n" + status)
+		}
+		footer.WriteString("\n" + m.statusBar())
+	}
+	var journal string
+	if m.journal.open {
+		journal = "\n" + m.renderJournal()
+	}
+
+	switch {
+	case m.keysOpen:
+		content.WriteString(m.renderHelp())
+	case m.center.open:
+		content.WriteString(m.renderAlertCenter())
+	case m.audit.open:
+		content.WriteString(m.renderAuditLog())
+	case m.split.Zoomed:
+		// The tab takes the header's and the journal's lines too
+		used := lipgloss.Height(content.String()) + lipgloss.Height(journal) - 1 + lipgloss.Height(footer.String())
+		footer := strings.TrimPrefix(footer.String(), "\n")
+		return m.renderZoomed(m.height-used, m.height-lipgloss.Height(footer)) + footer
+	case m.split.Active():
+		used := lipgloss.Height(content.String()) + lipgloss.Height(journal) - 1 + lipgloss.Height(footer.String())
+		content.WriteString(m.renderPanes(max(m.height-used, 4*len(m.split.Tabs))))
+	default:
+		content.WriteString(m.renderTab())
+	}
+	content.WriteString(journal)
+	content.WriteString(footer.String())
+
+	return content.String()
+}
+
+// statusBar is the bottom line: the alert count and the footer keys
+func (m model) statusBar() string {
+	attention := m.attention()
+	bar := ui.StatusBar{Alerts: len(attention), ReadOnly: *flagReadOnly, Source: m.source, Help: m.help()}
+	for _, a := range attention {
+		bar.Critical = bar.Critical || a.Level == alertCritical
+	}
+	return bar.Render(m.width, time.Now())
+}
+
+// selectedLine returns the row under the cursor as plain text for the
+// clipboard, or "" when the tab has no selection
+func (m model) selectedLine() string {
+	switch m.tab {
+	case tabDisk:
+		if m.diskCursor < len(m.mounts) {
+			d := m.mounts[m.diskCursor]
+			return fmt.Sprintf("%s\t%s\t%s\tused %s of %s", d.Path, d.Device, d.FSType, ui.FormatBytes(d.Used), ui.FormatBytes(d.Total))
+		}
+	case tabProcess:
+		if procs := m.visibleProcs(); !m.procByUser && !m.procGraph && m.procCursor < len(procs) {
+			p := procs[m.procCursor]
+			return fmt.Sprintf("%d\t%s\t%s\t%s\tcpu %.1f%%\tmem %s\tfds %d\toom %d",
+				p.PID, p.Name, p.User, p.State, p.CPU, ui.FormatBytes(p.Memory), p.FDs, p.OOMScore)
+		}
+	case tabDirScan:
+		if cur := m.scan.current; cur != nil && m.scan.cursor < len(cur.Children) {
+			e := cur.Children[m.scan.cursor]
+			return fmt.Sprintf("%s\t%s\t%d items", e.Path, ui.FormatBytes(e.Size), e.Items)
+		}
+	case tabContainers:
+		if m.vmView && m.vmCursor < len(m.vms) {
+			vm := m.vms[m.vmCursor]
+			return fmt.Sprintf("%s\t%d vcpus\tcpu %.1f%%\tballoon %s\trss %s", vm.Name, vm.VCPUs, vm.CPU, ui.FormatBytes(vm.BalloonActual), ui.FormatBytes(vm.RSS))
+		}
+		if !m.vmView && m.containerCursor < len(m.containers) {
+			c := m.containers[m.containerCursor]
+			return fmt.Sprintf("%s\t%s\t%s\t%s\tcpu %.1f%%\tmem %s", c.ID, c.Name, c.Image, c.Status, c.CPU, ui.FormatBytes(c.Memory))
+		}
+	case tabServices:
+		if svcs := m.visibleServices(); m.serviceCursor < len(svcs) {
+			svc := svcs[m.serviceCursor]
+			return fmt.Sprintf("%s\t%s/%s\t%s\trestarts %d", svc.Name, svc.ActiveState, svc.SubState, svc.Description, svc.Restarts)
+		}
+	case tabCgroups:
+		if rows := m.visibleCgroups(); m.cgroupCursor < len(rows) {
+			n := rows[m.cgroupCursor].node
+			return fmt.Sprintf("%s\tcpu %.1f%%\tmem %s", n.Path, n.CPU, ui.FormatBytes(n.Memory))
+		}
+	}
+	return ""
+}
+
+// renderPanes draws the split panes in height lines, each rendering its
+// tab as if the terminal were the pane's size plus the rest of the screen
+func (m model) renderPanes(height int) string {
+	title := func(tab int) string { return fmt.Sprintf("[%s] %s", m.tabKey(tab), tabNames[tab]) }
+	return m.split.Render(m.width, height, title, func(tab, width, h int) string {
+		p := m
+		p.tab, p.width, p.height = tab, width, m.height-height+h
+		return p.renderTab()
+	})
+}
+
+// renderZoomed draws the current tab alone in height lines, given the
+// area it has when not zoomed
+func (m model) renderZoomed(area, height int) string {
+	return m.split.RenderZoomed(m.width, height, func(width, h int) string {
+		p := m
+		p.width, p.height = width, m.height-area+h
+		return p.renderTab()
+	})
+}
+
+// renderTab renders the content of the selected tab
+func (m model) renderTab() string {
+	switch m.tab {
+	case tabSystem:
+		return m.renderSystemInfo()
+	case tabDisk:
+		return m.renderDiskInfo()
+	case tabProcess:
+		return m.renderProcessInfo()
+	case tabDirScan:
+		return m.renderDirScan()
+	case tabContainers:
+		return m.renderContainers()
+	case tabServices:
+		return m.renderServices()
+	case tabKernel:
+		return m.renderKernel()
+	case tabCgroups:
+		return m.renderCgroups()
+	case tabMemory:
+		return m.renderMemory()
+	case tabInterrupts:
+		return m.renderInterrupts()
+	case tabWatchdog:
+		return m.renderWatchdog()
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package sysmon
+
+import (
+	"strings"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+)
+
+// barKey identifies a rendered progress bar; few distinct bars appear on
+// screen, so each is styled once and reused on later frames
+type barKey struct {
+	metric        string
+	filled, width int
+	level         int
+}
+
+// barCache holds styled progress bars. View runs on the UI goroutine only,
+// so the map needs no locking.
+var barCache = make(map[barKey]string)
+
+// createProgressBar draws percent as a bar width cells wide, colored by
+// the thresholds of metric: cpu, memory, disk or other
+func createProgressBar(metric string, percent, width int) string {
+	if percent > 100 {
+		percent = 100
+	}
+	if percent < 0 {
+		percent = 0
+	}
+
+	key := barKey{
+		metric: metric,
+		filled: int(float64(width) * float64(percent) / 100.0),
+		width:  width,
+		level:  ui.ThresholdLevel(metric, float64(percent)),
+	}
+	if bar, ok := barCache[key]; ok {
+		return bar
+	}
+
+	// Colored by the metric's thresholds, green and red by default
+	style, ok := ui.ThresholdStyle(metric, key.level)
+	if !ok {
+		style = barStyle
+	}
+
+	bar := style.Render(ui.Blocks(key.filled) + ui.Shades(width-key.filled))
+	if len(barCache) >= 4096 {
+		clear(barCache) // Only possible after many resizes
+	}
+	barCache[key] = bar
+	return bar
+}
+
+func createASCIIPieChart(usedPercent float64) string {
+	var chart strings.Builder
+
+	// Simple text-based pie representation
+	used := int(usedPercent / 10)
+	free := 10 - used
+
+	chart.WriteString("┌" + strings.Repeat("─", 12) + "┐\n")
+	chart.WriteString("│ USED: ")
+	chart.WriteString(usedBarStyle.Render(strings.Repeat("█", used)))
+	chart.WriteString(strings.Repeat(" ", free))
+	chart.WriteString(" │\n")
+	chart.WriteString("│ FREE: ")
+	chart.WriteString(barStyle.Render(strings.Repeat("█", free)))
+	chart.WriteString(strings.Repeat(" ", used))
+	chart.WriteString(" │\n")
+	chart.WriteString("└" + strings.Repeat("─", 12) + "┘\n")
+
+	return chart.String()
+}
+
+func getHealthStatus(usedPercent float64) string {
+	switch {
+	case usedPercent < 70:
+		return barStyle.Render(ui.Icon("healthy") + "Healthy")
+	case usedPercent < 85:
+		return infoStyle.Render(ui.Icon("warning") + "Warning")
+	default:
+		return usedBarStyle.Render(ui.Icon("critical") + "Critical")
+	}
+}
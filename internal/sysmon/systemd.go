@@ -0,0 +1,26 @@
+package sysmon
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+)
+
+// servicePollInterval is how often the unit list is refreshed over D-Bus
+const servicePollInterval = 5 * time.Second
+
+func servicesCmd(c *systemdClient) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		services, err := c.list()
+		debug.Timing("sysmon: systemd units", start, err)
+		return servicesMsg{services: services, err: err}
+	}
+}
+
+func serviceActionCmd(c *systemdClient, name, action string) tea.Cmd {
+	return func() tea.Msg {
+		return serviceActionMsg{action: action, name: name, err: c.unitAction(name, action)}
+	}
+}
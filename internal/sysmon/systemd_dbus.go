@@ -0,0 +1,158 @@
+//go:build linux || darwin || freebsd || openbsd || netbsd || windows
+
+package sysmon
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// systemdClient queries systemd over the system D-Bus
+type systemdClient struct {
+	mu       sync.Mutex
+	conn     *dbus.Conn
+	prev     map[string]serviceCounters
+	restarts map[string]uint32 // NRestarts when each unit was first seen
+}
+
+// serviceCounters are the cumulative counters of a unit at the last poll
+type serviceCounters struct {
+	at      time.Time
+	cpuNsec uint64
+}
+
+// unsetValue is what systemd reports for accounting that is disabled
+const unsetValue = ^uint64(0)
+
+// connect opens the system bus connection on first use
+func (c *systemdClient) connect() (dbus.BusObject, error) {
+	if c.conn == nil {
+		conn, err := dbus.ConnectSystemBus()
+		if err != nil {
+			return nil, fmt.Errorf("systemd not reachable over D-Bus: %w", err)
+		}
+		c.conn = conn
+	}
+	return c.conn.Object("org.freedesktop.systemd1", "/org/freedesktop/systemd1"), nil
+}
+
+// list returns every loaded service unit with its accounting data
+func (c *systemdClient) list() ([]Service, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	manager, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	var units []struct {
+		Name        string
+		Description string
+		LoadState   string
+		ActiveState string
+		SubState    string
+		Following   string
+		Path        dbus.ObjectPath
+		JobID       uint32
+		JobType     string
+		JobPath     dbus.ObjectPath
+	}
+	if err := manager.Call("org.freedesktop.systemd1.Manager.ListUnits", 0).Store(&units); err != nil {
+		return nil, fmt.Errorf("listing units: %w", err)
+	}
+
+	if c.restarts == nil {
+		c.restarts = make(map[string]uint32)
+	}
+	counters := make(map[string]serviceCounters)
+	var services []Service
+	for _, u := range units {
+		if !strings.HasSuffix(u.Name, ".service") || u.LoadState != "loaded" {
+			continue
+		}
+		svc := Service{
+			Name:        u.Name,
+			Description: u.Description,
+			ActiveState: u.ActiveState,
+			SubState:    u.SubState,
+		}
+
+		var props map[string]dbus.Variant
+		err := c.conn.Object("org.freedesktop.systemd1", u.Path).
+			Call("org.freedesktop.DBus.Properties.GetAll", 0, "org.freedesktop.systemd1.Service").
+			Store(&props)
+		if err == nil {
+			if v, ok := props["MemoryCurrent"].Value().(uint64); ok && v != unsetValue {
+				svc.Memory, svc.HasMemory = v, true
+			}
+			if v, ok := props["NRestarts"].Value().(uint32); ok {
+				svc.Restarts = v
+				first, seen := c.restarts[u.Name]
+				if !seen {
+					c.restarts[u.Name] = v
+				} else if v > first {
+					svc.NewRestarts = v - first
+				}
+			}
+			if v, ok := props["CPUUsageNSec"].Value().(uint64); ok && v != unsetValue {
+				svc.HasCPU = true
+				cur := serviceCounters{at: time.Now(), cpuNsec: v}
+				if prev, seen := c.prev[u.Name]; seen && v >= prev.cpuNsec {
+					if elapsed := cur.at.Sub(prev.at).Seconds(); elapsed > 0 {
+						svc.CPU = float64(v-prev.cpuNsec) / 1e9 / elapsed * 100
+					}
+				}
+				counters[u.Name] = cur
+			}
+		}
+		services = append(services, svc)
+	}
+	c.prev = counters
+
+	// Failed units first, then alphabetical
+	sort.Slice(services, func(i, j int) bool {
+		fi, fj := services[i].ActiveState == "failed", services[j].ActiveState == "failed"
+		if fi != fj {
+			return fi
+		}
+		return services[i].Name < services[j].Name
+	})
+	return services, nil
+}
+
+// unitAction starts, stops or restarts a unit. Unprivileged callers are
+// subject to polkit, which may prompt through a desktop agent or refuse.
+func (c *systemdClient) unitAction(name, action string) error {
+	c.mu.Lock()
+	manager, err := c.connect()
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	method := map[string]string{
+		"start":   "org.freedesktop.systemd1.Manager.StartUnit",
+		"stop":    "org.freedesktop.systemd1.Manager.StopUnit",
+		"restart": "org.freedesktop.systemd1.Manager.RestartUnit",
+	}[action]
+	call := manager.Call(method, dbus.FlagAllowInteractiveAuthorization, name, "replace")
+	if call.Err == nil {
+		return nil
+	}
+
+	var dbusErr dbus.Error
+	if errors.As(call.Err, &dbusErr) {
+		switch dbusErr.Name {
+		case "org.freedesktop.DBus.Error.AccessDenied",
+			"org.freedesktop.DBus.Error.InteractiveAuthorizationRequired":
+			return fmt.Errorf("not authorized by polkit (run as root or allow org.freedesktop.systemd1.manage-units)")
+		}
+	}
+	return call.Err
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin && !freebsd && !openbsd && !netbsd && !windows
+
+package sysmon
+
+import "errors"
+
+// errNoDBus is what the services panel shows where the D-Bus client does
+// not build
+var errNoDBus = errors.New("systemd not reachable: no D-Bus client on this OS")
+
+// systemdClient stands in for the D-Bus client, which does not build here
+type systemdClient struct{}
+
+func (c *systemdClient) list() ([]Service, error) { return nil, errNoDBus }
+
+func (c *systemdClient) unitAction(name, action string) error { return errNoDBus }
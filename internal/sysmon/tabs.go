@@ -0,0 +1,2091 @@
+package sysmon
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/s-archdev/Terminal_ADVIS/internal/caps"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/proc"
+)
+
+// renderSystemInfo displays system information
+func (m model) renderSystemInfo() string {
+	var content strings.Builder
+
+	content.WriteString(headerStyle.Render(ui.Icon("stats")+"System Information") + "\n\n")
+
+	// Host identity
+	h := m.host
+	content.WriteString(fmt.Sprintf("Hostname: %s\n", h.Hostname))
+	content.WriteString(fmt.Sprintf("OS: %s (%s/%s)\n", h.Distro, m.sysInfo.OS, m.sysInfo.Arch))
+	content.WriteString(fmt.Sprintf("Kernel: %s\n", h.Kernel))
+	content.WriteString(fmt.Sprintf("CPU: %s\n", h.CPUModel))
+	if h.CPUCores > 0 {
+		content.WriteString(fmt.Sprintf("Topology: %d socket(s), %d cores, %d threads\n", h.CPUSockets, h.CPUCores, m.sysInfo.CPUs))
+	} else {
+		content.WriteString(fmt.Sprintf("CPU Threads: %d\n", m.sysInfo.CPUs))
+	}
+	if len(h.Caches) > 0 {
+		content.WriteString(fmt.Sprintf("Caches: %s\n", strings.Join(h.Caches, ", ")))
+	}
+	if h.MemoryTotal > 0 {
+		content.WriteString(fmt.Sprintf("RAM: %s\n", ui.FormatBytes(h.MemoryTotal)))
+	}
+	for _, mod := range h.MemoryModules {
+		line := fmt.Sprintf("  %s: %s %s", mod.Locator, ui.FormatBytes(mod.Size), mod.Type)
+		if mod.SpeedMTs > 0 {
+			line += fmt.Sprintf(" %d MT/s", mod.SpeedMTs)
+		}
+		content.WriteString(strings.TrimRight(line+" "+mod.Manufacturer+" "+mod.PartNumber, " ") + "\n")
+	}
+	content.WriteString(fmt.Sprintf("Virtualization: %s\n", h.Virtualization))
+	content.WriteString(fmt.Sprintf("Goroutines: %d\n", m.sysInfo.Goroutines))
+	content.WriteString(fmt.Sprintf("Load Average: %.2f\n", m.sysInfo.LoadAverage))
+	content.WriteString(fmt.Sprintf("Last Update: %s\n\n", m.lastTick.Format("15:04:05")))
+
+	// Memory usage
+	content.WriteString(headerStyle.Render(ui.Icon("ram")+"Memory Usage") + "\n")
+	if m.sysInfo.MemTotal > 0 {
+		memPercent := float64(m.sysInfo.MemUsed) / float64(m.sysInfo.MemTotal) * 100
+		memBar := createProgressBar("memory", int(memPercent), 40)
+		content.WriteString(fmt.Sprintf("Used: %s / %s (%.1f%%)\n",
+			ui.FormatBytes(m.sysInfo.MemUsed),
+			ui.FormatBytes(m.sysInfo.MemTotal),
+			memPercent))
+		content.WriteString(memBar + "\n")
+	} else {
+		content.WriteString("Memory information not available\n")
+	}
+
+	// File handles
+	if m.sysInfo.FilesMax > 0 {
+		percent := float64(m.sysInfo.FilesOpen) / float64(m.sysInfo.FilesMax) * 100
+		content.WriteString(fmt.Sprintf("\nFile handles: %d / %d (%.2f%%)\n", m.sysInfo.FilesOpen, m.sysInfo.FilesMax, percent))
+	}
+
+	if m.soc.Available {
+		content.WriteString(m.renderSoC())
+	}
+
+	// Pinned mounts
+	content.WriteString("\n" + headerStyle.Render(ui.Icon("disk")+"Pinned Mounts") + "\n")
+	shown := 0
+	for _, d := range m.mounts {
+		if !m.pinned[d.Path] || d.Total == 0 {
+			continue
+		}
+		usedPercent := float64(d.Used) / float64(d.Total) * 100
+		content.WriteString(fmt.Sprintf("%s %s %5.1f%%\n",
+			ui.Fit(d.Path, 20), createProgressBar("disk", int(usedPercent), 30), usedPercent))
+		shown++
+	}
+	if shown == 0 {
+		content.WriteString("No mounts pinned (pin them from the Disk tab)\n")
+	}
+
+	// CPU usage
+	content.WriteString("\n" + headerStyle.Render(ui.Icon("cpu")+"CPU Usage") + "\n")
+	switch {
+	case caps.Get().NoCPU:
+		content.WriteString("CPU usage not available (Android keeps /proc/stat from apps)\n")
+	case len(m.cores) == 0:
+		content.WriteString("Sampling...\n")
+	case len(m.cores) > heatmapMinCores:
+		content.WriteString(fmt.Sprintf("Total: %s %5.1f%%\n", createProgressBar("cpu", int(m.cpuTotal), 30), m.cpuTotal))
+		content.WriteString(renderCoreHeatmap(m.cores, m.width))
+	default:
+		for i, usage := range m.cores {
+			content.WriteString(fmt.Sprintf("Core %d: %s %5.1f%%\n", i, createProgressBar("cpu", int(usage), 30), usage))
+		}
+	}
+
+	return content.String()
+}
+
+// renderSoC displays throttling, under-voltage and temperature of an SBC
+func (m model) renderSoC() string {
+	var content strings.Builder
+	soc := m.soc
+
+	content.WriteString("\n" + headerStyle.Render(ui.Icon("sensors")+"SoC Power & Thermal") + "\n")
+	if soc.TempC > 0 {
+		content.WriteString(fmt.Sprintf("Temperature: %.1f°C\n", soc.TempC))
+	}
+	if soc.MaxMHz > 0 {
+		content.WriteString(fmt.Sprintf("CPU clock: %d / %d MHz\n", soc.FreqMHz, soc.MaxMHz))
+	}
+	if !soc.HasFlags {
+		return content.String()
+	}
+	var now, past []string
+	for _, f := range socFlags {
+		if soc.Flags&f.now != 0 {
+			now = append(now, f.name)
+		}
+		if soc.Flags&f.occurred != 0 {
+			past = append(past, f.name)
+		}
+	}
+	if len(now) == 0 {
+		content.WriteString(barStyle.Render("No throttling or under-voltage now") + "\n")
+	} else {
+		content.WriteString(usedBarStyle.Render("Now: "+strings.Join(now, ", ")) + "\n")
+	}
+	if len(past) > 0 {
+		content.WriteString(infoStyle.Render("Since boot: "+strings.Join(past, ", ")) + "\n")
+	}
+	return content.String()
+}
+
+// heatmapMinCores is the core count above which per-core bars give way
+// to the heatmap
+const heatmapMinCores = 16
+
+// heatLevel colors the heatmap cells below a utilization
+type heatLevel struct {
+	limit float64
+	style lipgloss.Style
+}
+
+// heatLevels maps utilization to cell colors, coolest first
+var heatLevels []heatLevel
+
+// heatStyle returns the heatmap color for a utilization percentage
+func heatStyle(percent float64) lipgloss.Style {
+	for _, l := range heatLevels {
+		if percent < l.limit {
+			return l.style
+		}
+	}
+	return usedBarStyle
+}
+
+// renderCoreHeatmap draws one cell per core, as many per row as fit
+func renderCoreHeatmap(cores []float64, width int) string {
+	var content strings.Builder
+
+	perRow := max((width-10)/3, 8)
+	for start := 0; start < len(cores); start += perRow {
+		end := min(start+perRow, len(cores))
+		content.WriteString(fmt.Sprintf("%4d-%-4d ", start, end-1))
+		for _, usage := range cores[start:end] {
+			content.WriteString(heatStyle(usage).Render("██") + " ")
+		}
+		content.WriteString("\n")
+	}
+
+	var legend []string
+	lower := 0.0
+	for i, l := range heatLevels {
+		label := fmt.Sprintf("%.0f-%.0f%%", lower, l.limit)
+		if i == len(heatLevels)-1 {
+			label = fmt.Sprintf("%.0f%%+", lower)
+		}
+		legend = append(legend, l.style.Render("██")+" "+label)
+		lower = l.limit
+	}
+	content.WriteString(dimStyle.Render("Legend: ") + strings.Join(legend, "  ") + "\n")
+	return content.String()
+}
+
+// renderDiskInfo displays disk usage for every mounted filesystem
+func (m model) renderDiskInfo() string {
+	var content strings.Builder
+
+	content.WriteString(headerStyle.Render(ui.Icon("disk")+"Disk Usage") + "\n\n")
+
+	if len(m.mounts) == 0 {
+		content.WriteString("Unable to retrieve disk information\n")
+		return content.String()
+	}
+
+	// Mount table
+	content.WriteString(fmt.Sprintf("  %-3s %-22s %-18s %-8s %-10s %-10s %s\n",
+		"PIN", "MOUNT", "DEVICE", "TYPE", "SIZE", "USED", "USAGE"))
+	content.WriteString(strings.Repeat("─", 100) + "\n")
+	for i, d := range m.mounts {
+		cursor := "  "
+		if i == m.diskCursor {
+			cursor = headerStyle.Render("▶ ")
+		}
+		pin := " "
+		if m.pinned[d.Path] {
+			pin = "★"
+		}
+		usedPercent := 0.0
+		if d.Total > 0 {
+			usedPercent = float64(d.Used) / float64(d.Total) * 100
+		}
+		if d.Hung {
+			content.WriteString(fmt.Sprintf("%s%-3s %s %s %s %s\n",
+				cursor, pin, ui.Fit(d.Path, 22), ui.Fit(d.Device, 18), ui.Fit(d.FSType, 8),
+				usedBarStyle.Render(ui.Icon("waiting")+"not responding")))
+			continue
+		}
+		content.WriteString(fmt.Sprintf("%s%-3s %s %s %s %-10s %-10s %s %5.1f%%\n",
+			cursor,
+			pin,
+			ui.Fit(d.Path, 22),
+			ui.Fit(d.Device, 18),
+			ui.Fit(d.FSType, 8),
+			ui.FormatBytes(d.Total),
+			ui.FormatBytes(d.Used),
+			createProgressBar("disk", int(usedPercent), 20),
+			usedPercent))
+	}
+
+	// Details for the selected mount
+	d := m.mounts[m.diskCursor]
+	content.WriteString("\n" + headerStyle.Render(ui.Icon("search")+d.Path) + "\n")
+	if d.Hung {
+		content.WriteString(usedBarStyle.Render("statfs has not returned; the server behind this mount may be down") + "\n")
+		if d.Total > 0 {
+			content.WriteString(dimStyle.Render(fmt.Sprintf("Last seen: %s of %s used", ui.FormatBytes(d.Used), ui.FormatBytes(d.Total))) + "\n")
+		}
+	} else if d.Total > 0 {
+		usedPercent := float64(d.Used) / float64(d.Total) * 100
+		freePercent := 100 - usedPercent
+
+		content.WriteString(fmt.Sprintf("Total: %s\n", ui.FormatBytes(d.Total)))
+		content.WriteString(fmt.Sprintf("Used:  %s (%.1f%%)\n", ui.FormatBytes(d.Used), usedPercent))
+		content.WriteString(fmt.Sprintf("Free:  %s (%.1f%%)\n", ui.FormatBytes(d.Free), freePercent))
+		content.WriteString(fmt.Sprintf("Status: %s\n\n", getHealthStatus(usedPercent)))
+
+		// ASCII pie chart representation
+		content.WriteString(createASCIIPieChart(usedPercent))
+	} else {
+		content.WriteString("Unable to retrieve usage for this mount\n")
+	}
+
+	content.WriteString(m.renderStorageArrays())
+
+	return content.String()
+}
+
+// renderStorageArrays displays RAID array and storage pool health
+func (m model) renderStorageArrays() string {
+	if len(m.arrays) == 0 {
+		return ""
+	}
+
+	var content strings.Builder
+	content.WriteString("\n" + headerStyle.Render(ui.Icon("raid")+"RAID Arrays & Pools") + "\n")
+	content.WriteString(fmt.Sprintf("%-7s %-14s %-8s %-12s %-10s %s\n",
+		"KIND", "NAME", "LEVEL", "STATE", "DEVICES", "ACTIVITY"))
+	for _, a := range m.arrays {
+		state := barStyle.Render(fmt.Sprintf("%-12s", a.State))
+		if a.Degraded {
+			state = usedBarStyle.Render(fmt.Sprintf("%-12s", a.State))
+		}
+		activity := ""
+		if a.Operation != "" {
+			activity = fmt.Sprintf("%s %s %.1f%%", a.Operation, createProgressBar("other", int(a.Progress), 15), a.Progress)
+		}
+		content.WriteString(fmt.Sprintf("%-7s %s %-8s %s %-10s %s\n",
+			a.Kind, ui.Fit(a.Name, 14), a.Level, state, a.Devices, activity))
+		if a.Detail != "" {
+			content.WriteString("        " + infoStyle.Render(a.Detail) + "\n")
+		}
+	}
+	return content.String()
+}
+
+// updateDiskKeys handles the mount table navigation and pinning keys
+func (m *model) updateDiskKeys(msg tea.KeyMsg) {
+	switch {
+	case keys.Moves(msg):
+		m.diskCursor = keys.Move(msg, m.diskCursor, len(m.mounts), m.height)
+	case key.Matches(msg, keys.Pin):
+		if m.diskCursor < len(m.mounts) {
+			path := m.mounts[m.diskCursor].Path
+			m.pinned[path] = !m.pinned[path]
+		}
+	}
+}
+
+// renderDirScan displays the directory size analyzer
+func (m model) renderDirScan() string {
+	var content strings.Builder
+
+	content.WriteString(headerStyle.Render(ui.Icon("folder")+"Directory Size Analyzer") + "\n\n")
+
+	if m.scan.editing {
+		content.WriteString(m.scan.input.View() + "\n\n")
+	} else {
+		content.WriteString(fmt.Sprintf("Scan path: %s\n\n", m.scan.path))
+	}
+
+	switch {
+	case m.scan.scanning:
+		content.WriteString(fmt.Sprintf("Scanning... (%s)\n", time.Since(m.scan.started).Truncate(time.Second)))
+		return content.String()
+	case m.scan.err != nil:
+		content.WriteString(usedBarStyle.Render("Error: "+m.scan.err.Error()) + "\n\n")
+	}
+	if m.scan.current == nil {
+		content.WriteString("Press " + keys.Scan.Help().Key + " to scan the directory above\n")
+		return content.String()
+	}
+
+	cur := m.scan.current
+	content.WriteString(fmt.Sprintf("%s  %s in %s items (scanned in %s)\n",
+		headerStyle.Render(cur.Path), ui.FormatBytes(cur.Size), ui.FormatInt(int64(cur.Items)), m.scan.elapsed.Truncate(time.Millisecond)))
+	if cur.Errors > 0 {
+		content.WriteString(infoStyle.Render(fmt.Sprintf("%d directories could not be read", cur.Errors)) + "\n")
+	}
+	content.WriteString("\n")
+
+	if len(cur.Children) == 0 {
+		content.WriteString("(empty)\n")
+		return content.String()
+	}
+
+	// Only render the rows that fit on screen, keeping the cursor visible
+	start, end := ui.Viewport(m.scan.cursor, len(cur.Children), max(m.height-16, 5))
+
+	for i := start; i < end; i++ {
+		child := cur.Children[i]
+		cursor := "  "
+		if i == m.scan.cursor {
+			cursor = headerStyle.Render("▶ ")
+		}
+		percent := 0.0
+		if cur.Size > 0 {
+			percent = float64(child.Size) / float64(cur.Size) * 100
+		}
+		name := child.Name
+		if child.IsDir {
+			name += "/"
+		}
+		if child.MountPoint {
+			name += dimStyle.Render(" (another filesystem)")
+		}
+		content.WriteString(fmt.Sprintf("%s%10s %5.1f%% %s %s\n",
+			cursor, ui.FormatBytes(child.Size), percent, createProgressBar("other", int(percent), 20), name))
+	}
+	if end < len(cur.Children) {
+		content.WriteString(fmt.Sprintf("  ... %d more\n", len(cur.Children)-end))
+	}
+
+	return content.String()
+}
+
+// updateDirScanKeys handles keys on the directory size analyzer tab
+func (m model) updateDirScanKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := &m.scan
+	switch {
+	case key.Matches(msg, keys.Scan):
+		if !s.scanning {
+			s.scanning = true
+			s.started = time.Now()
+			return m, scanDirCmd(s.path)
+		}
+	case key.Matches(msg, keys.EditPath):
+		s.editing = true
+		s.input.SetValue(s.path)
+		s.input.CursorEnd()
+		return m, s.input.Focus()
+	case key.Matches(msg, keys.Sort):
+		s.sortBy = (s.sortBy + 1) % 3
+		s.sortChildren()
+	}
+
+	cur := s.current
+	if cur == nil || s.scanning {
+		return m, nil
+	}
+	switch {
+	case keys.Moves(msg):
+		s.cursor = keys.Move(msg, s.cursor, len(cur.Children), m.height)
+	case key.Matches(msg, keys.Open):
+		if s.cursor < len(cur.Children) && cur.Children[s.cursor].MountPoint {
+			m.flash = cur.Children[s.cursor].Path + " is another filesystem; scan it on its own"
+		} else if s.cursor < len(cur.Children) && cur.Children[s.cursor].IsDir {
+			s.current = cur.Children[s.cursor]
+			s.cursor = 0
+			s.sortChildren()
+		}
+	case key.Matches(msg, keys.Parent):
+		if cur.Parent != nil {
+			s.current = cur.Parent
+			s.cursor = indexOf(cur.Parent.Children, cur)
+		}
+	case key.Matches(msg, keys.Delete):
+		// Directories, mount points among them, are not deleted from here
+		if s.cursor < len(cur.Children) && !cur.Children[s.cursor].IsDir && !cur.Children[s.cursor].MountPoint {
+			target := cur.Children[s.cursor]
+			m.confirmAction(fmt.Sprintf("Delete %s (%s)?", target.Path, ui.FormatBytes(target.Size)), deleteFileCmd(target))
+		}
+	}
+	return m, nil
+}
+
+// updateScanInput routes keys to the scan path input while it has focus
+func (m model) updateScanInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := &m.scan
+	switch {
+	case key.Matches(msg, keys.Accept):
+		if path := strings.TrimSpace(s.input.Value()); path != "" {
+			if abs, err := filepath.Abs(path); err == nil {
+				path = abs
+			}
+			s.path = path
+		}
+		s.editing = false
+		s.input.Blur()
+		return m, nil
+	case key.Matches(msg, keys.Dismiss):
+		s.editing = false
+		s.input.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return m, cmd
+}
+
+// sortChildren orders the children of the browsed directory by the
+// selected sort key
+func (s *dirScanState) sortChildren() {
+	if s.current == nil {
+		return
+	}
+	children := s.current.Children
+	sort.SliceStable(children, func(i, j int) bool {
+		switch s.sortBy {
+		case 1:
+			return children[i].Name < children[j].Name
+		case 2:
+			return children[i].Items > children[j].Items
+		default:
+			return children[i].Size > children[j].Size
+		}
+	})
+}
+
+// remove detaches a deleted file from the tree and subtracts its size
+// from every ancestor
+func (s *dirScanState) remove(e *dirEntry) {
+	parent := e.Parent
+	if parent == nil {
+		return
+	}
+	if i := indexOf(parent.Children, e); i >= 0 {
+		parent.Children = append(parent.Children[:i], parent.Children[i+1:]...)
+	}
+	for p := parent; p != nil; p = p.Parent {
+		p.Size -= min(e.Size, p.Size)
+		p.Items--
+	}
+	if s.current == parent && s.cursor >= len(parent.Children) {
+		s.cursor = max(len(parent.Children)-1, 0)
+	}
+}
+
+func indexOf(entries []*dirEntry, e *dirEntry) int {
+	for i, c := range entries {
+		if c == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderContainers displays running containers and their resource usage
+func (m model) renderContainers() string {
+	var content strings.Builder
+
+	if m.vmView {
+		return m.renderVMs()
+	}
+	content.WriteString(headerStyle.Render(ui.Icon("container")+"Containers") + "\n\n")
+
+	if m.containerRT == nil {
+		content.WriteString("No Docker or Podman socket found (set DOCKER_HOST to point at one)\n")
+		return content.String()
+	}
+	content.WriteString(fmt.Sprintf("Runtime: %s (%s)\n", m.containerRT.name, m.containerRT.socket))
+	if !caps.Get().ContainerAccess {
+		content.WriteString(alertStyle.Render("Connecting to the socket requires root or membership in its group") + "\n")
+	}
+	if m.containerStatus != "" {
+		content.WriteString(infoStyle.Render(m.containerStatus) + "\n")
+	}
+	content.WriteString("\n")
+
+	if len(m.containers) == 0 {
+		content.WriteString("No running containers\n")
+		return content.String()
+	}
+
+	content.WriteString(fmt.Sprintf("  %-14s %-20s %-7s %-20s %-21s %-21s\n",
+		"ID", "NAME", "CPU%", "MEMORY", "BLOCK R/W", "NET RX/TX"))
+	content.WriteString(strings.Repeat("─", 110) + "\n")
+	// Only render the rows that fit on screen, keeping the cursor visible
+	start, end := ui.Viewport(m.containerCursor, len(m.containers), max(m.height-18, 5))
+	for i := start; i < end; i++ {
+		c := m.containers[i]
+		cursor := "  "
+		if i == m.containerCursor {
+			cursor = headerStyle.Render("▶ ")
+		}
+		cpu, mem, block, netIO := "-", "-", "-", "-"
+		if c.HasStats {
+			cpu = fmt.Sprintf("%.1f", c.CPU)
+			mem = ui.FormatBytes(c.Memory)
+			if c.MemoryLimit > 0 {
+				mem += " / " + ui.FormatBytes(c.MemoryLimit)
+			}
+			block = ui.FormatBytes(uint64(c.ReadRate)) + " / " + ui.FormatBytes(uint64(c.WriteRate))
+			netIO = ui.FormatBytes(uint64(c.RxRate)) + " / " + ui.FormatBytes(uint64(c.TxRate))
+		}
+		content.WriteString(fmt.Sprintf("%s%-14s %s %-7s %-20s %-21s %-21s\n",
+			cursor, ui.Truncate(c.ID, 12), ui.Fit(c.Name, 20), cpu, mem, block, netIO))
+	}
+	if end < len(m.containers) {
+		content.WriteString(fmt.Sprintf("  ... %d more\n", len(m.containers)-end))
+	}
+
+	c := m.containers[m.containerCursor]
+	content.WriteString(fmt.Sprintf("\nImage: %s\nStatus: %s\n", c.Image, c.Status))
+	if !*flagContainerActions {
+		content.WriteString(infoStyle.Render("Start with -container-actions to enable stop/restart") + "\n")
+	}
+
+	return content.String()
+}
+
+// renderVMs displays running libvirt guests
+func (m model) renderVMs() string {
+	var content strings.Builder
+
+	content.WriteString(headerStyle.Render(ui.Icon("vm")+"Virtual Machines") + "\n\n")
+	if m.libvirt == nil {
+		content.WriteString("libvirt not found (virsh is not installed)\n")
+		return content.String()
+	}
+	content.WriteString(fmt.Sprintf("Hypervisor: libvirt (%s)\n", m.libvirt.uri))
+	if m.containerStatus != "" {
+		content.WriteString(infoStyle.Render(m.containerStatus) + "\n")
+	}
+	content.WriteString("\n")
+
+	if len(m.vms) == 0 {
+		content.WriteString("No running virtual machines\n")
+		return content.String()
+	}
+
+	content.WriteString(fmt.Sprintf("  %-20s %-6s %-7s %-22s %-11s %-21s %-21s\n",
+		"NAME", "VCPUS", "CPU%", "BALLOON / MAX", "HOST RSS", "DISK R/W", "NET RX/TX"))
+	content.WriteString(strings.Repeat("─", 116) + "\n")
+	// Only render the rows that fit on screen, keeping the cursor visible
+	start, end := ui.Viewport(m.vmCursor, len(m.vms), max(m.height-16, 5))
+	for i := start; i < end; i++ {
+		vm := m.vms[i]
+		cursor := "  "
+		if i == m.vmCursor {
+			cursor = headerStyle.Render("▶ ")
+		}
+		balloon := "-"
+		if vm.BalloonMax > 0 {
+			balloon = ui.FormatBytes(vm.BalloonActual) + " / " + ui.FormatBytes(vm.BalloonMax)
+		}
+		content.WriteString(fmt.Sprintf("%s%s %-6d %-7.1f %-22s %-11s %-21s %-21s\n",
+			cursor, ui.Fit(vm.Name, 20), vm.VCPUs, vm.CPU, balloon, ui.FormatBytes(vm.RSS),
+			ui.FormatBytes(uint64(vm.ReadRate))+" / "+ui.FormatBytes(uint64(vm.WriteRate)),
+			ui.FormatBytes(uint64(vm.RxRate))+" / "+ui.FormatBytes(uint64(vm.TxRate))))
+	}
+	if end < len(m.vms) {
+		content.WriteString(fmt.Sprintf("  ... %d more\n", len(m.vms)-end))
+	}
+	content.WriteString("\n" + dimStyle.Render("CPU% is of one host core; a guest using all its vCPUs shows VCPUS×100") + "\n")
+
+	return content.String()
+}
+
+// updateContainerKeys handles container selection and the gated
+// stop/restart actions
+func (m *model) updateContainerKeys(msg tea.KeyMsg) {
+	if key.Matches(msg, keys.VMs) {
+		m.vmView = !m.vmView
+		return
+	}
+	if m.vmView {
+		switch {
+		case keys.Moves(msg):
+			m.vmCursor = keys.Move(msg, m.vmCursor, len(m.vms), m.height)
+		}
+		return
+	}
+	switch {
+	case keys.Moves(msg):
+		m.containerCursor = keys.Move(msg, m.containerCursor, len(m.containers), m.height)
+	case key.Matches(msg, keys.Stop, keys.Restart):
+		if !*flagContainerActions || m.containerCursor >= len(m.containers) {
+			return
+		}
+		c := m.containers[m.containerCursor]
+		action := "stop"
+		if key.Matches(msg, keys.Restart) {
+			action = "restart"
+		}
+		m.confirmAction(fmt.Sprintf("%s container %s (%s)?", strings.ToUpper(action[:1])+action[1:], c.Name, ui.Truncate(c.ID, 12)),
+			containerActionCmd(m.containerRT, c, action))
+	}
+}
+
+// renderServices displays systemd service units
+func (m model) renderServices() string {
+	var content strings.Builder
+
+	content.WriteString(headerStyle.Render(ui.Icon("services")+"systemd Services") + "\n\n")
+	if m.serviceStatus != "" {
+		content.WriteString(infoStyle.Render(m.serviceStatus) + "\n\n")
+	}
+
+	services := m.visibleServices()
+	if len(services) == 0 {
+		if !caps.Get().SystemBus {
+			content.WriteString("Services require systemd and its system D-Bus\n")
+		} else if m.serviceFailed {
+			content.WriteString("No failed services\n")
+		} else {
+			content.WriteString("No services found\n")
+		}
+		return content.String()
+	}
+
+	content.WriteString(fmt.Sprintf("  %-34s %-10s %-10s %-10s %-7s %s\n",
+		"UNIT", "ACTIVE", "SUB", "MEMORY", "CPU%", "RESTARTS"))
+	content.WriteString(strings.Repeat("─", 90) + "\n")
+
+	// Only render the rows that fit on screen, keeping the cursor visible
+	start, end := ui.Viewport(m.serviceCursor, len(services), max(m.height-16, 5))
+
+	for i := start; i < end; i++ {
+		svc := services[i]
+		cursor := "  "
+		if i == m.serviceCursor {
+			cursor = headerStyle.Render("▶ ")
+		}
+		state := fmt.Sprintf("%-10s", svc.ActiveState)
+		switch svc.ActiveState {
+		case "active":
+			state = barStyle.Render(state)
+		case "failed":
+			state = usedBarStyle.Render(state)
+		}
+		mem, cpu := "-", "-"
+		if svc.HasMemory {
+			mem = ui.FormatBytes(svc.Memory)
+		}
+		if svc.HasCPU {
+			cpu = fmt.Sprintf("%.1f", svc.CPU)
+		}
+		restarts := strconv.Itoa(int(svc.Restarts))
+		if svc.NewRestarts > 0 {
+			restarts = usedBarStyle.Render(fmt.Sprintf("%s (+%d)", restarts, svc.NewRestarts))
+		}
+		content.WriteString(fmt.Sprintf("%s%s %s %-10s %-10s %-7s %s\n",
+			cursor, ui.Fit(svc.Name, 34), state, svc.SubState, mem, cpu, restarts))
+	}
+	if end < len(services) {
+		content.WriteString(fmt.Sprintf("  ... %d more\n", len(services)-end))
+	}
+
+	svc := services[m.serviceCursor]
+	content.WriteString("\n" + svc.Description + "\n")
+
+	return content.String()
+}
+
+// visibleServices returns the services matching the current filter
+func (m model) visibleServices() []Service {
+	if !m.serviceFailed {
+		return m.services
+	}
+	var failed []Service
+	for _, svc := range m.services {
+		if svc.ActiveState == "failed" {
+			failed = append(failed, svc)
+		}
+	}
+	return failed
+}
+
+// updateServiceKeys handles service selection, filtering and the
+// start/stop/restart actions
+func (m *model) updateServiceKeys(msg tea.KeyMsg) {
+	services := m.visibleServices()
+	switch {
+	case keys.Moves(msg):
+		m.serviceCursor = keys.Move(msg, m.serviceCursor, len(services), m.height)
+	case key.Matches(msg, keys.Failed):
+		m.serviceFailed = !m.serviceFailed
+		m.serviceCursor = 0
+	case key.Matches(msg, keys.Start, keys.Stop, keys.Restart):
+		if m.serviceCursor >= len(services) {
+			return
+		}
+		name := services[m.serviceCursor].Name
+		action := "restart"
+		switch {
+		case key.Matches(msg, keys.Start):
+			action = "start"
+		case key.Matches(msg, keys.Stop):
+			action = "stop"
+		}
+		m.confirmAction(fmt.Sprintf("%s %s?", strings.ToUpper(action[:1])+action[1:], name), serviceActionCmd(m.systemd, name, action))
+	}
+}
+
+// journalPaneHeight is the number of log lines shown in the journal pane
+const journalPaneHeight = 10
+
+// renderJournal displays the journal pane
+func (m model) renderJournal() string {
+	var content strings.Builder
+
+	title := ui.Icon("journal") + "Journal"
+	if m.journal.unit != "" {
+		title += " — " + m.journal.unit
+	}
+	if m.journal.offset > 0 {
+		title += fmt.Sprintf(" (scrolled back %d)", m.journal.offset)
+	}
+	content.WriteString(headerStyle.Render(title) + "\n")
+
+	if m.journal.editing {
+		content.WriteString(m.journal.input.View() + "\n")
+	} else if m.journal.filter != "" {
+		content.WriteString(infoStyle.Render("Filter: "+m.journal.filter) + "\n")
+	}
+	if m.journal.err != nil {
+		content.WriteString(usedBarStyle.Render("journalctl: "+m.journal.err.Error()) + "\n")
+	}
+
+	entries := m.journal.visible()
+	end := max(len(entries)-m.journal.offset, 0)
+	start := max(end-journalPaneHeight, 0)
+	width := max(m.width-30, 20)
+	for _, e := range entries[start:end] {
+		line := fmt.Sprintf("%s %s %s", e.Time.Format("15:04:05"), ui.Fit(e.Source, 16), ui.Truncate(e.Message, width))
+		switch {
+		case e.Priority <= 3:
+			line = usedBarStyle.Render(line)
+		case e.Priority == 4:
+			line = infoStyle.Render(line)
+		case e.Priority == 7:
+			line = dimStyle.Render(line)
+		}
+		content.WriteString(line + "\n")
+	}
+	if len(entries) == 0 {
+		content.WriteString("Waiting for log entries...\n")
+	}
+
+	return content.String()
+}
+
+// toggleJournal opens or closes the journal pane. On the Services tab it
+// follows the selected unit instead of the whole journal.
+func (m model) toggleJournal() (tea.Model, tea.Cmd) {
+	unit := ""
+	if services := m.visibleServices(); m.tab == tabServices && m.serviceCursor < len(services) {
+		unit = services[m.serviceCursor].Name
+	}
+
+	if m.journal.open && m.journal.unit == unit {
+		m.journal.stop()
+		m.journal.open = false
+		return m, nil
+	}
+
+	m.journal.stop()
+	m.journal.open = true
+	m.journal.unit = unit
+	m.journal.entries = nil
+	m.journal.offset = 0
+	m.journal.err = nil
+
+	tail, err := startJournal(unit)
+	if err != nil {
+		m.journal.err = err
+		return m, nil
+	}
+	m.journal.tail = tail
+	return m, waitJournalCmd(tail)
+}
+
+// updateJournalKeys handles scrolling and filtering in the open journal pane
+func (m model) updateJournalKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	j := &m.journal
+	switch {
+	case key.Matches(msg, keys.LogsBack):
+		j.offset = min(j.offset+journalPaneHeight/2, max(len(j.visible())-journalPaneHeight, 0))
+	case key.Matches(msg, keys.LogsForward):
+		j.offset = max(j.offset-journalPaneHeight/2, 0)
+	case key.Matches(msg, keys.LogsFilter):
+		j.editing = true
+		j.input.SetValue(j.filter)
+		j.input.CursorEnd()
+		return m, j.input.Focus()
+	}
+	return m, nil
+}
+
+// updateJournalFilter routes keys to the filter input while it has focus
+func (m model) updateJournalFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	j := &m.journal
+	switch {
+	case key.Matches(msg, keys.Accept, keys.Dismiss):
+		if key.Matches(msg, keys.Accept) {
+			j.filter = strings.TrimSpace(j.input.Value())
+		}
+		j.offset = 0
+		j.editing = false
+		j.input.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	j.input, cmd = j.input.Update(msg)
+	return m, cmd
+}
+
+// maxJournalEntries caps how many log lines the pane keeps in memory
+const maxJournalEntries = 1000
+
+// append adds new entries, keeping the scroll position anchored when the
+// user has scrolled back
+func (j *journalPane) append(entries []journalEntry) {
+	if j.offset > 0 {
+		j.offset += len(entries)
+	}
+	j.entries = append(j.entries, entries...)
+	if extra := len(j.entries) - maxJournalEntries; extra > 0 {
+		j.entries = append([]journalEntry(nil), j.entries[extra:]...)
+	}
+}
+
+// visible returns the entries matching the filter
+func (j *journalPane) visible() []journalEntry {
+	if j.filter == "" {
+		return j.entries
+	}
+	needle := strings.ToLower(j.filter)
+	var matched []journalEntry
+	for _, e := range j.entries {
+		if strings.Contains(strings.ToLower(e.Message), needle) || strings.Contains(strings.ToLower(e.Source), needle) {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+// stop kills the running journalctl, if any
+func (j *journalPane) stop() {
+	if j.tail != nil {
+		j.tail.cancel()
+		j.tail = nil
+	}
+}
+
+// renderLimits displays kernel limits whose exhaustion breaks things in
+// confusing ways, with their current consumption
+func (m model) renderLimits() string {
+	var content strings.Builder
+
+	content.WriteString(headerStyle.Render(ui.Icon("limits")+"Kernel Limits") + "\n\n")
+	if len(m.limits) == 0 {
+		content.WriteString("Reading limits...\n\n")
+		return content.String()
+	}
+	for _, l := range m.limits {
+		percent := 0.0
+		if l.Max > 0 {
+			percent = float64(l.Used) / float64(l.Max) * 100
+		}
+		usage := fmt.Sprintf("%d / %d", l.Used, l.Max)
+		if percent >= limitAlertPercent {
+			usage = usedBarStyle.Render(fmt.Sprintf("%-24s", usage))
+		} else {
+			usage = fmt.Sprintf("%-24s", usage)
+		}
+		content.WriteString(fmt.Sprintf("%-30s %s %s %5.1f%% %s\n",
+			l.Name, usage, createProgressBar("other", int(percent), 20), percent, dimStyle.Render(l.Detail)))
+	}
+	content.WriteString("\n")
+	return content.String()
+}
+
+// entropyLowBits is the pool level below which old kernels stall readers
+// of /dev/random, and with them TLS handshakes early after boot
+const entropyLowBits = 200
+
+// entropyDaemons feed the pool from hardware or timing jitter
+var entropyDaemons = []string{"rngd", "haveged", "jitterentropy-r"}
+
+// renderEntropy displays the entropy pool and whether a feeder daemon runs
+func (m model) renderEntropy() string {
+	var content strings.Builder
+
+	content.WriteString(headerStyle.Render(ui.Icon("entropy")+"Entropy") + "\n\n")
+	info := m.sysInfo
+	if info.EntropyPool == 0 {
+		content.WriteString("No entropy information in /proc/sys/kernel/random\n\n")
+		return content.String()
+	}
+	percent := float64(info.Entropy) / float64(info.EntropyPool) * 100
+	avail := fmt.Sprintf("%d / %d bits", info.Entropy, info.EntropyPool)
+	if m.entropyMatters() && info.Entropy < entropyLowBits {
+		avail = usedBarStyle.Render(avail)
+	}
+	content.WriteString(fmt.Sprintf("Available: %s %s %5.1f%%\n", avail, createProgressBar("other", int(percent), 20), percent))
+
+	daemon := "none running"
+	if name, known := m.entropyDaemon(); !known {
+		daemon = "unknown while processes are not scanned"
+	} else if name != "" {
+		daemon = name + " running"
+	}
+	content.WriteString(fmt.Sprintf("RNG daemon: %s\n", daemon))
+	if !m.entropyMatters() {
+		content.WriteString(dimStyle.Render("Kernel 5.6+ never blocks on an initialized pool, so a low count is harmless here") + "\n")
+	}
+	content.WriteString("\n")
+	return content.String()
+}
+
+// entropyMatters reports whether the running kernel still blocks
+// /dev/random readers on a depleted pool (before 5.6)
+func (m model) entropyMatters() bool {
+	var major, minor int
+	if _, err := fmt.Sscanf(m.host.Kernel, "%d.%d", &major, &minor); err != nil {
+		return false
+	}
+	return major < 5 || major == 5 && minor < 6
+}
+
+// entropyDaemon returns the name of a running entropy feeder, if any.
+// Known is false while the process list is missing or stale, as before the
+// first scan or with -lazy-procs away from the Process tab.
+func (m model) entropyDaemon() (name string, known bool) {
+	for _, p := range m.procs {
+		for _, name := range entropyDaemons {
+			if p.Name == name {
+				return name, true
+			}
+		}
+	}
+	return "", m.procsCurrent()
+}
+
+// renderKernel displays notable kernel messages against the load timeline
+func (m model) renderKernel() string {
+	var content strings.Builder
+
+	content.WriteString(m.renderLimits())
+	content.WriteString(m.renderEntropy())
+	content.WriteString(headerStyle.Render(ui.Icon("kernel")+"Kernel Events") + "\n\n")
+
+	if m.kmsgErr != nil {
+		content.WriteString(infoStyle.Render("Kernel log unavailable: "+m.kmsgErr.Error()) + "\n")
+		content.WriteString("Reading /dev/kmsg requires root or kernel.dmesg_restrict=0\n")
+		return content.String()
+	}
+
+	// Load timeline with a marker under every second that saw an event
+	if m.timeline.Len() > 0 {
+		width := min(m.timeline.Len(), max(m.width-12, 10))
+		samples := m.timeline.Range(m.timeline.Len()-width, m.timeline.Len())
+		maxLoad := 1.0
+		for _, t := range samples {
+			maxLoad = math.Max(maxLoad, t.Load)
+		}
+
+		levels := []rune("▁▂▃▄▅▆▇█")
+		var spark, marks strings.Builder
+		for _, t := range samples {
+			level := int(t.Load / maxLoad * float64(len(levels)-1))
+			spark.WriteRune(levels[level])
+
+			mark := " "
+			for _, e := range m.kernelEvents {
+				if !e.Time.Before(t.Time.Add(-time.Second)) && e.Time.Before(t.Time) {
+					mark = "▲"
+					if e.Level == alertCritical {
+						mark = usedBarStyle.Render("▲")
+					}
+				}
+			}
+			marks.WriteString(mark)
+		}
+		first := m.timeline.At(m.timeline.Len() - width)
+		content.WriteString(fmt.Sprintf("Load (max %.2f), last %s:\n", maxLoad, m.timeline.Last().Time.Sub(first.Time).Truncate(time.Second)))
+		content.WriteString("  " + barStyle.Render(spark.String()) + "\n")
+		content.WriteString("  " + marks.String() + "\n\n")
+	}
+
+	if len(m.kernelEvents) == 0 {
+		content.WriteString("No OOM kills, I/O errors, read-only remounts or thermal events in the kernel log\n")
+		return content.String()
+	}
+
+	content.WriteString(fmt.Sprintf("%-19s %-15s %s\n", "TIME", "EVENT", "MESSAGE"))
+	content.WriteString(strings.Repeat("─", 90) + "\n")
+	rows := max(m.height-20, 5)
+	width := max(m.width-38, 20)
+	for i := len(m.kernelEvents) - 1; i >= 0 && i >= len(m.kernelEvents)-rows; i-- {
+		e := m.kernelEvents[i]
+		kind := fmt.Sprintf("%-15s", e.Kind)
+		if e.Level == alertCritical {
+			kind = usedBarStyle.Render(kind)
+		} else {
+			kind = infoStyle.Render(kind)
+		}
+		content.WriteString(fmt.Sprintf("%-19s %s %s\n", e.Time.Format("2006-01-02 15:04:05"), kind, ui.Truncate(e.Message, width)))
+		if e.Context != "" {
+			content.WriteString(dimStyle.Render("                    └ "+e.Context) + "\n")
+		}
+	}
+
+	return content.String()
+}
+
+// metricContext summarizes the current metrics for annotating kernel events
+func (m model) metricContext() string {
+	parts := []string{fmt.Sprintf("load %.2f", m.sysInfo.LoadAverage)}
+	var topCPU, topMem *proc.Process
+	for i := range m.procs {
+		p := &m.procs[i]
+		if topCPU == nil || p.CPU > topCPU.CPU {
+			topCPU = p
+		}
+		if topMem == nil || p.Memory > topMem.Memory {
+			topMem = p
+		}
+	}
+	if topCPU != nil {
+		parts = append(parts, fmt.Sprintf("top CPU %s %.1f%%", topCPU.Name, topCPU.CPU))
+		parts = append(parts, fmt.Sprintf("top mem %s %s", topMem.Name, ui.FormatBytes(topMem.Memory)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// renderMemory displays NUMA topology and per-node memory
+func (m model) renderMemory() string {
+	var content strings.Builder
+
+	content.WriteString(headerStyle.Render(ui.Icon("memory")+"NUMA Topology") + "\n\n")
+	switch len(m.numaNodes) {
+	case 0:
+		content.WriteString("No NUMA information in /sys/devices/system/node\n")
+	case 1:
+		n := m.numaNodes[0]
+		content.WriteString(fmt.Sprintf("Single node (UMA): CPUs %s, %s\n", n.CPUs, ui.FormatBytes(n.MemTotal)))
+	default:
+		content.WriteString(fmt.Sprintf("%-6s %-16s %-24s %-32s %-12s %-12s %s\n",
+			"NODE", "CPUS", "MEMORY USED / TOTAL", "USAGE", "NUMA_MISS", "MISS/s", "OTHER_NODE"))
+		content.WriteString(strings.Repeat("─", 120) + "\n")
+		for _, n := range m.numaNodes {
+			used := n.MemTotal - min(n.MemFree, n.MemTotal)
+			percent := 0.0
+			if n.MemTotal > 0 {
+				percent = float64(used) / float64(n.MemTotal) * 100
+			}
+			missRate := fmt.Sprintf("%-12s", ui.FormatFloat(n.MissRate, 0))
+			if n.MissRate > 0 {
+				missRate = infoStyle.Render(missRate)
+			}
+			content.WriteString(fmt.Sprintf("%-6d %s %-24s %s %5.1f%% %-12s %s %s\n",
+				n.ID,
+				ui.Fit(n.CPUs, 16),
+				ui.FormatBytes(used)+" / "+ui.FormatBytes(n.MemTotal),
+				createProgressBar("memory", int(percent), 24),
+				percent,
+				ui.FormatInt(int64(n.Miss)),
+				missRate,
+				ui.FormatInt(int64(n.OtherNode))))
+		}
+		content.WriteString("\n" + dimStyle.Render("numa_miss counts pages allocated on this node that were meant for another; a rising rate means processes run away from their memory") + "\n")
+	}
+
+	content.WriteString(m.renderLeakSuspects())
+	content.WriteString(m.renderHugepages())
+
+	return content.String()
+}
+
+// renderInterrupts displays per-CPU interrupt rates and the hottest sources
+func (m model) renderInterrupts() string {
+	var content strings.Builder
+	irq := m.interrupts
+
+	content.WriteString(headerStyle.Render(ui.Icon("interrupts")+"Interrupts per CPU") + "\n\n")
+	if irq.CPUs == 0 {
+		content.WriteString("No interrupt counters in /proc/interrupts\n")
+		return content.String()
+	}
+	peak := 0.0
+	for _, r := range irq.PerCPU {
+		peak = max(peak, r)
+	}
+	for cpu, r := range irq.PerCPU {
+		percent := 0.0
+		if peak > 0 {
+			percent = r / peak * 100
+		}
+		content.WriteString(fmt.Sprintf("CPU%-4d %s %10.0f/s\n", cpu, createProgressBar("other", int(percent), 30), r))
+	}
+
+	content.WriteString("\n" + headerStyle.Render(ui.Icon("hot")+"Hottest IRQ sources") + "\n")
+	content.WriteString(fmt.Sprintf("%-8s %-12s %-14s %s\n", "IRQ", "RATE/s", "BUSIEST CPU", "DEVICE"))
+	content.WriteString(strings.Repeat("─", 80) + "\n")
+	imbalanced := false
+	for i, src := range irq.Sources {
+		if i >= maxIRQSources || src.Rate == 0 {
+			break
+		}
+		cpu, share := src.busiestCPU()
+		busiest := fmt.Sprintf("%-14s", fmt.Sprintf("CPU%d %.0f%%", cpu, share))
+		// One CPU taking nearly all of a busy source is the classic
+		// unbalanced NIC queue
+		if irq.CPUs > 1 && share >= irqImbalanceShare && src.Rate >= irqImbalanceRate {
+			busiest = usedBarStyle.Render(busiest)
+			imbalanced = true
+		}
+		content.WriteString(fmt.Sprintf("%-8s %-12s %s %s\n", src.Name, ui.FormatFloat(src.Rate, 0), busiest, ui.Truncate(src.Description, 50)))
+	}
+	if imbalanced {
+		content.WriteString(dimStyle.Render("Highlighted sources are pinned to a single CPU; check irqbalance or the device's smp_affinity") + "\n")
+	}
+
+	content.WriteString("\n" + headerStyle.Render(ui.Icon("softirqs")+"Softirqs") + "\n")
+	content.WriteString(fmt.Sprintf("%-10s %-12s %s\n", "TYPE", "RATE/s", "BUSIEST CPU"))
+	for _, src := range irq.Softirqs {
+		cpu, share := src.busiestCPU()
+		content.WriteString(fmt.Sprintf("%-10s %-12s CPU%d %.0f%%\n", src.Name, ui.FormatFloat(src.Rate, 0), cpu, share))
+	}
+
+	return content.String()
+}
+
+// renderLeakSuspects lists processes whose memory grows without dropping
+func (m model) renderLeakSuspects() string {
+	var content strings.Builder
+
+	content.WriteString("\n" + headerStyle.Render(ui.Icon("leak")+"Leak Suspects") + "\n")
+	if len(m.leakSuspect) == 0 {
+		content.WriteString(fmt.Sprintf("No process has grown steadily by %.1f MiB/min or more over the last %s\n",
+			*flagLeakRate, leakMinSamples*leakSampleInterval))
+		return content.String()
+	}
+	content.WriteString(fmt.Sprintf("%-8s %-20s %-12s %-12s %-12s %s\n", "PID", "NAME", "RSS", "GROWTH", "SLOPE/min", "OBSERVED"))
+	for _, l := range m.leakSuspect {
+		content.WriteString(fmt.Sprintf("%-8d %s %-12s %-12s %s %s\n",
+			l.PID, ui.Fit(l.Name, 20), ui.FormatBytes(l.RSS), "+"+ui.FormatBytes(l.Growth),
+			usedBarStyle.Render(fmt.Sprintf("%-12s", ui.FormatBytes(uint64(l.Slope*60)))),
+			l.Window.Truncate(time.Second)))
+	}
+	return content.String()
+}
+
+// renderHugepages displays hugetlb pool usage and transparent hugepage status
+func (m model) renderHugepages() string {
+	var content strings.Builder
+	h := m.hugepages
+
+	content.WriteString("\n" + headerStyle.Render(ui.Icon("hugepages")+"Hugepages") + "\n")
+	if len(h.Pools) == 0 {
+		content.WriteString("No hugepage pools in /sys/kernel/mm/hugepages\n")
+	} else {
+		content.WriteString(fmt.Sprintf("%-10s %-8s %-8s %-8s %-8s %-12s %s\n",
+			"PAGE SIZE", "TOTAL", "FREE", "RSVD", "SURPLUS", "POOL SIZE", "IN USE"))
+		for _, p := range h.Pools {
+			used := p.Total - min(p.Free, p.Total)
+			percent := 0.0
+			if p.Total > 0 {
+				percent = float64(used) / float64(p.Total) * 100
+			}
+			content.WriteString(fmt.Sprintf("%-10s %-8d %-8d %-8d %-8d %-12s %s %5.1f%%\n",
+				ui.FormatBytes(p.PageSize), p.Total, p.Free, p.Reserved, p.Surplus,
+				ui.FormatBytes(p.Total*p.PageSize), createProgressBar("memory", int(percent), 20), percent))
+		}
+	}
+	if h.HugetlbAllocFail > 0 {
+		content.WriteString(infoStyle.Render(fmt.Sprintf("Pool growth failures: %d (htlb_buddy_alloc_fail)", h.HugetlbAllocFail)) + "\n")
+	}
+
+	content.WriteString("\n" + headerStyle.Render(ui.Icon("thp")+"Transparent Hugepages") + "\n")
+	if h.THPEnabled == "" {
+		content.WriteString("Transparent hugepages not supported by this kernel\n")
+		return content.String()
+	}
+	content.WriteString(fmt.Sprintf("Mode: %s (defrag: %s)\n", h.THPEnabled, h.THPDefrag))
+	content.WriteString(fmt.Sprintf("Anonymous THP memory: %s\n", ui.FormatBytes(h.AnonHuge)))
+	fallback := fmt.Sprintf("%d", h.THPFaultFallback)
+	if total := h.THPFaultAlloc + h.THPFaultFallback; total > 0 {
+		fallback += fmt.Sprintf(" (%.1f%% of THP faults)", float64(h.THPFaultFallback)/float64(total)*100)
+	}
+	content.WriteString(fmt.Sprintf("Fault allocations: %d, fallbacks: %s\n", h.THPFaultAlloc, fallback))
+	content.WriteString(fmt.Sprintf("Collapse allocation failures: %d\n", h.THPCollapseFail))
+
+	return content.String()
+}
+
+// cgroupRow is a visible line of the cgroup tree
+type cgroupRow struct {
+	node   *cgroupNode
+	prefix string
+}
+
+// visibleCgroups flattens the expanded part of the cgroup tree
+func (m model) visibleCgroups() []cgroupRow {
+	if m.cgroups == nil {
+		return nil
+	}
+	rows := []cgroupRow{{node: m.cgroups}}
+	var walk func(n *cgroupNode, indent string)
+	walk = func(n *cgroupNode, indent string) {
+		if !m.cgroupExpanded[n.Path] {
+			return
+		}
+		for i, c := range n.Children {
+			branch, next := "├─ ", "│  "
+			if i == len(n.Children)-1 {
+				branch, next = "└─ ", "   "
+			}
+			rows = append(rows, cgroupRow{node: c, prefix: indent + branch})
+			walk(c, indent+next)
+		}
+	}
+	walk(m.cgroups, "")
+	return rows
+}
+
+// renderCgroups displays the cgroup v2 hierarchy as a tree
+func (m model) renderCgroups() string {
+	var content strings.Builder
+
+	content.WriteString(headerStyle.Render(ui.Icon("cgroups")+"cgroup v2 Tree") + "\n\n")
+
+	rows := m.visibleCgroups()
+	if len(rows) == 0 {
+		content.WriteString("No cgroup v2 hierarchy at /sys/fs/cgroup\n")
+		return content.String()
+	}
+
+	content.WriteString(fmt.Sprintf("  %-48s %-7s %-22s %-11s %-11s %s\n",
+		"CGROUP", "CPU%", "MEMORY / MAX", "READ/s", "WRITE/s", "PROCS"))
+	content.WriteString(strings.Repeat("─", 115) + "\n")
+
+	// Only render the rows that fit on screen, keeping the cursor visible
+	start, end := ui.Viewport(m.cgroupCursor, len(rows), max(m.height-14, 5))
+
+	for i := start; i < end; i++ {
+		n := rows[i].node
+		cursor := "  "
+		if i == m.cgroupCursor {
+			cursor = headerStyle.Render("▶ ")
+		}
+		marker := "  "
+		if len(n.Children) > 0 {
+			marker = "▸ "
+			if m.cgroupExpanded[n.Path] {
+				marker = "▾ "
+			}
+		}
+		mem := ui.FormatBytes(n.Memory)
+		if n.MemoryMax > 0 {
+			mem += " / " + ui.FormatBytes(n.MemoryMax)
+		}
+		content.WriteString(fmt.Sprintf("%s%s %-7.1f %-22s %-11s %-11s %d\n",
+			cursor,
+			ui.Fit(rows[i].prefix+marker+n.Name, 48),
+			n.CPU,
+			mem,
+			ui.FormatBytes(uint64(n.ReadRate))+"/s",
+			ui.FormatBytes(uint64(n.WriteRate))+"/s",
+			n.Procs))
+	}
+	if end < len(rows) {
+		content.WriteString(fmt.Sprintf("  ... %d more\n", len(rows)-end))
+	}
+
+	return content.String()
+}
+
+// updateCgroupKeys handles navigation, folding and sorting of the cgroup tree
+func (m *model) updateCgroupKeys(msg tea.KeyMsg) {
+	rows := m.visibleCgroups()
+	switch {
+	case keys.Moves(msg):
+		m.cgroupCursor = keys.Move(msg, m.cgroupCursor, len(rows), m.height)
+	case key.Matches(msg, keys.Expand):
+		if m.cgroupCursor < len(rows) {
+			path := rows[m.cgroupCursor].node.Path
+			m.cgroupExpanded[path] = !m.cgroupExpanded[path]
+		}
+	case key.Matches(msg, keys.Sort):
+		// Cycle CPU, memory, read, write and name (sortByPID)
+		m.cgroupSort = (m.cgroupSort + 1) % len(procSortNames)
+		for m.cgroupSort == sortByFDs || m.cgroupSort == sortByOOM {
+			m.cgroupSort++
+		}
+		sortCgroups(m.cgroups, m.cgroupSort)
+	}
+}
+
+// sortCgroups orders every level of the tree by a sortBy* column, using
+// the name for sortByPID
+func sortCgroups(n *cgroupNode, by int) {
+	if n == nil {
+		return
+	}
+	key := func(c *cgroupNode) float64 {
+		switch by {
+		case sortByCPU:
+			return c.CPU
+		case sortByRead:
+			return c.ReadRate
+		case sortByWrite:
+			return c.WriteRate
+		case sortByPID:
+			return 0
+		default:
+			return float64(c.Memory)
+		}
+	}
+	sort.Slice(n.Children, func(i, j int) bool {
+		if a, b := key(n.Children[i]), key(n.Children[j]); a != b {
+			return a > b
+		}
+		return n.Children[i].Name < n.Children[j].Name
+	})
+	for _, c := range n.Children {
+		sortCgroups(c, by)
+	}
+}
+
+// renderProcessInfo displays the process table
+func (m model) renderProcessInfo() string {
+	var content strings.Builder
+
+	content.WriteString(headerStyle.Render(ui.Icon("tree")+"Process Information") + "\n\n")
+
+	if len(m.procs) == 0 {
+		if !caps.Get().Procfs {
+			content.WriteString("Process information not available (requires /proc)\n")
+		} else {
+			content.WriteString("Sampling...\n")
+		}
+		return content.String()
+	}
+
+	if m.procByUser {
+		content.WriteString(m.renderUserUsage())
+		return content.String()
+	}
+	if m.procGraph {
+		content.WriteString(m.renderTopGraph())
+		return content.String()
+	}
+
+	content.WriteString(m.renderWatched())
+
+	stuck := fmt.Sprintf("%d zombie, %d in D state", m.zombies, m.dState)
+	if m.dState >= *flagDStateThreshold {
+		stuck = usedBarStyle.Render(stuck)
+	}
+	procs := m.visibleProcs()
+	if m.procStuck {
+		content.WriteString(fmt.Sprintf("Showing %d zombie/D state processes of %d (%s), sorted by %s\n\n",
+			len(procs), len(m.procs), stuck, procSortNames[m.procSort]))
+	} else {
+		content.WriteString(fmt.Sprintf("%d processes (%s), sorted by %s\n\n", len(m.procs), stuck, procSortNames[m.procSort]))
+	}
+	switch host := caps.Get(); {
+	case host.Android && !host.Root:
+		content.WriteString(dimStyle.Render("Android lists only this app's processes; other apps' require root") + "\n\n")
+	case !host.OtherProcesses():
+		content.WriteString(dimStyle.Render("I/O and FDs of other users' processes require root or CAP_SYS_PTRACE; see advis setcap") + "\n\n")
+	}
+
+	// The command lines run past the screen, so the columns after the PID
+	// scroll sideways
+	content.WriteString(ui.ScrollX(m.renderProcessTable(procs), procFrozen, m.scrollX, m.width))
+	if m.procSort == sortByOOM {
+		content.WriteString(m.renderOOMHistory())
+	}
+
+	return content.String()
+}
+
+// procFrozen is the width of the process table's cursor and PID columns,
+// which stay in place when it scrolls sideways
+const procFrozen = 11
+
+// renderProcessTable draws the header and the visible rows of the process
+// table at full width
+func (m model) renderProcessTable(procs []proc.Process) string {
+	var content strings.Builder
+
+	// Mark the sort column in the header
+	header := []string{"PID", "NAME", "CPU%", "MEMORY", "READ/s", "WRITE/s", "FDS", "OOM"}
+	sortColumn := [...]int{sortByCPU: 2, sortByMemory: 3, sortByRead: 4, sortByWrite: 5, sortByFDs: 6, sortByOOM: 7, sortByPID: 0}
+	header[sortColumn[m.procSort]] += "▼"
+	content.WriteString(fmt.Sprintf("  %-8s %-18s %-2s %-7s %-11s %-11s %-11s %-13s %-10s %-15s %s\n",
+		header[0], header[1], "S", header[2], header[3], header[4], header[5], header[6], header[7], "MEM BAR", "COMMAND"))
+	content.WriteString(strings.Repeat("─", 118) + "\n")
+
+	var maxMem uint64
+	for _, p := range procs {
+		maxMem = max(maxMem, p.Memory)
+	}
+
+	// Only render the rows that fit on screen, keeping the cursor visible
+	rows := max(m.height-14, 5)
+	if m.procSort == sortByOOM {
+		rows = max(rows-6, 5) // Leave room for the OOM history
+	}
+	start, end := ui.Viewport(m.procCursor, len(procs), rows)
+
+	for i := start; i < end; i++ {
+		p := procs[i]
+		memPercent := 0.0
+		if maxMem > 0 {
+			memPercent = float64(p.Memory) / float64(maxMem) * 100
+		}
+		m.frames.row = m.appendProcessRow(m.frames.row[:0], p, i == m.procCursor, memPercent)
+		content.Write(m.frames.row)
+	}
+	if end < len(procs) {
+		content.WriteString(fmt.Sprintf("  ... %d more\n", len(procs)-end))
+	}
+	if len(procs) == 0 {
+		content.WriteString("  (none)\n")
+	}
+	return content.String()
+}
+
+// Fragments repeated across process rows and frames, styled once each
+var cursorMarker, alertCell, driftCell *ui.Styled
+
+// appendProcessRow appends one line of the process table to dst. It builds
+// the row in place rather than formatting each cell into its own string,
+// since this runs for every visible process on every redraw.
+func (m model) appendProcessRow(dst []byte, p proc.Process, selected bool, memPercent float64) []byte {
+	if selected {
+		dst = append(dst, cursorMarker.Render("▶ ")...)
+	} else {
+		dst = append(dst, "  "...)
+	}
+
+	start := len(dst)
+	dst = strconv.AppendInt(dst, int64(p.PID), 10)
+	dst = append(ui.Pad(dst, start, 8), ' ')
+
+	start = len(dst)
+	dst = ui.AppendTruncated(dst, p.Name, 18)
+	dst = ui.Pad(dst, start, 18)
+	if m.newProcess(p) {
+		dst = append(dst[:start], driftCell.Render(string(dst[start:]))...)
+	}
+	dst = append(dst, ' ')
+
+	if p.State == "Z" || p.State == "D" {
+		dst = append(dst, alertCell.Render(p.State+" ")...)
+	} else {
+		start = len(dst)
+		dst = ui.Pad(append(dst, p.State...), start, 2)
+	}
+	dst = append(dst, ' ')
+
+	start = len(dst)
+	dst = strconv.AppendFloat(dst, p.CPU, 'f', 1, 64)
+	dst = append(ui.Pad(dst, start, 7), ' ')
+
+	start = len(dst)
+	dst = ui.AppendBytes(dst, p.Memory)
+	dst = append(ui.Pad(dst, start, 11), ' ')
+
+	for _, rate := range [2]float64{p.ReadRate, p.WriteRate} {
+		start = len(dst)
+		if p.HasIO {
+			dst = append(ui.AppendBytes(dst, uint64(rate)), "/s"...)
+		} else {
+			dst = append(dst, '-')
+		}
+		dst = append(ui.Pad(dst, start, 11), ' ')
+	}
+
+	start = len(dst)
+	if p.FDs < 0 {
+		dst = append(dst, '-')
+	} else {
+		dst = strconv.AppendInt(dst, int64(p.FDs), 10)
+		if p.FDLimit > 0 {
+			dst = strconv.AppendUint(append(dst, '/'), p.FDLimit, 10)
+		}
+	}
+	dst = ui.Pad(dst, start, 13)
+	if p.FDs >= 0 && p.FDLimit > 0 && fdPercent(p) >= *flagFDAlert {
+		dst = append(dst[:start], alertCell.Render(string(dst[start:]))...)
+	}
+	dst = append(dst, ' ')
+
+	start = len(dst)
+	dst = strconv.AppendInt(dst, int64(p.OOMScore), 10)
+	if p.OOMAdj != 0 {
+		dst = append(dst, '(')
+		if p.OOMAdj > 0 {
+			dst = append(dst, '+')
+		}
+		dst = append(strconv.AppendInt(dst, int64(p.OOMAdj), 10), ')')
+	}
+	dst = ui.Pad(dst, start, 10)
+	if m.oomVictims[p.PID] {
+		dst = append(dst[:start], alertCell.Render(string(dst[start:]))...)
+	}
+	dst = append(dst, ' ')
+
+	dst = append(dst, createProgressBar("memory", int(memPercent), 15)...)
+	dst = append(dst, ' ')
+	if p.Cmdline != "" {
+		dst = append(dst, p.Cmdline...)
+	} else {
+		dst = append(append(append(dst, '['), p.Name...), ']') // A kernel thread
+	}
+	return append(dst, '\n')
+}
+
+// oomVictimCount is how many of the highest oom_score processes are
+// highlighted as the likely next victims
+const oomVictimCount = 3
+
+// likelyOOMVictims returns the PIDs the OOM killer would pick first
+func likelyOOMVictims(procs []proc.Process) map[int]bool {
+	sorted := append([]proc.Process(nil), procs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].OOMScore > sorted[j].OOMScore })
+	victims := make(map[int]bool)
+	for _, p := range sorted[:min(oomVictimCount, len(sorted))] {
+		if p.OOMScore > 0 {
+			victims[p.PID] = true
+		}
+	}
+	return victims
+}
+
+// oomKilledRe extracts the victim from "Out of memory: Killed process
+// 1234 (java) total-vm:..., anon-rss:123456kB, ..."
+var oomKilledRe = regexp.MustCompile(`Killed process (\d+) \(([^)]*)\).*?anon-rss:(\d+)kB`)
+
+// renderOOMHistory lists past OOM kills found in the kernel log
+func (m model) renderOOMHistory() string {
+	var content strings.Builder
+
+	content.WriteString("\n" + headerStyle.Render(ui.Icon("oom")+"Past OOM kills") + "\n")
+	shown := 0
+	for i := len(m.kernelEvents) - 1; i >= 0; i-- {
+		e := m.kernelEvents[i]
+		match := oomKilledRe.FindStringSubmatch(e.Message)
+		if e.Kind != "OOM kill" || match == nil {
+			continue
+		}
+		rss, _ := strconv.ParseUint(match[3], 10, 64)
+		content.WriteString(fmt.Sprintf("%s  %-8s %s anon-rss %s\n",
+			e.Time.Format("2006-01-02 15:04:05"), match[1], ui.Fit(match[2], 18), ui.FormatBytes(rss*1024)))
+		shown++
+	}
+	if shown == 0 {
+		content.WriteString("No OOM kills in the kernel log since boot\n")
+	}
+	return content.String()
+}
+
+// fdPercent returns how much of its file descriptor limit a process uses
+func fdPercent(p proc.Process) float64 {
+	if p.FDs < 0 || p.FDLimit == 0 {
+		return 0
+	}
+	return float64(p.FDs) / float64(p.FDLimit) * 100
+}
+
+// visibleProcs returns the processes matching the current filter
+func (m model) visibleProcs() []proc.Process {
+	if !m.procStuck {
+		return m.procs
+	}
+	var stuck []proc.Process
+	for _, p := range m.procs {
+		if p.State == "Z" || p.State == "D" {
+			stuck = append(stuck, p)
+		}
+	}
+	return stuck
+}
+
+// countStuckProcesses updates the zombie and D state counts and tracks how
+// long the D state count has been at or above the alert threshold
+func (m *model) countStuckProcesses() {
+	m.zombies, m.dState = 0, 0
+	for _, p := range m.procs {
+		switch p.State {
+		case "Z":
+			m.zombies++
+		case "D":
+			m.dState++
+		}
+	}
+	if m.dState < *flagDStateThreshold {
+		m.dStateSince = time.Time{}
+	} else if m.dStateSince.IsZero() {
+		m.dStateSince = m.lastTick
+	}
+}
+
+// updateProcessKeys handles process table navigation and sorting
+func (m *model) updateProcessKeys(msg tea.KeyMsg) {
+	switch {
+	case keys.Moves(msg):
+		m.procCursor = keys.Move(msg, m.procCursor, len(m.visibleProcs()), m.height)
+	case key.Matches(msg, keys.Stuck):
+		m.procStuck = !m.procStuck
+		m.procCursor = 0
+	case key.Matches(msg, keys.Sort):
+		m.procSort = (m.procSort + 1) % len(procSortNames)
+		sortProcesses(m.procs, m.procSort)
+	case key.Matches(msg, keys.ByUser):
+		m.procByUser = !m.procByUser
+	case key.Matches(msg, keys.Graph):
+		m.procGraph = !m.procGraph
+	}
+}
+
+// renderWatched summarizes the -watch rules in one line each
+func (m model) renderWatched() string {
+	if len(m.watched) == 0 {
+		return ""
+	}
+	var content strings.Builder
+	for _, w := range m.watched {
+		if w.Procs == 0 {
+			content.WriteString(usedBarStyle.Render(fmt.Sprintf("%s%s: not running", ui.Icon("watch"), w.Rule.Name)) + "\n")
+			continue
+		}
+		content.WriteString(fmt.Sprintf("%s%s: %d procs, %.1f%% CPU, %s, %d restarts\n", ui.Icon("watch"),
+			w.Rule.Name, w.Procs, w.CPU, ui.FormatBytes(w.Memory), w.Restarts))
+	}
+	return content.String() + "\n"
+}
+
+// Top consumers graph
+const (
+	topKept        = 10 // Processes kept per sample and metric
+	topSeries      = 5
+	topGraphHeight = 12
+)
+
+// topColors tells the graphed processes apart
+var topColors []lipgloss.Color
+
+// sampleTopProcesses keeps the heaviest CPU and memory users of one tick
+func sampleTopProcesses(procs []proc.Process, at time.Time) topSample {
+	s := topSample{Time: at, CPU: make(map[string]float64), Memory: make(map[string]float64)}
+	keep := func(dst map[string]float64, value func(proc.Process) float64) {
+		sorted := append([]proc.Process(nil), procs...)
+		sort.Slice(sorted, func(i, j int) bool { return value(sorted[i]) > value(sorted[j]) })
+		for _, p := range sorted[:min(topKept, len(sorted))] {
+			if v := value(p); v > 0 {
+				dst[fmt.Sprintf("%s (%d)", p.Name, p.PID)] = v
+			}
+		}
+	}
+	keep(s.CPU, func(p proc.Process) float64 { return p.CPU })
+	keep(s.Memory, func(p proc.Process) float64 { return float64(p.Memory) })
+	return s
+}
+
+// renderTopGraph draws a stacked graph of the top processes over the
+// history; memory when sorting by memory, CPU otherwise
+func (m model) renderTopGraph() string {
+	var content strings.Builder
+
+	byMemory := m.procSort == sortByMemory
+	values := func(s topSample) map[string]float64 { return s.CPU }
+	metric := "CPU"
+	if byMemory {
+		values = func(s topSample) map[string]float64 { return s.Memory }
+		metric = "memory"
+	}
+	format := func(v float64) string {
+		if byMemory {
+			return ui.FormatBytes(uint64(v))
+		}
+		return fmt.Sprintf("%.1f%%", v)
+	}
+
+	// Pick the series that used the most over the whole window, so a
+	// spike that has already ended stays on the graph
+	totals := make(map[string]float64)
+	peaks := make(map[string]float64)
+	for _, s := range m.topHistory.All() {
+		for name, v := range values(s) {
+			totals[name] += v
+			peaks[name] = max(peaks[name], v)
+		}
+	}
+	var series []string
+	for name := range totals {
+		series = append(series, name)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		if totals[series[i]] != totals[series[j]] {
+			return totals[series[i]] > totals[series[j]]
+		}
+		return series[i] < series[j]
+	})
+	series = series[:min(topSeries, len(series))]
+
+	// The window is the time actually covered, which ticks delayed under
+	// load stretch beyond samples times the interval
+	var window time.Duration
+	if m.topHistory.Len() > 0 {
+		window = m.topHistory.Last().Time.Sub(m.topHistory.At(0).Time).Truncate(time.Second)
+	}
+	content.WriteString(fmt.Sprintf("Top %d processes by %s over the last %s (sort by MEM to graph memory)\n\n",
+		len(series), metric, window))
+	if len(series) == 0 {
+		content.WriteString("Collecting samples...\n")
+		return content.String()
+	}
+
+	// One column per sample, newest on the right, averaged into buckets
+	// when the history is wider than the screen
+	width := max(min(m.width-14, m.topHistory.Len()), 1)
+	columns := make([][]float64, width)
+	scale := 0.0
+	for c := range columns {
+		start := c * m.topHistory.Len() / width
+		end := max((c+1)*m.topHistory.Len()/width, start+1)
+		columns[c] = make([]float64, len(series))
+		total := 0.0
+		for _, s := range m.topHistory.Range(start, end) {
+			for i, name := range series {
+				columns[c][i] += values(s)[name] / float64(end-start)
+			}
+		}
+		for _, v := range columns[c] {
+			total += v
+		}
+		scale = max(scale, total)
+	}
+	if scale == 0 {
+		scale = 1
+	}
+
+	for row := topGraphHeight; row > 0; row-- {
+		level := scale * (float64(row) - 0.5) / topGraphHeight
+		label := ""
+		if row == topGraphHeight {
+			label = format(scale)
+		}
+		content.WriteString(fmt.Sprintf("%10s │", label))
+		for _, col := range columns {
+			cell := " "
+			cumulative := 0.0
+			for i, v := range col {
+				cumulative += v
+				if level < cumulative {
+					cell = lipgloss.NewStyle().Foreground(topColors[i]).Render("█")
+					break
+				}
+			}
+			content.WriteString(cell)
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString(fmt.Sprintf("%10s └%s\n\n", "0", strings.Repeat("─", width)))
+
+	for i, name := range series {
+		content.WriteString(fmt.Sprintf("%s %s peak %-10s now %s\n",
+			lipgloss.NewStyle().Foreground(topColors[i]).Render("██"), ui.Fit(name, 30),
+			format(peaks[name]), format(values(m.topHistory.Last())[name])))
+	}
+	return content.String()
+}
+
+// renderUserUsage displays process resource usage summed per user
+func (m model) renderUserUsage() string {
+	var content strings.Builder
+
+	users := aggregateUsers(m.procs, m.procSort)
+	content.WriteString(fmt.Sprintf("%d users, sorted by %s\n\n", len(users), procSortNames[m.procSort]))
+	content.WriteString(fmt.Sprintf("%-16s %-7s %-7s %-11s %-11s %-11s %-7s %s\n",
+		"USER", "PROCS", "CPU%", "MEMORY", "READ/s", "WRITE/s", "FDS", "CPU BAR"))
+	content.WriteString(strings.Repeat("─", 95) + "\n")
+
+	var totalCPU float64
+	for _, u := range users {
+		totalCPU += u.CPU
+	}
+	for _, u := range users {
+		share := 0.0
+		if totalCPU > 0 {
+			share = u.CPU / totalCPU * 100
+		}
+		content.WriteString(fmt.Sprintf("%s %-7d %-7.1f %-11s %-11s %-11s %-7d %s\n",
+			ui.Fit(u.User, 16),
+			u.Procs,
+			u.CPU,
+			ui.FormatBytes(u.Memory),
+			ui.FormatBytes(uint64(u.ReadRate))+"/s",
+			ui.FormatBytes(uint64(u.WriteRate))+"/s",
+			u.FDs,
+			createProgressBar("other", int(share), 15)))
+	}
+
+	return content.String()
+}
+
+// aggregateUsers sums process usage per owner, ordered by a sortBy*
+// column (process count for sortByPID, memory for sortByOOM)
+func aggregateUsers(procs []proc.Process, by int) []UserUsage {
+	byName := make(map[string]*UserUsage)
+	for _, p := range procs {
+		u, ok := byName[p.User]
+		if !ok {
+			u = &UserUsage{User: p.User}
+			byName[p.User] = u
+		}
+		u.Procs++
+		u.CPU += p.CPU
+		u.Memory += p.Memory
+		u.ReadRate += p.ReadRate
+		u.WriteRate += p.WriteRate
+		if p.FDs > 0 {
+			u.FDs += p.FDs
+		}
+	}
+
+	users := make([]UserUsage, 0, len(byName))
+	for _, u := range byName {
+		users = append(users, *u)
+	}
+	key := func(u UserUsage) float64 {
+		switch by {
+		case sortByMemory, sortByOOM:
+			return float64(u.Memory)
+		case sortByRead:
+			return u.ReadRate
+		case sortByWrite:
+			return u.WriteRate
+		case sortByFDs:
+			return float64(u.FDs)
+		case sortByPID:
+			return float64(u.Procs)
+		default:
+			return u.CPU
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if a, b := key(users[i]), key(users[j]); a != b {
+			return a > b
+		}
+		return users[i].User < users[j].User
+	})
+	return users
+}
+
+// sortProcesses orders processes by the given sortBy* column, largest
+// first, breaking ties by PID
+func sortProcesses(procs []proc.Process, by int) {
+	key := func(p proc.Process) float64 {
+		switch by {
+		case sortByMemory:
+			return float64(p.Memory)
+		case sortByRead:
+			return p.ReadRate
+		case sortByWrite:
+			return p.WriteRate
+		case sortByFDs:
+			return float64(p.FDs)
+		case sortByOOM:
+			return float64(p.OOMScore)
+		case sortByPID:
+			return 0
+		default:
+			return p.CPU
+		}
+	}
+	sort.Slice(procs, func(i, j int) bool {
+		if a, b := key(procs[i]), key(procs[j]); a != b {
+			return a > b
+		}
+		return procs[i].PID < procs[j].PID
+	})
+}
+
+// checkAlerts derives the active alerts from the latest samples
+func (m model) checkAlerts() []Alert {
+	var alerts []Alert
+	if m.sysInfo.FilesMax > 0 {
+		if percent := float64(m.sysInfo.FilesOpen) / float64(m.sysInfo.FilesMax) * 100; percent >= *flagFDAlert {
+			alerts = append(alerts, Alert{
+				Level:   alertCritical,
+				Source:  "fds",
+				Subject: "system",
+				Message: fmt.Sprintf("system file handles at %.0f%% (%d of %d)", percent, m.sysInfo.FilesOpen, m.sysInfo.FilesMax),
+			})
+		}
+	}
+	for _, p := range m.procs {
+		if percent := fdPercent(p); percent >= *flagFDAlert {
+			level := alertWarning
+			if percent >= 95 {
+				level = alertCritical
+			}
+			alerts = append(alerts, Alert{
+				Level:   level,
+				Source:  "fds",
+				Subject: strconv.Itoa(p.PID),
+				Message: fmt.Sprintf("%s (%d) has %d of %d file descriptors open", p.Name, p.PID, p.FDs, p.FDLimit),
+			})
+		}
+	}
+	if !m.dStateSince.IsZero() {
+		if stuck := m.lastTick.Sub(m.dStateSince); stuck >= *flagDStateDuration {
+			alerts = append(alerts, Alert{
+				Level:   alertCritical,
+				Source:  "processes",
+				Subject: "D state",
+				Message: fmt.Sprintf("%d processes in uninterruptible sleep for %s (failing storage or NFS hang?)",
+					m.dState, stuck.Truncate(time.Second)),
+			})
+		}
+	}
+	for _, d := range m.mounts {
+		if d.Hung {
+			alerts = append(alerts, Alert{
+				Level:   alertCritical,
+				Source:  "storage",
+				Subject: d.Path,
+				Message: fmt.Sprintf("%s (%s) is not responding (hung NFS or FUSE mount?)", d.Path, d.FSType),
+			})
+		}
+	}
+	for _, e := range m.kernelEvents {
+		if time.Since(e.Time) < kernelAlertDuration {
+			alerts = append(alerts, Alert{
+				Level:   e.Level,
+				Source:  "kernel",
+				Subject: e.Time.Format(time.RFC3339Nano) + " " + e.Kind + " " + e.Message,
+				Message: fmt.Sprintf("%s at %s: %s", e.Kind, e.Time.Format("15:04:05"), ui.Truncate(e.Message, 80)),
+			})
+		}
+	}
+	for _, svc := range m.services {
+		if svc.ActiveState == "failed" {
+			alerts = append(alerts, Alert{
+				Level:   alertWarning,
+				Source:  "systemd",
+				Subject: svc.Name,
+				Message: svc.Name + " has failed",
+			})
+		}
+	}
+	for _, w := range m.watched {
+		alerts = append(alerts, w.alerts(m.lastTick)...)
+	}
+	alerts = append(alerts, watchdogAlerts(m.watchdogStatus)...)
+	for _, l := range m.leakSuspect {
+		alerts = append(alerts, Alert{
+			Level:   alertWarning,
+			Source:  "memory",
+			Subject: strconv.Itoa(l.PID),
+			Message: fmt.Sprintf("%s (%d) RSS grew %s in %s, %s/min (possible leak)",
+				l.Name, l.PID, ui.FormatBytes(l.Growth), l.Window.Truncate(time.Second), ui.FormatBytes(uint64(l.Slope*60))),
+		})
+	}
+	alerts = append(alerts, m.soc.alerts()...)
+	if m.sysInfo.EntropyPool > 0 && m.sysInfo.Entropy < entropyLowBits && m.entropyMatters() {
+		// The Kernel tab shows the same; the daemon is left out while the
+		// process list is stale, as with -lazy-procs
+		daemon := ""
+		if name, known := m.entropyDaemon(); known && name == "" {
+			daemon = ", no RNG daemon such as rngd or haveged running"
+		} else if known {
+			daemon = ", " + name + " running"
+		}
+		alerts = append(alerts, Alert{
+			Level:   alertWarning,
+			Source:  "entropy",
+			Subject: "pool",
+			Message: fmt.Sprintf("entropy pool low: %d bits%s", m.sysInfo.Entropy, daemon),
+		})
+	}
+	for _, l := range m.limits {
+		if l.Max == 0 {
+			continue
+		}
+		if percent := float64(l.Used) / float64(l.Max) * 100; percent >= limitAlertPercent {
+			alerts = append(alerts, Alert{
+				Level:   alertCritical,
+				Source:  "limits",
+				Subject: l.Name,
+				Message: fmt.Sprintf("%s at %.0f%% (%d of %d) %s", l.Name, percent, l.Used, l.Max, l.Detail),
+			})
+		}
+	}
+	for _, a := range m.arrays {
+		if a.Degraded {
+			alerts = append(alerts, Alert{
+				Level:   alertCritical,
+				Source:  a.Kind,
+				Subject: a.Name,
+				Message: fmt.Sprintf("%s is %s %s", a.Name, a.State, a.Devices),
+			})
+		}
+	}
+	return append(alerts, m.driftAlerts()...)
+}
+
+// renderAlerts draws the alert banner, most severe first
+func renderAlerts(alerts []Alert) string {
+	if len(alerts) == 0 {
+		return ""
+	}
+	sorted := append([]Alert(nil), alerts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Level > sorted[j].Level
+	})
+
+	const maxShown = 3
+	var content strings.Builder
+	for i, a := range sorted {
+		if i == maxShown {
+			content.WriteString(infoStyle.Render(fmt.Sprintf("+%d more alerts", len(sorted)-maxShown)) + "\n")
+			break
+		}
+		icon := ui.Icon("warning")
+		style := infoStyle
+		if a.Level == alertCritical {
+			icon = ui.Icon("critical")
+			style = alertStyle
+		}
+		content.WriteString(style.Render(fmt.Sprintf("%s%s: %s", icon, a.Source, a.Message)) + "\n")
+	}
+	return content.String() + "\n"
+}
@@ -0,0 +1,40 @@
+package sysmon
+
+import (
+	"testing"
+
+	"github.com/s-archdev/Terminal_ADVIS/pkg/proc"
+)
+
+// benchProcesses are rows of the kinds the process table shows: a busy
+// daemon, a kernel thread and a zombie near its descriptor limit
+var benchProcesses = []proc.Process{
+	{PID: 1234, Name: "postgres", Cmdline: "postgres: checkpointer", State: "S", Memory: 512 << 20,
+		CPU: 12.5, ReadRate: 4 << 20, WriteRate: 1 << 20, HasIO: true, FDs: 120, FDLimit: 1024, OOMScore: 667},
+	{PID: 2, Name: "kthreadd", State: "S", FDs: -1},
+	{PID: 98765, Name: "a-process-with-a-long-name", Cmdline: "/usr/bin/a-process-with-a-long-name --flag", State: "Z",
+		Memory: 3 << 30, CPU: 99.9, FDs: 1000, FDLimit: 1024, OOMScore: 900, OOMAdj: 500},
+}
+
+func TestAppendProcessRow(t *testing.T) {
+	m := model{oomVictims: map[int]bool{98765: true}}
+	for _, p := range benchProcesses {
+		row := string(m.appendProcessRow(nil, p, false, 25))
+		if row == "" || row[len(row)-1] != '\n' {
+			t.Errorf("row of %s = %q, want one line", p.Name, row)
+		}
+	}
+}
+
+// BenchmarkProcessRow renders a frame's worth of the rows into the buffer
+// the table reuses; it should allocate little beyond the styled cells
+func BenchmarkProcessRow(b *testing.B) {
+	b.ReportAllocs()
+	m := model{oomVictims: map[int]bool{98765: true}}
+	var row []byte
+	for range b.N {
+		for i, p := range benchProcesses {
+			row = m.appendProcessRow(row[:0], p, i == 0, 25)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package sysmon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/pkg/proc"
+)
+
+// watchRestartAlert is how long a restart of a watched process stays alerted
+const watchRestartAlert = 10 * time.Minute
+
+func newWatchStates(rules []watchRule) []watchState {
+	states := make([]watchState, len(rules))
+	for i, r := range rules {
+		states[i] = watchState{Rule: r}
+	}
+	return states
+}
+
+// update matches the rule against the process list. Restarts are counted
+// on the main processes only, those whose parent is not a match as well,
+// so worker churn under a master process does not count.
+func (w *watchState) update(procs []proc.Process, now time.Time) {
+	matched := make(map[int]bool)
+	w.Procs, w.CPU, w.Memory = 0, 0, 0
+	for _, p := range procs {
+		if p.Name == w.Rule.Name {
+			matched[p.PID] = true
+			w.Procs++
+			w.CPU += p.CPU
+			w.Memory += p.Memory
+		}
+	}
+	roots := make(map[int]bool)
+	for _, p := range procs {
+		if matched[p.PID] && !matched[p.PPID] {
+			roots[p.PID] = true
+		}
+	}
+
+	replaced := false
+	for pid := range roots {
+		if !w.roots[pid] {
+			replaced = true
+		}
+	}
+	// A new main process counts as a restart unless this is the first
+	// time the rule matched
+	if replaced && w.Seen {
+		w.Restarts++
+		w.LastRestart = now
+	}
+	if len(roots) > 0 {
+		w.Seen = true
+	}
+	w.roots = roots
+}
+
+// alerts reports the rule's violations
+func (w watchState) alerts(now time.Time) []Alert {
+	var alerts []Alert
+	add := func(level int, what, format string, args ...any) {
+		alerts = append(alerts, Alert{Level: level, Source: "watch", Subject: w.Rule.Name + " " + what, Message: fmt.Sprintf(format, args...)})
+	}
+	if w.Procs == 0 {
+		if w.Seen {
+			add(alertCritical, "running", "watched process %s has exited", w.Rule.Name)
+		} else {
+			add(alertWarning, "running", "watched process %s is not running", w.Rule.Name)
+		}
+		return alerts
+	}
+	if !w.LastRestart.IsZero() && now.Sub(w.LastRestart) < watchRestartAlert {
+		add(alertWarning, "restarted", "%s restarted at %s (%d restarts)", w.Rule.Name, w.LastRestart.Format("15:04:05"), w.Restarts)
+	}
+	if w.Rule.MaxCPU > 0 && w.CPU > w.Rule.MaxCPU {
+		add(alertWarning, "cpu", "%s uses %.1f%% CPU (limit %.0f%%)", w.Rule.Name, w.CPU, w.Rule.MaxCPU)
+	}
+	if w.Rule.MaxMemory > 0 && w.Memory > w.Rule.MaxMemory {
+		add(alertWarning, "memory", "%s uses %s memory (limit %s)", w.Rule.Name, ui.FormatBytes(w.Memory), ui.FormatBytes(w.Rule.MaxMemory))
+	}
+	return alerts
+}
+
+// parseSize parses a byte count with an optional binary suffix such as
+// "512M" or "2GiB"
+func parseSize(s string) (uint64, error) {
+	s = strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B"), "I")
+	shift := 0
+	if s != "" {
+		if i := strings.IndexByte("KMGTPE", s[len(s)-1]); i >= 0 {
+			shift = 10 * (i + 1)
+			s = s[:len(s)-1]
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return uint64(n * float64(uint64(1)<<shift)), nil
+}
@@ -0,0 +1,229 @@
+package sysmon
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/alerting"
+	"github.com/s-archdev/Terminal_ADVIS/internal/config"
+	"github.com/s-archdev/Terminal_ADVIS/internal/ui"
+	"github.com/s-archdev/Terminal_ADVIS/internal/watchdog"
+)
+
+// watchdogServices are the services of the config file's "watchdog"
+// section, which the live monitor supervises
+var watchdogServices []watchdog.Service
+
+// SetWatchdog sets the services the monitor supervises
+func SetWatchdog(services []watchdog.Service) error {
+	if err := watchdog.Check(services); err != nil {
+		return err
+	}
+	watchdogServices = services
+	return nil
+}
+
+// startWatchdog checks the configured services, nil for none. It only
+// recovers them with -watchdog-recover, as "advis watchdog" running as a
+// service would recover each failure a second time.
+func startWatchdog() *watchdog.Supervisor {
+	if len(watchdogServices) == 0 {
+		return nil
+	}
+	noRecover := ""
+	switch {
+	case *flagReadOnly:
+		noRecover = "read-only"
+	case !*flagWatchdogRecover:
+		noRecover = "-watchdog-recover is off"
+	}
+	s, err := watchdog.Start(watchdogServices, noRecover)
+	if err != nil {
+		return nil // SetWatchdog checked them
+	}
+	return s
+}
+
+// watchdogAlerts are the alerts of the services that are down. The
+// messages leave out the error of the last check, which may change from
+// one check to the next while the service stays down.
+func watchdogAlerts(statuses []watchdog.Status) []Alert {
+	var alerts []Alert
+	for _, s := range statuses {
+		if !s.Down {
+			continue
+		}
+		why := "health check failing"
+		switch {
+		case s.Process != "" && s.Running == 0:
+			why = "process " + s.Process + " not running"
+		case s.HTTPStatus != 0:
+			why = fmt.Sprintf("health check answered %d", s.HTTPStatus)
+		case s.URL != "":
+			why = "health check unanswered"
+		}
+		alerts = append(alerts, Alert{Level: alertCritical, Source: "watchdog", Subject: s.Name, Message: s.Name + " is down: " + why})
+		if s.RecoverErr != nil && !s.Recovering {
+			alerts = append(alerts, Alert{Level: alertWarning, Source: "watchdog", Subject: s.Name + " recovery", Message: "recovery of " + s.Name + " failed"})
+		}
+	}
+	return alerts
+}
+
+// renderWatchdog lists the supervised services, with the last check and
+// recovery of each
+func (m model) renderWatchdog() string {
+	var content strings.Builder
+	content.WriteString(headerStyle.Render(ui.Icon("watchdog")+"Watchdog") + "\n\n")
+	switch {
+	case m.source == "demo":
+		content.WriteString("The demo supervises no services\n")
+		return content.String()
+	case m.watchdog == nil:
+		content.WriteString(`No services in the config file's "watchdog" section` + "\n")
+		return content.String()
+	}
+	switch {
+	case *flagReadOnly:
+		content.WriteString(infoStyle.Render("Read-only: services are checked but not recovered") + "\n\n")
+	case !*flagWatchdogRecover:
+		content.WriteString(dimStyle.Render("Services are checked but not recovered; -watchdog-recover runs their recovery commands") + "\n\n")
+	}
+
+	content.WriteString(fmt.Sprintf("  %-20s %-10s %-8s %-10s %s\n", "SERVICE", "STATE", "FAILED", "CHECKED", "RECOVERIES"))
+	content.WriteString(strings.Repeat("─", 70) + "\n")
+	for _, s := range m.watchdogStatus {
+		state := fmt.Sprintf("%-10s", "up")
+		switch {
+		case s.Checked.IsZero():
+			state = fmt.Sprintf("%-10s", "checking")
+		case s.Down:
+			state = usedBarStyle.Render(fmt.Sprintf("%-10s", "down"))
+		case s.Failures > 0:
+			state = infoStyle.Render(fmt.Sprintf("%-10s", "failing"))
+		default:
+			state = barStyle.Render(state)
+		}
+		checked := "-"
+		if !s.Checked.IsZero() {
+			checked = s.Checked.Format("15:04:05")
+		}
+		content.WriteString(fmt.Sprintf("  %s %s %-8s %-10s %d\n",
+			ui.Fit(s.Name, 20), state, fmt.Sprintf("%d/%d", s.Failures, s.Service.Failures), checked, s.Recoveries))
+
+		var detail []string
+		if s.Process != "" {
+			detail = append(detail, fmt.Sprintf("process %s: %d running", s.Process, s.Running))
+		}
+		if s.URL != "" && s.HTTPStatus != 0 {
+			detail = append(detail, fmt.Sprintf("%s: %d in %v", s.URL, s.HTTPStatus, s.Latency.Round(time.Millisecond)))
+		} else if s.URL != "" {
+			detail = append(detail, s.URL)
+		}
+		content.WriteString(dimStyle.Render(ui.Truncate("    "+strings.Join(detail, ", "), m.width)) + "\n")
+		if s.Err != nil {
+			content.WriteString(infoStyle.Render(ui.Truncate("    "+s.Err.Error(), m.width)) + "\n")
+		}
+		if line := recoveryLine(s); line != "" {
+			content.WriteString(ui.Truncate("    "+line, m.width) + "\n")
+		}
+	}
+	return content.String()
+}
+
+// recoveryLine describes the last recovery of a service, empty for none
+func recoveryLine(s watchdog.Status) string {
+	switch {
+	case len(s.Recover) == 0:
+		return ""
+	case s.Recovering:
+		return "recovering since " + s.Recovered.Format("15:04:05")
+	case s.RecoverSkip != "":
+		return "not recovered: " + s.RecoverSkip
+	case s.Recovered.IsZero():
+		return ""
+	}
+	line := "recovered at " + s.Recovered.Format("15:04:05")
+	if s.RecoverErr != nil {
+		line = "recovery at " + s.Recovered.Format("15:04:05") + " failed: " + s.RecoverErr.Error()
+	}
+	if s.AuditErr != nil {
+		line += " (not in the audit log: " + s.AuditErr.Error() + ")"
+	}
+	if out := strings.TrimSpace(s.RecoverOut); out != "" {
+		line += " | " + out[strings.LastIndex(out, "\n")+1:]
+	}
+	return line
+}
+
+// RunWatchdog implements "advis watchdog": the services of the config
+// file are supervised without a terminal, their alerts going to the
+// alerting channels and the alert history until interrupted
+func RunWatchdog(args []string) error {
+	fs := flag.NewFlagSet("advis watchdog", flag.ContinueOnError)
+	readOnly := fs.Bool("read-only", false, "check the services and raise their alerts, but run no recovery command")
+	quiet := fs.Bool("quiet", false, "print nothing as alerts fire and clear")
+	config.Flags.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	if err := config.SetFlagsFromEnv(fs, config.Flags); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := config.Read()
+	if err != nil {
+		return err
+	}
+	if len(cfg.Watchdog) == 0 {
+		return fmt.Errorf(`%s: no services in the "watchdog" section`, config.Path())
+	}
+	if err := alerting.Configure(cfg.Alerting); err != nil {
+		return fmt.Errorf("%s: %w", config.Path(), err)
+	}
+	defer alerting.Close(alertingGrace)
+	noRecover := ""
+	if *readOnly {
+		noRecover = "read-only"
+	}
+	s, err := watchdog.Start(cfg.Watchdog, noRecover)
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.Path(), err)
+	}
+	defer s.Stop()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	center := newAlertCenter()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			records := center.observe(now, watchdogAlerts(s.Statuses()))
+			deliverAlerts(records)
+			if cmd := persistAlertsCmd(records); cmd != nil {
+				if msg := cmd().(alertsPersistedMsg); msg.err != nil {
+					fmt.Fprintf(os.Stderr, "alert history: %v\n", msg.err)
+				}
+			}
+			if err := alerting.Failures(); err != nil {
+				fmt.Fprintf(os.Stderr, "alerting: %v\n", err)
+			}
+			for _, r := range records {
+				if !*quiet {
+					fmt.Printf("%s %s %s: %s\n", r.Time.Format(time.DateTime), r.Event, r.Level, r.Message)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,25 @@
+// Package tail keeps the end of what a command writes, for the hooks and
+// recovery commands whose output is shown once they finish. A command
+// printing without end then holds no more than Size bytes.
+package tail
+
+// Size is how much of the output is kept
+const Size = 4 << 10
+
+// Buffer keeps the last Size bytes written to it. The zero value is ready
+// to use.
+type Buffer struct {
+	buf []byte
+}
+
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - Size; over > 0 {
+		b.buf = b.buf[over:]
+	}
+	return len(p), nil
+}
+
+func (b *Buffer) String() string {
+	return string(b.buf)
+}
@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// The Append functions build table rows into a reused byte slice instead
+// of formatting every cell into its own string. Each frame with thousands
+// of rows then allocates little more than the finished row.
+
+// AppendBytes appends the FormatBytes rendering of bytes to dst
+func AppendBytes(dst []byte, bytes uint64) []byte {
+	const unit = 1024
+	if bytes < unit {
+		return append(strconv.AppendUint(dst, bytes, 10), " B"...)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	start := len(dst)
+	dst = strconv.AppendFloat(dst, float64(bytes)/float64(div), 'f', 1, 64)
+	if numbers.decimal != "" && numbers.decimal != "." {
+		point := start + slices.Index(dst[start:], '.')
+		dst = append(dst[:point], append([]byte(numbers.decimal), dst[point+1:]...)...)
+	}
+	return append(dst, ' ', "KMGTPE"[exp], 'B')
+}
+
+// AppendTruncated appends s shortened to at most n columns, marking the
+// cut with an ellipsis like Truncate
+func AppendTruncated(dst []byte, s string, n int) []byte {
+	switch {
+	case Width(s) <= n:
+		return append(dst, s...)
+	case n <= 0:
+		return dst
+	case n == 1:
+		return append(dst, ansi.Truncate(s, 1, "")...)
+	}
+	return append(dst, ansi.Truncate(s, n, "…")...)
+}
+
+// Pad appends spaces until what was written to dst since start spans width
+// columns, the append form of %-*s
+func Pad(dst []byte, start, width int) []byte {
+	for n := widthOf(dst[start:]); n < width; n++ {
+		dst = append(dst, ' ')
+	}
+	return dst
+}
+
+// widthOf is Width for a row being built, sparing the copy to a string
+// for the plain ASCII most cells hold
+func widthOf(b []byte) int {
+	for _, c := range b {
+		if c < ' ' || c > '~' {
+			return ansi.StringWidth(string(b))
+		}
+	}
+	return len(b)
+}
+
+// maxBar is the longest bar Blocks and Shades slice without allocating
+const maxBar = 256
+
+var (
+	blocks = strings.Repeat("█", maxBar)
+	shades = strings.Repeat("░", maxBar)
+)
+
+// Blocks returns n full blocks, the filled part of a bar
+func Blocks(n int) string {
+	if n > maxBar {
+		return strings.Repeat("█", n)
+	}
+	return blocks[:max(n, 0)*len("█")]
+}
+
+// Shades returns n light shades, the empty part of a bar
+func Shades(n int) string {
+	if n > maxBar {
+		return strings.Repeat("░", n)
+	}
+	return shades[:max(n, 0)*len("░")]
+}
+
+// maxStyled bounds a Styled cache; past it the cache starts over
+const maxStyled = 4096
+
+// Styled renders short fragments that repeat from row to row and frame to
+// frame, such as state letters and cursor markers, once per distinct text.
+// Views render on the UI goroutine only, so it does no locking.
+type Styled struct {
+	Style lipgloss.Style
+	cache map[string]string
+}
+
+// Render returns text rendered in the style
+func (s *Styled) Render(text string) string {
+	if out, ok := s.cache[text]; ok {
+		return out
+	}
+	if s.cache == nil || len(s.cache) >= maxStyled {
+		s.cache = make(map[string]string)
+	}
+	out := s.Style.Render(text)
+	s.cache[text] = out
+	return out
+}
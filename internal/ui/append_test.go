@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+)
+
+// sizes are byte counts across every unit FormatBytes renders
+var sizes = []uint64{0, 999, 1023, 1024, 1536, 10 << 20, 3<<30 + 1<<29, 5 << 40, 1<<64 - 1}
+
+func TestAppendBytesMatchesFormatBytes(t *testing.T) {
+	for _, n := range sizes {
+		if got, want := FormatBytes(n), sprintfBytes(n); got != want {
+			t.Errorf("FormatBytes(%d) = %q, want %q as before", n, got, want)
+		}
+		if got, want := string(AppendBytes([]byte("x"), n)), "x"+FormatBytes(n); got != want {
+			t.Errorf("AppendBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestPad(t *testing.T) {
+	for _, tc := range []struct {
+		cell  string
+		width int
+		want  string
+	}{
+		{"ab", 4, "ab  "},
+		{"abcd", 2, "abcd"},
+		{"日本", 6, "日本  "},
+	} {
+		dst := []byte("|")
+		if got := string(Pad(append(dst, tc.cell...), 1, tc.width)); got != "|"+tc.want {
+			t.Errorf("Pad(%q, %d) = %q, want %q", tc.cell, tc.width, got, "|"+tc.want)
+		}
+	}
+}
+
+// BenchmarkFormatBytes is the cost of a byte size rendered into a string
+// of its own, as callers outside the tables still do
+func BenchmarkFormatBytes(b *testing.B) {
+	b.ReportAllocs()
+	var out string
+	for i := range b.N {
+		out = FormatBytes(sizes[i%len(sizes)])
+	}
+	_ = out
+}
+
+// sprintfBytes is FormatBytes as it was before AppendBytes, the baseline
+// the benchmarks compare against
+func sprintfBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+func BenchmarkSprintfBytes(b *testing.B) {
+	b.ReportAllocs()
+	var out string
+	for i := range b.N {
+		out = sprintfBytes(sizes[i%len(sizes)])
+	}
+	_ = out
+}
+
+// BenchmarkAppendBytes is the same rendering into a reused row buffer,
+// which should not allocate at all
+func BenchmarkAppendBytes(b *testing.B) {
+	b.ReportAllocs()
+	buf := make([]byte, 0, 64)
+	for i := range b.N {
+		buf = AppendBytes(buf[:0], sizes[i%len(sizes)])
+	}
+}
+
+func BenchmarkAppendTruncated(b *testing.B) {
+	b.ReportAllocs()
+	buf := make([]byte, 0, 64)
+	for range b.N {
+		buf = AppendTruncated(buf[:0], "systemd-journald-with-a-long-name", 18)
+	}
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Panel is one titled block of the dashboard
+type Panel struct {
+	Title string
+	Body  string
+	// Widget names the panel in the layouts of the config file
+	Widget string
+	// Extra panels only appear in layouts naming them, not on the
+	// default dashboard
+	Extra bool
+}
+
+// Paneler is implemented by monitors that contribute to the dashboard.
+// width is the usable width inside a panel.
+type Paneler interface {
+	Panels(width int) []Panel
+}
+
+// VisibilityMsg tells a monitor run by "advis all" whether it is shown full
+// screen, contributes to the visible dashboard, or is hidden, so it can
+// skip collecting what nobody looks at. A monitor run on its own never
+// receives one and is always full screen.
+type VisibilityMsg struct {
+	Full      bool
+	Dashboard bool
+	Widgets   []string // Extra panels the dashboard's layout shows
+}
+
+// Shows reports whether the visible dashboard includes the extra panel
+// named widget
+func (v VisibilityMsg) Shows(widget string) bool {
+	return v.Dashboard && slices.Contains(v.Widgets, widget)
+}
+
+// minPanelWidth is the narrowest column the dashboard splits into
+const minPanelWidth = 48
+
+var panelStyle, panelTitleStyle lipgloss.Style
+
+// DashboardColumns is how many panel columns fit in width
+func DashboardColumns(width int) int {
+	return max(min(width/minPanelWidth, 3), 1)
+}
+
+// PanelWidth is the usable width inside a panel when width is split into
+// DashboardColumns columns
+func PanelWidth(width int) int {
+	return max(width/DashboardColumns(width)-panelStyle.GetHorizontalFrameSize(), 10)
+}
+
+// Dashboard lays panels out in as many columns as fit in width, sharing
+// height evenly between the rows and clipping bodies that do not fit.
+// Extra panels are left out.
+func Dashboard(panels []Panel, width, height int) string {
+	panels = slices.DeleteFunc(slices.Clone(panels), func(p Panel) bool { return p.Extra })
+	if len(panels) == 0 {
+		return ""
+	}
+	cols := DashboardColumns(width)
+	rows := (len(panels) + cols - 1) / cols
+	colWidth := width / cols
+	// Title line plus border
+	bodyHeight := max(height/rows-panelStyle.GetVerticalFrameSize()-1, 3)
+
+	var lines []string
+	for start := 0; start < len(panels); start += cols {
+		var row []string
+		for _, p := range panels[start:min(start+cols, len(panels))] {
+			body := strings.Split(strings.TrimRight(p.Body, "\n"), "\n")
+			if len(body) > bodyHeight {
+				body = body[:bodyHeight]
+			}
+			content := panelTitleStyle.Render(p.Title) + "\n" + strings.Join(body, "\n")
+			row = append(row, panelStyle.
+				Width(colWidth-panelStyle.GetHorizontalBorderSize()).
+				Height(bodyHeight+1).
+				MaxWidth(colWidth).
+				Render(content))
+		}
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// Sparkline draws the last width values scaled to their maximum, or to
+// ceiling when that is larger
+func Sparkline(values []float64, width int, ceiling float64) string {
+	if width <= 0 || len(values) == 0 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	top := ceiling
+	for _, v := range values {
+		top = math.Max(top, v)
+	}
+	if top <= 0 {
+		top = 1
+	}
+
+	levels := []rune("▁▂▃▄▅▆▇█")
+	var b strings.Builder
+	for _, v := range values {
+		level := int(math.Max(v, 0) / top * float64(len(levels)-1))
+		b.WriteRune(levels[level])
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxErrors bounds how many distinct errors an ErrorLog keeps
+const maxErrors = 50
+
+var errorLineStyle, errorDimStyle lipgloss.Style
+
+// ErrorEntry is one distinct failure and how often it recurred
+type ErrorEntry struct {
+	Source  string
+	Message string
+	First   time.Time
+	Last    time.Time
+	Count   int
+}
+
+// ErrorLog collects the failures of a monitor's collectors so they show
+// up in a status line instead of as silently empty or zero panels. The
+// same message from the same source is counted rather than repeated.
+// Models hold a pointer so the copies Update makes share it.
+type ErrorLog struct {
+	entries  []ErrorEntry // Most recent last
+	Expanded bool
+}
+
+// Add records err from source at t. Joined errors count separately.
+func (l *ErrorLog) Add(source string, err error, t time.Time) {
+	if err == nil {
+		return
+	}
+	for _, msg := range strings.Split(err.Error(), "\n") {
+		if msg == "" {
+			continue
+		}
+		i := slices.IndexFunc(l.entries, func(e ErrorEntry) bool {
+			return e.Source == source && e.Message == msg
+		})
+		if i < 0 {
+			l.entries = append(l.entries, ErrorEntry{Source: source, Message: msg, First: t})
+			i = len(l.entries) - 1
+		}
+		e := l.entries[i]
+		e.Last = t
+		e.Count++
+		// Keep the list ordered by the last occurrence
+		l.entries = append(slices.Delete(l.entries, i, i+1), e)
+	}
+	if len(l.entries) > maxErrors {
+		l.entries = slices.Delete(l.entries, 0, len(l.entries)-maxErrors)
+	}
+}
+
+// Len is the number of distinct errors recorded
+func (l *ErrorLog) Len() int { return len(l.entries) }
+
+// Status is a one-line summary of the most recent error, or "" when
+// nothing failed. It names the key of toggle, the binding expanding the
+// error pane.
+func (l *ErrorLog) Status(width int, toggle key.Binding) string {
+	if len(l.entries) == 0 {
+		return ""
+	}
+	last := l.entries[len(l.entries)-1]
+	line := fmt.Sprintf("⚠ %d error(s), last %s %s: %s",
+		len(l.entries), last.Last.Format("15:04:05"), last.Source, last.Message)
+	if toggle.Enabled() {
+		line += " | " + toggle.Help().Key + " details"
+	}
+	return errorLineStyle.Render(Truncate(line, width))
+}
+
+// Render lists the most recent errors, newest first, in up to rows lines
+func (l *ErrorLog) Render(width, rows int) string {
+	if len(l.entries) == 0 {
+		return errorDimStyle.Render("No collector errors") + "\n"
+	}
+	var b strings.Builder
+	for i := len(l.entries) - 1; i >= 0 && len(l.entries)-i <= rows; i-- {
+		e := l.entries[i]
+		count := ""
+		if e.Count > 1 {
+			count = fmt.Sprintf(" ×%d since %s", e.Count, e.First.Format("15:04:05"))
+		}
+		b.WriteString(errorDimStyle.Render(e.Last.Format("15:04:05")+" "+e.Source+count) + "\n")
+		b.WriteString(errorLineStyle.Render(Truncate("  "+e.Message, width)) + "\n")
+	}
+	return b.String()
+}
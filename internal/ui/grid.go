@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var panelMissingStyle lipgloss.Style
+
+// Layout is a named dashboard arrangement from the config file: rows of
+// widgets, each row taking a share of the height and each widget spanning
+// one or more of the grid's columns
+type Layout struct {
+	Name    string      `json:"name"`
+	Columns int         `json:"columns"` // Defaults to the widest row
+	Rows    []LayoutRow `json:"rows"`
+}
+
+// LayoutRow is one row of a Layout
+type LayoutRow struct {
+	Height  int          `json:"height"` // Share of the dashboard height, 1 when unset
+	Widgets []LayoutCell `json:"widgets"`
+}
+
+// LayoutCell places a widget, a panel by its Widget name, in a row
+type LayoutCell struct {
+	Widget string `json:"widget"`
+	Span   int    `json:"span"` // Columns covered, 1 when unset
+}
+
+// UnmarshalJSON also accepts a bare widget name for a one-column cell
+func (c *LayoutCell) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.Widget); err == nil {
+		c.Span = 1
+		return nil
+	}
+	type cell LayoutCell // Without this method
+	return json.Unmarshal(data, (*cell)(c))
+}
+
+// Check reports the first problem with the layout, naming it
+func (l Layout) Check() error {
+	if l.Name == "" {
+		return fmt.Errorf("layout without a name")
+	}
+	if len(l.Rows) == 0 {
+		return fmt.Errorf("layout %q has no rows", l.Name)
+	}
+	for i, row := range l.Rows {
+		if len(row.Widgets) == 0 {
+			return fmt.Errorf("layout %q: row %d has no widgets", l.Name, i+1)
+		}
+		if row.Height < 0 {
+			return fmt.Errorf("layout %q: row %d has a negative height", l.Name, i+1)
+		}
+		span := 0
+		for _, c := range row.Widgets {
+			if c.Widget == "" || c.Span < 0 {
+				return fmt.Errorf("layout %q: row %d has a widget without a name or with a negative span", l.Name, i+1)
+			}
+			span += max(c.Span, 1)
+		}
+		if l.Columns > 0 && span > l.Columns {
+			return fmt.Errorf("layout %q: row %d spans %d of %d columns", l.Name, i+1, span, l.Columns)
+		}
+	}
+	return nil
+}
+
+// columns is the number of grid columns: as configured or the widest row
+func (l Layout) columns() int {
+	if l.Columns > 0 {
+		return l.Columns
+	}
+	cols := 1
+	for _, row := range l.Rows {
+		span := 0
+		for _, c := range row.Widgets {
+			span += max(c.Span, 1)
+		}
+		cols = max(cols, span)
+	}
+	return cols
+}
+
+// LayoutPanelWidth is the usable width inside a one-column widget of l,
+// what the monitors are asked to draw their panels at
+func LayoutPanelWidth(l Layout, width int) int {
+	return max(width/l.columns()-panelStyle.GetHorizontalFrameSize(), 10)
+}
+
+// Grid draws the panels named by l in a width by height area. A widget
+// no monitor provides is drawn as an empty panel saying so, rather than
+// failing the whole layout over one name.
+func Grid(l Layout, panels []Panel, width, height int) string {
+	byWidget := make(map[string]Panel, len(panels))
+	for _, p := range panels {
+		byWidget[p.Widget] = p
+	}
+	cols := l.columns()
+	colWidth := width / cols
+
+	weights := make([]int, len(l.Rows))
+	for i, row := range l.Rows {
+		weights[i] = max(row.Height, 1)
+	}
+	heights := share(height, weights)
+
+	lines := make([]string, len(l.Rows))
+	for i, row := range l.Rows {
+		// Title line plus border
+		bodyHeight := max(heights[i]-panelStyle.GetVerticalFrameSize()-1, 1)
+		cells := make([]string, len(row.Widgets))
+		for j, c := range row.Widgets {
+			w := colWidth * max(c.Span, 1)
+			p, ok := byWidget[c.Widget]
+			if !ok {
+				p = Panel{Title: c.Widget, Body: panelMissingStyle.Render("No monitor provides this widget")}
+			}
+			body := strings.Split(strings.TrimRight(p.Body, "\n"), "\n")
+			if len(body) > bodyHeight {
+				body = body[:bodyHeight]
+			}
+			content := panelTitleStyle.Render(p.Title) + "\n" + strings.Join(body, "\n")
+			cells[j] = panelStyle.
+				Width(w - panelStyle.GetHorizontalBorderSize()).
+				Height(bodyHeight + 1).
+				MaxWidth(w).
+				MaxHeight(heights[i]).
+				Render(content)
+		}
+		lines[i] = lipgloss.JoinHorizontal(lipgloss.Top, cells...)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+)
+
+// icon is one icon of the monitors in each icon set. A wide emoji, one
+// that terminals commonly draw in a single cell although it is meant to
+// take two, is followed by an extra space.
+type icon struct {
+	emoji string
+	wide  bool
+	nerd  string // Nerd Font glyph
+	text  string // Plain text stand-in, empty for a decorative icon
+}
+
+// icons are the monitors' icons by name. The Nerd Font glyphs are in
+// the private use area, escaped as editors show them blank.
+var icons = map[string]icon{
+	"alerts":      {emoji: "🚨", nerd: "\uf0f3"},
+	"bell":        {emoji: "🔔", nerd: "\uf0f3"},
+	"cgroups":     {emoji: "🗂️", wide: true, nerd: "\uf0e8"},
+	"commands":    {emoji: "🛠️", wide: true, nerd: "\uf120"},
+	"connections": {emoji: "🔗", nerd: "\uf0c1"},
+	"container":   {emoji: "📦", nerd: "\uf1b2"},
+	"cpu":         {emoji: "⚡", nerd: "\uf4bc"},
+	"critical":    {emoji: "🚨", nerd: "\uf06a", text: "CRIT"},
+	"disk":        {emoji: "💽", nerd: "\uf0a0"},
+	"download":    {emoji: "📥", nerd: "\uf019"},
+	"entropy":     {emoji: "🎲", nerd: "\uf074"},
+	"errors":      {emoji: "⚠️", wide: true, nerd: "\uf071"},
+	"folder":      {emoji: "📂", nerd: "\uf07c"},
+	"graph":       {emoji: "📈", nerd: "\uf201"},
+	"healthy":     {emoji: "✅", nerd: "\uf058", text: "OK"},
+	"hot":         {emoji: "🔥", nerd: "\uf06d"},
+	"hugepages":   {emoji: "📐", nerd: "\uf009"},
+	"interfaces":  {emoji: "🔌", nerd: "\uf1e6"},
+	"interrupts":  {emoji: "⚡", nerd: "\uf0e7"},
+	"journal":     {emoji: "📜", nerd: "\uf15c"},
+	"kernel":      {emoji: "🐧", nerd: "\uf17c"},
+	"leak":        {emoji: "🕳️", wide: true, nerd: "\uf043"},
+	"limits":      {emoji: "🔒", nerd: "\uf023"},
+	"map":         {emoji: "🗺️", wide: true, nerd: "\uf279"},
+	"memory":      {emoji: "🧠", nerd: "\uf2db"},
+	"metrics":     {emoji: "📏", nerd: "\uf1de"},
+	"monitor":     {emoji: "🖥️", wide: true, nerd: "\uf108"},
+	"network":     {emoji: "🌐", nerd: "\uf0ac"},
+	"oom":         {emoji: "💀", nerd: "\uf1e2"},
+	"paused":      {emoji: "🔴", nerd: "\uf04c"},
+	"plugin":      {emoji: "🧩", nerd: "\uf12e"},
+	"processes":   {emoji: "📋", nerd: "\uf03a"},
+	"raid":        {emoji: "🧱", nerd: "\uf1c0"},
+	"ram":         {emoji: "💾", nerd: "\uf2db"},
+	"rules":       {emoji: "📐", nerd: "\uf0e3"},
+	"running":     {emoji: "🟢", nerd: "\uf04b"},
+	"search":      {emoji: "🔍", nerd: "\uf002"},
+	"sensors":     {emoji: "🍓", nerd: "\uf2c9"},
+	"services":    {emoji: "⚙️", wide: true, nerd: "\uf085"},
+	"softirqs":    {emoji: "🌀", nerd: "\uf021"},
+	"speed":       {emoji: "⚡", nerd: "\uf0e4"},
+	"stats":       {emoji: "📊", nerd: "\uf080"},
+	"talkers":     {emoji: "🗣️", wide: true, nerd: "\uf086"},
+	"thp":         {emoji: "🧩", nerd: "\uf009"},
+	"tree":        {emoji: "🌳", nerd: "\uf1bb"},
+	"upload":      {emoji: "📤", nerd: "\uf093"},
+	"vm":          {emoji: "🖧", wide: true, nerd: "\uf233"},
+	"waiting":     {emoji: "⏳", nerd: "\uf252"},
+	"warning":     {emoji: "⚠️", wide: true, nerd: "\uf071", text: "WARN"},
+	"watch":       {emoji: "👁", wide: true, nerd: "\uf06e"},
+	"watchdog":    {emoji: "🐕", nerd: "\uf132"},
+}
+
+// iconSet is how Icon draws: "emoji", "nerdfont" or "text"
+var iconSet = "emoji"
+
+// SetIcons picks the icon set: "emoji", "nerdfont" for the glyphs of a
+// Nerd Font, or "text" for none. An empty name guesses from the
+// terminal: the Linux console draws no emoji, and WezTerm and kitty
+// bundle the Nerd Font symbols.
+func SetIcons(name string) error {
+	switch name {
+	case "emoji", "nerdfont", "text":
+		iconSet = name
+	case "":
+		iconSet = detectIcons()
+	default:
+		return fmt.Errorf("unknown icon set %q (known: emoji, nerdfont, text)", name)
+	}
+	return nil
+}
+
+func detectIcons() string {
+	switch term := os.Getenv("TERM"); {
+	case term == "linux" || term == "dumb":
+		return "text"
+	case term == "xterm-kitty" || os.Getenv("TERM_PROGRAM") == "WezTerm":
+		return "nerdfont"
+	}
+	return "emoji"
+}
+
+// Icon is the icon called name in the icon set followed by a space, or
+// nothing for a decorative icon in the text set
+func Icon(name string) string {
+	i, ok := icons[name]
+	if !ok {
+		panic("ui: unknown icon " + name)
+	}
+	switch {
+	case iconSet == "nerdfont":
+		return i.nerd + " "
+	case iconSet == "text" && i.text == "":
+		return ""
+	case iconSet == "text":
+		return i.text + " "
+	case i.wide:
+		return i.emoji + "  "
+	}
+	return i.emoji + " "
+}
@@ -0,0 +1,272 @@
+package ui
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyLabels are the footer spellings of keys whose names are long
+var keyLabels = map[string]string{
+	"up": "↑", "down": "↓", "left": "←", "right": "→",
+	"backspace": "⌫", "tab": "Tab", " ": "space",
+}
+
+// KeyLabel is how a footer shows keys: one key as it is, two joined by a
+// slash and a longer run, such as the tab numbers, as its first and last
+func KeyLabel(keys ...string) string {
+	labels := keyLabelsOf(keys)
+	if len(labels) > 2 {
+		return labels[0] + "-" + labels[len(labels)-1]
+	}
+	return strings.Join(labels, "/")
+}
+
+// keyLabelsOf spells each key as a footer shows it
+func keyLabelsOf(keys []string) []string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		labels[i] = k
+		if l, ok := keyLabels[k]; ok {
+			labels[i] = l
+		}
+	}
+	return labels
+}
+
+// Rebind overrides the bindings of a keymap, the struct km points to,
+// from the config file. Each key.Binding field, including those of
+// embedded keymaps, is named by its `key` tag; an unknown name is an error
+// so a typo does not go unnoticed, and an empty list unbinds the action.
+func Rebind(km any, overrides map[string][]string) error {
+	fields := make(map[string]*key.Binding)
+	bindingsOf(reflect.ValueOf(km).Elem(), fields)
+	for name, keys := range overrides {
+		b, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("unknown key binding %q", name)
+		}
+		if len(keys) == 0 {
+			b.Unbind()
+			continue
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(KeyLabel(keys...), b.Help().Desc)
+	}
+	return nil
+}
+
+// bindingsOf collects the tagged bindings of the keymap v by name
+func bindingsOf(v reflect.Value, fields map[string]*key.Binding) {
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		switch name := f.Tag.Get("key"); {
+		case name != "":
+			fields[name] = v.Field(i).Addr().Interface().(*key.Binding)
+		case f.Anonymous && f.Type.Kind() == reflect.Struct:
+			bindingsOf(v.Field(i), fields)
+		}
+	}
+}
+
+// HelpLine lists the bindings for a footer as "key description" pairs.
+// Neighbours sharing a description are merged, so up and down bindings
+// both described as "select" read "↑/↓ select". Bindings without keys or
+// help are left out.
+func HelpLine(bindings ...key.Binding) string {
+	var items []string
+	lastDesc := ""
+	for _, b := range bindings {
+		h := b.Help()
+		if !b.Enabled() || h.Key == "" {
+			continue
+		}
+		if len(items) > 0 && h.Desc == lastDesc {
+			items[len(items)-1] = strings.TrimSuffix(items[len(items)-1], " "+h.Desc) + "/" + h.Key + " " + h.Desc
+			continue
+		}
+		items = append(items, h.Key+" "+h.Desc)
+		lastDesc = h.Desc
+	}
+	return strings.Join(items, " | ")
+}
+
+// TrimKeys is b limited to its first n keys, so the help of a binding with
+// a key per tab shows only the range of tabs there are
+func TrimKeys(b key.Binding, n int) key.Binding {
+	if keys := b.Keys(); len(keys) > n {
+		b.SetKeys(keys[:n]...)
+		b.SetHelp(KeyLabel(keys[:n]...), b.Help().Desc)
+	}
+	return b
+}
+
+// KeyGroup is a titled list of bindings in a help overlay
+type KeyGroup struct {
+	Title    string
+	Bindings []key.Binding
+}
+
+var overlayTitleStyle, overlayGroupStyle, overlayKeyStyle lipgloss.Style
+
+// HelpOverlay lists every enabled binding of the groups with all of its
+// keys, rather than the one a footer has room for. A binding with more
+// than three keys, such as one key per tab, shows its help key instead.
+// Neighbours sharing a description are merged as in HelpLine.
+func HelpOverlay(title string, groups []KeyGroup, width int) string {
+	type row struct{ keys, desc string }
+	sections := make([][]row, len(groups))
+	keyWidth := 0
+	for i, g := range groups {
+		for _, b := range g.Bindings {
+			h := b.Help()
+			if !b.Enabled() || h.Desc == "" {
+				continue
+			}
+			label := h.Key
+			if len(b.Keys()) <= 3 {
+				label = strings.Join(keyLabelsOf(b.Keys()), "/")
+			}
+			rows := sections[i]
+			if n := len(rows); n > 0 && rows[n-1].desc == h.Desc {
+				rows[n-1].keys += ", " + label
+			} else {
+				rows = append(rows, row{label, h.Desc})
+			}
+			keyWidth = max(keyWidth, Width(rows[len(rows)-1].keys))
+			sections[i] = rows
+		}
+	}
+	keyWidth = min(keyWidth, width/2)
+
+	var b strings.Builder
+	b.WriteString(overlayTitleStyle.Render(title) + "\n")
+	for i, g := range groups {
+		if len(sections[i]) == 0 {
+			continue
+		}
+		b.WriteString("\n" + overlayGroupStyle.Render(g.Title) + "\n")
+		for _, r := range sections[i] {
+			keys := Truncate(r.keys, keyWidth)
+			keys += strings.Repeat(" ", keyWidth-Width(keys))
+			b.WriteString("  " + overlayKeyStyle.Render(keys) + "  " + Truncate(r.desc, max(width-keyWidth-4, 0)) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// NavKeyMap holds the bindings that move a table's cursor: a row at a
+// time with the arrows or j/k, and after vim to either end or by half a
+// screen. Monitors embed it in their keymap.
+type NavKeyMap struct {
+	Up       key.Binding `key:"up"`
+	Down     key.Binding `key:"down"`
+	Top      key.Binding `key:"top"`
+	Bottom   key.Binding `key:"bottom"`
+	PageUp   key.Binding `key:"page_up"`
+	PageDown key.Binding `key:"page_down"`
+
+	// Sideways, for tables wider than the screen
+	ScrollLeft  key.Binding `key:"scroll_left"`
+	ScrollRight key.Binding `key:"scroll_right"`
+}
+
+// NewNavKeyMap returns the default navigation bindings. "gg" is a two-key
+// sequence, which arrives as one key only through a KeyChord.
+func NewNavKeyMap() NavKeyMap {
+	return NavKeyMap{
+		Up:       key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑", "select")),
+		Down:     key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓", "select")),
+		Top:      key.NewBinding(key.WithKeys("gg", "home"), key.WithHelp("gg", "first")),
+		Bottom:   key.NewBinding(key.WithKeys("G", "end"), key.WithHelp("G", "last")),
+		PageUp:   key.NewBinding(key.WithKeys("ctrl+u", "pgup"), key.WithHelp("ctrl+u", "half page up")),
+		PageDown: key.NewBinding(key.WithKeys("ctrl+d", "pgdown"), key.WithHelp("ctrl+d", "half page down")),
+
+		ScrollLeft:  key.NewBinding(key.WithKeys("<", "shift+left"), key.WithHelp("<", "scroll left")),
+		ScrollRight: key.NewBinding(key.WithKeys(">", "shift+right"), key.WithHelp(">", "scroll right")),
+	}
+}
+
+// Scrolls reports whether msg is a sideways scroll key
+func (k NavKeyMap) Scrolls(msg tea.KeyMsg) bool {
+	return key.Matches(msg, k.ScrollLeft, k.ScrollRight)
+}
+
+// Moves reports whether msg is a navigation key
+func (k NavKeyMap) Moves(msg tea.KeyMsg) bool {
+	return key.Matches(msg, k.Up, k.Down, k.Top, k.Bottom, k.PageUp, k.PageDown)
+}
+
+// Move is the cursor of a table of n rows after the navigation key msg,
+// on a screen height lines tall
+func (k NavKeyMap) Move(msg tea.KeyMsg, cursor, n, height int) int {
+	half := max(height/2, 1)
+	switch {
+	case key.Matches(msg, k.Up):
+		cursor--
+	case key.Matches(msg, k.Down):
+		cursor++
+	case key.Matches(msg, k.Top):
+		cursor = 0
+	case key.Matches(msg, k.Bottom):
+		cursor = n - 1
+	case key.Matches(msg, k.PageUp):
+		cursor -= half
+	case key.Matches(msg, k.PageDown):
+		cursor += half
+	}
+	return max(min(cursor, n-1), 0)
+}
+
+// Bindings lists the navigation bindings for help
+func (k NavKeyMap) Bindings() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Top, k.Bottom, k.PageUp, k.PageDown, k.ScrollLeft, k.ScrollRight}
+}
+
+// KeyChord joins the keys of multi-key sequences such as "gg". Its zero
+// value is ready to use.
+type KeyChord struct {
+	held    tea.KeyMsg
+	holding bool
+}
+
+// Feed takes a key press and returns those to handle now: none while it
+// may start a sequence of one of bindings, the sequence as one key once
+// it completes, and the held key before msg when it does not, so a key
+// that also works alone still does.
+func (c *KeyChord) Feed(msg tea.KeyMsg, bindings ...key.Binding) []tea.KeyMsg {
+	if c.holding {
+		c.holding = false
+		joined := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(c.held.String() + msg.String())}
+		for _, b := range bindings {
+			if key.Matches(joined, b) {
+				return []tea.KeyMsg{joined}
+			}
+		}
+		return append([]tea.KeyMsg{c.held}, c.Feed(msg, bindings...)...)
+	}
+	if msg.Type == tea.KeyRunes && !msg.Alt {
+		for _, b := range bindings {
+			for _, k := range b.Keys() {
+				if b.Enabled() && len(k) > len(msg.String()) && strings.HasPrefix(k, msg.String()) && !keyName(k) {
+					c.held, c.holding = msg, true
+					return nil
+				}
+			}
+		}
+	}
+	return []tea.KeyMsg{msg}
+}
+
+// keyName reports whether k names a single key, such as "pgdown", rather
+// than spelling a sequence of characters
+func keyName(k string) bool {
+	return strings.Contains(k, "+") || slices.Contains([]string{"home", "end", "pgup", "pgdown", "up", "down",
+		"left", "right", "enter", "tab", "esc", "backspace", "delete", "insert", "space"}, k) ||
+		len(k) > 1 && k[0] == 'f' && k[1] >= '0' && k[1] <= '9'
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// numbers formats figures for the reader's locale. A nil printer, until
+// SetLocale is called, leaves them plain, as scripts reading a status
+// line expect.
+var numbers struct {
+	printer *message.Printer
+	decimal string // Separator AppendBytes writes instead of '.'
+}
+
+// SetLocale groups digits and picks the decimal separator after the
+// locale name, a BCP 47 tag such as "de" or "en-IN". An empty name is the
+// environment's LC_ALL, LC_NUMERIC or LANG, and "none" keeps figures plain.
+func SetLocale(name string) error {
+	if name == "" {
+		name = localeFromEnv()
+	}
+	if name == "none" {
+		numbers.printer, numbers.decimal = nil, ""
+		return nil
+	}
+	tag, err := language.Parse(name)
+	if err != nil {
+		return fmt.Errorf("locale %q: %w", name, err)
+	}
+	numbers.printer = message.NewPrinter(tag)
+	numbers.decimal = ""
+	// Locales writing other digits keep AppendBytes' Latin ones and point
+	if s := numbers.printer.Sprint(number.Decimal(1.5, number.Scale(1))); strings.HasPrefix(s, "1") && strings.HasSuffix(s, "5") {
+		numbers.decimal = strings.TrimSuffix(strings.TrimPrefix(s, "1"), "5")
+	}
+	return nil
+}
+
+// localeFromEnv is the numeric locale of the environment as a language
+// tag, English for the C locale
+func localeFromEnv() string {
+	for _, v := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		name := os.Getenv(v)
+		if name == "" {
+			continue
+		}
+		name, _, _ = strings.Cut(name, ".") // en_US.UTF-8
+		name, _, _ = strings.Cut(name, "@") // de_DE@euro
+		if name == "C" || name == "POSIX" {
+			return "en"
+		}
+		return strings.ReplaceAll(name, "_", "-")
+	}
+	return "en"
+}
+
+// FormatInt renders n with the locale's digit grouping, such as
+// 1,234,567
+func FormatInt(n int64) string {
+	if numbers.printer == nil {
+		return strconv.FormatInt(n, 10)
+	}
+	return numbers.printer.Sprint(number.Decimal(n))
+}
+
+// FormatFloat renders v with prec decimals, grouped and separated as the
+// locale writes them
+func FormatFloat(v float64, prec int) string {
+	if numbers.printer == nil {
+		return strconv.FormatFloat(v, 'f', prec, 64)
+	}
+	return numbers.printer.Sprint(number.Decimal(v, number.Scale(prec)))
+}
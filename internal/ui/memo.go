@@ -0,0 +1,19 @@
+package ui
+
+// Memo keeps the last output of one component of a view and recomputes
+// it only when the key describing the component's inputs changes, so
+// frames where nothing changed cost a comparison instead of a redraw.
+// Models hold a pointer to it so the copies Update makes share the cache.
+type Memo[K comparable, V any] struct {
+	key   K
+	value V
+	valid bool
+}
+
+// Get returns the cached output for key, calling render when it is stale
+func (c *Memo[K, V]) Get(key K, render func() V) V {
+	if !c.valid || c.key != key {
+		c.key, c.value, c.valid = key, render(), true
+	}
+	return c.value
+}
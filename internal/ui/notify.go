@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// flashDuration is how long a flash keeps the screen in reverse video
+const flashDuration = 150 * time.Millisecond
+
+// NotifyRule is how an alert draws attention when it fires. In the
+// overrides of a Notification, fields left out follow its defaults.
+type NotifyRule struct {
+	On         string `json:"on"`          // bell, flash, both or off
+	Level      string `json:"level"`       // Lowest level notified: warning or critical
+	QuietHours string `json:"quiet_hours"` // Such as 22:00-07:00, or none
+}
+
+// Notification is the "notify" section of the config file: the defaults
+// and the overrides by alert source or rule name
+type Notification struct {
+	NotifyRule
+	Rules map[string]NotifyRule `json:"rules"`
+}
+
+// defaultNotify notifies nothing, for a config without "notify"
+var defaultNotify = NotifyRule{On: "off", Level: "warning", QuietHours: "none"}
+
+// notification is the applied config
+var notification = Notification{NotifyRule: defaultNotify}
+
+// SetNotification applies the "notify" section of the config file
+func SetNotification(n Notification) error {
+	def := defaultNotify
+	def.On = orDefault(n.On, def.On)
+	def.Level = orDefault(n.Level, def.Level)
+	def.QuietHours = orDefault(n.QuietHours, def.QuietHours)
+	if err := def.check(); err != nil {
+		return fmt.Errorf("notify: %w", err)
+	}
+	rules := make(map[string]NotifyRule, len(n.Rules))
+	for name, r := range n.Rules {
+		r.On = orDefault(r.On, def.On)
+		r.Level = orDefault(r.Level, def.Level)
+		r.QuietHours = orDefault(r.QuietHours, def.QuietHours)
+		if err := r.check(); err != nil {
+			return fmt.Errorf("notify.rules.%s: %w", name, err)
+		}
+		rules[name] = r
+	}
+	notification = Notification{NotifyRule: def, Rules: rules}
+	return nil
+}
+
+func (r NotifyRule) check() error {
+	switch {
+	case r.On != "bell" && r.On != "flash" && r.On != "both" && r.On != "off":
+		return fmt.Errorf("on %q is not bell, flash, both or off", r.On)
+	case r.Level != "warning" && r.Level != "critical":
+		return fmt.Errorf("level %q is not warning or critical", r.Level)
+	}
+	_, _, err := quietHours(r.QuietHours)
+	return err
+}
+
+// quietHours parses hours such as 22:00-07:00 into minutes of the day.
+// "none" is an empty range.
+func quietHours(s string) (from, to int, err error) {
+	if s == "none" {
+		return 0, 0, nil
+	}
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("quiet hours %q are not written as 22:00-07:00", s)
+	}
+	var bounds [2]int
+	for i, part := range []string{start, end} {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return 0, 0, fmt.Errorf("quiet hours %q: %q is not a time of day", s, part)
+		}
+		bounds[i] = t.Hour()*60 + t.Minute()
+	}
+	return bounds[0], bounds[1], nil
+}
+
+// quiet reports whether at falls within the rule's quiet hours, which
+// wrap past midnight when they end before they start
+func (r NotifyRule) quiet(at time.Time) bool {
+	from, to, _ := quietHours(r.QuietHours)
+	minute := at.Hour()*60 + at.Minute()
+	if from <= to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// Notify rings the terminal bell or flashes the screen for an alert of
+// source at level that fired at, as the config file asks. The escapes go
+// to stderr, the terminal the program draws on, as with Copy.
+func Notify(source string, level int, at time.Time) tea.Cmd {
+	r, ok := notification.Rules[source]
+	if !ok {
+		r = notification.NotifyRule
+	}
+	if r.On == "off" || level == LevelWarning && r.Level == "critical" || r.quiet(at.Local()) {
+		return nil
+	}
+	return func() tea.Msg {
+		if r.On == "bell" || r.On == "both" {
+			os.Stderr.WriteString("\a")
+		}
+		if r.On == "flash" || r.On == "both" {
+			os.Stderr.WriteString("\x1b[?5h") // Reverse video
+			time.Sleep(flashDuration)
+			os.Stderr.WriteString("\x1b[?5l")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Palette overrides the colors of the monitors by role. A role left empty
+// keeps each monitor's own color for it, so the default palette is empty.
+type Palette struct {
+	Title    string // Title bar text
+	TitleBg  string
+	Accent   string // Headers, cursors and the focused pane
+	Info     string // Secondary text and hints
+	Dim      string
+	Border   string
+	Good     string // Healthy bars and states
+	Warning  string
+	Critical string
+	AlertFg  string // The alert banner
+	AlertBg  string
+	Download string // Received traffic, told apart from Upload
+	Upload   string
+	Series   []string // Lines of a multi-series graph, in order
+}
+
+// palettes are the palettes the config file picks from by name. The
+// colorblind one takes the Okabe-Ito colors, which stay distinct with
+// deuteranopia and protanopia: blue against orange rather than green
+// against red or pink. The high-contrast one keeps to saturated colors
+// and white on black.
+var palettes = map[string]Palette{
+	"default": {},
+	"colorblind": {
+		Title:    "#CC79A7",
+		Accent:   "#CC79A7",
+		Info:     "#F0E442",
+		Dim:      "#999999",
+		Good:     "#0072B2",
+		Warning:  "#E69F00",
+		Critical: "#D55E00",
+		AlertFg:  "#FFFFFF",
+		AlertBg:  "#D55E00",
+		Download: "#56B4E9",
+		Upload:   "#E69F00",
+		Series:   []string{"#E69F00", "#56B4E9", "#009E73", "#F0E442", "#CC79A7"},
+	},
+	"high-contrast": {
+		Title:    "#FFFFFF",
+		TitleBg:  "#000000",
+		Accent:   "#00FFFF",
+		Info:     "#FFFFFF",
+		Dim:      "#D0D0D0",
+		Border:   "#FFFFFF",
+		Good:     "#00FF00",
+		Warning:  "#FFFF00",
+		Critical: "#FF0000",
+		AlertFg:  "#FFFFFF",
+		AlertBg:  "#B00000",
+		Download: "#00FFFF",
+		Upload:   "#FFFF00",
+		Series:   []string{"#FFFFFF", "#00FFFF", "#FFFF00", "#00FF00", "#FF00FF"},
+	},
+}
+
+var (
+	palette   Palette
+	onPalette []func(Palette)
+)
+
+// OnPalette registers a package's style setup, calling it with the
+// current palette now and with every palette SetPalette picks later
+func OnPalette(apply func(Palette)) {
+	onPalette = append(onPalette, apply)
+	apply(palette)
+}
+
+// SetPalette switches to the palette called name and restyles the
+// monitors. The threshold colors follow it, so set it before applying
+// threshold overrides.
+func SetPalette(name string) error {
+	if name == "" {
+		name = "default"
+	}
+	p, ok := palettes[name]
+	if !ok {
+		names := make([]string, 0, len(palettes))
+		for n := range palettes {
+			names = append(names, n)
+		}
+		slices.Sort(names)
+		return fmt.Errorf("unknown palette %q (known: %s)", name, strings.Join(names, ", "))
+	}
+	palette = p
+	for _, set := range []map[string]Threshold{thresholds, defaultThresholds} {
+		for metric, t := range set {
+			if t.NormalColor != "" {
+				t.NormalColor = orDefault(p.Good, "#04B575")
+			}
+			t.WarningColor = orDefault(p.Warning, "#FBBF24")
+			t.CriticalColor = orDefault(p.Critical, "#FF6B6B")
+			set[metric] = t
+		}
+	}
+	for _, apply := range onPalette {
+		apply(p)
+	}
+	return nil
+}
+
+// ColorOr is the palette's color for a role, or own when the palette
+// leaves the role to the monitor
+func ColorOr(role, own string) lipgloss.Color {
+	return lipgloss.Color(orDefault(role, own))
+}
+
+func init() { OnPalette(setStyles) }
+
+// setStyles styles the shared widgets: the status bar, help overlay,
+// panes, dashboard panels and error log
+func setStyles(p Palette) {
+	statusBarStyle = lipgloss.NewStyle().Foreground(ColorOr(p.Dim, "#AAAAAA")).Background(lipgloss.Color("#262626"))
+	statusSegStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Background(lipgloss.Color("#3C3C3C")).Padding(0, 1)
+	statusWarnStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#000000")).
+		Background(ColorOr(p.Warning, "#FBBF24")).Padding(0, 1)
+	statusCritStyle = lipgloss.NewStyle().Bold(true).Blink(true).Foreground(ColorOr(p.AlertFg, "#FFFFFF")).
+		Background(ColorOr(p.AlertBg, "#DC2626")).Padding(0, 1)
+	statusPausedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#000000")).
+		Background(ColorOr(p.Accent, "#7DD3FC")).Padding(0, 1)
+
+	overlayTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(ColorOr(p.Title, "#FFFFFF")).
+		Background(ColorOr(p.TitleBg, "#3C3C3C")).Padding(0, 1)
+	overlayGroupStyle = lipgloss.NewStyle().Bold(true).Foreground(ColorOr(p.Accent, "#06D6A0"))
+	overlayKeyStyle = lipgloss.NewStyle().Foreground(ColorOr(p.Warning, "#FBBF24"))
+
+	paneTitleStyle = lipgloss.NewStyle().Foreground(ColorOr(p.Dim, "#777777"))
+	paneFocusStyle = lipgloss.NewStyle().Bold(true).Foreground(ColorOr(p.Accent, "#06D6A0"))
+
+	panelStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorOr(p.Border, "#444444")).Padding(0, 1)
+	panelTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(ColorOr(p.Title, "#7D56F4"))
+	panelMissingStyle = lipgloss.NewStyle().Foreground(ColorOr(p.Dim, "#777777"))
+
+	errorLineStyle = lipgloss.NewStyle().Foreground(ColorOr(p.Critical, "#FF6B6B"))
+	errorDimStyle = lipgloss.NewStyle().Foreground(ColorOr(p.Dim, "#777777"))
+}
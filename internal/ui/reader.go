@@ -0,0 +1,26 @@
+package ui
+
+import "strings"
+
+// Reading is one figure of the screen reader mode, labelled in words
+// rather than by bars, emoji or colors
+type Reading struct {
+	Label string
+	Value string
+}
+
+// Reader is implemented by monitors that describe themselves to the
+// screen reader mode. Readings keep their order from update to update, so
+// a line is the same figure each time it is read.
+type Reader interface {
+	Readings() []Reading
+}
+
+// RenderReadings writes readings one per line, the label before the value
+func RenderReadings(readings []Reading) string {
+	var b strings.Builder
+	for _, r := range readings {
+		b.WriteString(r.Label + ": " + r.Value + "\n")
+	}
+	return b.String()
+}
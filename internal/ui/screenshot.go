@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ScreenshotFlags configures where Screenshot writes, merged into the
+// flags of every monitor command
+var ScreenshotFlags = flag.NewFlagSet("screenshot", flag.ExitOnError)
+
+var (
+	flagScreenshotDir  = ScreenshotFlags.String("screenshot-dir", ".", "directory screenshots are written to")
+	flagScreenshotText = ScreenshotFlags.Bool("screenshot-text", false, "also write each screenshot as plain text, for pasting where colors do not survive")
+)
+
+// ScreenshotMsg reports the outcome of a Screenshot
+type ScreenshotMsg struct {
+	Path string // The ANSI file; the text one shares its name
+	Text bool   // A plain text copy was written too
+	Err  error
+}
+
+// Status is the footer line naming the screenshot
+func (m ScreenshotMsg) Status() string {
+	if m.Err != nil {
+		return "Screenshot failed: " + m.Err.Error()
+	}
+	if m.Text {
+		return "Saved screenshot to " + m.Path + " and .txt"
+	}
+	return "Saved screenshot to " + m.Path
+}
+
+// Screenshot writes frame, a rendered view, to a timestamped .ans file
+// that "cat" or "less -R" shows exactly as it was on screen, and with
+// -screenshot-text a .txt copy without the escape sequences
+func Screenshot(frame string) tea.Cmd {
+	dir, text := *flagScreenshotDir, *flagScreenshotText
+	return func() tea.Msg {
+		base := filepath.Join(dir, "advis-"+time.Now().Format("20060102-150405"))
+		msg := ScreenshotMsg{Path: base + ".ans", Text: text}
+		err := os.WriteFile(msg.Path, []byte(frame+"\n"), 0o644)
+		if text {
+			err = errors.Join(err, os.WriteFile(base+".txt", []byte(ansi.Strip(frame)+"\n"), 0o644))
+		}
+		msg.Err = err
+		return msg
+	}
+}
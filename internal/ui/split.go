@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// maxPanes bounds how many panes a split holds
+	maxPanes = 4
+	// defaultWeight is a new pane's share, leaving room to shrink it
+	defaultWeight = 2
+	// maxWeight bounds how much larger than its neighbours a pane grows
+	maxWeight = 8
+)
+
+var paneTitleStyle, paneFocusStyle lipgloss.Style
+
+// SplitKeyMap holds the pane bindings. Tabbed monitors embed it in their
+// keymap, so the config file overrides them in each monitor's section.
+type SplitKeyMap struct {
+	Stacked    key.Binding `key:"split_stacked"`
+	SideBySide key.Binding `key:"split_side"`
+	NextPane   key.Binding `key:"next_pane"`
+	PrevPane   key.Binding `key:"prev_pane"`
+	ClosePane  key.Binding `key:"close_pane"`
+	Grow       key.Binding `key:"grow_pane"`
+	Shrink     key.Binding `key:"shrink_pane"`
+	Zoom       key.Binding `key:"zoom"`
+}
+
+// NewSplitKeyMap returns the default pane bindings, after tmux where the
+// keys are free, with vim's h and l to move between panes
+func NewSplitKeyMap() SplitKeyMap {
+	return SplitKeyMap{
+		Stacked:    key.NewBinding(key.WithKeys(`"`), key.WithHelp(`"`, "split stacked")),
+		SideBySide: key.NewBinding(key.WithKeys("%"), key.WithHelp("%", "split side by side")),
+		NextPane:   key.NewBinding(key.WithKeys("w", "l"), key.WithHelp("w/l", "next pane")),
+		PrevPane:   key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "previous pane")),
+		ClosePane:  key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "close pane")),
+		Grow:       key.NewBinding(key.WithKeys("+", "="), key.WithHelp("+", "grow pane")),
+		Shrink:     key.NewBinding(key.WithKeys("-"), key.WithHelp("-", "shrink pane")),
+		Zoom:       key.NewBinding(key.WithKeys("Z"), key.WithHelp("Z", "zoom")),
+	}
+}
+
+// Split divides a monitor's tab area into panes, stacked or side by side,
+// each showing the content of any tab. The focused pane's tab is the
+// monitor's current tab, so keys act on it as they would unsplit.
+// Zoomed expands the current tab, the focused pane when split, to the
+// whole terminal until zoomed back.
+type Split struct {
+	Tabs     []int // Tab shown in each pane, empty when not split
+	Weights  []int // Share of the area each pane gets
+	Vertical bool  // Side by side rather than stacked
+	Focus    int
+	Zoomed   bool
+}
+
+// Active reports whether the area is split
+func (s *Split) Active() bool { return len(s.Tabs) > 1 }
+
+// Shows reports whether a pane shows tab. While zoomed only the current
+// tab is on screen.
+func (s *Split) Shows(tab int) bool {
+	return s.Active() && !s.Zoomed && slices.Contains(s.Tabs, tab)
+}
+
+// SetTab puts tab in the focused pane, for the monitor's tab keys
+func (s *Split) SetTab(tab int) {
+	if s.Active() {
+		s.Tabs[s.Focus] = tab
+	}
+}
+
+// Update handles a pane key of keys in a monitor whose current tab is tab.
+// It returns the tab to make current and whether msg was a pane key.
+// Splitting adds a pane showing the current tab after the focused one,
+// and zooms back out.
+func (s *Split) Update(msg tea.KeyMsg, keys SplitKeyMap, tab int) (int, bool) {
+	switch {
+	case key.Matches(msg, keys.Zoom):
+		s.Zoomed = !s.Zoomed
+		return tab, true
+	case key.Matches(msg, keys.Stacked, keys.SideBySide):
+		s.Zoomed = false
+		if !s.Active() {
+			s.Tabs, s.Weights, s.Focus = []int{tab}, []int{defaultWeight}, 0
+		}
+		s.Vertical = key.Matches(msg, keys.SideBySide)
+		if len(s.Tabs) < maxPanes {
+			s.Tabs = slices.Insert(slices.Clone(s.Tabs), s.Focus+1, tab)
+			s.Weights = slices.Insert(slices.Clone(s.Weights), s.Focus+1, defaultWeight)
+			s.Focus++
+		}
+	case !s.Active():
+		return tab, false
+	case key.Matches(msg, keys.NextPane):
+		s.Focus = (s.Focus + 1) % len(s.Tabs)
+	case key.Matches(msg, keys.PrevPane):
+		s.Focus = (s.Focus + len(s.Tabs) - 1) % len(s.Tabs)
+	case key.Matches(msg, keys.ClosePane):
+		s.Tabs = slices.Delete(slices.Clone(s.Tabs), s.Focus, s.Focus+1)
+		s.Weights = slices.Delete(slices.Clone(s.Weights), s.Focus, s.Focus+1)
+		s.Focus = min(s.Focus, len(s.Tabs)-1)
+		if len(s.Tabs) == 1 {
+			tab = s.Tabs[0]
+			*s = Split{Zoomed: s.Zoomed}
+			return tab, true
+		}
+	case key.Matches(msg, keys.Grow):
+		s.Weights = slices.Clone(s.Weights)
+		s.Weights[s.Focus] = min(s.Weights[s.Focus]+1, maxWeight)
+	case key.Matches(msg, keys.Shrink):
+		s.Weights = slices.Clone(s.Weights)
+		s.Weights[s.Focus] = max(s.Weights[s.Focus]-1, 1)
+	default:
+		return tab, false
+	}
+	return s.Tabs[s.Focus], true
+}
+
+// Render lays the panes out in a width by height area, each under a rule
+// holding its title, the focused one highlighted. pane draws a tab in the
+// space it gets; what does not fit is cut off.
+func (s *Split) Render(width, height int, title func(tab int) string, pane func(tab, width, height int) string) string {
+	total := height
+	if s.Vertical {
+		total = width - (len(s.Tabs) - 1) // A separator column between panes
+	}
+	sizes := share(total, s.Weights)
+
+	columns := make([][]string, len(s.Tabs))
+	for i, tab := range s.Tabs {
+		w, h := width, sizes[i]
+		if s.Vertical {
+			w, h = sizes[i], height
+		}
+		style := paneTitleStyle
+		if i == s.Focus {
+			style = paneFocusStyle
+		}
+		label := Truncate("─ "+title(tab)+" ", w)
+		rule := style.Render(label + strings.Repeat("─", max(w-ansi.StringWidth(label), 0)))
+		columns[i] = append([]string{rule}, fit(pane(tab, w, h-1), w, h-1)...)
+	}
+
+	if !s.Vertical {
+		var lines []string
+		for _, c := range columns {
+			lines = append(lines, c...)
+		}
+		return strings.Join(lines, "\n") + "\n"
+	}
+	var b strings.Builder
+	sep := paneTitleStyle.Render("│")
+	for row := range height {
+		for i, c := range columns {
+			if i > 0 {
+				b.WriteString(sep)
+			}
+			b.WriteString(c[row])
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// RenderZoomed draws the current tab alone in a width by height area,
+// what does not fit cut off as in a pane
+func (s *Split) RenderZoomed(width, height int, pane func(width, height int) string) string {
+	return strings.Join(fit(pane(width, height), width, height), "\n") + "\n"
+}
+
+// share divides total between weights, giving every share at least two
+// lines or columns and the rounding remainder to the last
+func share(total int, weights []int) []int {
+	sum := 0
+	for _, w := range weights {
+		sum += w
+	}
+	sizes := make([]int, len(weights))
+	left := total
+	for i, w := range weights {
+		sizes[i] = max(total*w/sum, 2)
+		left -= sizes[i]
+	}
+	sizes[len(sizes)-1] = max(sizes[len(sizes)-1]+left, 2)
+	return sizes
+}
+
+// fit cuts s to exactly height lines of exactly width cells
+func fit(s string, width, height int) []string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	out := make([]string, height)
+	for i := range out {
+		line := ""
+		if i < len(lines) {
+			line = ansi.Truncate(lines[i], width, "")
+		}
+		out[i] = line + strings.Repeat(" ", max(width-ansi.StringWidth(line), 0))
+	}
+	return out
+}
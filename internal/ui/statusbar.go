@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var statusBarStyle, statusSegStyle, statusWarnStyle, statusCritStyle, statusPausedStyle lipgloss.Style
+
+// hostname is read once; the bar is redrawn every tick
+var hostname = sync.OnceValue(func() string {
+	h, err := os.Hostname()
+	if err != nil {
+		return "?"
+	}
+	return h
+})
+
+// StatusBar is the bottom line of a monitor: the time, the host, how many
+// alerts are active, whether updates are paused and where the data comes
+// from, followed by the key hints that fit
+type StatusBar struct {
+	Alerts   int
+	Critical bool   // Some alert is critical, making the count blink
+	Paused   bool   // Updates are stopped
+	ReadOnly bool   // Actions changing the host are refused
+	Source   string // Such as "live", "demo" or "sim"
+	Help     string // Key hints, cut to the width left
+}
+
+// Render draws the bar across width at time now
+func (b StatusBar) Render(width int, now time.Time) string {
+	segs := []string{statusSegStyle.Render(now.Format("15:04:05")), statusSegStyle.Render(hostname())}
+	switch {
+	case b.Alerts == 0:
+		segs = append(segs, statusSegStyle.Render("no alerts"))
+	case b.Critical:
+		segs = append(segs, statusCritStyle.Render(plural(b.Alerts, "alert")))
+	default:
+		segs = append(segs, statusWarnStyle.Render(plural(b.Alerts, "alert")))
+	}
+	if b.Paused {
+		segs = append(segs, statusPausedStyle.Render("PAUSED"))
+	}
+	if b.ReadOnly {
+		segs = append(segs, statusSegStyle.Render("READ-ONLY"))
+	}
+	segs = append(segs, statusSegStyle.Render(strings.ToUpper(b.Source)))
+
+	left := strings.Join(segs, " ")
+	help := ansi.Truncate(" "+b.Help, max(width-ansi.StringWidth(left), 0), "…")
+	return ansi.Truncate(left+statusBarStyle.Width(max(width-ansi.StringWidth(left), 0)).Render(help), width, "")
+}
+
+// plural is n followed by word, with an s unless n is one
+func plural(n int, word string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, word)
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Viewport returns the rows [start, end) of a table of total rows that
+// fit in height lines, scrolled just enough to keep the cursor visible.
+// Tables render only this window so frame time does not grow with the
+// number of rows.
+func Viewport(cursor, total, height int) (start, end int) {
+	height = max(height, 1)
+	if cursor >= height {
+		start = cursor - height + 1
+	}
+	return start, min(start+height, total)
+}
+
+// hscrollStep is how many columns a sideways scroll key moves a table
+const hscrollStep = 8
+
+// ScrollX shows the columns [offset, offset+width) of each line of a
+// table, so one wider than the screen scrolls sideways rather than being
+// cut. The first frozen columns, such as a cursor and an ID, stay in place
+// and an arrow marks a side with more beyond it.
+func ScrollX(table string, frozen, offset, width int) string {
+	lines := strings.Split(table, "\n")
+	view := max(width-frozen, 2)
+	for i, line := range lines {
+		w := ansi.StringWidth(line)
+		if w <= width && offset == 0 {
+			continue
+		}
+		start, end := frozen+offset, frozen+offset+view
+		left, right := "", ""
+		if offset > 0 && w > start {
+			left, start = "‹", start+1
+		}
+		if w > end {
+			right, end = "›", end-1
+		}
+		lines[i] = ansi.Cut(line, 0, frozen) + left + ansi.Cut(line, start, end) + right
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ScrollOffset is the sideways offset of a table after the scroll key
+// msg, given how many columns it has beyond the screen
+func (k NavKeyMap) ScrollOffset(msg tea.KeyMsg, offset, beyond int) int {
+	offset = min(offset, beyond)
+	if key.Matches(msg, k.ScrollLeft) {
+		return max(offset-hscrollStep, 0)
+	}
+	return max(min(offset+hscrollStep, beyond), 0)
+}
+
+// Widest is the width of the longest line of s
+func Widest(s string) int {
+	n := 0
+	for line := range strings.SplitSeq(s, "\n") {
+		n = max(n, ansi.StringWidth(line))
+	}
+	return n
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TabSet is the tabs a monitor's tab bar shows: those the config file
+// enables, in its order, less any hidden this session. Tabs are the
+// monitor's tab indexes; the tab keys select them by position on the bar.
+type TabSet struct {
+	Order  []int // Enabled tabs in bar order
+	Hidden []int
+}
+
+// NewTabSet enables the tabs named by ids, a monitor's tab names as the
+// config file spells them, in the order of names. No names enables every
+// tab in the monitor's order.
+func NewTabSet(ids, names []string) (TabSet, error) {
+	if len(names) == 0 {
+		order := make([]int, len(ids))
+		for i := range order {
+			order[i] = i
+		}
+		return TabSet{Order: order}, nil
+	}
+	var s TabSet
+	for _, name := range names {
+		i := slices.Index(ids, name)
+		switch {
+		case i < 0:
+			return s, fmt.Errorf("unknown tab %q (known: %s)", name, strings.Join(ids, ", "))
+		case slices.Contains(s.Order, i):
+			return s, fmt.Errorf("tab %q listed twice", name)
+		}
+		s.Order = append(s.Order, i)
+	}
+	return s, nil
+}
+
+// Shown lists the tabs on the bar, in order
+func (s TabSet) Shown() []int {
+	return slices.DeleteFunc(slices.Clone(s.Order), func(tab int) bool { return slices.Contains(s.Hidden, tab) })
+}
+
+// At is the tab at position i of the bar, or -1
+func (s TabSet) At(i int) int {
+	if shown := s.Shown(); i >= 0 && i < len(shown) {
+		return shown[i]
+	}
+	return -1
+}
+
+// Position is where tab is on the bar, or -1 when it is not shown
+func (s TabSet) Position(tab int) int {
+	return slices.Index(s.Shown(), tab)
+}
+
+// Next is the tab after tab on the bar, wrapping around. A tab not on
+// the bar is followed by the first one.
+func (s TabSet) Next(tab int) int {
+	shown := s.Shown()
+	return shown[(slices.Index(shown, tab)+1)%len(shown)]
+}
+
+// Hide takes tab off the bar and returns the tab to show instead. The
+// last tab shown stays, so ok is false for it.
+func (s *TabSet) Hide(tab int) (next int, ok bool) {
+	if len(s.Shown()) <= 1 || s.Position(tab) < 0 {
+		return tab, false
+	}
+	next = s.Next(tab)
+	s.Hidden = append(slices.Clone(s.Hidden), tab)
+	return next, true
+}
+
+// Unhide puts every hidden tab back on the bar
+func (s *TabSet) Unhide() {
+	s.Hidden = nil
+}
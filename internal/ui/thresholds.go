@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Threshold colors the bars of a metric by how full they are. A level of
+// 0 is not set: an override leaves it as it was, and a metric without one
+// never reaches it.
+type Threshold struct {
+	Warning       float64 `json:"warning"`  // Percent above which a bar is a warning
+	Critical      float64 `json:"critical"` // Percent above which a bar is critical
+	NormalColor   string  `json:"normal_color"`
+	WarningColor  string  `json:"warning_color"`
+	CriticalColor string  `json:"critical_color"`
+}
+
+// Threshold levels
+const (
+	LevelNormal = iota
+	LevelWarning
+	LevelCritical
+)
+
+// thresholds hold the metrics' bar colors. Bandwidth bars are relative to
+// the peak rate seen and keep the monitor's colors below a level.
+var thresholds = map[string]Threshold{
+	"cpu":       {Critical: 80, NormalColor: "#04B575", WarningColor: "#FBBF24", CriticalColor: "#FF6B6B"},
+	"memory":    {Critical: 80, NormalColor: "#04B575", WarningColor: "#FBBF24", CriticalColor: "#FF6B6B"},
+	"disk":      {Critical: 80, NormalColor: "#04B575", WarningColor: "#FBBF24", CriticalColor: "#FF6B6B"},
+	"bandwidth": {WarningColor: "#FBBF24", CriticalColor: "#FF6B6B"},
+	// Every other bar, such as limits, shares and progress
+	"other": {Critical: 80, NormalColor: "#04B575", WarningColor: "#FBBF24", CriticalColor: "#FF6B6B"},
+}
+
+// defaultThresholds are the thresholds before the config file's
+var defaultThresholds = maps.Clone(thresholds)
+
+// SetThresholds applies the "thresholds" section of the config file. Fields
+// left out keep their defaults, also when a profile switch applies it again.
+func SetThresholds(overrides map[string]Threshold) error {
+	applied := maps.Clone(defaultThresholds)
+	for metric, o := range overrides {
+		t, ok := applied[metric]
+		if !ok {
+			return fmt.Errorf("unknown threshold metric %q (known: %s)", metric, thresholdMetrics())
+		}
+		if o.Warning < 0 || o.Warning > 100 || o.Critical < 0 || o.Critical > 100 {
+			return fmt.Errorf("thresholds.%s: levels are percentages", metric)
+		}
+		t.Warning = orDefault(o.Warning, t.Warning)
+		t.Critical = orDefault(o.Critical, t.Critical)
+		t.NormalColor = orDefault(o.NormalColor, t.NormalColor)
+		t.WarningColor = orDefault(o.WarningColor, t.WarningColor)
+		t.CriticalColor = orDefault(o.CriticalColor, t.CriticalColor)
+		if t.Warning > 0 && t.Critical > 0 && t.Warning > t.Critical {
+			return fmt.Errorf("thresholds.%s: warning %.0f%% is above critical %.0f%%", metric, t.Warning, t.Critical)
+		}
+		applied[metric] = t
+	}
+	thresholds = applied
+	return nil
+}
+
+// orDefault is override unless it is the zero value, then current
+func orDefault[T comparable](override, current T) T {
+	var zero T
+	if override == zero {
+		return current
+	}
+	return override
+}
+
+// thresholdMetrics lists the metric names for error messages
+func thresholdMetrics() string {
+	var names []string
+	for name := range thresholds {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// ThresholdLevel is the level a bar of metric at percent is at
+func ThresholdLevel(metric string, percent float64) int {
+	t := thresholdOf(metric)
+	switch {
+	case t.Critical > 0 && percent > t.Critical:
+		return LevelCritical
+	case t.Warning > 0 && percent > t.Warning:
+		return LevelWarning
+	}
+	return LevelNormal
+}
+
+// ThresholdStyle colors a bar of metric at level. ok is false for a normal
+// bar of a metric without a normal color, which keeps its own.
+func ThresholdStyle(metric string, level int) (style lipgloss.Style, ok bool) {
+	t := thresholdOf(metric)
+	color := t.NormalColor
+	switch level {
+	case LevelWarning:
+		color = t.WarningColor
+	case LevelCritical:
+		color = t.CriticalColor
+	}
+	if color == "" {
+		return lipgloss.NewStyle(), false
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)), true
+}
+
+// thresholdOf is the threshold of metric, falling back to "other"
+func thresholdOf(metric string) Threshold {
+	if t, ok := thresholds[metric]; ok {
+		return t
+	}
+	return thresholds["other"]
+}
@@ -0,0 +1,101 @@
+// Package ui holds the formatting and terminal helpers shared by the
+// advis monitors.
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// FormatBytes renders a byte count with a binary unit suffix
+func FormatBytes(bytes uint64) string {
+	var buf [16]byte
+	return string(AppendBytes(buf[:0], bytes))
+}
+
+// Truncate shortens s to at most n columns, marking the cut with an
+// ellipsis
+func Truncate(s string, n int) string {
+	if Width(s) <= n {
+		return s
+	}
+	return string(AppendTruncated(make([]byte, 0, len(s)), s, n))
+}
+
+// Width is how many terminal columns s takes. CJK characters and most
+// emoji take two and combining marks none, so neither bytes nor runes
+// line up a column holding them.
+func Width(s string) int {
+	for i := range len(s) {
+		if s[i] < ' ' || s[i] > '~' {
+			return ansi.StringWidth(s)
+		}
+	}
+	return len(s)
+}
+
+// Fit is s cut or padded to exactly n columns, the width-aware form of
+// %-*s for cells holding names from the system
+func Fit(s string, n int) string {
+	s = Truncate(s, n)
+	if w := Width(s); w < n {
+		return s + strings.Repeat(" ", n-w)
+	}
+	return s
+}
+
+// FormatValue renders a metric value in its unit. "bytes" and "bytes/s"
+// use binary suffixes and "%" one decimal.
+func FormatValue(v float64, unit string) string {
+	switch unit {
+	case "bytes":
+		return FormatBytes(uint64(max(v, 0)))
+	case "bytes/s":
+		return FormatBytes(uint64(max(v, 0))) + "/s"
+	case "%":
+		return FormatFloat(v, 1) + "%"
+	}
+	s := FormatFloat(v, 0)
+	if v != float64(int64(v)) {
+		s = FormatFloat(v, 2)
+	}
+	if unit != "" {
+		s += " " + unit
+	}
+	return s
+}
+
+// ClipboardMsg reports the outcome of a Copy
+type ClipboardMsg struct {
+	What string
+	Size int
+	Err  error
+}
+
+// Status is the footer line describing the copy
+func (m ClipboardMsg) Status() string {
+	if m.Err != nil {
+		return "Copy failed: " + m.Err.Error()
+	}
+	return fmt.Sprintf("Copied %s (%d bytes) to the clipboard", m.What, m.Size)
+}
+
+// Copy puts text on the system clipboard with an OSC 52 escape, which
+// terminals honor even over SSH; tmux and screen need it wrapped
+func Copy(text, what string) tea.Cmd {
+	return func() tea.Msg {
+		seq := osc52.New(text)
+		if os.Getenv("TMUX") != "" {
+			seq = seq.Tmux()
+		} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+			seq = seq.Screen()
+		}
+		_, err := seq.WriteTo(os.Stderr)
+		return ClipboardMsg{What: what, Size: len(text), Err: err}
+	}
+}
@@ -0,0 +1,351 @@
+// Package watchdog supervises the services the config file's "watchdog"
+// section lists: every interval it checks that a service's process runs
+// and that its health URL answers, and once a service fails its checks
+// failures times in a row it runs the service's recovery command, at most
+// once per cooldown. "advis watchdog" runs the loop without a terminal;
+// the system monitor shows where each service stands on its Watchdog tab
+// and raises its alerts, recovering the services only when asked to.
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/alerting"
+	"github.com/s-archdev/Terminal_ADVIS/internal/audit"
+	"github.com/s-archdev/Terminal_ADVIS/internal/debug"
+	"github.com/s-archdev/Terminal_ADVIS/internal/platform"
+	"github.com/s-archdev/Terminal_ADVIS/internal/tail"
+)
+
+// Service is one entry of the "watchdog" section of the config file
+type Service struct {
+	Name     string   `json:"name"`
+	Process  string   `json:"process"`  // Name of a process that must be running, empty for none
+	URL      string   `json:"url"`      // Must answer a GET with a 2xx status, empty for none
+	Interval string   `json:"interval"` // Between checks, 10s by default
+	Timeout  string   `json:"timeout"`  // Of the URL check, 5s by default
+	Failures int      `json:"failures"` // Failed checks in a row that make the service down, 3 by default
+	Recover  []string `json:"recover"`  // Command and arguments run once the service is down, none by default
+	Cooldown string   `json:"cooldown"` // Between two recoveries, 5m by default
+}
+
+// The defaults of what a service leaves out
+const (
+	defaultInterval = 10 * time.Second
+	defaultTimeout  = 5 * time.Second
+	defaultFailures = 3
+	defaultCooldown = 5 * time.Minute
+)
+
+// recoverTimeout bounds a recovery command
+const recoverTimeout = time.Minute
+
+// Status is where a service stands after its last check
+type Status struct {
+	Service
+	Checked    time.Time
+	Running    int           // Processes called Process
+	HTTPStatus int           // Of the last URL check, 0 when it got no answer
+	Latency    time.Duration // Of the last URL check
+	Err        error         // Why the last check failed, nil when it passed
+	Failures   int           // Failed checks in a row
+	Down       bool          // Failures reached the service's limit
+	Since      time.Time     // When the service went down, or came back up
+
+	Recoveries  int       // Commands run since advis started
+	Recovered   time.Time // When the last one ran
+	RecoverErr  error
+	RecoverOut  string // Its output, the end of it
+	AuditErr    error  // Of writing it to the audit log
+	Recovering  bool   // The command is running
+	RecoverSkip string // Why the command was not run, such as read-only
+}
+
+// service is a service as configured, with its state
+type service struct {
+	Status
+	interval, timeout, cooldown time.Duration
+	next                        time.Time
+}
+
+// Supervisor checks the services in the background until stopped
+type Supervisor struct {
+	mu        sync.Mutex
+	services  []*service
+	noRecover string // Why the recovery commands are not run, empty to run them
+	client    *http.Client
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// Check validates the services of the config file
+func Check(services []Service) error {
+	_, err := parse(services)
+	return err
+}
+
+func parse(services []Service) ([]*service, error) {
+	var parsed []*service
+	names := make(map[string]bool)
+	for i, s := range services {
+		if s.Name == "" {
+			return nil, fmt.Errorf("watchdog[%d]: no name", i)
+		}
+		if names[s.Name] {
+			return nil, fmt.Errorf("watchdog: %q listed twice", s.Name)
+		}
+		names[s.Name] = true
+		if s.Process == "" && s.URL == "" {
+			return nil, fmt.Errorf("watchdog.%s: neither process nor url to check", s.Name)
+		}
+		if s.URL != "" && !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://") {
+			return nil, fmt.Errorf("watchdog.%s: url %q is not http:// or https://", s.Name, s.URL)
+		}
+		if s.Failures < 0 {
+			return nil, fmt.Errorf("watchdog.%s: failures %d is negative", s.Name, s.Failures)
+		}
+		if s.Failures == 0 {
+			s.Failures = defaultFailures
+		}
+		p := &service{Status: Status{Service: s}}
+		for _, d := range []struct {
+			name, s string
+			to      *time.Duration
+			def     time.Duration
+		}{
+			{"interval", s.Interval, &p.interval, defaultInterval},
+			{"timeout", s.Timeout, &p.timeout, defaultTimeout},
+			{"cooldown", s.Cooldown, &p.cooldown, defaultCooldown},
+		} {
+			*d.to = d.def
+			if d.s == "" {
+				continue
+			}
+			v, err := time.ParseDuration(d.s)
+			if err != nil || v <= 0 {
+				return nil, fmt.Errorf("watchdog.%s: invalid %s %q", s.Name, d.name, d.s)
+			}
+			*d.to = v
+		}
+		parsed = append(parsed, p)
+	}
+	return parsed, nil
+}
+
+// Start begins checking services, each on its own interval. Given why
+// not, such as "read-only", the supervisor never runs the recovery
+// commands, showing that reason instead.
+func Start(services []Service, noRecover string) (*Supervisor, error) {
+	parsed, err := parse(services)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Supervisor{
+		services:  parsed,
+		noRecover: noRecover,
+		client: &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse // A redirect is an answer, such as to a login page
+		}},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go s.run(ctx)
+	return s, nil
+}
+
+// Stop stops checking, leaving a running recovery command to finish
+func (s *Supervisor) Stop() {
+	s.cancel()
+	<-s.done
+}
+
+// Statuses returns where every service stands, in the config file's order
+func (s *Supervisor) Statuses() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]Status, len(s.services))
+	for i, p := range s.services {
+		statuses[i] = p.Status
+	}
+	return statuses
+}
+
+// run checks the services that are due every second
+func (s *Supervisor) run(ctx context.Context) {
+	defer close(s.done)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		s.checkDue(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkDue checks the services due at now, reading the process table once
+// for all of them
+func (s *Supervisor) checkDue(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	var due []*service
+	for _, p := range s.services {
+		if !now.Before(p.next) {
+			p.next = now.Add(p.interval)
+			due = append(due, p)
+		}
+	}
+	s.mu.Unlock()
+	if len(due) == 0 {
+		return
+	}
+
+	var running map[string]int
+	var procErr error
+	if slices.ContainsFunc(due, func(p *service) bool { return p.Process != "" }) {
+		procs, err := platform.Processes(ctx)
+		running, procErr = make(map[string]int), err
+		for _, p := range procs {
+			running[p.Name]++
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, p := range due {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var r result
+			if p.Process != "" {
+				r.running, r.err = running[p.Process], procErr
+				if r.err == nil && r.running == 0 {
+					r.err = fmt.Errorf("%s is not running", p.Process)
+				}
+			}
+			if p.URL != "" && r.err == nil {
+				r.status, r.latency, r.err = s.get(ctx, p.URL, p.timeout)
+			}
+			if ctx.Err() == nil {
+				s.record(p, now, r)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// result is the outcome of one check of a service
+type result struct {
+	running int
+	status  int
+	latency time.Duration
+	err     error
+}
+
+// get checks a health URL
+func (s *Supervisor) get(ctx context.Context, url string, timeout time.Duration) (int, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("User-Agent", "advis-watchdog")
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	latency := time.Since(start)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, latency, fmt.Errorf("no answer within %v", timeout)
+		}
+		return 0, latency, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, latency, fmt.Errorf("%s answered %s", url, resp.Status)
+	}
+	return resp.StatusCode, latency, nil
+}
+
+// record moves a service on by the result of a check, recovering it once
+// it is down
+func (s *Supervisor) record(p *service, now time.Time, r result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.Checked, p.Running, p.HTTPStatus, p.Latency, p.Err = now, r.running, r.status, r.latency, r.err
+	if r.err == nil {
+		if p.Down {
+			p.Down, p.Since = false, now
+			debug.Logf("watchdog: %s is up again", p.Name)
+		}
+		p.Failures, p.RecoverSkip = 0, ""
+		return
+	}
+	p.Failures++
+	if p.Failures < p.Service.Failures {
+		return
+	}
+	if !p.Down {
+		p.Down, p.Since = true, now
+		debug.Logf("watchdog: %s is down: %v", p.Name, r.err)
+	}
+
+	switch {
+	case len(p.Recover) == 0 || p.Recovering:
+	case s.noRecover != "":
+		p.RecoverSkip = s.noRecover
+	case !p.Recovered.IsZero() && now.Sub(p.Recovered) < p.cooldown:
+		p.RecoverSkip = fmt.Sprintf("cooling down until %s", p.Recovered.Add(p.cooldown).Format("15:04:05"))
+	default:
+		p.Recovering, p.Recovered, p.RecoverSkip = true, now, ""
+		p.Recoveries++
+		go s.recover(p, r.err)
+	}
+}
+
+// recover runs the recovery command of a service that is down because of
+// cause
+func (s *Supervisor) recover(p *service, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), recoverTimeout)
+	defer cancel()
+	var out tail.Buffer
+	cmd := exec.CommandContext(ctx, p.Recover[0], p.Recover[1:]...)
+	cmd.Env = append(os.Environ(), "ADVIS_SERVICE="+p.Name, "ADVIS_CAUSE="+cause.Error())
+	cmd.Stdout, cmd.Stderr = &out, &out
+	cmd.WaitDelay = 2 * time.Second
+	start := time.Now()
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		err = fmt.Errorf("timed out after %v", recoverTimeout)
+	}
+	debug.Timing("watchdog: recovery of "+p.Name, start, err)
+
+	outcome := "done"
+	fields := map[string]string{"action": "recover", "target": p.Name, "command": strings.Join(p.Recover, " "), "cause": cause.Error()}
+	if err != nil {
+		outcome, fields["error"] = "failed", err.Error()
+	}
+	fields["outcome"] = outcome
+	alerting.Deliver([]alerting.Event{{Time: time.Now(), Kind: alerting.Action, Source: "watchdog",
+		Message: fmt.Sprintf("recover %s: %s", p.Name, outcome), Fields: fields}})
+	// The command changes the host, unattended as often as not, so it is
+	// logged with the actions taken from the monitors
+	auditErr := audit.Append(audit.New(time.Now(), "recover service", p.Name, err))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.Recovering, p.RecoverErr, p.RecoverOut, p.AuditErr = false, err, out.String(), auditErr
+	// Check again soon, rather than a full interval after the restart
+	p.next = time.Now().Add(min(p.interval, 2*time.Second))
+}
@@ -0,0 +1,57 @@
+package netstat
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Snapshot is the network state at one instant. Rates are the difference
+// between two snapshots divided by the time between them.
+type Snapshot struct {
+	Time        time.Time
+	Interfaces  []Counter
+	Connections []Connection
+	// ConnectionsRead tells a socket table read empty from one left
+	// unread, as when skipped or out of time
+	ConnectionsRead bool
+	Protocols       *Protocols // nil where the kernel's protocol counters are not read
+}
+
+// Collector is a source of snapshots
+type Collector interface {
+	Collect(ctx context.Context) (Snapshot, error)
+}
+
+// ConnectionSkipper is implemented by collectors whose socket table is
+// expensive enough to leave out while nobody looks at it
+type ConnectionSkipper interface {
+	SkipConnections(skip bool)
+}
+
+// System collects from the local kernel. The zero value is ready to use.
+type System struct {
+	skip atomic.Bool
+}
+
+// SkipConnections stops or resumes reading the socket table, which on a
+// busy server holds hundreds of thousands of entries. It may be called
+// while a Collect is running.
+func (s *System) SkipConnections(skip bool) {
+	s.skip.Store(skip)
+}
+
+// Collect reads the interface and protocol counters and, unless skipped,
+// the socket table
+func (s *System) Collect(ctx context.Context) (Snapshot, error) {
+	snap := Snapshot{Time: time.Now(), Interfaces: Interfaces()}
+	if p, ok := ProtocolCounters(); ok {
+		snap.Protocols = &p
+	}
+	if err := ctx.Err(); err != nil || s.skip.Load() {
+		return snap, err
+	}
+	conns, err := Connections()
+	snap.Connections, snap.ConnectionsRead = conns, err == nil
+	return snap, err
+}
@@ -0,0 +1,13 @@
+package netstat
+
+import "github.com/s-archdev/Terminal_ADVIS/internal/platform"
+
+// Connection is one socket from the kernel's TCP or UDP table
+type Connection = platform.Connection
+
+// Connections reads every TCP and UDP socket the host can list. Tables
+// that are missing, such as tcp6 with IPv6 disabled, are skipped; an error
+// is only returned when none could be read.
+func Connections() ([]Connection, error) {
+	return platform.Connections()
+}
@@ -0,0 +1,24 @@
+// Package netstat reads network interface counters and the socket table:
+// from /proc on Linux, from the routing sysctl and netstat on macOS and
+// the BSDs, and from the IP Helper tables on Windows.
+package netstat
+
+import "github.com/s-archdev/Terminal_ADVIS/internal/platform"
+
+// Counter is the cumulative traffic of a network interface
+type Counter = platform.Counter
+
+// Interfaces reads the counters of every interface, nil when none could
+// be read
+func Interfaces() []Counter {
+	return platform.Interfaces()
+}
+
+// Protocols are the kernel's cumulative ICMP, TCP and UDP counters
+type Protocols = platform.Protocols
+
+// ProtocolCounters reads the protocol counters, reporting false where
+// they are not kept, as everywhere but Linux
+func ProtocolCounters() (Protocols, bool) {
+	return platform.ProtocolCounters()
+}
@@ -0,0 +1,125 @@
+// Package proc scans the processes of the host, from /proc on Linux,
+// sysctl and libproc on macOS, libkvm on FreeBSD and OpenBSD and Toolhelp
+// snapshots on Windows, turning the cumulative CPU and I/O counters the
+// kernel keeps into rates between successive scans.
+package proc
+
+import (
+	"context"
+	"os/user"
+	"strconv"
+	"time"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/platform"
+)
+
+// Process is one process as of the last scan
+type Process struct {
+	PID        int
+	PPID       int
+	Name       string
+	Cmdline    string // Arguments joined by spaces, empty for kernel threads
+	State      string
+	Memory     uint64  // Resident set size in bytes
+	CPU        float64 // Percent of one core
+	ReadBytes  uint64  // Cumulative bytes read from storage
+	WriteBytes uint64  // Cumulative bytes written to storage
+	ReadRate   float64 // Bytes per second
+	WriteRate  float64 // Bytes per second
+	HasIO      bool    // The I/O counters were readable
+	UID        uint32
+	User       string
+	FDs        int    // Open file descriptors, -1 when not readable
+	FDLimit    uint64 // Soft RLIMIT_NOFILE, 0 when unknown or unlimited
+	OOMScore   int    // Kernel badness score, higher is killed first; Linux only
+	OOMAdj     int    // oom_score_adj, -1000 (never) to 1000; Linux only
+}
+
+// counters are the cumulative counters of a process at the last scan
+type counters struct {
+	at         time.Time     // When they were read
+	cpu        time.Duration // User and system time
+	readBytes  uint64
+	writeBytes uint64
+}
+
+// Sampler turns cumulative process counters into per-second rates. Each
+// process's counters are divided by the time between its own two reads,
+// so a slow scan or a late tick does not skew the rates. The zero value
+// is ready to use; the first Sample reports no rates.
+type Sampler struct {
+	prev  map[int]counters
+	users map[uint32]string // Cached user names by UID
+}
+
+// Sample scans the processes and returns every one with CPU and I/O rates
+// computed against the previous scan
+func (s *Sampler) Sample() []Process {
+	procs, _ := s.scan(context.Background())
+	return procs
+}
+
+// scan is Sample, giving up when ctx is done. An abandoned scan leaves the
+// previous counters in place so the next one still reports rates.
+func (s *Sampler) scan(ctx context.Context) ([]Process, error) {
+	read, err := platform.Processes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	procs := make([]Process, 0, len(read))
+	scanned := make(map[int]counters, len(read))
+	for _, p := range read {
+		proc := Process{
+			PID: p.PID, PPID: p.PPID, Name: p.Name, Cmdline: p.Cmdline, State: p.State,
+			Memory: p.Memory, ReadBytes: p.ReadBytes, WriteBytes: p.WriteBytes, HasIO: p.HasIO,
+			UID: p.UID, User: p.User, FDs: p.FDs, FDLimit: p.FDLimit, OOMScore: p.OOMScore, OOMAdj: p.OOMAdj,
+		}
+		c := counters{at: p.At, cpu: p.CPUTime, readBytes: p.ReadBytes, writeBytes: p.WriteBytes}
+		if proc.User == "" { // Windows names the owner itself
+			proc.User = s.userName(proc.UID)
+		}
+		if prev, seen := s.prev[proc.PID]; seen && c.at.After(prev.at) {
+			elapsed := c.at.Sub(prev.at).Seconds()
+			if c.cpu >= prev.cpu {
+				proc.CPU = (c.cpu - prev.cpu).Seconds() / elapsed * 100
+			}
+			if proc.HasIO && c.readBytes >= prev.readBytes && c.writeBytes >= prev.writeBytes {
+				proc.ReadRate = float64(c.readBytes-prev.readBytes) / elapsed
+				proc.WriteRate = float64(c.writeBytes-prev.writeBytes) / elapsed
+			}
+		}
+		scanned[proc.PID] = c
+		procs = append(procs, proc)
+	}
+
+	s.prev = scanned
+	return procs, nil
+}
+
+// userName resolves a UID to a user name, falling back to the number
+func (s *Sampler) userName(uid uint32) string {
+	if name, ok := s.users[uid]; ok {
+		return name
+	}
+	if s.users == nil {
+		s.users = make(map[uint32]string)
+	}
+	id := strconv.FormatUint(uint64(uid), 10)
+	name := id
+	if u, err := user.LookupId(id); err == nil {
+		name = u.Username
+	}
+	s.users[uid] = name
+	return name
+}
+
+// Collector is a source of process lists
+type Collector interface {
+	Collect(ctx context.Context) ([]Process, error)
+}
+
+// Collect implements Collector with a fresh scan
+func (s *Sampler) Collect(ctx context.Context) ([]Process, error) {
+	return s.scan(ctx)
+}
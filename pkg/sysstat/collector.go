@@ -0,0 +1,39 @@
+package sysstat
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Snapshot is the host state at one instant
+type Snapshot struct {
+	Time   time.Time
+	Info   Info
+	CPU    float64   // Overall utilization in percent since the previous snapshot
+	Cores  []float64 // Utilization of each logical CPU, indexed by CPU number
+	Mounts []Disk
+}
+
+// Collector is a source of snapshots
+type Collector interface {
+	Collect(ctx context.Context) (Snapshot, error)
+}
+
+// System collects from the local kernel. The zero value is ready to use.
+type System struct {
+	cpu CPUSampler
+}
+
+// Collect reads memory, load, CPU utilization and mounted filesystems.
+// Filesystems that do not answer before ctx is done are returned marked
+// Hung. Every figure that could not be read is named in the returned
+// error; the rest of the snapshot is still valid.
+func (s *System) Collect(ctx context.Context) (Snapshot, error) {
+	snap := Snapshot{Time: time.Now()}
+	var infoErr, cpuErr, mountsErr error
+	snap.Info, infoErr = readInfo()
+	snap.CPU, snap.Cores, cpuErr = s.cpu.sample()
+	snap.Mounts, mountsErr = MountsContext(ctx)
+	return snap, errors.Join(infoErr, cpuErr, mountsErr)
+}
@@ -0,0 +1,48 @@
+package sysstat
+
+import (
+	"strconv"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/platform"
+)
+
+// CPUSampler computes per-CPU utilization from the busy and idle time
+// the kernel counts for each CPU. The zero value is ready to use.
+type CPUSampler struct {
+	prev map[string]platform.CPUTicks
+}
+
+// Sample returns overall and per-CPU utilization since the last call;
+// the first call reports usage since boot
+func (s *CPUSampler) Sample() (float64, []float64) {
+	total, cores, _ := s.sample()
+	return total, cores
+}
+
+// sample is Sample also reporting why the CPU times could not be read
+func (s *CPUSampler) sample() (float64, []float64, error) {
+	times, err := platform.CPUTimes()
+	if err != nil {
+		return 0, nil, err
+	}
+	var total float64
+	var cores []float64
+	for name, t := range times {
+		usage := 0.0
+		prev := s.prev[name]
+		if t.Total > prev.Total && t.Busy >= prev.Busy {
+			usage = float64(t.Busy-prev.Busy) / float64(t.Total-prev.Total) * 100
+		}
+		if name == "cpu" {
+			total = usage
+		} else if id, err := strconv.Atoi(name[3:]); err == nil {
+			// Offline CPUs have no times, so index by number
+			for len(cores) <= id {
+				cores = append(cores, 0)
+			}
+			cores[id] = usage
+		}
+	}
+	s.prev = times
+	return total, cores, nil
+}
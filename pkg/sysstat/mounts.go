@@ -0,0 +1,123 @@
+package sysstat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/platform"
+)
+
+// Disk is the usage of one mounted filesystem
+type Disk struct {
+	Total  uint64
+	Used   uint64
+	Free   uint64
+	Path   string
+	Device string
+	FSType string
+	Hung   bool // statfs did not return in time, usage is unknown
+}
+
+// DiskUsage returns the usage of the filesystem holding path
+func DiskUsage(path string) Disk {
+	d, _ := diskUsage(path)
+	return d
+}
+
+// diskUsage is DiskUsage also returning why the usage could not be read
+func diskUsage(path string) (Disk, error) {
+	u, err := platform.DiskUsage(path)
+	if err != nil {
+		return Disk{Path: path}, err
+	}
+	return Disk{Total: u.Total, Used: u.Total - min(u.Free, u.Total), Free: u.Free, Path: path}, nil
+}
+
+// Mounts returns usage for every real filesystem of the mount table,
+// skipping pseudo filesystems and duplicate bind mounts of the same device
+func Mounts() []Disk {
+	mounts, _ := MountsContext(context.Background())
+	return mounts
+}
+
+// statfsPending holds the paths whose statfs has not returned yet. A hung
+// NFS or FUSE mount can block statfs indefinitely; such a path is reported
+// as Hung instead of being queried again until the first call returns.
+var statfsPending = struct {
+	sync.Mutex
+	paths map[string]bool
+}{paths: make(map[string]bool)}
+
+// MountsContext is Mounts querying every filesystem concurrently. Those
+// still outstanding when ctx is done are returned marked Hung, with an
+// error naming them.
+func MountsContext(ctx context.Context) ([]Disk, error) {
+	table, err := platform.Mounts()
+	if err != nil {
+		// Fall back to the root filesystem only
+		root, rootErr := diskUsage("/")
+		return []Disk{root}, errors.Join(err, rootErr)
+	}
+
+	mounts := make([]Disk, len(table))
+	for i, m := range table {
+		// Hung until its statfs returns
+		mounts[i] = Disk{Path: m.Path, Device: m.Device, FSType: m.FSType, Hung: true}
+	}
+
+	type result struct {
+		index int
+		usage Disk
+		err   error
+	}
+	results := make(chan result, len(mounts))
+	outstanding := 0
+	statfsPending.Lock()
+	for i, d := range mounts {
+		if statfsPending.paths[d.Path] {
+			continue
+		}
+		statfsPending.paths[d.Path] = true
+		outstanding++
+		go func(i int, path string) {
+			usage, err := diskUsage(path)
+			statfsPending.Lock()
+			delete(statfsPending.paths, path)
+			statfsPending.Unlock()
+			results <- result{i, usage, err}
+		}(i, d.Path)
+	}
+	statfsPending.Unlock()
+
+	var errs []error
+wait:
+	for ; outstanding > 0; outstanding-- {
+		select {
+		case r := <-results:
+			d := &mounts[r.index]
+			d.Total, d.Used, d.Free, d.Hung = r.usage.Total, r.usage.Used, r.usage.Free, false
+			errs = append(errs, r.err)
+		case <-ctx.Done():
+			break wait
+		}
+	}
+
+	var hung []string
+	for _, d := range mounts {
+		if d.Hung {
+			hung = append(hung, d.Path)
+		}
+	}
+	if len(hung) > 0 {
+		errs = append(errs, fmt.Errorf("statfs did not return for %s", strings.Join(hung, ", ")))
+	}
+
+	sort.Slice(mounts, func(i, j int) bool {
+		return mounts[i].Path < mounts[j].Path
+	})
+	return mounts, errors.Join(errs...)
+}
@@ -0,0 +1,82 @@
+// Package sysstat reads host-wide CPU, memory, load and filesystem
+// statistics: from /proc and statfs on Linux, from sysctl and getfsstat
+// on macOS, FreeBSD and OpenBSD, with the Mach host statistics on macOS,
+// and from GlobalMemoryStatusEx, PDH counters and the drive letters on
+// Windows.
+package sysstat
+
+import (
+	"errors"
+	"runtime"
+
+	"github.com/s-archdev/Terminal_ADVIS/internal/platform"
+)
+
+// Info is a point-in-time view of the host
+type Info struct {
+	OS          string
+	Arch        string
+	CPUs        int
+	Goroutines  int
+	MemTotal    uint64
+	MemUsed     uint64
+	MemFree     uint64
+	LoadAverage float64
+	FilesOpen   uint64 // Allocated file handles
+	FilesMax    uint64 // System-wide file handle limit
+	Entropy     uint64 // Bits in the kernel entropy pool, 0 where none is exposed
+	EntropyPool uint64 // Pool size in bits
+}
+
+// ReadInfo samples the host memory, load, file handle and entropy figures
+func ReadInfo() Info {
+	info, _ := readInfo()
+	return info
+}
+
+// readInfo is ReadInfo also returning why a figure could not be read
+func readInfo() (Info, error) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	info := Info{
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		CPUs:       runtime.NumCPU(),
+		Goroutines: runtime.NumGoroutine(),
+		MemTotal:   m.Sys,
+		MemUsed:    m.Alloc,
+		MemFree:    m.Sys - m.Alloc,
+	}
+	var loadErr, memErr, filesErr error
+	info.LoadAverage, loadErr = platform.LoadAverage()
+	// Prefer host memory over the Go runtime's own heap figures
+	mem, memErr := platform.Meminfo()
+	if mem["MemTotal"] > 0 {
+		info.MemTotal = mem["MemTotal"]
+		info.MemFree = mem["MemAvailable"]
+		info.MemUsed = info.MemTotal - min(info.MemFree, info.MemTotal)
+	}
+	info.FilesOpen, info.FilesMax, filesErr = platform.FileHandles()
+	info.Entropy, info.EntropyPool = platform.Entropy()
+	return info, errors.Join(loadErr, memErr, filesErr)
+}
+
+// ReadMeminfo returns the /proc/meminfo fields in bytes. Elsewhere it
+// holds the MemTotal and MemAvailable figures the host can tell.
+func ReadMeminfo() map[string]uint64 {
+	info, _ := platform.Meminfo()
+	return info
+}
+
+// FileHandles returns the allocated and maximum file handles of the host
+func FileHandles() (open, limit uint64) {
+	open, limit, _ = platform.FileHandles()
+	return open, limit
+}
+
+// LoadAverage returns the one-minute load average
+func LoadAverage() float64 {
+	load, _ := platform.LoadAverage()
+	return load
+}